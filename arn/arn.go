@@ -0,0 +1,186 @@
+// Package arn parses, validates and builds Amazon Resource Names:
+//
+//	a, err := arn.Parse("arn:aws:ecs:us-east-1:123456789012:service/web/api")
+//	// a.Service == "ecs", a.ResourceType == "service", a.ResourceID == "web/api", a.Name() == "api"
+//
+//	role := arn.IAMRole("123456789012", "/ops/", "deployer").String()
+//	// arn:aws:iam::123456789012:role/ops/deployer
+//
+// It knows the resource formats of the services wrapped by the clients
+// package and checks ARNs of those services against them.
+package arn
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws/endpoints"
+)
+
+// ARN is the parsed form of
+//
+//	arn:partition:service:region:account-id:resource-type/resource-id
+//
+// where the resource type is followed by "/" or ":" depending on the
+// service. Resources without a type, such as S3 buckets and SQS queues,
+// get the type of their format ("bucket", "queue").
+type ARN struct {
+	Partition    string
+	Service      string
+	Region       string
+	AccountID    string
+	ResourceType string
+	ResourceID   string
+	// sep is the separator parsed between the type and the ID of a
+	// resource whose format is unknown.
+	sep string
+}
+
+var (
+	partitionPattern = regexp.MustCompile(`^aws(-[a-z]+)*$`)
+	servicePattern   = regexp.MustCompile(`^[a-z0-9-]+$`)
+	regionPattern    = regexp.MustCompile(`^[a-z]{2}(-[a-z]+)+-\d+$`)
+	accountPattern   = regexp.MustCompile(`^(\d{12}|aws)$`)
+)
+
+// IsARN reports whether s has the shape of an ARN, without validating it.
+func IsARN(s string) bool {
+	return strings.HasPrefix(s, "arn:") && strings.Count(s, ":") >= 5
+}
+
+// Parse splits and validates an ARN. Resources of the types this package
+// knows must match their format; others are split at their first "/" or
+// ":".
+func Parse(s string) (ARN, error) {
+	parts := strings.SplitN(s, ":", 6)
+	if len(parts) != 6 || parts[0] != "arn" {
+		return ARN{}, fmt.Errorf("arn: %q: not an ARN", s)
+	}
+
+	a := ARN{Partition: parts[1], Service: parts[2], Region: parts[3], AccountID: parts[4]}
+	resource := parts[5]
+
+	switch {
+	case !partitionPattern.MatchString(a.Partition):
+		return ARN{}, fmt.Errorf("arn: %q: invalid partition %q", s, a.Partition)
+	case !servicePattern.MatchString(a.Service):
+		return ARN{}, fmt.Errorf("arn: %q: invalid service %q", s, a.Service)
+	case a.Region != "" && !regionPattern.MatchString(a.Region):
+		return ARN{}, fmt.Errorf("arn: %q: invalid region %q", s, a.Region)
+	case a.AccountID != "" && !accountPattern.MatchString(a.AccountID):
+		return ARN{}, fmt.Errorf("arn: %q: invalid account ID %q", s, a.AccountID)
+	case resource == "":
+		return ARN{}, fmt.Errorf("arn: %q: missing resource", s)
+	}
+
+	if f, id, ok := match(a, resource); ok {
+		a.ResourceType, a.ResourceID = f.typ, id
+
+		return a, nil
+	}
+
+	if i := strings.IndexAny(resource, "/:"); i >= 0 {
+		a.ResourceType, a.sep, a.ResourceID = resource[:i], resource[i:i+1], resource[i+1:]
+	} else {
+		a.ResourceType = resource
+	}
+
+	if lookup(a.Service, a.ResourceType) != nil {
+		return ARN{}, fmt.Errorf("arn: %q: invalid %s %s resource %q", s, a.Service, a.ResourceType, resource)
+	}
+
+	return a, nil
+}
+
+// Resource returns the resource part of the ARN, such as "role/ops/deployer".
+func (a ARN) Resource() string {
+	if f := lookup(a.Service, a.ResourceType); f != nil {
+		if f.sep == "" {
+			return a.ResourceID
+		}
+
+		return f.typ + f.sep + a.ResourceID
+	}
+
+	if a.ResourceID == "" {
+		return a.ResourceType
+	}
+
+	sep := a.sep
+	if sep == "" {
+		sep = "/"
+	}
+
+	return a.ResourceType + sep + a.ResourceID
+}
+
+func (a ARN) String() string {
+	return "arn:" + a.Partition + ":" + a.Service + ":" + a.Region + ":" + a.AccountID + ":" + a.Resource()
+}
+
+// Name returns the name or ID that the APIs of the service take in place of
+// the ARN, such as the role name of an IAM role or the queue name of an SQS
+// queue, and "" when they take none.
+func (a ARN) Name() string {
+	var (
+		f *format
+		m []string
+	)
+
+	for _, candidate := range formats[a.Service] {
+		if candidate.typ == a.ResourceType {
+			if m = candidate.id.FindStringSubmatch(a.ResourceID); m != nil {
+				f = candidate
+
+				break
+			}
+		}
+	}
+
+	if f == nil {
+		return ""
+	}
+
+	name := ""
+	if i := f.id.SubexpIndex("name"); i >= 0 {
+		name = m[i]
+	}
+
+	if f.name != nil && name != "" {
+		name = f.name(name)
+	}
+
+	return name
+}
+
+// ResourceName returns the name of the resource when s is an ARN of
+// resourceType in service, and s unchanged otherwise. The account and region
+// of the ARN are dropped, so callers taking ARNs where the API takes names
+// must check them first.
+func ResourceName(s, service, resourceType string) string {
+	if !IsARN(s) {
+		return s
+	}
+
+	a, err := Parse(s)
+	if err != nil || a.Service != service || a.ResourceType != resourceType {
+		return s
+	}
+
+	if name := a.Name(); name != "" {
+		return name
+	}
+
+	return s
+}
+
+// PartitionForRegion returns the partition of region, "aws" for regions the
+// SDK does not know.
+func PartitionForRegion(region string) string {
+	if p, ok := endpoints.PartitionForRegion(endpoints.DefaultPartitions(), region); ok {
+		return p.ID()
+	}
+
+	return endpoints.AwsPartitionID
+}
@@ -0,0 +1,222 @@
+package arn_test
+
+import (
+	"testing"
+
+	"github.com/mwlng/aws-go-clients/arn"
+)
+
+func TestParse(t *testing.T) {
+	for _, tt := range []struct {
+		in   string
+		want arn.ARN
+		name string
+	}{
+		{
+			"arn:aws:iam::123456789012:role/ops/deployer",
+			arn.ARN{Partition: "aws", Service: "iam", AccountID: "123456789012", ResourceType: "role", ResourceID: "ops/deployer"},
+			"deployer",
+		},
+		{
+			"arn:aws:ecs:us-east-1:123456789012:service/web/api",
+			arn.ARN{Partition: "aws", Service: "ecs", Region: "us-east-1", AccountID: "123456789012", ResourceType: "service", ResourceID: "web/api"},
+			"api",
+		},
+		{
+			"arn:aws:ecs:us-east-1:123456789012:task-definition/web:12",
+			arn.ARN{Partition: "aws", Service: "ecs", Region: "us-east-1", AccountID: "123456789012", ResourceType: "task-definition", ResourceID: "web:12"},
+			"web:12",
+		},
+		{
+			"arn:aws:rds:eu-west-1:123456789012:snapshot:rds:orders-2021-01-01",
+			arn.ARN{Partition: "aws", Service: "rds", Region: "eu-west-1", AccountID: "123456789012", ResourceType: "snapshot", ResourceID: "rds:orders-2021-01-01"},
+			"rds:orders-2021-01-01",
+		},
+		{
+			"arn:aws:lambda:us-east-1:123456789012:function:resize:live",
+			arn.ARN{Partition: "aws", Service: "lambda", Region: "us-east-1", AccountID: "123456789012", ResourceType: "function", ResourceID: "resize:live"},
+			"resize",
+		},
+		{
+			"arn:aws:autoscaling:us-east-1:123456789012:autoScalingGroup:0f1e2d3c-aaaa-bbbb-cccc-123456789abc:autoScalingGroupName/web-asg",
+			arn.ARN{
+				Partition: "aws", Service: "autoscaling", Region: "us-east-1", AccountID: "123456789012",
+				ResourceType: "autoScalingGroup", ResourceID: "0f1e2d3c-aaaa-bbbb-cccc-123456789abc:autoScalingGroupName/web-asg",
+			},
+			"web-asg",
+		},
+		{
+			"arn:aws:ssm:us-east-1:123456789012:parameter/app/db/password",
+			arn.ARN{Partition: "aws", Service: "ssm", Region: "us-east-1", AccountID: "123456789012", ResourceType: "parameter", ResourceID: "app/db/password"},
+			"/app/db/password",
+		},
+		{
+			"arn:aws:secretsmanager:us-east-1:123456789012:secret:prod/db-AbC123",
+			arn.ARN{Partition: "aws", Service: "secretsmanager", Region: "us-east-1", AccountID: "123456789012", ResourceType: "secret", ResourceID: "prod/db-AbC123"},
+			"prod/db",
+		},
+		{
+			"arn:aws:dynamodb:us-east-1:123456789012:table/orders/stream/2021-01-01T00:00:00.000",
+			arn.ARN{Partition: "aws", Service: "dynamodb", Region: "us-east-1", AccountID: "123456789012", ResourceType: "table", ResourceID: "orders/stream/2021-01-01T00:00:00.000"},
+			"",
+		},
+		{
+			"arn:aws:s3:::logs.example.com",
+			arn.ARN{Partition: "aws", Service: "s3", ResourceType: "bucket", ResourceID: "logs.example.com"},
+			"logs.example.com",
+		},
+		{
+			"arn:aws:s3:::logs/2021/01/01/app.log",
+			arn.ARN{Partition: "aws", Service: "s3", ResourceType: "object", ResourceID: "logs/2021/01/01/app.log"},
+			"",
+		},
+		{
+			"arn:aws:sqs:us-east-1:123456789012:orders.fifo",
+			arn.ARN{Partition: "aws", Service: "sqs", Region: "us-east-1", AccountID: "123456789012", ResourceType: "queue", ResourceID: "orders.fifo"},
+			"orders.fifo",
+		},
+		{
+			"arn:aws:ec2:us-east-1::image/ami-0abc123",
+			arn.ARN{Partition: "aws", Service: "ec2", Region: "us-east-1", ResourceType: "image", ResourceID: "ami-0abc123"},
+			"ami-0abc123",
+		},
+		{
+			"arn:aws-cn:ec2:cn-north-1:123456789012:instance/i-0abc123",
+			arn.ARN{Partition: "aws-cn", Service: "ec2", Region: "cn-north-1", AccountID: "123456789012", ResourceType: "instance", ResourceID: "i-0abc123"},
+			"i-0abc123",
+		},
+		{
+			"arn:aws:iam::aws:policy/ReadOnlyAccess",
+			arn.ARN{Partition: "aws", Service: "iam", AccountID: "aws", ResourceType: "policy", ResourceID: "ReadOnlyAccess"},
+			"ReadOnlyAccess",
+		},
+		// Unknown services are split at the first "/" or ":".
+		{
+			"arn:aws:kinesis:us-east-1:123456789012:stream/clicks",
+			arn.ARN{Partition: "aws", Service: "kinesis", Region: "us-east-1", AccountID: "123456789012", ResourceType: "stream", ResourceID: "clicks"},
+			"",
+		},
+		{
+			"arn:aws:sns:us-east-1:123456789012:alerts",
+			arn.ARN{Partition: "aws", Service: "sns", Region: "us-east-1", AccountID: "123456789012", ResourceType: "alerts"},
+			"",
+		},
+	} {
+		a, err := arn.Parse(tt.in)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.in, err)
+
+			continue
+		}
+
+		if a.Partition != tt.want.Partition || a.Service != tt.want.Service || a.Region != tt.want.Region ||
+			a.AccountID != tt.want.AccountID || a.ResourceType != tt.want.ResourceType || a.ResourceID != tt.want.ResourceID {
+			t.Errorf("Parse(%q) = %+v, want %+v", tt.in, a, tt.want)
+		}
+
+		if name := a.Name(); name != tt.name {
+			t.Errorf("Parse(%q).Name() = %q, want %q", tt.in, name, tt.name)
+		}
+
+		if s := a.String(); s != tt.in {
+			t.Errorf("Parse(%q).String() = %q", tt.in, s)
+		}
+	}
+}
+
+func TestParseRejects(t *testing.T) {
+	for _, in := range []string{
+		"",
+		"deployer",
+		"arn:aws:iam::123456789012",
+		"urn:aws:iam::123456789012:role/deployer",
+		"arn:azure:iam::123456789012:role/deployer",
+		"arn:aws:IAM::123456789012:role/deployer",
+		"arn:aws:ecs:useast1:123456789012:cluster/web",
+		"arn:aws:ecs:us-east-1:1234:cluster/web",
+		"arn:aws:ecs:us-east-1:123456789012:",
+		// Known types must match their format and scope.
+		"arn:aws:ec2:us-east-1:123456789012:instance/web",
+		"arn:aws:iam:us-east-1:123456789012:role/deployer",
+		"arn:aws:rds:us-east-1:123456789012:db:1st",
+		"arn:aws:ecs:us-east-1:123456789012:cluster/web/extra",
+	} {
+		if a, err := arn.Parse(in); err == nil {
+			t.Errorf("Parse(%q) = %+v, want an error", in, a)
+		}
+	}
+}
+
+func TestPartitionForRegion(t *testing.T) {
+	for region, want := range map[string]string{
+		"us-east-1":      "aws",
+		"eu-central-1":   "aws",
+		"cn-north-1":     "aws-cn",
+		"cn-northwest-1": "aws-cn",
+		"us-gov-west-1":  "aws-us-gov",
+		"us-gov-east-1":  "aws-us-gov",
+		"":               "aws",
+		"xx-nowhere-1":   "aws",
+	} {
+		if got := arn.PartitionForRegion(region); got != want {
+			t.Errorf("PartitionForRegion(%q) = %q, want %q", region, got, want)
+		}
+	}
+}
+
+func TestBuilders(t *testing.T) {
+	const account = "123456789012"
+
+	for _, tt := range []struct {
+		a    arn.ARN
+		want string
+	}{
+		{arn.IAMRole(account, "/", "deployer"), "arn:aws:iam::123456789012:role/deployer"},
+		{arn.IAMRole(account, "/ops/", "deployer"), "arn:aws:iam::123456789012:role/ops/deployer"},
+		{arn.IAMUser(account, "", "alice"), "arn:aws:iam::123456789012:user/alice"},
+		{arn.IAMPolicy("aws", "/", "ReadOnlyAccess"), "arn:aws:iam::aws:policy/ReadOnlyAccess"},
+		{arn.STSAssumedRole(account, "deployer", "ci"), "arn:aws:sts::123456789012:assumed-role/deployer/ci"},
+		{arn.S3Bucket("logs"), "arn:aws:s3:::logs"},
+		{arn.S3Object("logs", "2021/app.log"), "arn:aws:s3:::logs/2021/app.log"},
+		{arn.Route53HostedZone("/hostedzone/Z123ABC"), "arn:aws:route53:::hostedzone/Z123ABC"},
+		{arn.EC2Instance("us-east-1", account, "i-0abc"), "arn:aws:ec2:us-east-1:123456789012:instance/i-0abc"},
+		{arn.EC2Instance("cn-north-1", account, "i-0abc"), "arn:aws-cn:ec2:cn-north-1:123456789012:instance/i-0abc"},
+		{arn.ECSService("us-gov-west-1", account, "web", "api"), "arn:aws-us-gov:ecs:us-gov-west-1:123456789012:service/web/api"},
+		{arn.ECSTaskDefinition("us-east-1", account, "web", 12), "arn:aws:ecs:us-east-1:123456789012:task-definition/web:12"},
+		{arn.RDSCluster("eu-west-1", account, "orders"), "arn:aws:rds:eu-west-1:123456789012:cluster:orders"},
+		{arn.RDSClusterSnapshot("eu-west-1", account, "rds:orders-1"), "arn:aws:rds:eu-west-1:123456789012:cluster-snapshot:rds:orders-1"},
+		{arn.RedshiftCluster("us-east-1", account, "dw"), "arn:aws:redshift:us-east-1:123456789012:cluster:dw"},
+		{arn.DynamoDBTable("us-east-1", account, "orders"), "arn:aws:dynamodb:us-east-1:123456789012:table/orders"},
+		{arn.LambdaFunction("us-east-1", account, "resize"), "arn:aws:lambda:us-east-1:123456789012:function:resize"},
+		{arn.SQSQueue("us-east-1", account, "orders"), "arn:aws:sqs:us-east-1:123456789012:orders"},
+		{arn.SSMParameter("us-east-1", account, "/app/db"), "arn:aws:ssm:us-east-1:123456789012:parameter/app/db"},
+		{arn.GlueTable("us-east-1", account, "sales", "orders"), "arn:aws:glue:us-east-1:123456789012:table/sales/orders"},
+		{arn.EMRCluster("us-east-1", account, "j-ABC123"), "arn:aws:elasticmapreduce:us-east-1:123456789012:cluster/j-ABC123"},
+	} {
+		if got := tt.a.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+
+		if _, err := arn.Parse(tt.want); err != nil {
+			t.Errorf("the built ARN does not parse: %v", err)
+		}
+	}
+}
+
+func TestResourceName(t *testing.T) {
+	for _, tt := range []struct {
+		s, service, typ, want string
+	}{
+		{"deployer", "iam", "role", "deployer"},
+		{"arn:aws:iam::123456789012:role/ops/deployer", "iam", "role", "deployer"},
+		{"arn:aws:iam::123456789012:user/alice", "iam", "role", "arn:aws:iam::123456789012:user/alice"},
+		{"arn:aws:sqs:us-east-1:123456789012:orders", "sqs", "queue", "orders"},
+		{"arn:aws:ssm:us-east-1:123456789012:parameter/app/db", "ssm", "parameter", "/app/db"},
+		{"arn:aws:ssm:us-east-1:123456789012:parameter/flat", "ssm", "parameter", "flat"},
+		{"arn:aws:ec2:us-east-1:123456789012:instance/web", "ec2", "instance", "arn:aws:ec2:us-east-1:123456789012:instance/web"},
+	} {
+		if got := arn.ResourceName(tt.s, tt.service, tt.typ); got != tt.want {
+			t.Errorf("ResourceName(%q, %s, %s) = %q, want %q", tt.s, tt.service, tt.typ, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package arn
+
+import (
+	"strconv"
+	"strings"
+)
+
+// New returns the ARN of a resource in region, in the partition of the
+// region. Pass "" as region or account for the resources that have none;
+// the ARNs of global resources, such as IAM roles and S3 buckets, are in the
+// "aws" partition, and need Partition set for others.
+func New(service, region, account, resourceType, resourceID string) ARN {
+	return ARN{
+		Partition:    PartitionForRegion(region),
+		Service:      service,
+		Region:       region,
+		AccountID:    account,
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+	}
+}
+
+// iamID returns the resource ID of name under path, which defaults to "/".
+func iamID(path, name string) string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return name
+	}
+
+	return path + "/" + name
+}
+
+func IAMRole(account, path, name string) ARN {
+	return New("iam", "", account, "role", iamID(path, name))
+}
+
+func IAMUser(account, path, name string) ARN {
+	return New("iam", "", account, "user", iamID(path, name))
+}
+
+func IAMGroup(account, path, name string) ARN {
+	return New("iam", "", account, "group", iamID(path, name))
+}
+
+// IAMPolicy returns the ARN of a customer managed policy, or of an AWS
+// managed one when account is "aws".
+func IAMPolicy(account, path, name string) ARN {
+	return New("iam", "", account, "policy", iamID(path, name))
+}
+
+func IAMInstanceProfile(account, path, name string) ARN {
+	return New("iam", "", account, "instance-profile", iamID(path, name))
+}
+
+func STSAssumedRole(account, role, session string) ARN {
+	return New("sts", "", account, "assumed-role", role+"/"+session)
+}
+
+func S3Bucket(bucket string) ARN {
+	return New("s3", "", "", "bucket", bucket)
+}
+
+func S3Object(bucket, key string) ARN {
+	return New("s3", "", "", "object", bucket+"/"+key)
+}
+
+// Route53HostedZone accepts the IDs returned by ListHostedZones, which start
+// with "/hostedzone/".
+func Route53HostedZone(id string) ARN {
+	return New("route53", "", "", "hostedzone", strings.TrimPrefix(id, "/hostedzone/"))
+}
+
+func EC2Instance(region, account, id string) ARN {
+	return New("ec2", region, account, "instance", id)
+}
+
+func EC2VPC(region, account, id string) ARN {
+	return New("ec2", region, account, "vpc", id)
+}
+
+func EC2SecurityGroup(region, account, id string) ARN {
+	return New("ec2", region, account, "security-group", id)
+}
+
+func ECSCluster(region, account, name string) ARN {
+	return New("ecs", region, account, "cluster", name)
+}
+
+func ECSService(region, account, cluster, name string) ARN {
+	return New("ecs", region, account, "service", cluster+"/"+name)
+}
+
+func ECSTask(region, account, cluster, id string) ARN {
+	return New("ecs", region, account, "task", cluster+"/"+id)
+}
+
+func ECSTaskDefinition(region, account, family string, revision int) ARN {
+	return New("ecs", region, account, "task-definition", family+":"+strconv.Itoa(revision))
+}
+
+func ECRRepository(region, account, name string) ARN {
+	return New("ecr", region, account, "repository", name)
+}
+
+func RDSInstance(region, account, id string) ARN {
+	return New("rds", region, account, "db", id)
+}
+
+func RDSCluster(region, account, id string) ARN {
+	return New("rds", region, account, "cluster", id)
+}
+
+func RDSSnapshot(region, account, id string) ARN {
+	return New("rds", region, account, "snapshot", id)
+}
+
+func RDSClusterSnapshot(region, account, id string) ARN {
+	return New("rds", region, account, "cluster-snapshot", id)
+}
+
+func RedshiftCluster(region, account, name string) ARN {
+	return New("redshift", region, account, "cluster", name)
+}
+
+func DynamoDBTable(region, account, name string) ARN {
+	return New("dynamodb", region, account, "table", name)
+}
+
+func LambdaFunction(region, account, name string) ARN {
+	return New("lambda", region, account, "function", name)
+}
+
+func SQSQueue(region, account, name string) ARN {
+	return New("sqs", region, account, "queue", name)
+}
+
+// SSMParameter accepts names with or without a leading "/".
+func SSMParameter(region, account, name string) ARN {
+	return New("ssm", region, account, "parameter", strings.TrimPrefix(name, "/"))
+}
+
+func GlueDatabase(region, account, name string) ARN {
+	return New("glue", region, account, "database", name)
+}
+
+func GlueTable(region, account, database, name string) ARN {
+	return New("glue", region, account, "table", database+"/"+name)
+}
+
+func AthenaWorkGroup(region, account, name string) ARN {
+	return New("athena", region, account, "workgroup", name)
+}
+
+func EMRCluster(region, account, id string) ARN {
+	return New("elasticmapreduce", region, account, "cluster", id)
+}
+
+func CloudTrailTrail(region, account, name string) ARN {
+	return New("cloudtrail", region, account, "trail", name)
+}
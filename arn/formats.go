@@ -0,0 +1,227 @@
+package arn
+
+import (
+	"regexp"
+	"strings"
+)
+
+type scope int
+
+const (
+	// regional resources have a region and an account.
+	regional scope = iota
+	// public resources have a region, and an account unless AWS owns them,
+	// like public EC2 images.
+	public
+	// account resources, like IAM roles, have an account but no region.
+	account
+	// global resources, like S3 buckets, have neither.
+	global
+)
+
+type format struct {
+	typ string
+	// sep follows the type in the resource, "" when the resource is the ID
+	// alone, like S3 bucket names.
+	sep   string
+	scope scope
+	// id matches the resource ID; its "name" group is what the APIs take in
+	// place of the ARN.
+	id *regexp.Regexp
+	// name, when set, turns the "name" group into the name.
+	name func(string) string
+}
+
+func f(typ, sep string, s scope, id string) *format {
+	return &format{typ: typ, sep: sep, scope: s, id: regexp.MustCompile(`^(?:` + id + `)$`)}
+}
+
+const (
+	iamPath = `(?:[\x21-\x7e]*/)?`
+	iamName = `[\w+=,.@-]+`
+	ecsName = `[A-Za-z0-9_-]+`
+	rdsName = `[A-Za-z][A-Za-z0-9-]*`
+)
+
+// formats are the resource formats of the services wrapped by the clients
+// package, tried in order.
+var formats = map[string][]*format{
+	"athena": {
+		f("workgroup", "/", regional, `(?P<name>[A-Za-z0-9._-]+)`),
+		f("datacatalog", "/", regional, `(?P<name>[A-Za-z0-9_@-]+)`),
+	},
+	"autoscaling": {
+		f("autoScalingGroup", ":", regional, `[0-9a-f-]+:autoScalingGroupName/(?P<name>.+)`),
+		f("launchConfiguration", ":", regional, `[0-9a-f-]+:launchConfigurationName/(?P<name>.+)`),
+	},
+	"cloudformation": {
+		f("stack", "/", regional, `(?P<name>[A-Za-z][A-Za-z0-9-]*)/[0-9a-f-]+`),
+		f("changeSet", "/", regional, `(?P<name>[A-Za-z][A-Za-z0-9-]*)/[0-9a-f-]+`),
+		f("stackset", "/", regional, `(?P<name>[A-Za-z][A-Za-z0-9-]*):[0-9a-f-]+`),
+	},
+	"cloudtrail": {
+		f("trail", "/", regional, `(?P<name>[A-Za-z0-9._-]+)`),
+	},
+	"dynamodb": {
+		f("table", "/", regional, `(?P<name>[A-Za-z0-9_.-]+)`),
+		f("table", "/", regional, `[A-Za-z0-9_.-]+/(?:stream|index|backup|export)/.+`),
+		f("global-table", "/", account, `(?P<name>[A-Za-z0-9_.-]+)`),
+	},
+	"ec2": {
+		f("instance", "/", regional, `(?P<name>i-[0-9a-f]+)`),
+		f("vpc", "/", regional, `(?P<name>vpc-[0-9a-f]+)`),
+		f("subnet", "/", regional, `(?P<name>subnet-[0-9a-f]+)`),
+		f("security-group", "/", regional, `(?P<name>sg-[0-9a-f]+)`),
+		f("network-interface", "/", regional, `(?P<name>eni-[0-9a-f]+)`),
+		f("volume", "/", regional, `(?P<name>vol-[0-9a-f]+)`),
+		f("snapshot", "/", public, `(?P<name>snap-[0-9a-f]+)`),
+		f("image", "/", public, `(?P<name>ami-[0-9a-f]+)`),
+		f("launch-template", "/", regional, `(?P<name>lt-[0-9a-f]+)`),
+		f("key-pair", "/", regional, `(?P<name>.+)`),
+	},
+	"ecr": {
+		f("repository", "/", regional, `(?P<name>[a-z0-9]+(?:[._-][a-z0-9]+)*(?:/[a-z0-9]+(?:[._-][a-z0-9]+)*)*)`),
+	},
+	"ecs": {
+		f("cluster", "/", regional, `(?P<name>`+ecsName+`)`),
+		// Older services, tasks and container instances leave out the
+		// cluster.
+		f("service", "/", regional, `(?:`+ecsName+`/)?(?P<name>`+ecsName+`)`),
+		f("task", "/", regional, `(?:`+ecsName+`/)?(?P<name>[A-Za-z0-9-]+)`),
+		f("container-instance", "/", regional, `(?:`+ecsName+`/)?(?P<name>[A-Za-z0-9-]+)`),
+		f("task-definition", "/", regional, `(?P<name>`+ecsName+`:\d+)`),
+		f("capacity-provider", "/", regional, `(?P<name>`+ecsName+`)`),
+	},
+	"elasticmapreduce": {
+		f("cluster", "/", regional, `(?P<name>j-[A-Z0-9]+)`),
+	},
+	"glue": {
+		f("database", "/", regional, `(?P<name>[^/]+)`),
+		f("table", "/", regional, `[^/]+/(?P<name>[^/]+)`),
+		f("connection", "/", regional, `(?P<name>[^/]+)`),
+		f("crawler", "/", regional, `(?P<name>[^/]+)`),
+		f("job", "/", regional, `(?P<name>[^/]+)`),
+		f("trigger", "/", regional, `(?P<name>[^/]+)`),
+		f("workflow", "/", regional, `(?P<name>[^/]+)`),
+	},
+	"iam": {
+		f("role", "/", account, iamPath+`(?P<name>`+iamName+`)`),
+		f("user", "/", account, iamPath+`(?P<name>`+iamName+`)`),
+		f("group", "/", account, iamPath+`(?P<name>`+iamName+`)`),
+		f("policy", "/", account, iamPath+`(?P<name>`+iamName+`)`),
+		f("instance-profile", "/", account, iamPath+`(?P<name>`+iamName+`)`),
+		f("server-certificate", "/", account, iamPath+`(?P<name>`+iamName+`)`),
+		f("mfa", "/", account, iamPath+`(?P<name>`+iamName+`)`),
+	},
+	"lambda": {
+		f("function", ":", regional, `(?P<name>[A-Za-z0-9_-]+)(?::[A-Za-z0-9$_-]+)?`),
+		f("layer", ":", regional, `(?P<name>[A-Za-z0-9_-]+)(?::\d+)?`),
+		f("event-source-mapping", ":", regional, `(?P<name>[0-9a-f-]+)`),
+	},
+	"rds": {
+		f("db", ":", regional, `(?P<name>`+rdsName+`)`),
+		f("cluster", ":", regional, `(?P<name>`+rdsName+`)`),
+		// Automated snapshot IDs start with "rds:".
+		f("snapshot", ":", regional, `(?P<name>(?:[a-z]+:)?`+rdsName+`)`),
+		f("cluster-snapshot", ":", regional, `(?P<name>(?:[a-z]+:)?`+rdsName+`)`),
+		f("cluster-endpoint", ":", regional, `(?P<name>`+rdsName+`)`),
+		f("subgrp", ":", regional, `(?P<name>[A-Za-z0-9 ._-]+)`),
+		f("pg", ":", regional, `(?P<name>[A-Za-z0-9.-]+)`),
+		f("cluster-pg", ":", regional, `(?P<name>[A-Za-z0-9.-]+)`),
+		f("og", ":", regional, `(?P<name>[A-Za-z0-9.-]+)`),
+		f("es", ":", regional, `(?P<name>[A-Za-z0-9-]+)`),
+	},
+	"redshift": {
+		f("cluster", ":", regional, `(?P<name>[a-z][a-z0-9-]*)`),
+		f("snapshot", ":", regional, `[a-z][a-z0-9-]*/(?P<name>.+)`),
+		f("dbuser", ":", regional, `[a-z][a-z0-9-]*/(?P<name>.+)`),
+		f("dbname", ":", regional, `[a-z][a-z0-9-]*/(?P<name>.+)`),
+		f("dbgroup", ":", regional, `[a-z][a-z0-9-]*/(?P<name>.+)`),
+	},
+	"route53": {
+		f("hostedzone", "/", global, `(?P<name>[A-Z0-9]+)`),
+		f("healthcheck", "/", global, `(?P<name>[0-9a-f-]+)`),
+		f("change", "/", global, `(?P<name>[A-Z0-9]+)`),
+	},
+	"s3": {
+		f("accesspoint", "/", regional, `(?P<name>[a-z0-9-]+)`),
+		f("bucket", "", global, `(?P<name>[a-z0-9][a-z0-9.-]*[a-z0-9])`),
+		f("object", "", global, `[a-z0-9][a-z0-9.-]*[a-z0-9]/.+`),
+	},
+	"secretsmanager": {
+		// AWS appends a hyphen and six random characters to secret names.
+		f("secret", ":", regional, `(?P<name>.+)-[A-Za-z0-9]{6}`),
+	},
+	"sqs": {
+		f("queue", "", regional, `(?P<name>[A-Za-z0-9_-]{1,80}(?:\.fifo)?)`),
+	},
+	"ssm": {
+		{
+			typ: "parameter", sep: "/", scope: regional, id: regexp.MustCompile(`^(?P<name>.+)$`),
+			// The ARNs of hierarchical names, such as "/app/db", leave out
+			// the leading "/".
+			name: func(name string) string {
+				if strings.Contains(name, "/") {
+					return "/" + name
+				}
+
+				return name
+			},
+		},
+		f("document", "/", regional, `(?P<name>[A-Za-z0-9_.-]+)`),
+		f("managed-instance", "/", regional, `(?P<name>mi-[0-9a-f]+)`),
+	},
+	"sts": {
+		f("assumed-role", "/", account, iamName+`/`+iamName),
+		f("federated-user", "/", account, `(?P<name>`+iamName+`)`),
+	},
+}
+
+// match finds the format of resource in the service of a.
+func match(a ARN, resource string) (*format, string, bool) {
+	for _, f := range formats[a.Service] {
+		if !f.scope.allows(a.Region, a.AccountID) {
+			continue
+		}
+
+		id := resource
+
+		if f.sep != "" {
+			if !strings.HasPrefix(resource, f.typ+f.sep) {
+				continue
+			}
+
+			id = resource[len(f.typ)+len(f.sep):]
+		}
+
+		if f.id.MatchString(id) {
+			return f, id, true
+		}
+	}
+
+	return nil, "", false
+}
+
+// lookup returns the first format of the resource type of service.
+func lookup(service, typ string) *format {
+	for _, f := range formats[service] {
+		if f.typ == typ {
+			return f
+		}
+	}
+
+	return nil
+}
+
+func (s scope) allows(region, accountID string) bool {
+	switch s {
+	case regional:
+		return region != "" && accountID != ""
+	case public:
+		return region != ""
+	case account:
+		return region == "" && accountID != ""
+	}
+
+	return region == "" && accountID == ""
+}
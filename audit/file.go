@@ -0,0 +1,190 @@
+// Package audit stores the audit records of sessions built by
+// service.Service:
+//
+//	sink, err := audit.NewFileSink("/var/log/aws-audit.jsonl", audit.Rotation{MaxBytes: 100 << 20, MaxBackups: 10})
+//	svc := service.Service{Profile: "prod", Region: "us-east-1", Audit: sink}
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/mwlng/aws-go-clients/service"
+)
+
+// Rotation controls when a FileSink starts a new file. Once the file
+// reaches MaxBytes it is renamed with the suffix ".1", older files are
+// shifted to ".2", ".3" and so on, and files beyond MaxBackups are removed.
+// A zero MaxBytes never rotates; rotating requires at least one backup, so
+// that records are never deleted before they are moved aside.
+type Rotation struct {
+	MaxBytes   int64
+	MaxBackups int
+}
+
+// FileSink appends one JSON line per record to a file. It is safe for
+// concurrent use.
+type FileSink struct {
+	path     string
+	rotation Rotation
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// NewFileSink opens the file at path for appending, creating it readable by
+// its owner only when it does not exist.
+func NewFileSink(path string, rotation Rotation) (*FileSink, error) {
+	if rotation.MaxBytes > 0 && rotation.MaxBackups <= 0 {
+		return nil, fmt.Errorf("audit: %s: rotation with MaxBytes needs MaxBackups of 1 or more", path)
+	}
+
+	sink := &FileSink{path: path, rotation: rotation}
+
+	if err := sink.open(); err != nil {
+		return nil, err
+	}
+
+	return sink, nil
+}
+
+func (s *FileSink) open() error {
+	file, err := os.OpenFile(s.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return fmt.Errorf("audit: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+
+		return fmt.Errorf("audit: %w", err)
+	}
+
+	s.file = file
+	s.size = info.Size()
+
+	return nil
+}
+
+func (s *FileSink) WriteAudit(record service.AuditRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("audit: %w", err)
+	}
+
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return fmt.Errorf("audit: %s: %w", s.path, os.ErrClosed)
+	}
+
+	var rotateErr error
+
+	if s.rotation.MaxBytes > 0 && s.size > 0 && s.size+int64(len(line)) > s.rotation.MaxBytes {
+		if rotateErr = s.rotate(); s.file == nil {
+			return rotateErr
+		}
+	}
+
+	n, err := s.file.Write(line)
+	s.size += int64(n)
+
+	if err != nil {
+		return fmt.Errorf("audit: %w", err)
+	}
+
+	return rotateErr
+}
+
+// rename is os.Rename, replaced by tests.
+var rename = os.Rename
+
+// rotate moves the file aside and opens a new one. When a file cannot be
+// moved, the rotation stops rather than overwrite a backup, and the current
+// file is reopened to append to it; the error is returned with the sink
+// still writing.
+func (s *FileSink) rotate() error {
+	err := s.file.Close()
+	s.file = nil
+
+	if err == nil {
+		err = s.shift()
+	}
+
+	if openErr := s.open(); openErr != nil {
+		return openErr
+	}
+
+	if err != nil {
+		return fmt.Errorf("audit: rotating %s: %w", s.path, err)
+	}
+
+	return nil
+}
+
+func (s *FileSink) shift() error {
+	if err := os.Remove(s.backup(s.rotation.MaxBackups)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	for i := s.rotation.MaxBackups - 1; i >= 1; i-- {
+		if err := rename(s.backup(i), s.backup(i+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	return rename(s.path, s.backup(1))
+}
+
+func (s *FileSink) backup(n int) string {
+	return fmt.Sprintf("%s.%d", s.path, n)
+}
+
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return nil
+	}
+
+	err := s.file.Close()
+	s.file = nil
+
+	return err
+}
+
+// WriterSink writes one JSON line per record to w, such as os.Stdout or a
+// network connection.
+type WriterSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewWriterSink(w io.Writer) *WriterSink {
+	return &WriterSink{w: w}
+}
+
+func (s *WriterSink) WriteAudit(record service.AuditRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("audit: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.w.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("audit: %w", err)
+	}
+
+	return nil
+}
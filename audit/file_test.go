@@ -0,0 +1,131 @@
+package audit
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mwlng/aws-go-clients/service"
+)
+
+func TestFileSinkRefusesRotationWithoutBackups(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+
+	if _, err := NewFileSink(path, Rotation{MaxBytes: 1}); err == nil {
+		t.Fatal("NewFileSink accepted MaxBytes without MaxBackups")
+	}
+
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("NewFileSink created %s: %v", path, err)
+	}
+}
+
+func TestFileSinkRotationKeepsRecords(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+
+	sink, err := NewFileSink(path, Rotation{MaxBytes: 1, MaxBackups: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+
+	for _, op := range []string{"CreateRole", "DeleteRole", "PutObject", "DeleteObject"} {
+		if err := sink.WriteAudit(service.AuditRecord{Operation: op}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for name, want := range map[string]string{
+		path:        "DeleteObject",
+		path + ".1": "PutObject",
+		path + ".2": "DeleteRole",
+	} {
+		data, err := ioutil.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !strings.Contains(string(data), want) || strings.Count(string(data), "\n") != 1 {
+			t.Errorf("%s holds %q, want the %s record only", name, data, want)
+		}
+	}
+
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("rotation kept more than MaxBackups files: %v", err)
+	}
+}
+
+func TestFileSinkKeepsWritingWhenRotationFails(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+
+	sink, err := NewFileSink(path, Rotation{MaxBytes: 1, MaxBackups: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+
+	failure := errors.New("device busy")
+	rename = func(oldpath, newpath string) error { return failure }
+	t.Cleanup(func() { rename = os.Rename })
+
+	if err := sink.WriteAudit(service.AuditRecord{Operation: "CreateRole"}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, op := range []string{"DeleteRole", "PutObject"} {
+		if err := sink.WriteAudit(service.AuditRecord{Operation: op}); !errors.Is(err, failure) {
+			t.Errorf("WriteAudit of %s = %v, want the rename error", op, err)
+		}
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, op := range []string{"CreateRole", "DeleteRole", "PutObject"} {
+		if !strings.Contains(string(data), op) {
+			t.Errorf("%s lacks the %s record: %q", path, op, data)
+		}
+	}
+
+	rename = os.Rename
+
+	if err := sink.WriteAudit(service.AuditRecord{Operation: "DeleteObject"}); err != nil {
+		t.Fatalf("WriteAudit once renames work again: %v", err)
+	}
+
+	if data, err := ioutil.ReadFile(path + ".1"); err != nil || strings.Count(string(data), "\n") != 3 {
+		t.Errorf("backup holds %q, %v; want the 3 records written during the failure", data, err)
+	}
+}
+
+func TestFileSinkReportsBackupShiftErrors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+
+	// A directory in place of the oldest backup cannot be removed.
+	if err := os.MkdirAll(filepath.Join(path+".2", "x"), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	sink, err := NewFileSink(path, Rotation{MaxBytes: 1, MaxBackups: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+
+	if err := sink.WriteAudit(service.AuditRecord{Operation: "CreateRole"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := sink.WriteAudit(service.AuditRecord{Operation: "DeleteRole"}); err == nil {
+		t.Error("WriteAudit hid the failure to remove the oldest backup")
+	}
+
+	if data, _ := ioutil.ReadFile(path); strings.Count(string(data), "\n") != 2 {
+		t.Errorf("%s holds %q, want both records", path, data)
+	}
+}
@@ -1,24 +1,55 @@
 package clients
 
 import (
-	"fmt"
+	"context"
+	"errors"
+	"time"
 
-	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/athena"
+	"github.com/aws/aws-sdk-go/service/athena/athenaiface"
 )
 
+type AthenaAPI interface {
+	StartQueryExecution(catalogDB, query *string) (*string, error)
+	StartQueryExecutionWithContext(ctx context.Context, catalogDB, query *string) (*string, error)
+	GetQueryExecution(queryExecutionID *string) (*athena.QueryExecutionStatus, error)
+	GetQueryExecutionWithContext(ctx context.Context, queryExecutionID *string) (*athena.QueryExecutionStatus, error)
+	GetQueryResults(queryExecutionID, nextToken *string) (*athena.ResultSet, *string, error)
+	GetQueryResultsWithContext(ctx context.Context, queryExecutionID, nextToken *string) (*athena.ResultSet, *string, error)
+	GetQueryResultsPages(queryExecutionID *string, pageSize int64, fn func(results *athena.ResultSet) bool) error
+	GetQueryResultsPagesWithContext(ctx context.Context, queryExecutionID *string, pageSize int64, fn func(results *athena.ResultSet) bool) error
+	WaitForQuery(queryExecutionID *string, opts WaitOptions) (*athena.QueryExecutionStatus, error)
+	WaitForQueryWithContext(ctx context.Context, queryExecutionID *string, opts WaitOptions) (*athena.QueryExecutionStatus, error)
+}
+
+var _ AthenaAPI = (*AthenaClient)(nil)
+
 type AthenaClient struct {
-	cli *athena.Athena
+	logging
+	cli athenaiface.AthenaAPI
 }
 
-func NewAthena(sess *session.Session) *AthenaClient {
-	client := athena.New(sess)
+func init() {
+	Register("athena", func(sess *session.Session) interface{} { return NewAthena(sess) })
+}
+
+func NewAthena(sess *session.Session, cfgs ...*aws.Config) *AthenaClient {
+	client := athena.New(sess, cfgs...)
 
-	return &AthenaClient{cli: client}
+	return &AthenaClient{logging: newLogging(sess), cli: client}
+}
+
+func NewAthenaFromAPI(api athenaiface.AthenaAPI) *AthenaClient {
+	return &AthenaClient{logging: logging{logger: NopLogger}, cli: api}
 }
 
 func (athenaCli *AthenaClient) StartQueryExecution(catalogDB, query *string) (*string, error) {
+	return athenaCli.StartQueryExecutionWithContext(context.Background(), catalogDB, query)
+}
+
+func (athenaCli *AthenaClient) StartQueryExecutionWithContext(ctx context.Context, catalogDB, query *string) (*string, error) {
 	input := &athena.StartQueryExecutionInput{
 		QueryExecutionContext: &athena.QueryExecutionContext{
 			Database: catalogDB,
@@ -26,28 +57,36 @@ func (athenaCli *AthenaClient) StartQueryExecution(catalogDB, query *string) (*s
 		QueryString: query,
 	}
 
-	resp, err := athenaCli.cli.StartQueryExecution(input)
+	resp, err := athenaCli.cli.StartQueryExecutionWithContext(ctx, input)
 	if err != nil {
-		return nil, err
+		return nil, athenaCli.handleError("StartQueryExecution", err)
 	}
 
 	return resp.QueryExecutionId, nil
 }
 
 func (athenaCli *AthenaClient) GetQueryExecution(queryExecutionID *string) (*athena.QueryExecutionStatus, error) {
+	return athenaCli.GetQueryExecutionWithContext(context.Background(), queryExecutionID)
+}
+
+func (athenaCli *AthenaClient) GetQueryExecutionWithContext(ctx context.Context, queryExecutionID *string) (*athena.QueryExecutionStatus, error) {
 	input := &athena.GetQueryExecutionInput{
 		QueryExecutionId: queryExecutionID,
 	}
 
-	resp, err := athenaCli.cli.GetQueryExecution(input)
+	resp, err := athenaCli.cli.GetQueryExecutionWithContext(ctx, input)
 	if err != nil {
-		return nil, err
+		return nil, athenaCli.handleError("GetQueryExecution", err)
 	}
 
 	return resp.QueryExecution.Status, nil
 }
 
 func (athenaCli *AthenaClient) GetQueryResults(queryExecutionID, nextToken *string) (*athena.ResultSet, *string, error) {
+	return athenaCli.GetQueryResultsWithContext(context.Background(), queryExecutionID, nextToken)
+}
+
+func (athenaCli *AthenaClient) GetQueryResultsWithContext(ctx context.Context, queryExecutionID, nextToken *string) (*athena.ResultSet, *string, error) {
 	input := &athena.GetQueryResultsInput{
 		QueryExecutionId: queryExecutionID,
 	}
@@ -55,29 +94,90 @@ func (athenaCli *AthenaClient) GetQueryResults(queryExecutionID, nextToken *stri
 		input.NextToken = nextToken
 	}
 
-	resp, err := athenaCli.cli.GetQueryResults(input)
+	resp, err := athenaCli.cli.GetQueryResultsWithContext(ctx, input)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, athenaCli.handleError("GetQueryResults", err)
 	}
 
 	return resp.ResultSet, resp.NextToken, nil
 }
 
-func (athenaCli *AthenaClient) HandleError(err error) {
-	if aerr, ok := err.(awserr.Error); ok {
-		switch aerr.Code() {
-		case athena.ErrCodeInternalServerException:
-			fmt.Println(athena.ErrCodeInternalServerException, aerr.Error())
-		case athena.ErrCodeInvalidRequestException:
-			fmt.Println(athena.ErrCodeInvalidRequestException, aerr.Error())
-		case athena.ErrCodeTooManyRequestsException:
-			fmt.Println(athena.ErrCodeTooManyRequestsException, aerr.Error())
-		default:
-			fmt.Println(aerr.Error())
+func (athenaCli *AthenaClient) GetQueryResultsPages(queryExecutionID *string, pageSize int64, fn func(results *athena.ResultSet) bool) error {
+	return athenaCli.GetQueryResultsPagesWithContext(context.Background(), queryExecutionID, pageSize, fn)
+}
+
+func (athenaCli *AthenaClient) GetQueryResultsPagesWithContext(ctx context.Context, queryExecutionID *string, pageSize int64, fn func(results *athena.ResultSet) bool) error {
+	input := &athena.GetQueryResultsInput{
+		QueryExecutionId: queryExecutionID,
+		MaxResults:       pageLimit(pageSize, 1, 1000),
+	}
+
+	for {
+		resp, err := athenaCli.cli.GetQueryResultsWithContext(ctx, input)
+		if err != nil {
+			return athenaCli.handleError("GetQueryResults", err)
 		}
-	} else {
-		// Print the error, cast err to awserr.Error to get the Code and
-		// Message from an error.
-		fmt.Println(err.Error())
+
+		if !fn(resp.ResultSet) || resp.NextToken == nil {
+			return nil
+		}
+
+		input.NextToken = resp.NextToken
+	}
+}
+
+func (athenaCli *AthenaClient) WaitForQuery(queryExecutionID *string, opts WaitOptions) (*athena.QueryExecutionStatus, error) {
+	return athenaCli.WaitForQueryWithContext(context.Background(), queryExecutionID, opts)
+}
+
+// WaitForQueryWithContext waits for the query to succeed, polling after one
+// second, then twice as long every time up to 10 seconds, for up to 30
+// minutes. Failed and cancelled queries end the wait with ErrFailureState,
+// and the returned status tells why.
+func (athenaCli *AthenaClient) WaitForQueryWithContext(ctx context.Context, queryExecutionID *string, opts WaitOptions) (*athena.QueryExecutionStatus, error) {
+	var status *athena.QueryExecutionStatus
+
+	w := &Waiter{
+		Resource: "Athena query " + aws.StringValue(queryExecutionID),
+		State: func(ctx context.Context) (string, error) {
+			var err error
+
+			status, err = athenaCli.GetQueryExecutionWithContext(ctx, queryExecutionID)
+			if status == nil {
+				return resourceState(false, nil, err)
+			}
+
+			return resourceState(true, status.State, err)
+		},
+		Success: []string{athena.QueryExecutionStateSucceeded},
+		Failure: []string{athena.QueryExecutionStateFailed, athena.QueryExecutionStateCancelled, StateNotFound},
+		Retry:   []string{athena.QueryExecutionStateQueued, athena.QueryExecutionStateRunning},
+		WaitOptions: opts.withDefaults(WaitOptions{
+			Timeout: 30 * time.Minute,
+			Backoff: Backoff{Delay: time.Second, MaxDelay: 10 * time.Second, Multiplier: 2},
+		}),
+	}
+
+	_, err := w.Wait(ctx)
+
+	return status, err
+}
+
+// HandleError records err with the logger of the client. Errors returned
+// by the methods of AthenaClient were recorded when they were returned, and
+// are not recorded again.
+//
+// Deprecated: the methods of AthenaClient return an *Error, which callers
+// inspect with errors.As or helpers such as IsThrottled.
+func (athenaCli *AthenaClient) HandleError(err error) {
+	var e *Error
+	if err == nil || errors.As(err, &e) {
+		return
 	}
+
+	athenaCli.logError(newError(athena.ServiceName, "", err))
+}
+
+func (athenaCli *AthenaClient) handleError(operation string, err error) error {
+	return athenaCli.logError(newError(athena.ServiceName, operation, err))
 }
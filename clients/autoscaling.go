@@ -1,96 +1,131 @@
 package clients
 
 import (
-	"fmt"
+	"context"
 
 	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/aws/aws-sdk-go/service/autoscaling/autoscalingiface"
 )
 
+type ASGAPI interface {
+	DescribeAutoScalngInstances(instanceID string) (*autoscaling.DescribeAutoScalingInstancesOutput, error)
+	DescribeAutoScalngInstancesWithContext(ctx context.Context, instanceID string) (*autoscaling.DescribeAutoScalingInstancesOutput, error)
+	GetAutoScalingGroupByName(name string) (*autoscaling.Group, error)
+	GetAutoScalingGroupByNameWithContext(ctx context.Context, name string) (*autoscaling.Group, error)
+	ListAllAutoScalingGroups() ([]*autoscaling.Group, error)
+	ListAllAutoScalingGroupsWithContext(ctx context.Context) ([]*autoscaling.Group, error)
+	ListAllAutoScalingGroupsPages(pageSize int64, fn func(groups []*autoscaling.Group) bool) error
+	ListAllAutoScalingGroupsPagesWithContext(ctx context.Context, pageSize int64, fn func(groups []*autoscaling.Group) bool) error
+}
+
+var _ ASGAPI = (*ASGClient)(nil)
+
 type ASGClient struct {
-	cli *autoscaling.AutoScaling
+	logging
+	*owner
+	cli autoscalingiface.AutoScalingAPI
+}
+
+func init() {
+	Register("autoscaling", func(sess *session.Session) interface{} { return NewASG(sess) })
 }
 
-func NewASG(sess *session.Session) *ASGClient {
-	client := autoscaling.New(sess)
+func NewASG(sess *session.Session, cfgs ...*aws.Config) *ASGClient {
+	client := autoscaling.New(sess, cfgs...)
+
+	return &ASGClient{logging: newLogging(sess), owner: newOwner(sess, client.Config), cli: client}
+}
 
-	return &ASGClient{cli: client}
+func NewASGFromAPI(api autoscalingiface.AutoScalingAPI) *ASGClient {
+	return &ASGClient{logging: logging{logger: NopLogger}, owner: &owner{}, cli: api}
 }
 
-func (asgCli *ASGClient) DescribeAutoScalngInstances(instanceID string) *autoscaling.DescribeAutoScalingInstancesOutput {
+func (asgCli *ASGClient) DescribeAutoScalngInstances(instanceID string) (*autoscaling.DescribeAutoScalingInstancesOutput, error) {
+	return asgCli.DescribeAutoScalngInstancesWithContext(context.Background(), instanceID)
+}
+
+func (asgCli *ASGClient) DescribeAutoScalngInstancesWithContext(ctx context.Context, instanceID string) (*autoscaling.DescribeAutoScalingInstancesOutput, error) {
 	input := &autoscaling.DescribeAutoScalingInstancesInput{
 		InstanceIds: []*string{
 			aws.String(instanceID),
 		},
 	}
 
-	result, err := asgCli.cli.DescribeAutoScalingInstances(input)
-
+	result, err := asgCli.cli.DescribeAutoScalingInstancesWithContext(ctx, input)
 	if err != nil {
-		asgCli.handleError(err)
+		return nil, asgCli.handleError("DescribeAutoScalingInstances", err)
 	}
 
-	return result
+	return result, nil
+}
+
+func (asgCli *ASGClient) GetAutoScalingGroupByName(name string) (*autoscaling.Group, error) {
+	return asgCli.GetAutoScalingGroupByNameWithContext(context.Background(), name)
 }
 
-func (asgCli *ASGClient) GetAutoScalingGroupByName(name string) *autoscaling.Group {
+func (asgCli *ASGClient) GetAutoScalingGroupByNameWithContext(ctx context.Context, name string) (*autoscaling.Group, error) {
+	groupName, err := asgCli.nameOf(ctx, aws.String(name), "autoscaling", "autoScalingGroup")
+	if err != nil {
+		return nil, asgCli.handleError("DescribeAutoScalingGroups", err)
+	}
+
 	input := &autoscaling.DescribeAutoScalingGroupsInput{
-		AutoScalingGroupNames: []*string{&name},
+		AutoScalingGroupNames: []*string{groupName},
 	}
 
-	resp, err := asgCli.cli.DescribeAutoScalingGroups(input)
+	resp, err := asgCli.cli.DescribeAutoScalingGroupsWithContext(ctx, input)
 	if err != nil {
-		asgCli.handleError(err)
+		return nil, asgCli.handleError("DescribeAutoScalingGroups", err)
 	}
 
 	groups := resp.AutoScalingGroups
 
 	if len(groups) > 0 {
-		return groups[0]
+		return groups[0], nil
 	}
 
-	return nil
+	return nil, nil
 }
 
-func (asgCli *ASGClient) ListAllAutoScalingGroups() []*autoscaling.Group {
-	input := &autoscaling.DescribeAutoScalingGroupsInput{}
+func (asgCli *ASGClient) ListAllAutoScalingGroups() ([]*autoscaling.Group, error) {
+	return asgCli.ListAllAutoScalingGroupsWithContext(context.Background())
+}
 
-	resp, err := asgCli.cli.DescribeAutoScalingGroups(input)
-	if err != nil {
-		asgCli.handleError(err)
-	}
+func (asgCli *ASGClient) ListAllAutoScalingGroupsWithContext(ctx context.Context) ([]*autoscaling.Group, error) {
+	var groups []*autoscaling.Group
 
-	groups := resp.AutoScalingGroups
+	err := asgCli.ListAllAutoScalingGroupsPagesWithContext(ctx, 0, func(page []*autoscaling.Group) bool {
+		groups = append(groups, page...)
+
+		return true
+	})
+
+	return groups, err
+}
 
-	for resp.NextToken != nil {
-		input = &autoscaling.DescribeAutoScalingGroupsInput{NextToken: resp.NextToken}
+func (asgCli *ASGClient) ListAllAutoScalingGroupsPages(pageSize int64, fn func(groups []*autoscaling.Group) bool) error {
+	return asgCli.ListAllAutoScalingGroupsPagesWithContext(context.Background(), pageSize, fn)
+}
+
+func (asgCli *ASGClient) ListAllAutoScalingGroupsPagesWithContext(ctx context.Context, pageSize int64, fn func(groups []*autoscaling.Group) bool) error {
+	input := &autoscaling.DescribeAutoScalingGroupsInput{MaxRecords: pageLimit(pageSize, 1, 100)}
 
-		resp, err = asgCli.cli.DescribeAutoScalingGroups(input)
+	for {
+		resp, err := asgCli.cli.DescribeAutoScalingGroupsWithContext(ctx, input)
 		if err != nil {
-			asgCli.handleError(err)
+			return asgCli.handleError("DescribeAutoScalingGroups", err)
 		}
 
-		groups = append(groups, resp.AutoScalingGroups...)
-	}
+		if !fn(resp.AutoScalingGroups) || resp.NextToken == nil {
+			return nil
+		}
 
-	return groups
+		input.NextToken = resp.NextToken
+	}
 }
 
-func (asgCli *ASGClient) handleError(err error) {
-	if aerr, ok := err.(awserr.Error); ok {
-		switch aerr.Code() {
-		case autoscaling.ErrCodeInvalidNextToken:
-			fmt.Println(autoscaling.ErrCodeInvalidNextToken, aerr.Error())
-		case autoscaling.ErrCodeResourceContentionFault:
-			fmt.Println(autoscaling.ErrCodeResourceContentionFault, aerr.Error())
-		default:
-			fmt.Println(aerr.Error())
-		}
-	} else {
-		// Print the error, cast err to awserr.Error to get the Code and
-		// Message from an error.
-		fmt.Println(err.Error())
-	}
+func (asgCli *ASGClient) handleError(operation string, err error) error {
+	return asgCli.logError(newError(autoscaling.ServiceName, operation, err))
 }
@@ -1,52 +1,227 @@
 package clients
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/mwlng/aws-go-clients/arn"
 )
 
-func NewClient(service string, sess *session.Session) interface{} {
-	var client interface{}
-
-	switch service {
-	case "athena":
-		client = NewAthena(sess)
-	case "autoscaling":
-		client = NewASG(sess)
-	case "cloudformation":
-		client = NewCloudformation(sess)
-	case "dynamodb":
-		client = NewDynamoDB(sess)
-	case "ec2":
-		client = NewEC2(sess)
-	case "ecr":
-		client = NewECR(sess)
-	case "ecs":
-		client = NewECS(sess)
-	case "emr":
-		client = NewEMR(sess)
-	case "glue":
-		client = NewGlue(sess)
-	case "iam":
-		client = NewIAM(sess)
-	case "lambda":
-		client = NewLambda(sess)
-	case "redshift":
-		client = NewRedShift(sess)
-	case "rds":
-		client = NewRDS(sess)
-	case "route53":
-		client = NewR53(sess)
-	case "s3":
-		client = NewS3(sess)
-	case "ssm":
-		client = NewSSM(sess)
-	case "sts":
-		client = NewSTS(sess)
-	case "secretsmanager":
-		client = NewSecretsManager(sess)
-	default:
-		client = nil
-	}
-
-	return client
+// ErrUnknownService is returned, wrapped, for service names nothing has been
+// registered under.
+var ErrUnknownService = errors.New("unknown service")
+
+// Constructor builds a client from a session.
+type Constructor func(sess *session.Session) interface{}
+
+var registry = struct {
+	sync.RWMutex
+	constructors map[string]Constructor
+}{constructors: map[string]Constructor{}}
+
+// Register makes a client available to NewClient under name. Every client
+// of this package registers itself; other packages may register their own
+// clients, usually from an init function. Register panics if name is empty
+// or already registered, or if constructor is nil.
+func Register(name string, constructor Constructor) {
+	if name == "" {
+		panic("clients: Register with an empty service name")
+	}
+
+	if constructor == nil {
+		panic("clients: Register constructor is nil for " + name)
+	}
+
+	registry.Lock()
+	defer registry.Unlock()
+
+	if _, dup := registry.constructors[name]; dup {
+		panic("clients: Register called twice for " + name)
+	}
+
+	registry.constructors[name] = constructor
+}
+
+// Services returns the sorted names of the registered clients.
+func Services() []string {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	names := make([]string, 0, len(registry.constructors))
+	for name := range registry.constructors {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// NewClient builds the client registered under service.
+func NewClient(service string, sess *session.Session) (interface{}, error) {
+	registry.RLock()
+	constructor, ok := registry.constructors[service]
+	registry.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("clients: %w %q", ErrUnknownService, service)
+	}
+
+	return constructor(sess), nil
+}
+
+// NewClientAs builds the client registered under service and stores it in
+// target, which must be a non-nil pointer to the client type or to an
+// interface the client implements:
+//
+//	var s3Cli *clients.S3Client
+//	err := clients.NewClientAs("s3", sess, &s3Cli)
+func NewClientAs(service string, sess *session.Session, target interface{}) error {
+	value := reflect.ValueOf(target)
+	if value.Kind() != reflect.Ptr || value.IsNil() {
+		return fmt.Errorf("clients: NewClientAs target must be a non-nil pointer, got %T", target)
+	}
+
+	client, err := NewClient(service, sess)
+	if err != nil {
+		return err
+	}
+
+	elem := value.Elem()
+
+	clientValue := reflect.ValueOf(client)
+	if !clientValue.IsValid() || !clientValue.Type().AssignableTo(elem.Type()) {
+		return fmt.Errorf("clients: %s client is %T, not assignable to %s", service, client, elem.Type())
+	}
+
+	elem.Set(clientValue)
+
+	return nil
+}
+
+// owner is embedded by the clients whose methods take the ARN of a resource
+// where the API takes its name. The name alone would resolve to a resource
+// of the client's account and region, so the ARNs of other accounts and
+// regions are refused rather than reduced to their name.
+type owner struct {
+	region string
+	sts    STSAPI
+
+	mu      sync.Mutex
+	account string
+}
+
+// newOwner returns the owner of a client built from sess with config, the
+// resolved config of its SDK client.
+func newOwner(sess *session.Session, config aws.Config) *owner {
+	return &owner{
+		region: aws.StringValue(config.Region),
+		sts:    NewSTS(sess, &aws.Config{Region: config.Region, Credentials: config.Credentials}),
+	}
+}
+
+// SetOwner sets the account and region the client works in. The clients
+// built with a New*FromAPI constructor need it to accept ARNs that name an
+// account or a region.
+func (o *owner) SetOwner(accountID, region string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.account = accountID
+	o.region = region
+}
+
+// accountID looks up the account of the client's credentials once.
+func (o *owner) accountID(ctx context.Context) (string, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.account != "" || o.sts == nil {
+		return o.account, nil
+	}
+
+	account, _, _, err := o.sts.GetCallerIDWithContext(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	o.account = account
+
+	return account, nil
+}
+
+// nameOf lets the methods whose API takes the name of a resource also take
+// its ARN, which must name the client's account and region.
+func (o *owner) nameOf(ctx context.Context, id *string, service, resourceType string) (*string, error) {
+	name, a, err := o.parse(id, service, resourceType)
+	if err != nil || a == nil {
+		return name, err
+	}
+
+	if a.AccountID != "" {
+		account, err := o.accountID(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		if a.AccountID != account {
+			return nil, foreignARN(a, "account", account)
+		}
+	}
+
+	return name, nil
+}
+
+// nameAndAccountOf is nameOf for the APIs that take the account of a
+// resource along with its name. The account is nil for names.
+func (o *owner) nameAndAccountOf(id *string, service, resourceType string) (*string, *string, error) {
+	name, a, err := o.parse(id, service, resourceType)
+	if err != nil || a == nil || a.AccountID == "" {
+		return name, nil, err
+	}
+
+	return name, aws.String(a.AccountID), nil
+}
+
+// parse returns the name id stands for, and its ARN when it is one of
+// resourceType in service after checking its region.
+func (o *owner) parse(id *string, service, resourceType string) (*string, *arn.ARN, error) {
+	if id == nil || !arn.IsARN(*id) {
+		return id, nil, nil
+	}
+
+	name := arn.ResourceName(*id, service, resourceType)
+	if name == *id {
+		return id, nil, nil
+	}
+
+	a, err := arn.Parse(*id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	o.mu.Lock()
+	region := o.region
+	o.mu.Unlock()
+
+	if a.Region != "" && a.Region != region {
+		return nil, nil, foreignARN(&a, "region", region)
+	}
+
+	return aws.String(name), &a, nil
+}
+
+func foreignARN(a *arn.ARN, field, want string) error {
+	if want == "" {
+		return awserr.New("InvalidParameterValue", fmt.Sprintf("%s: the %s of the client is unknown; call SetOwner", a, field), nil)
+	}
+
+	return awserr.New("InvalidParameterValue", fmt.Sprintf("%s is not in the %s %s of the client", a, field, want), nil)
 }
@@ -0,0 +1,196 @@
+package clients
+
+import (
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/mwlng/aws-go-clients/service"
+)
+
+// ClientSet hands out the clients of one session. Each client is built on
+// first use and cached; a ClientSet is safe for concurrent use.
+type ClientSet struct {
+	svc service.Service
+
+	mu       sync.Mutex
+	clients  map[string]interface{}
+	siblings map[string]*ClientSet
+}
+
+// NewClientSet returns a ClientSet sharing svc.Session, which is created
+// with svc.NewSession when it is nil.
+func NewClientSet(svc *service.Service) *ClientSet {
+	if svc.Session == nil {
+		svc.NewSession()
+	}
+
+	return newClientSet(*svc)
+}
+
+func newClientSet(svc service.Service) *ClientSet {
+	return &ClientSet{
+		svc:      svc,
+		clients:  map[string]interface{}{},
+		siblings: map[string]*ClientSet{},
+	}
+}
+
+func (cs *ClientSet) Session() *session.Session {
+	return cs.svc.Session
+}
+
+func (cs *ClientSet) Region() string {
+	return aws.StringValue(cs.svc.Session.Config.Region)
+}
+
+// ForRegion returns the ClientSet of another region. Its session is a copy
+// of this one and shares its credentials and logger.
+func (cs *ClientSet) ForRegion(region string) *ClientSet {
+	if region == cs.Region() {
+		return cs
+	}
+
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	if sibling, ok := cs.siblings[region]; ok {
+		return sibling
+	}
+
+	svc := cs.svc
+	svc.Region = region
+	svc.Session = cs.svc.Session.Copy(&aws.Config{Region: aws.String(region)})
+
+	if logger, ok := sessionLoggers.Load(cs.svc.Session); ok {
+		sessionLoggers.Store(svc.Session, logger)
+	}
+
+	sibling := newClientSet(svc)
+	cs.siblings[region] = sibling
+
+	return sibling
+}
+
+// Client returns the client registered under service, which may come from
+// another package.
+func (cs *ClientSet) Client(service string) (interface{}, error) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	if client, ok := cs.clients[service]; ok {
+		return client, nil
+	}
+
+	client, err := NewClient(service, cs.svc.Session)
+	if err != nil {
+		return nil, err
+	}
+
+	cs.clients[service] = client
+
+	return client, nil
+}
+
+// SetClient makes Client return client for service, such as a client built
+// with a New*FromAPI constructor over a fake in tests.
+func (cs *ClientSet) SetClient(service string, client interface{}) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	cs.clients[service] = client
+}
+
+// builtin returns a client of this package, which is always registered.
+func (cs *ClientSet) builtin(service string) interface{} {
+	client, err := cs.Client(service)
+	if err != nil {
+		panic(err)
+	}
+
+	return client
+}
+
+func (cs *ClientSet) Athena() *AthenaClient {
+	return cs.builtin("athena").(*AthenaClient)
+}
+
+func (cs *ClientSet) ASG() *ASGClient {
+	return cs.builtin("autoscaling").(*ASGClient)
+}
+
+func (cs *ClientSet) Cloudformation() *CFNClient {
+	return cs.builtin("cloudformation").(*CFNClient)
+}
+
+func (cs *ClientSet) CloudTrail() *CloudTrailClient {
+	return cs.builtin("cloudtrail").(*CloudTrailClient)
+}
+
+func (cs *ClientSet) DynamoDB() *DynamoDBClient {
+	return cs.builtin("dynamodb").(*DynamoDBClient)
+}
+
+func (cs *ClientSet) EC2() *EC2Client {
+	return cs.builtin("ec2").(*EC2Client)
+}
+
+func (cs *ClientSet) ECR() *ECRClient {
+	return cs.builtin("ecr").(*ECRClient)
+}
+
+func (cs *ClientSet) ECS() *ECSClient {
+	return cs.builtin("ecs").(*ECSClient)
+}
+
+func (cs *ClientSet) EMR() *EMRClient {
+	return cs.builtin("emr").(*EMRClient)
+}
+
+func (cs *ClientSet) Glue() *GlueClient {
+	return cs.builtin("glue").(*GlueClient)
+}
+
+func (cs *ClientSet) IAM() *IAMClient {
+	return cs.builtin("iam").(*IAMClient)
+}
+
+func (cs *ClientSet) Lambda() *LambdaClient {
+	return cs.builtin("lambda").(*LambdaClient)
+}
+
+func (cs *ClientSet) RDS() *RDSClient {
+	return cs.builtin("rds").(*RDSClient)
+}
+
+func (cs *ClientSet) RedShift() *RedShiftClient {
+	return cs.builtin("redshift").(*RedShiftClient)
+}
+
+func (cs *ClientSet) R53() *R53Client {
+	return cs.builtin("route53").(*R53Client)
+}
+
+func (cs *ClientSet) S3() *S3Client {
+	return cs.builtin("s3").(*S3Client)
+}
+
+func (cs *ClientSet) SecretsManager() *SecretsManagerClient {
+	return cs.builtin("secretsmanager").(*SecretsManagerClient)
+}
+
+func (cs *ClientSet) SQS() *SQSClient {
+	return cs.builtin("sqs").(*SQSClient)
+}
+
+func (cs *ClientSet) SSM() *SSMClient {
+	return cs.builtin("ssm").(*SSMClient)
+}
+
+func (cs *ClientSet) STS() *STSClient {
+	return cs.builtin("sts").(*STSClient)
+}
+
+func (cs *ClientSet) Tagging() *TaggingClient {
+	return cs.builtin("tagging").(*TaggingClient)
+}
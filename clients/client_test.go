@@ -0,0 +1,83 @@
+package clients
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/mwlng/aws-go-clients/clients/fakes"
+)
+
+func TestOwnerNameOf(t *testing.T) {
+	o := &owner{region: "us-east-1", sts: NewSTSFromAPI(fakes.NewSTS())}
+	own := "arn:aws:dynamodb:us-east-1:" + fakes.Account + ":table/orders"
+
+	for _, tt := range []struct {
+		id, service, typ string
+		want             string
+		refused          string
+	}{
+		{"orders", "dynamodb", "table", "orders", ""},
+		{own, "dynamodb", "table", "orders", ""},
+		{"arn:aws:iam::" + fakes.Account + ":role/ops/deployer", "iam", "role", "deployer", ""},
+		// ARNs of other resource types are passed on for the API to refuse.
+		{own, "dynamodb", "global-table", own, ""},
+		{"arn:aws:dynamodb:us-east-1:111122223333:table/orders", "dynamodb", "table", "", "account " + fakes.Account},
+		{"arn:aws:dynamodb:eu-west-1:" + fakes.Account + ":table/orders", "dynamodb", "table", "", "region us-east-1"},
+		{"arn:aws:iam::111122223333:role/deployer", "iam", "role", "", "account " + fakes.Account},
+	} {
+		name, err := o.nameOf(context.Background(), aws.String(tt.id), tt.service, tt.typ)
+
+		if tt.refused == "" {
+			if err != nil || aws.StringValue(name) != tt.want {
+				t.Errorf("nameOf(%s) = %q, %v, want %q", tt.id, aws.StringValue(name), err, tt.want)
+			}
+
+			continue
+		}
+
+		aerr, ok := err.(awserr.Error)
+		if !ok || aerr.Code() != "InvalidParameterValue" || !strings.Contains(aerr.Message(), "not in the "+tt.refused) {
+			t.Errorf("nameOf(%s) = %q, %v, want it refused as not in the %s", tt.id, aws.StringValue(name), err, tt.refused)
+		}
+	}
+
+	if name, err := o.nameOf(context.Background(), nil, "dynamodb", "table"); name != nil || err != nil {
+		t.Errorf("nameOf(nil) = %v, %v", name, err)
+	}
+}
+
+func TestOwnerOfAPIClientsNeedsSetOwner(t *testing.T) {
+	o := &owner{}
+	id := aws.String("arn:aws:iam::" + fakes.Account + ":role/deployer")
+
+	if _, err := o.nameOf(context.Background(), id, "iam", "role"); err == nil || !strings.Contains(err.Error(), "call SetOwner") {
+		t.Errorf("nameOf without an owner = %v, want a SetOwner hint", err)
+	}
+
+	o.SetOwner(fakes.Account, "us-east-1")
+
+	if name, err := o.nameOf(context.Background(), id, "iam", "role"); err != nil || aws.StringValue(name) != "deployer" {
+		t.Errorf("nameOf after SetOwner = %q, %v, want deployer", aws.StringValue(name), err)
+	}
+}
+
+func TestOwnerNameAndAccountOf(t *testing.T) {
+	o := &owner{region: "us-east-1"}
+
+	name, account, err := o.nameAndAccountOf(aws.String("arn:aws:ecr:us-east-1:111122223333:repository/team/web"), "ecr", "repository")
+	if err != nil || aws.StringValue(name) != "team/web" || aws.StringValue(account) != "111122223333" {
+		t.Errorf("nameAndAccountOf = %q, %q, %v, want team/web of 111122223333", aws.StringValue(name), aws.StringValue(account), err)
+	}
+
+	name, account, err = o.nameAndAccountOf(aws.String("web"), "ecr", "repository")
+	if err != nil || aws.StringValue(name) != "web" || account != nil {
+		t.Errorf("nameAndAccountOf a name = %q, %v, %v, want no account", aws.StringValue(name), account, err)
+	}
+
+	if _, _, err := o.nameAndAccountOf(aws.String("arn:aws:ecr:eu-west-1:111122223333:repository/web"), "ecr", "repository"); err == nil {
+		t.Error("nameAndAccountOf accepted an ARN of another region")
+	}
+}
@@ -1,145 +1,344 @@
 package clients
 
 import (
-	"fmt"
+	"context"
+	"strings"
+	"time"
 
-	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/aws/aws-sdk-go/service/cloudformation/cloudformationiface"
 )
 
+type CFNAPI interface {
+	ListStacks() ([]*cloudformation.StackSummary, error)
+	ListStacksWithContext(ctx context.Context) ([]*cloudformation.StackSummary, error)
+	ListStacksPages(pageSize int64, fn func(summaries []*cloudformation.StackSummary) bool) error
+	ListStacksPagesWithContext(ctx context.Context, pageSize int64, fn func(summaries []*cloudformation.StackSummary) bool) error
+	DescribeStacks() ([]*cloudformation.Stack, error)
+	DescribeStacksWithContext(ctx context.Context) ([]*cloudformation.Stack, error)
+	DescribeStacksPages(pageSize int64, fn func(stacks []*cloudformation.Stack) bool) error
+	DescribeStacksPagesWithContext(ctx context.Context, pageSize int64, fn func(stacks []*cloudformation.Stack) bool) error
+	DescribeStack(stackName *string) (*cloudformation.Stack, error)
+	DescribeStackWithContext(ctx context.Context, stackName *string) (*cloudformation.Stack, error)
+	GetTemplate(stackName *string) (*string, error)
+	GetTemplateWithContext(ctx context.Context, stackName *string) (*string, error)
+	ListStackResources(stackName *string) ([]*cloudformation.StackResourceSummary, error)
+	ListStackResourcesWithContext(ctx context.Context, stackName *string) ([]*cloudformation.StackResourceSummary, error)
+	ListStackResourcesPages(stackName *string, pageSize int64, fn func(summaries []*cloudformation.StackResourceSummary) bool) error
+	ListStackResourcesPagesWithContext(ctx context.Context, stackName *string, pageSize int64, fn func(summaries []*cloudformation.StackResourceSummary) bool) error
+	ListChangeSets(stackName *string) ([]*cloudformation.ChangeSetSummary, error)
+	ListChangeSetsWithContext(ctx context.Context, stackName *string) ([]*cloudformation.ChangeSetSummary, error)
+	ListChangeSetsPages(stackName *string, pageSize int64, fn func(summaries []*cloudformation.ChangeSetSummary) bool) error
+	ListChangeSetsPagesWithContext(ctx context.Context, stackName *string, pageSize int64, fn func(summaries []*cloudformation.ChangeSetSummary) bool) error
+	ListStackSets() ([]*cloudformation.StackSetSummary, error)
+	ListStackSetsWithContext(ctx context.Context) ([]*cloudformation.StackSetSummary, error)
+	ListStackSetsPages(pageSize int64, fn func(summaries []*cloudformation.StackSetSummary) bool) error
+	ListStackSetsPagesWithContext(ctx context.Context, pageSize int64, fn func(summaries []*cloudformation.StackSetSummary) bool) error
+	WaitForStack(stackName *string, opts WaitOptions) (*cloudformation.Stack, error)
+	WaitForStackWithContext(ctx context.Context, stackName *string, opts WaitOptions) (*cloudformation.Stack, error)
+}
+
+var _ CFNAPI = (*CFNClient)(nil)
+
 type CFNClient struct {
-	cli *cloudformation.CloudFormation
+	logging
+	cli cloudformationiface.CloudFormationAPI
+}
+
+func init() {
+	Register("cloudformation", func(sess *session.Session) interface{} { return NewCloudformation(sess) })
+}
+
+func NewCloudformation(sess *session.Session, cfgs ...*aws.Config) *CFNClient {
+	client := cloudformation.New(sess, cfgs...)
+
+	return &CFNClient{logging: newLogging(sess), cli: client}
+}
+
+func NewCloudformationFromAPI(api cloudformationiface.CloudFormationAPI) *CFNClient {
+	return &CFNClient{logging: logging{logger: NopLogger}, cli: api}
 }
 
-func NewCloudformation(sess *session.Session) *CFNClient {
-	client := cloudformation.New(sess)
+func (cfn *CFNClient) ListStacks() ([]*cloudformation.StackSummary, error) {
+	return cfn.ListStacksWithContext(context.Background())
+}
+
+func (cfn *CFNClient) ListStacksWithContext(ctx context.Context) ([]*cloudformation.StackSummary, error) {
+	var summaries []*cloudformation.StackSummary
+
+	err := cfn.ListStacksPagesWithContext(ctx, 0, func(page []*cloudformation.StackSummary) bool {
+		summaries = append(summaries, page...)
+
+		return true
+	})
+
+	return summaries, err
+}
 
-	return &CFNClient{cli: client}
+func (cfn *CFNClient) ListStacksPages(pageSize int64, fn func(summaries []*cloudformation.StackSummary) bool) error {
+	return cfn.ListStacksPagesWithContext(context.Background(), pageSize, fn)
 }
 
-func (cfn *CFNClient) ListStacks() []*cloudformation.StackSummary {
+func (cfn *CFNClient) ListStacksPagesWithContext(ctx context.Context, pageSize int64, fn func(summaries []*cloudformation.StackSummary) bool) error {
 	input := &cloudformation.ListStacksInput{}
 
-	resp, err := cfn.cli.ListStacks(input)
-	if err != nil {
-		cfn.handleError(err)
+	for {
+		resp, err := cfn.cli.ListStacksWithContext(ctx, input)
+		if err != nil {
+			return cfn.handleError("ListStacks", err)
+		}
+
+		if !fn(resp.StackSummaries) || resp.NextToken == nil {
+			return nil
+		}
+
+		input.NextToken = resp.NextToken
 	}
+}
+
+// DescribeStacks describes every stack, with its tags and outputs. Unlike
+// ListStacks, it leaves out the stacks deleted in the last 90 days.
+func (cfn *CFNClient) DescribeStacks() ([]*cloudformation.Stack, error) {
+	return cfn.DescribeStacksWithContext(context.Background())
+}
 
-	summaries := resp.StackSummaries
+func (cfn *CFNClient) DescribeStacksWithContext(ctx context.Context) ([]*cloudformation.Stack, error) {
+	var stacks []*cloudformation.Stack
 
-	for resp.NextToken != nil {
-		input = &cloudformation.ListStacksInput{NextToken: resp.NextToken}
+	err := cfn.DescribeStacksPagesWithContext(ctx, 0, func(page []*cloudformation.Stack) bool {
+		stacks = append(stacks, page...)
+
+		return true
+	})
+
+	return stacks, err
+}
+
+func (cfn *CFNClient) DescribeStacksPages(pageSize int64, fn func(stacks []*cloudformation.Stack) bool) error {
+	return cfn.DescribeStacksPagesWithContext(context.Background(), pageSize, fn)
+}
 
-		resp, err = cfn.cli.ListStacks(input)
+func (cfn *CFNClient) DescribeStacksPagesWithContext(ctx context.Context, pageSize int64, fn func(stacks []*cloudformation.Stack) bool) error {
+	input := &cloudformation.DescribeStacksInput{}
+
+	for {
+		resp, err := cfn.cli.DescribeStacksWithContext(ctx, input)
 		if err != nil {
-			cfn.handleError(err)
+			return cfn.handleError("DescribeStacks", err)
+		}
+
+		if !fn(resp.Stacks) || resp.NextToken == nil {
+			return nil
 		}
 
-		summaries = append(summaries, resp.StackSummaries...)
+		input.NextToken = resp.NextToken
 	}
+}
 
-	return summaries
+func (cfn *CFNClient) DescribeStack(stackName *string) (*cloudformation.Stack, error) {
+	return cfn.DescribeStackWithContext(context.Background(), stackName)
 }
 
-func (cfn *CFNClient) GetTemplate(stackName *string) *string {
-	input := &cloudformation.GetTemplateInput{
+func (cfn *CFNClient) DescribeStackWithContext(ctx context.Context, stackName *string) (*cloudformation.Stack, error) {
+	input := &cloudformation.DescribeStacksInput{
 		StackName: stackName,
 	}
 
-	resp, err := cfn.cli.GetTemplate(input)
+	resp, err := cfn.cli.DescribeStacksWithContext(ctx, input)
 	if err != nil {
-		cfn.handleError(err)
+		return nil, cfn.handleError("DescribeStacks", err)
 	}
 
-	return resp.TemplateBody
+	if len(resp.Stacks) > 0 {
+		return resp.Stacks[0], nil
+	}
+
+	return nil, nil
+}
+
+func (cfn *CFNClient) GetTemplate(stackName *string) (*string, error) {
+	return cfn.GetTemplateWithContext(context.Background(), stackName)
 }
 
-func (cfn *CFNClient) ListStackResources(stackName *string) []*cloudformation.StackResourceSummary {
-	input := &cloudformation.ListStackResourcesInput{
+func (cfn *CFNClient) GetTemplateWithContext(ctx context.Context, stackName *string) (*string, error) {
+	input := &cloudformation.GetTemplateInput{
 		StackName: stackName,
 	}
 
-	resp, err := cfn.cli.ListStackResources(input)
+	resp, err := cfn.cli.GetTemplateWithContext(ctx, input)
 	if err != nil {
-		cfn.handleError(err)
+		return nil, cfn.handleError("GetTemplate", err)
 	}
 
-	summaries := resp.StackResourceSummaries
+	return resp.TemplateBody, nil
+}
+
+func (cfn *CFNClient) ListStackResources(stackName *string) ([]*cloudformation.StackResourceSummary, error) {
+	return cfn.ListStackResourcesWithContext(context.Background(), stackName)
+}
+
+func (cfn *CFNClient) ListStackResourcesWithContext(ctx context.Context, stackName *string) ([]*cloudformation.StackResourceSummary, error) {
+	var summaries []*cloudformation.StackResourceSummary
+
+	err := cfn.ListStackResourcesPagesWithContext(ctx, stackName, 0, func(page []*cloudformation.StackResourceSummary) bool {
+		summaries = append(summaries, page...)
+
+		return true
+	})
+
+	return summaries, err
+}
+
+func (cfn *CFNClient) ListStackResourcesPages(stackName *string, pageSize int64, fn func(summaries []*cloudformation.StackResourceSummary) bool) error {
+	return cfn.ListStackResourcesPagesWithContext(context.Background(), stackName, pageSize, fn)
+}
 
-	for resp.NextToken != nil {
-		input = &cloudformation.ListStackResourcesInput{NextToken: resp.NextToken}
+func (cfn *CFNClient) ListStackResourcesPagesWithContext(ctx context.Context, stackName *string, pageSize int64, fn func(summaries []*cloudformation.StackResourceSummary) bool) error {
+	input := &cloudformation.ListStackResourcesInput{StackName: stackName}
 
-		resp, err = cfn.cli.ListStackResources(input)
+	for {
+		resp, err := cfn.cli.ListStackResourcesWithContext(ctx, input)
 		if err != nil {
-			cfn.handleError(err)
+			return cfn.handleError("ListStackResources", err)
+		}
+
+		if !fn(resp.StackResourceSummaries) || resp.NextToken == nil {
+			return nil
 		}
 
-		summaries = append(summaries, resp.StackResourceSummaries...)
+		input.NextToken = resp.NextToken
 	}
+}
 
-	return summaries
+func (cfn *CFNClient) ListChangeSets(stackName *string) ([]*cloudformation.ChangeSetSummary, error) {
+	return cfn.ListChangeSetsWithContext(context.Background(), stackName)
 }
 
-func (cfn *CFNClient) ListChangeSets(stackName *string) []*cloudformation.ChangeSetSummary {
-	input := &cloudformation.ListChangeSetsInput{
-		StackName: stackName,
-	}
+func (cfn *CFNClient) ListChangeSetsWithContext(ctx context.Context, stackName *string) ([]*cloudformation.ChangeSetSummary, error) {
+	var summaries []*cloudformation.ChangeSetSummary
 
-	resp, err := cfn.cli.ListChangeSets(input)
-	if err != nil {
-		cfn.handleError(err)
-	}
+	err := cfn.ListChangeSetsPagesWithContext(ctx, stackName, 0, func(page []*cloudformation.ChangeSetSummary) bool {
+		summaries = append(summaries, page...)
 
-	summaries := resp.Summaries
+		return true
+	})
 
-	for resp.NextToken != nil {
-		input = &cloudformation.ListChangeSetsInput{NextToken: resp.NextToken}
+	return summaries, err
+}
 
-		resp, err = cfn.cli.ListChangeSets(input)
+func (cfn *CFNClient) ListChangeSetsPages(stackName *string, pageSize int64, fn func(summaries []*cloudformation.ChangeSetSummary) bool) error {
+	return cfn.ListChangeSetsPagesWithContext(context.Background(), stackName, pageSize, fn)
+}
+
+func (cfn *CFNClient) ListChangeSetsPagesWithContext(ctx context.Context, stackName *string, pageSize int64, fn func(summaries []*cloudformation.ChangeSetSummary) bool) error {
+	input := &cloudformation.ListChangeSetsInput{StackName: stackName}
+
+	for {
+		resp, err := cfn.cli.ListChangeSetsWithContext(ctx, input)
 		if err != nil {
-			cfn.handleError(err)
+			return cfn.handleError("ListChangeSets", err)
 		}
 
-		summaries = append(summaries, resp.Summaries...)
+		if !fn(resp.Summaries) || resp.NextToken == nil {
+			return nil
+		}
+
+		input.NextToken = resp.NextToken
 	}
+}
 
-	return summaries
+func (cfn *CFNClient) ListStackSets() ([]*cloudformation.StackSetSummary, error) {
+	return cfn.ListStackSetsWithContext(context.Background())
 }
 
-func (cfn *CFNClient) ListStackSets() []*cloudformation.StackSetSummary {
-	input := &cloudformation.ListStackSetsInput{}
+func (cfn *CFNClient) ListStackSetsWithContext(ctx context.Context) ([]*cloudformation.StackSetSummary, error) {
+	var summaries []*cloudformation.StackSetSummary
 
-	resp, err := cfn.cli.ListStackSets(input)
-	if err != nil {
-		cfn.handleError(err)
-	}
+	err := cfn.ListStackSetsPagesWithContext(ctx, 0, func(page []*cloudformation.StackSetSummary) bool {
+		summaries = append(summaries, page...)
 
-	summaries := resp.Summaries
+		return true
+	})
 
-	for resp.NextToken != nil {
-		input = &cloudformation.ListStackSetsInput{NextToken: resp.NextToken}
+	return summaries, err
+}
+
+func (cfn *CFNClient) ListStackSetsPages(pageSize int64, fn func(summaries []*cloudformation.StackSetSummary) bool) error {
+	return cfn.ListStackSetsPagesWithContext(context.Background(), pageSize, fn)
+}
 
-		resp, err = cfn.cli.ListStackSets(input)
+func (cfn *CFNClient) ListStackSetsPagesWithContext(ctx context.Context, pageSize int64, fn func(summaries []*cloudformation.StackSetSummary) bool) error {
+	input := &cloudformation.ListStackSetsInput{MaxResults: pageLimit(pageSize, 1, 100)}
+
+	for {
+		resp, err := cfn.cli.ListStackSetsWithContext(ctx, input)
 		if err != nil {
-			cfn.handleError(err)
+			return cfn.handleError("ListStackSets", err)
+		}
+
+		if !fn(resp.Summaries) || resp.NextToken == nil {
+			return nil
 		}
 
-		summaries = append(summaries, resp.Summaries...)
+		input.NextToken = resp.NextToken
 	}
+}
 
-	return summaries
+func (cfn *CFNClient) WaitForStack(stackName *string, opts WaitOptions) (*cloudformation.Stack, error) {
+	return cfn.WaitForStackWithContext(context.Background(), stackName, opts)
 }
 
-func (cfn *CFNClient) handleError(err error) {
-	if aerr, ok := err.(awserr.Error); ok {
-		switch aerr.Code() {
-		default:
-			fmt.Println(aerr.Error())
-		}
-	} else {
-		// Print the error, cast err to awserr.Error to get the Code and
-		// Message from an error.
-		fmt.Println(err.Error())
+// WaitForStackWithContext waits for the create, update or import of the
+// stack to complete, polling every 30 seconds for up to an hour. Stacks
+// that fail, roll back or are deleted end the wait with ErrFailureState.
+func (cfn *CFNClient) WaitForStackWithContext(ctx context.Context, stackName *string, opts WaitOptions) (*cloudformation.Stack, error) {
+	var stack *cloudformation.Stack
+
+	w := &Waiter{
+		Resource: "CloudFormation stack " + aws.StringValue(stackName),
+		State: func(ctx context.Context) (string, error) {
+			var err error
+
+			stack, err = cfn.DescribeStackWithContext(ctx, stackName)
+			if stack == nil {
+				// DescribeStacks rejects the names of stacks that do not
+				// exist as invalid.
+				if IsInvalidInput(err) && strings.Contains(err.Error(), "does not exist") {
+					err = nil
+				}
+
+				return resourceState(false, nil, err)
+			}
+
+			return resourceState(true, stack.StackStatus, err)
+		},
+		Success: []string{
+			cloudformation.StackStatusCreateComplete,
+			cloudformation.StackStatusUpdateComplete,
+			cloudformation.StackStatusImportComplete,
+		},
+		Failure: []string{
+			cloudformation.StackStatusCreateFailed,
+			cloudformation.StackStatusRollbackComplete,
+			cloudformation.StackStatusRollbackFailed,
+			cloudformation.StackStatusDeleteComplete,
+			cloudformation.StackStatusDeleteFailed,
+			cloudformation.StackStatusUpdateRollbackComplete,
+			cloudformation.StackStatusUpdateRollbackFailed,
+			cloudformation.StackStatusImportRollbackComplete,
+			cloudformation.StackStatusImportRollbackFailed,
+			StateNotFound,
+		},
+		WaitOptions: opts.withDefaults(WaitOptions{Timeout: time.Hour, Backoff: Backoff{Delay: 30 * time.Second}}),
 	}
+
+	_, err := w.Wait(ctx)
+
+	return stack, err
+}
+
+func (cfn *CFNClient) handleError(operation string, err error) error {
+	return cfn.logError(newError(cloudformation.ServiceName, operation, err))
 }
@@ -0,0 +1,33 @@
+package clients_test
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/mwlng/aws-go-clients/clients"
+)
+
+func TestCFNListStackResources(t *testing.T) {
+	cfn := clients.NewCloudformation(recordedSession(t, "list_stack_resources"))
+
+	resources, err := cfn.ListStackResources(aws.String("my-stack"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"Bucket":   "AWS::S3::Bucket",
+		"Queue":    "AWS::SQS::Queue",
+		"TaskRole": "AWS::IAM::Role",
+	}
+	if len(resources) != len(want) {
+		t.Fatalf("ListStackResources returned %d resources, want %d", len(resources), len(want))
+	}
+
+	for _, resource := range resources {
+		id := aws.StringValue(resource.LogicalResourceId)
+		if typ := aws.StringValue(resource.ResourceType); typ != want[id] {
+			t.Errorf("resource %s has type %q, want %q", id, typ, want[id])
+		}
+	}
+}
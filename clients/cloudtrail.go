@@ -1,28 +1,53 @@
 package clients
 
 import (
-	"fmt"
+	"context"
 
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/cloudtrail"
+	"github.com/aws/aws-sdk-go/service/cloudtrail/cloudtrailiface"
 )
 
+type CloudTrailAPI interface {
+	DescribeTrails(input *cloudtrail.DescribeTrailsInput) (*cloudtrail.DescribeTrailsOutput, error)
+	DescribeTrailsWithContext(ctx context.Context, input *cloudtrail.DescribeTrailsInput) (*cloudtrail.DescribeTrailsOutput, error)
+}
+
+var _ CloudTrailAPI = (*CloudTrailClient)(nil)
+
 type CloudTrailClient struct {
-	cli *cloudtrail.CloudTrail
+	logging
+	cli cloudtrailiface.CloudTrailAPI
 }
 
-func NewCloudTrail(sess *session.Session) *CloudTrailClient {
-	client := cloudtrail.New(sess)
+func init() {
+	Register("cloudtrail", func(sess *session.Session) interface{} { return NewCloudTrail(sess) })
+}
+
+func NewCloudTrail(sess *session.Session, cfgs ...*aws.Config) *CloudTrailClient {
+	client := cloudtrail.New(sess, cfgs...)
 
-	return &CloudTrailClient{cli: client}
+	return &CloudTrailClient{logging: newLogging(sess), cli: client}
 }
 
-func (ct *CloudTrailClient) DescribeTrails(input *cloudtrail.DescribeTrailsInput) *cloudtrail.DescribeTrailsOutput {
-	resp, err := ct.cli.DescribeTrails(input)
+func NewCloudTrailFromAPI(api cloudtrailiface.CloudTrailAPI) *CloudTrailClient {
+	return &CloudTrailClient{logging: logging{logger: NopLogger}, cli: api}
+}
+
+func (ct *CloudTrailClient) DescribeTrails(input *cloudtrail.DescribeTrailsInput) (*cloudtrail.DescribeTrailsOutput, error) {
+	return ct.DescribeTrailsWithContext(context.Background(), input)
+}
+
+func (ct *CloudTrailClient) DescribeTrailsWithContext(ctx context.Context, input *cloudtrail.DescribeTrailsInput) (*cloudtrail.DescribeTrailsOutput, error) {
+	resp, err := ct.cli.DescribeTrailsWithContext(ctx, input)
 	if err != nil {
-		fmt.Println("Got error describing trails:")
-		fmt.Println(err.Error())
+		return nil, ct.handleError("DescribeTrails", err)
 	}
 
-	return resp
+	return resp, nil
+}
+
+func (ct *CloudTrailClient) handleError(operation string, err error) error {
+	return ct.logError(newError(cloudtrail.ServiceName, operation, err))
 }
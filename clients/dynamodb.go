@@ -1,29 +1,67 @@
 package clients
 
 import (
-	"fmt"
+	"context"
 
 	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
 )
 
+type DynamoDBAPI interface {
+	CreateTable(tableName *string, attributeDefinitions []*dynamodb.AttributeDefinition, keySchema []*dynamodb.KeySchemaElement, provisionedThroughput *dynamodb.ProvisionedThroughput) (*dynamodb.TableDescription, error)
+	CreateTableWithContext(ctx context.Context, tableName *string, attributeDefinitions []*dynamodb.AttributeDefinition, keySchema []*dynamodb.KeySchemaElement, provisionedThroughput *dynamodb.ProvisionedThroughput) (*dynamodb.TableDescription, error)
+	ListTables() ([]*string, error)
+	ListTablesWithContext(ctx context.Context) ([]*string, error)
+	ListTablesPages(pageSize int64, fn func(tableNames []*string) bool) error
+	ListTablesPagesWithContext(ctx context.Context, pageSize int64, fn func(tableNames []*string) bool) error
+	GetItem(tableName *string, key map[string]*dynamodb.AttributeValue, item interface{}) error
+	GetItemWithContext(ctx context.Context, tableName *string, key map[string]*dynamodb.AttributeValue, item interface{}) error
+	PutItem(tableName *string, key map[string]*dynamodb.AttributeValue, item interface{}) error
+	PutItemWithContext(ctx context.Context, tableName *string, key map[string]*dynamodb.AttributeValue, item interface{}) error
+	UpdateItem(tableName *string, key map[string]*dynamodb.AttributeValue, attributeValues map[string]*dynamodb.AttributeValue) (map[string]*dynamodb.AttributeValue, error)
+	UpdateItemWithContext(ctx context.Context, tableName *string, key map[string]*dynamodb.AttributeValue, attributeValues map[string]*dynamodb.AttributeValue) (map[string]*dynamodb.AttributeValue, error)
+	DeleteItem(tableName *string, key map[string]*dynamodb.AttributeValue) error
+	DeleteItemWithContext(ctx context.Context, tableName *string, key map[string]*dynamodb.AttributeValue) error
+	ListTagsOfResource(arn *string) ([]*dynamodb.Tag, error)
+	ListTagsOfResourceWithContext(ctx context.Context, arn *string) ([]*dynamodb.Tag, error)
+}
+
+var _ DynamoDBAPI = (*DynamoDBClient)(nil)
+
 type DynamoDBClient struct {
-	cli *dynamodb.DynamoDB
+	logging
+	*owner
+	cli dynamodbiface.DynamoDBAPI
+}
+
+func init() {
+	Register("dynamodb", func(sess *session.Session) interface{} { return NewDynamoDB(sess) })
 }
 
-func NewDynamoDB(sess *session.Session) *DynamoDBClient {
-	client := dynamodb.New(sess)
+func NewDynamoDB(sess *session.Session, cfgs ...*aws.Config) *DynamoDBClient {
+	client := dynamodb.New(sess, cfgs...)
+
+	return &DynamoDBClient{logging: newLogging(sess), owner: newOwner(sess, client.Config), cli: client}
+}
 
-	return &DynamoDBClient{cli: client}
+func NewDynamoDBFromAPI(api dynamodbiface.DynamoDBAPI) *DynamoDBClient {
+	return &DynamoDBClient{logging: logging{logger: NopLogger}, owner: &owner{}, cli: api}
 }
 
 func (dynamoDBCli *DynamoDBClient) CreateTable(tableName *string,
 	attributeDefinitions []*dynamodb.AttributeDefinition,
 	keySchema []*dynamodb.KeySchemaElement,
-	provisionedThroughput *dynamodb.ProvisionedThroughput) {
+	provisionedThroughput *dynamodb.ProvisionedThroughput) (*dynamodb.TableDescription, error) {
+	return dynamoDBCli.CreateTableWithContext(context.Background(), tableName, attributeDefinitions, keySchema, provisionedThroughput)
+}
+
+func (dynamoDBCli *DynamoDBClient) CreateTableWithContext(ctx context.Context, tableName *string,
+	attributeDefinitions []*dynamodb.AttributeDefinition,
+	keySchema []*dynamodb.KeySchemaElement,
+	provisionedThroughput *dynamodb.ProvisionedThroughput) (*dynamodb.TableDescription, error) {
 	input := &dynamodb.CreateTableInput{
 		TableName:             tableName,
 		AttributeDefinitions:  attributeDefinitions,
@@ -31,52 +69,96 @@ func (dynamoDBCli *DynamoDBClient) CreateTable(tableName *string,
 		ProvisionedThroughput: provisionedThroughput,
 	}
 
-	_, err := dynamoDBCli.cli.CreateTable(input)
+	resp, err := dynamoDBCli.cli.CreateTableWithContext(ctx, input)
 	if err != nil {
-		handleError(err)
+		return nil, dynamoDBCli.handleError("CreateTable", err)
 	}
+
+	return resp.TableDescription, nil
 }
 
-func (dynamoDBCli *DynamoDBClient) ListTables() []*string {
-	input := &dynamodb.ListTablesInput{}
+func (dynamoDBCli *DynamoDBClient) ListTables() ([]*string, error) {
+	return dynamoDBCli.ListTablesWithContext(context.Background())
+}
 
-	result, err := dynamoDBCli.cli.ListTables(input)
-	if err != nil {
-		handleError(err)
+func (dynamoDBCli *DynamoDBClient) ListTablesWithContext(ctx context.Context) ([]*string, error) {
+	tableNames := []*string{}
 
-		return []*string{}
-	}
+	err := dynamoDBCli.ListTablesPagesWithContext(ctx, 0, func(page []*string) bool {
+		tableNames = append(tableNames, page...)
 
-	return result.TableNames
+		return true
+	})
+
+	return tableNames, err
+}
+
+func (dynamoDBCli *DynamoDBClient) ListTablesPages(pageSize int64, fn func(tableNames []*string) bool) error {
+	return dynamoDBCli.ListTablesPagesWithContext(context.Background(), pageSize, fn)
+}
+
+func (dynamoDBCli *DynamoDBClient) ListTablesPagesWithContext(ctx context.Context, pageSize int64, fn func(tableNames []*string) bool) error {
+	input := &dynamodb.ListTablesInput{Limit: pageLimit(pageSize, 1, 100)}
+
+	for {
+		resp, err := dynamoDBCli.cli.ListTablesWithContext(ctx, input)
+		if err != nil {
+			return dynamoDBCli.handleError("ListTables", err)
+		}
+
+		if !fn(resp.TableNames) || resp.LastEvaluatedTableName == nil {
+			return nil
+		}
+
+		input.ExclusiveStartTableName = resp.LastEvaluatedTableName
+	}
 }
 
 func (dynamoDBCli *DynamoDBClient) GetItem(tableName *string,
-	key map[string]*dynamodb.AttributeValue, item interface{}) {
+	key map[string]*dynamodb.AttributeValue, item interface{}) error {
+	return dynamoDBCli.GetItemWithContext(context.Background(), tableName, key, item)
+}
+
+func (dynamoDBCli *DynamoDBClient) GetItemWithContext(ctx context.Context, tableName *string,
+	key map[string]*dynamodb.AttributeValue, item interface{}) error {
+	tableName, err := dynamoDBCli.nameOf(ctx, tableName, "dynamodb", "table")
+	if err != nil {
+		return dynamoDBCli.handleError("GetItem", err)
+	}
+
 	input := &dynamodb.GetItemInput{
 		TableName: tableName,
 		Key:       key,
 	}
 
-	result, err := dynamoDBCli.cli.GetItem(input)
+	result, err := dynamoDBCli.cli.GetItemWithContext(ctx, input)
 	if err != nil {
-		handleError(err)
-
-		return
+		return dynamoDBCli.handleError("GetItem", err)
 	}
 
 	err = dynamodbattribute.UnmarshalMap(result.Item, item)
 	if err != nil {
-		handleError(err)
+		return dynamoDBCli.handleError("GetItem", err)
 	}
+
+	return nil
 }
 
 func (dynamoDBCli *DynamoDBClient) PutItem(tableName *string,
-	key map[string]*dynamodb.AttributeValue, item interface{}) {
+	key map[string]*dynamodb.AttributeValue, item interface{}) error {
+	return dynamoDBCli.PutItemWithContext(context.Background(), tableName, key, item)
+}
+
+func (dynamoDBCli *DynamoDBClient) PutItemWithContext(ctx context.Context, tableName *string,
+	key map[string]*dynamodb.AttributeValue, item interface{}) error {
 	av, err := dynamodbattribute.MarshalMap(item)
 	if err != nil {
-		handleError(err)
+		return dynamoDBCli.handleError("PutItem", err)
+	}
 
-		return
+	tableName, err = dynamoDBCli.nameOf(ctx, tableName, "dynamodb", "table")
+	if err != nil {
+		return dynamoDBCli.handleError("PutItem", err)
 	}
 
 	input := &dynamodb.PutItemInput{
@@ -84,15 +166,28 @@ func (dynamoDBCli *DynamoDBClient) PutItem(tableName *string,
 		Item:      av,
 	}
 
-	_, err = dynamoDBCli.cli.PutItem(input)
+	_, err = dynamoDBCli.cli.PutItemWithContext(ctx, input)
 	if err != nil {
-		handleError(err)
+		return dynamoDBCli.handleError("PutItem", err)
 	}
+
+	return nil
 }
 
 func (dynamoDBCli *DynamoDBClient) UpdateItem(tableName *string,
 	key map[string]*dynamodb.AttributeValue,
-	attributeValues map[string]*dynamodb.AttributeValue) {
+	attributeValues map[string]*dynamodb.AttributeValue) (map[string]*dynamodb.AttributeValue, error) {
+	return dynamoDBCli.UpdateItemWithContext(context.Background(), tableName, key, attributeValues)
+}
+
+func (dynamoDBCli *DynamoDBClient) UpdateItemWithContext(ctx context.Context, tableName *string,
+	key map[string]*dynamodb.AttributeValue,
+	attributeValues map[string]*dynamodb.AttributeValue) (map[string]*dynamodb.AttributeValue, error) {
+	tableName, err := dynamoDBCli.nameOf(ctx, tableName, "dynamodb", "table")
+	if err != nil {
+		return nil, dynamoDBCli.handleError("UpdateItem", err)
+	}
+
 	input := &dynamodb.UpdateItemInput{
 		TableName:                 tableName,
 		Key:                       key,
@@ -101,34 +196,63 @@ func (dynamoDBCli *DynamoDBClient) UpdateItem(tableName *string,
 		UpdateExpression:          aws.String("set Rating = :r"),
 	}
 
-	_, err := dynamoDBCli.cli.UpdateItem(input)
+	resp, err := dynamoDBCli.cli.UpdateItemWithContext(ctx, input)
 	if err != nil {
-		handleError(err)
+		return nil, dynamoDBCli.handleError("UpdateItem", err)
 	}
+
+	return resp.Attributes, nil
 }
 
 func (dynamoDBCli *DynamoDBClient) DeleteItem(tableName *string,
-	key map[string]*dynamodb.AttributeValue) {
+	key map[string]*dynamodb.AttributeValue) error {
+	return dynamoDBCli.DeleteItemWithContext(context.Background(), tableName, key)
+}
+
+func (dynamoDBCli *DynamoDBClient) DeleteItemWithContext(ctx context.Context, tableName *string,
+	key map[string]*dynamodb.AttributeValue) error {
+	tableName, err := dynamoDBCli.nameOf(ctx, tableName, "dynamodb", "table")
+	if err != nil {
+		return dynamoDBCli.handleError("DeleteItem", err)
+	}
+
 	input := &dynamodb.DeleteItemInput{
 		TableName: tableName,
 		Key:       key,
 	}
 
-	_, err := dynamoDBCli.cli.DeleteItem(input)
+	_, err = dynamoDBCli.cli.DeleteItemWithContext(ctx, input)
 	if err != nil {
-		handleError(err)
+		return dynamoDBCli.handleError("DeleteItem", err)
 	}
+
+	return nil
+}
+
+func (dynamoDBCli *DynamoDBClient) ListTagsOfResource(arn *string) ([]*dynamodb.Tag, error) {
+	return dynamoDBCli.ListTagsOfResourceWithContext(context.Background(), arn)
 }
 
-func handleError(err error) {
-	if aerr, ok := err.(awserr.Error); ok {
-		switch aerr.Code() {
-		case dynamodb.ErrCodeInternalServerError:
-			fmt.Println(dynamodb.ErrCodeInternalServerError, aerr.Error())
-		default:
-			fmt.Println(aerr.Error())
+func (dynamoDBCli *DynamoDBClient) ListTagsOfResourceWithContext(ctx context.Context, arn *string) ([]*dynamodb.Tag, error) {
+	input := &dynamodb.ListTagsOfResourceInput{ResourceArn: arn}
+	tags := []*dynamodb.Tag{}
+
+	for {
+		resp, err := dynamoDBCli.cli.ListTagsOfResourceWithContext(ctx, input)
+		if err != nil {
+			return nil, dynamoDBCli.handleError("ListTagsOfResource", err)
+		}
+
+		tags = append(tags, resp.Tags...)
+
+		if resp.NextToken == nil {
+			return tags, nil
 		}
-	} else {
-		fmt.Println(err.Error())
+
+		input.NextToken = resp.NextToken
 	}
 }
+
+func (dynamoDBCli *DynamoDBClient) handleError(operation string, err error) error {
+	return dynamoDBCli.logError(newError(dynamodb.ServiceName, operation, err))
+}
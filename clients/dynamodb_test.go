@@ -0,0 +1,56 @@
+package clients_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/mwlng/aws-go-clients/clients"
+	"github.com/mwlng/aws-go-clients/clients/fakes"
+)
+
+func TestDynamoDBRefusesForeignTableARNs(t *testing.T) {
+	api := fakes.NewDynamoDB()
+
+	_, err := api.CreateTableWithContext(context.Background(), &dynamodb.CreateTableInput{
+		TableName: aws.String("orders"),
+		AttributeDefinitions: []*dynamodb.AttributeDefinition{
+			{AttributeName: aws.String("id"), AttributeType: aws.String(dynamodb.ScalarAttributeTypeS)},
+		},
+		KeySchema: []*dynamodb.KeySchemaElement{
+			{AttributeName: aws.String("id"), KeyType: aws.String(dynamodb.KeyTypeHash)},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dynamoCli := clients.NewDynamoDBFromAPI(api)
+	key := map[string]*dynamodb.AttributeValue{"id": {S: aws.String("1")}}
+	item := map[string]interface{}{"id": "1"}
+
+	own := aws.String("arn:aws:dynamodb:" + fakes.Region + ":" + fakes.Account + ":table/orders")
+	if err := dynamoCli.PutItem(own, key, item); err == nil {
+		t.Fatal("PutItem took an ARN before the owner of the client was set")
+	}
+
+	dynamoCli.SetOwner(fakes.Account, fakes.Region)
+
+	if err := dynamoCli.PutItem(own, key, item); err != nil {
+		t.Fatalf("PutItem with the ARN of the client's table: %v", err)
+	}
+
+	for _, foreign := range []string{
+		"arn:aws:dynamodb:" + fakes.Region + ":999999999999:table/orders",
+		"arn:aws:dynamodb:eu-west-1:" + fakes.Account + ":table/orders",
+	} {
+		if err := dynamoCli.DeleteItem(aws.String(foreign), key); err == nil {
+			t.Errorf("DeleteItem took %s", foreign)
+		}
+	}
+
+	if len(api.Items("orders")) != 1 {
+		t.Errorf("table holds %d items, want the item put", len(api.Items("orders")))
+	}
+}
@@ -1,44 +1,153 @@
 package clients
 
 import (
-	"fmt"
+	"context"
 
 	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
 )
 
+type EC2API interface {
+	ListAllVpcs() ([]*ec2.Vpc, error)
+	ListAllVpcsWithContext(ctx context.Context) ([]*ec2.Vpc, error)
+	ListAllVpcsPages(pageSize int64, fn func(vpcs []*ec2.Vpc) bool) error
+	ListAllVpcsPagesWithContext(ctx context.Context, pageSize int64, fn func(vpcs []*ec2.Vpc) bool) error
+	ListAllAvailbleZones() (*ec2.DescribeAvailabilityZonesOutput, error)
+	ListAllAvailbleZonesWithContext(ctx context.Context) (*ec2.DescribeAvailabilityZonesOutput, error)
+	ListAllSubnets() (*ec2.DescribeSubnetsOutput, error)
+	ListAllSubnetsWithContext(ctx context.Context) (*ec2.DescribeSubnetsOutput, error)
+	ListAllSubnetsPages(pageSize int64, fn func(subnets []*ec2.Subnet) bool) error
+	ListAllSubnetsPagesWithContext(ctx context.Context, pageSize int64, fn func(subnets []*ec2.Subnet) bool) error
+	DescribeInstanceByName(name string) ([]*ec2.Instance, error)
+	DescribeInstanceByNameWithContext(ctx context.Context, name string) ([]*ec2.Instance, error)
+	ListAllInstances() ([]*ec2.Instance, error)
+	ListAllInstancesWithContext(ctx context.Context) ([]*ec2.Instance, error)
+	ListAllInstancesPages(pageSize int64, fn func(instances []*ec2.Instance) bool) error
+	ListAllInstancesPagesWithContext(ctx context.Context, pageSize int64, fn func(instances []*ec2.Instance) bool) error
+	ListAMIsByOwner(owner string) (*ec2.DescribeImagesOutput, error)
+	ListAMIsByOwnerWithContext(ctx context.Context, owner string) (*ec2.DescribeImagesOutput, error)
+}
+
+var _ EC2API = (*EC2Client)(nil)
+
 type EC2Client struct {
-	cli *ec2.EC2
+	logging
+	cli ec2iface.EC2API
+}
+
+func init() {
+	Register("ec2", func(sess *session.Session) interface{} { return NewEC2(sess) })
+}
+
+func NewEC2(sess *session.Session, cfgs ...*aws.Config) *EC2Client {
+	client := ec2.New(sess, cfgs...)
+
+	return &EC2Client{logging: newLogging(sess), cli: client}
+}
+
+func NewEC2FromAPI(api ec2iface.EC2API) *EC2Client {
+	return &EC2Client{logging: logging{logger: NopLogger}, cli: api}
+}
+
+func (ec2Cli *EC2Client) ListAllVpcs() ([]*ec2.Vpc, error) {
+	return ec2Cli.ListAllVpcsWithContext(context.Background())
+}
+
+func (ec2Cli *EC2Client) ListAllVpcsWithContext(ctx context.Context) ([]*ec2.Vpc, error) {
+	var vpcs []*ec2.Vpc
+
+	err := ec2Cli.ListAllVpcsPagesWithContext(ctx, 0, func(page []*ec2.Vpc) bool {
+		vpcs = append(vpcs, page...)
+
+		return true
+	})
+
+	return vpcs, err
+}
+
+func (ec2Cli *EC2Client) ListAllVpcsPages(pageSize int64, fn func(vpcs []*ec2.Vpc) bool) error {
+	return ec2Cli.ListAllVpcsPagesWithContext(context.Background(), pageSize, fn)
 }
 
-func NewEC2(sess *session.Session) *EC2Client {
-	client := ec2.New(sess)
+func (ec2Cli *EC2Client) ListAllVpcsPagesWithContext(ctx context.Context, pageSize int64, fn func(vpcs []*ec2.Vpc) bool) error {
+	input := &ec2.DescribeVpcsInput{MaxResults: pageLimit(pageSize, 5, 1000)}
+
+	for {
+		resp, err := ec2Cli.cli.DescribeVpcsWithContext(ctx, input)
+		if err != nil {
+			return ec2Cli.handleError("DescribeVpcs", err)
+		}
+
+		if !fn(resp.Vpcs) || resp.NextToken == nil {
+			return nil
+		}
+
+		input.NextToken = resp.NextToken
+	}
+}
 
-	return &EC2Client{cli: client}
+func (ec2Cli *EC2Client) ListAllAvailbleZones() (*ec2.DescribeAvailabilityZonesOutput, error) {
+	return ec2Cli.ListAllAvailbleZonesWithContext(context.Background())
 }
 
-func (ec2Cli *EC2Client) ListAllVpcs() []*ec2.Vpc {
-	input := &ec2.DescribeVpcsInput{}
+func (ec2Cli *EC2Client) ListAllAvailbleZonesWithContext(ctx context.Context) (*ec2.DescribeAvailabilityZonesOutput, error) {
+	input := &ec2.DescribeAvailabilityZonesInput{}
 
-	resp, err := ec2Cli.cli.DescribeVpcs(input)
+	resp, err := ec2Cli.cli.DescribeAvailabilityZonesWithContext(ctx, input)
 	if err != nil {
-		ec2Cli.handleError(err)
+		return nil, ec2Cli.handleError("DescribeAvailabilityZones", err)
+	}
+
+	return resp, nil
+}
+
+func (ec2Cli *EC2Client) ListAllSubnets() (*ec2.DescribeSubnetsOutput, error) {
+	return ec2Cli.ListAllSubnetsWithContext(context.Background())
+}
+
+func (ec2Cli *EC2Client) ListAllSubnetsWithContext(ctx context.Context) (*ec2.DescribeSubnetsOutput, error) {
+	var subnets []*ec2.Subnet
+
+	err := ec2Cli.ListAllSubnetsPagesWithContext(ctx, 0, func(page []*ec2.Subnet) bool {
+		subnets = append(subnets, page...)
+
+		return true
+	})
+	if err != nil && subnets == nil {
+		return nil, err
 	}
 
-	return resp.Vpcs
+	return &ec2.DescribeSubnetsOutput{Subnets: subnets}, err
+}
+
+func (ec2Cli *EC2Client) ListAllSubnetsPages(pageSize int64, fn func(subnets []*ec2.Subnet) bool) error {
+	return ec2Cli.ListAllSubnetsPagesWithContext(context.Background(), pageSize, fn)
 }
 
-func (ec2Cli *EC2Client) ListAllAvailbleZones() *ec2.DescribeAvailabilityZonesOutput {
-	return nil
+func (ec2Cli *EC2Client) ListAllSubnetsPagesWithContext(ctx context.Context, pageSize int64, fn func(subnets []*ec2.Subnet) bool) error {
+	input := &ec2.DescribeSubnetsInput{MaxResults: pageLimit(pageSize, 5, 1000)}
+
+	for {
+		resp, err := ec2Cli.cli.DescribeSubnetsWithContext(ctx, input)
+		if err != nil {
+			return ec2Cli.handleError("DescribeSubnets", err)
+		}
+
+		if !fn(resp.Subnets) || resp.NextToken == nil {
+			return nil
+		}
+
+		input.NextToken = resp.NextToken
+	}
 }
 
-func (ec2Cli *EC2Client) ListAllSubnets() *ec2.DescribeSubnetsOutput {
-	return nil
+func (ec2Cli *EC2Client) DescribeInstanceByName(name string) ([]*ec2.Instance, error) {
+	return ec2Cli.DescribeInstanceByNameWithContext(context.Background(), name)
 }
 
-func (ec2Cli *EC2Client) DescribeInstanceByName(name string) []*ec2.Instance {
+func (ec2Cli *EC2Client) DescribeInstanceByNameWithContext(ctx context.Context, name string) ([]*ec2.Instance, error) {
 	input := &ec2.DescribeInstancesInput{
 		Filters: []*ec2.Filter{
 			{
@@ -50,9 +159,9 @@ func (ec2Cli *EC2Client) DescribeInstanceByName(name string) []*ec2.Instance {
 		},
 	}
 
-	resp, err := ec2Cli.cli.DescribeInstances(input)
+	resp, err := ec2Cli.cli.DescribeInstancesWithContext(ctx, input)
 	if err != nil {
-		ec2Cli.handleError(err)
+		return nil, ec2Cli.handleError("DescribeInstances", err)
 	}
 
 	reservations := resp.Reservations
@@ -62,62 +171,72 @@ func (ec2Cli *EC2Client) DescribeInstanceByName(name string) []*ec2.Instance {
 		instances = append(instances, r.Instances...)
 	}
 
-	return instances
+	return instances, nil
 }
 
-func (ec2Cli *EC2Client) ListAllInstances() []*ec2.Instance {
-	input := &ec2.DescribeInstancesInput{}
+func (ec2Cli *EC2Client) ListAllInstances() ([]*ec2.Instance, error) {
+	return ec2Cli.ListAllInstancesWithContext(context.Background())
+}
 
-	resp, err := ec2Cli.cli.DescribeInstances(input)
-	if err != nil {
-		ec2Cli.handleError(err)
-	}
+func (ec2Cli *EC2Client) ListAllInstancesWithContext(ctx context.Context) ([]*ec2.Instance, error) {
+	instances := []*ec2.Instance{}
 
-	reservations := resp.Reservations
+	err := ec2Cli.ListAllInstancesPagesWithContext(ctx, 0, func(page []*ec2.Instance) bool {
+		instances = append(instances, page...)
+
+		return true
+	})
+
+	return instances, err
+}
 
-	for resp.NextToken != nil {
-		input = &ec2.DescribeInstancesInput{NextToken: resp.NextToken}
+func (ec2Cli *EC2Client) ListAllInstancesPages(pageSize int64, fn func(instances []*ec2.Instance) bool) error {
+	return ec2Cli.ListAllInstancesPagesWithContext(context.Background(), pageSize, fn)
+}
+
+// ListAllInstancesPagesWithContext flattens the reservations of every page
+// into its instances.
+func (ec2Cli *EC2Client) ListAllInstancesPagesWithContext(ctx context.Context, pageSize int64, fn func(instances []*ec2.Instance) bool) error {
+	input := &ec2.DescribeInstancesInput{MaxResults: pageLimit(pageSize, 5, 1000)}
 
-		resp, err = ec2Cli.cli.DescribeInstances(input)
+	for {
+		resp, err := ec2Cli.cli.DescribeInstancesWithContext(ctx, input)
 		if err != nil {
-			ec2Cli.handleError(err)
+			return ec2Cli.handleError("DescribeInstances", err)
 		}
 
-		reservations = append(reservations, resp.Reservations...)
-	}
+		instances := []*ec2.Instance{}
+		for _, r := range resp.Reservations {
+			instances = append(instances, r.Instances...)
+		}
 
-	instances := []*ec2.Instance{}
-	for _, r := range reservations {
-		instances = append(instances, r.Instances...)
+		if !fn(instances) || resp.NextToken == nil {
+			return nil
+		}
+
+		input.NextToken = resp.NextToken
 	}
+}
 
-	return instances
+func (ec2Cli *EC2Client) ListAMIsByOwner(owner string) (*ec2.DescribeImagesOutput, error) {
+	return ec2Cli.ListAMIsByOwnerWithContext(context.Background(), owner)
 }
 
-func (ec2Cli *EC2Client) ListAMIsByOwner(owner string) *ec2.DescribeImagesOutput {
+func (ec2Cli *EC2Client) ListAMIsByOwnerWithContext(ctx context.Context, owner string) (*ec2.DescribeImagesOutput, error) {
 	input := &ec2.DescribeImagesInput{
 		Owners: []*string{
 			aws.String(owner),
 		},
 	}
 
-	resp, err := ec2Cli.cli.DescribeImages(input)
+	resp, err := ec2Cli.cli.DescribeImagesWithContext(ctx, input)
 	if err != nil {
-		ec2Cli.handleError(err)
+		return nil, ec2Cli.handleError("DescribeImages", err)
 	}
 
-	return resp
+	return resp, nil
 }
 
-func (ec2Cli *EC2Client) handleError(err error) {
-	if aerr, ok := err.(awserr.Error); ok {
-		switch aerr.Code() {
-		default:
-			fmt.Println(aerr.Error())
-		}
-	} else {
-		// Print the error, cast err to awserr.Error to get the Code and
-		// Message from an error.
-		fmt.Println(err.Error())
-	}
+func (ec2Cli *EC2Client) handleError(operation string, err error) error {
+	return ec2Cli.logError(newError(ec2.ServiceName, operation, err))
 }
@@ -1,179 +1,301 @@
 package clients
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/ecr"
+	"github.com/aws/aws-sdk-go/service/ecr/ecriface"
 )
 
+type ECRAPI interface {
+	CreateRepository(repoName string) (*ecr.Repository, error)
+	CreateRepositoryWithContext(ctx context.Context, repoName string) (*ecr.Repository, error)
+	ListRepositories() ([]*ecr.Repository, error)
+	ListRepositoriesWithContext(ctx context.Context) ([]*ecr.Repository, error)
+	ListRepositoriesPages(pageSize int64, fn func(repositories []*ecr.Repository) bool) error
+	ListRepositoriesPagesWithContext(ctx context.Context, pageSize int64, fn func(repositories []*ecr.Repository) bool) error
+	ListImageIdsByRepository(repoName *string) ([]*ecr.ImageIdentifier, error)
+	ListImageIdsByRepositoryWithContext(ctx context.Context, repoName *string) ([]*ecr.ImageIdentifier, error)
+	ListImageIdsByRepositoryPages(repoName *string, pageSize int64, fn func(images []*ecr.ImageIdentifier) bool) error
+	ListImageIdsByRepositoryPagesWithContext(ctx context.Context, repoName *string, pageSize int64, fn func(images []*ecr.ImageIdentifier) bool) error
+	DescribeImageByID(repoName *string, id *ecr.ImageIdentifier) (*ecr.ImageDetail, error)
+	DescribeImageByIDWithContext(ctx context.Context, repoName *string, id *ecr.ImageIdentifier) (*ecr.ImageDetail, error)
+	SetRepositoryPolicy(input *ecr.SetRepositoryPolicyInput) (*ecr.SetRepositoryPolicyOutput, error)
+	SetRepositoryPolicyWithContext(ctx context.Context, input *ecr.SetRepositoryPolicyInput) (*ecr.SetRepositoryPolicyOutput, error)
+	GetRepositoryPolicy(input *ecr.GetRepositoryPolicyInput) (*ecr.GetRepositoryPolicyOutput, error)
+	GetRepositoryPolicyWithContext(ctx context.Context, input *ecr.GetRepositoryPolicyInput) (*ecr.GetRepositoryPolicyOutput, error)
+	DeleteRepository(input *ecr.DeleteRepositoryInput) (*ecr.DeleteRepositoryOutput, error)
+	DeleteRepositoryWithContext(ctx context.Context, input *ecr.DeleteRepositoryInput) (*ecr.DeleteRepositoryOutput, error)
+	GetAuthorizationToken() ([]*ecr.AuthorizationData, error)
+	GetAuthorizationTokenWithContext(ctx context.Context) ([]*ecr.AuthorizationData, error)
+	UploadImage(srcImage, imageTag, registryID, RepoName string) (*ecr.Image, error)
+	UploadImageWithContext(ctx context.Context, srcImage, imageTag, registryID, RepoName string) (*ecr.Image, error)
+	ListTagsForResource(arn *string) ([]*ecr.Tag, error)
+	ListTagsForResourceWithContext(ctx context.Context, arn *string) ([]*ecr.Tag, error)
+}
+
+var _ ECRAPI = (*ECRClient)(nil)
+
 type ECRClient struct {
-	cli *ecr.ECR
+	logging
+	*owner
+	cli ecriface.ECRAPI
+}
+
+func init() {
+	Register("ecr", func(sess *session.Session) interface{} { return NewECR(sess) })
 }
 
-func NewECR(sess *session.Session) *ECRClient {
-	client := ecr.New(sess)
+func NewECR(sess *session.Session, cfgs ...*aws.Config) *ECRClient {
+	client := ecr.New(sess, cfgs...)
+
+	return &ECRClient{logging: newLogging(sess), owner: newOwner(sess, client.Config), cli: client}
+}
+
+func NewECRFromAPI(api ecriface.ECRAPI) *ECRClient {
+	return &ECRClient{logging: logging{logger: NopLogger}, owner: &owner{}, cli: api}
+}
 
-	return &ECRClient{cli: client}
+func (ecrCli *ECRClient) CreateRepository(repoName string) (*ecr.Repository, error) {
+	return ecrCli.CreateRepositoryWithContext(context.Background(), repoName)
 }
 
-func (ecrCli *ECRClient) CreateRepository(repoName string) {
+func (ecrCli *ECRClient) CreateRepositoryWithContext(ctx context.Context, repoName string) (*ecr.Repository, error) {
 	input := &ecr.CreateRepositoryInput{
 		RepositoryName: aws.String(repoName),
 	}
 
-	_, err := ecrCli.cli.CreateRepository(input)
-
+	resp, err := ecrCli.cli.CreateRepositoryWithContext(ctx, input)
 	if err != nil {
-		ecrCli.handleError(err)
+		return nil, ecrCli.handleError("CreateRepository", err)
 	}
+
+	return resp.Repository, nil
 }
 
-func (ecrCli *ECRClient) ListRepositories() []*ecr.Repository {
-	input := &ecr.DescribeRepositoriesInput{}
+func (ecrCli *ECRClient) ListRepositories() ([]*ecr.Repository, error) {
+	return ecrCli.ListRepositoriesWithContext(context.Background())
+}
 
-	resp, err := ecrCli.cli.DescribeRepositories(input)
-	if err != nil {
-		ecrCli.handleError(err)
-	}
+func (ecrCli *ECRClient) ListRepositoriesWithContext(ctx context.Context) ([]*ecr.Repository, error) {
+	var repositories []*ecr.Repository
+
+	err := ecrCli.ListRepositoriesPagesWithContext(ctx, 0, func(page []*ecr.Repository) bool {
+		repositories = append(repositories, page...)
+
+		return true
+	})
+
+	return repositories, err
+}
 
-	repositories := resp.Repositories
-	input = &ecr.DescribeRepositoriesInput{NextToken: resp.NextToken}
+func (ecrCli *ECRClient) ListRepositoriesPages(pageSize int64, fn func(repositories []*ecr.Repository) bool) error {
+	return ecrCli.ListRepositoriesPagesWithContext(context.Background(), pageSize, fn)
+}
+
+func (ecrCli *ECRClient) ListRepositoriesPagesWithContext(ctx context.Context, pageSize int64, fn func(repositories []*ecr.Repository) bool) error {
+	input := &ecr.DescribeRepositoriesInput{MaxResults: pageLimit(pageSize, 1, 1000)}
 
-	for resp.NextToken != nil {
-		resp, err = ecrCli.cli.DescribeRepositories(input)
+	for {
+		resp, err := ecrCli.cli.DescribeRepositoriesWithContext(ctx, input)
 		if err != nil {
-			ecrCli.handleError(err)
+			return ecrCli.handleError("DescribeRepositories", err)
 		}
 
-		repositories = append(repositories, resp.Repositories...)
+		if !fn(resp.Repositories) || resp.NextToken == nil {
+			return nil
+		}
+
+		input.NextToken = resp.NextToken
 	}
+}
+
+func (ecrCli *ECRClient) ListImageIdsByRepository(repoName *string) ([]*ecr.ImageIdentifier, error) {
+	return ecrCli.ListImageIdsByRepositoryWithContext(context.Background(), repoName)
+}
+
+func (ecrCli *ECRClient) ListImageIdsByRepositoryWithContext(ctx context.Context, repoName *string) ([]*ecr.ImageIdentifier, error) {
+	var images []*ecr.ImageIdentifier
+
+	err := ecrCli.ListImageIdsByRepositoryPagesWithContext(ctx, repoName, 0, func(page []*ecr.ImageIdentifier) bool {
+		images = append(images, page...)
 
-	return repositories
+		return true
+	})
+
+	return images, err
 }
 
-func (ecrCli *ECRClient) ListImageIdsByRepository(repoName *string) []*ecr.ImageIdentifier {
-	input := &ecr.ListImagesInput{RepositoryName: repoName}
+func (ecrCli *ECRClient) ListImageIdsByRepositoryPages(repoName *string, pageSize int64, fn func(images []*ecr.ImageIdentifier) bool) error {
+	return ecrCli.ListImageIdsByRepositoryPagesWithContext(context.Background(), repoName, pageSize, fn)
+}
 
-	resp, err := ecrCli.cli.ListImages(input)
+func (ecrCli *ECRClient) ListImageIdsByRepositoryPagesWithContext(ctx context.Context, repoName *string, pageSize int64, fn func(images []*ecr.ImageIdentifier) bool) error {
+	repoName, registryID, err := ecrCli.nameAndAccountOf(repoName, "ecr", "repository")
 	if err != nil {
-		ecrCli.handleError(err)
+		return ecrCli.handleError("ListImages", err)
 	}
 
-	images := resp.ImageIds
+	input := &ecr.ListImagesInput{
+		RegistryId:     registryID,
+		RepositoryName: repoName,
+		MaxResults:     pageLimit(pageSize, 1, 1000),
+	}
 
-	for resp.NextToken != nil {
-		input = &ecr.ListImagesInput{
-			NextToken:      resp.NextToken,
-			RepositoryName: repoName,
+	for {
+		resp, err := ecrCli.cli.ListImagesWithContext(ctx, input)
+		if err != nil {
+			return ecrCli.handleError("ListImages", err)
 		}
 
-		resp, err = ecrCli.cli.ListImages(input)
-		if err != nil {
-			ecrCli.handleError(err)
+		if !fn(resp.ImageIds) || resp.NextToken == nil {
+			return nil
 		}
 
-		images = append(images, resp.ImageIds...)
+		input.NextToken = resp.NextToken
 	}
+}
 
-	return images
+func (ecrCli *ECRClient) DescribeImageByID(repoName *string, id *ecr.ImageIdentifier) (*ecr.ImageDetail, error) {
+	return ecrCli.DescribeImageByIDWithContext(context.Background(), repoName, id)
 }
 
-func (ecrCli *ECRClient) DescribeImageByID(repoName *string, id *ecr.ImageIdentifier) *ecr.ImageDetail {
+func (ecrCli *ECRClient) DescribeImageByIDWithContext(ctx context.Context, repoName *string, id *ecr.ImageIdentifier) (*ecr.ImageDetail, error) {
+	repoName, registryID, err := ecrCli.nameAndAccountOf(repoName, "ecr", "repository")
+	if err != nil {
+		return nil, ecrCli.handleError("DescribeImages", err)
+	}
+
 	input := &ecr.DescribeImagesInput{
+		RegistryId:     registryID,
 		RepositoryName: repoName,
 		ImageIds:       []*ecr.ImageIdentifier{id},
 	}
 
-	resp, err := ecrCli.cli.DescribeImages(input)
+	resp, err := ecrCli.cli.DescribeImagesWithContext(ctx, input)
 	if err != nil {
-		ecrCli.handleError(err)
+		return nil, ecrCli.handleError("DescribeImages", err)
+	}
 
-		return &ecr.ImageDetail{}
+	if len(resp.ImageDetails) > 0 {
+		return resp.ImageDetails[0], nil
 	}
 
-	return resp.ImageDetails[0]
+	return nil, nil
 }
 
-func (ecrCli *ECRClient) SetRepositoryPolicy(input *ecr.SetRepositoryPolicyInput) *ecr.SetRepositoryPolicyOutput {
-	resp, err := ecrCli.cli.SetRepositoryPolicy(input)
+func (ecrCli *ECRClient) SetRepositoryPolicy(input *ecr.SetRepositoryPolicyInput) (*ecr.SetRepositoryPolicyOutput, error) {
+	return ecrCli.SetRepositoryPolicyWithContext(context.Background(), input)
+}
+
+func (ecrCli *ECRClient) SetRepositoryPolicyWithContext(ctx context.Context, input *ecr.SetRepositoryPolicyInput) (*ecr.SetRepositoryPolicyOutput, error) {
+	resp, err := ecrCli.cli.SetRepositoryPolicyWithContext(ctx, input)
 	if err != nil {
-		ecrCli.handleError(err)
+		return nil, ecrCli.handleError("SetRepositoryPolicy", err)
 	}
 
-	return resp
+	return resp, nil
 }
 
-func (ecrCli *ECRClient) GetRepositoryPolicy(input *ecr.GetRepositoryPolicyInput) *ecr.GetRepositoryPolicyOutput {
-	resp, err := ecrCli.cli.GetRepositoryPolicy(input)
+func (ecrCli *ECRClient) GetRepositoryPolicy(input *ecr.GetRepositoryPolicyInput) (*ecr.GetRepositoryPolicyOutput, error) {
+	return ecrCli.GetRepositoryPolicyWithContext(context.Background(), input)
+}
+
+func (ecrCli *ECRClient) GetRepositoryPolicyWithContext(ctx context.Context, input *ecr.GetRepositoryPolicyInput) (*ecr.GetRepositoryPolicyOutput, error) {
+	resp, err := ecrCli.cli.GetRepositoryPolicyWithContext(ctx, input)
 	if err != nil {
-		ecrCli.handleError(err)
+		return nil, ecrCli.handleError("GetRepositoryPolicy", err)
 	}
 
-	return resp
+	return resp, nil
+}
+
+func (ecrCli *ECRClient) DeleteRepository(input *ecr.DeleteRepositoryInput) (*ecr.DeleteRepositoryOutput, error) {
+	return ecrCli.DeleteRepositoryWithContext(context.Background(), input)
 }
 
-func (ecrCli *ECRClient) DeleteRepository(input *ecr.DeleteRepositoryInput) *ecr.DeleteRepositoryOutput {
-	resp, err := ecrCli.cli.DeleteRepository(input)
+func (ecrCli *ECRClient) DeleteRepositoryWithContext(ctx context.Context, input *ecr.DeleteRepositoryInput) (*ecr.DeleteRepositoryOutput, error) {
+	resp, err := ecrCli.cli.DeleteRepositoryWithContext(ctx, input)
 	if err != nil {
-		ecrCli.handleError(err)
+		return nil, ecrCli.handleError("DeleteRepository", err)
 	}
 
-	return resp
+	return resp, nil
+}
+
+func (ecrCli *ECRClient) GetAuthorizationToken() ([]*ecr.AuthorizationData, error) {
+	return ecrCli.GetAuthorizationTokenWithContext(context.Background())
 }
 
-func (ecrCli *ECRClient) GetAuthorizationToken() []*ecr.AuthorizationData {
+func (ecrCli *ECRClient) GetAuthorizationTokenWithContext(ctx context.Context) ([]*ecr.AuthorizationData, error) {
 	input := &ecr.GetAuthorizationTokenInput{}
 
-	result, err := ecrCli.cli.GetAuthorizationToken(input)
+	result, err := ecrCli.cli.GetAuthorizationTokenWithContext(ctx, input)
 	if err != nil {
-		ecrCli.handleError(err)
-
-		return nil
+		return nil, ecrCli.handleError("GetAuthorizationToken", err)
 	}
 
-	return result.AuthorizationData
+	return result.AuthorizationData, nil
 }
 
-func (ecrCli *ECRClient) UploadImage(srcImage, imageTag, registryID, RepoName string) {
+func (ecrCli *ECRClient) UploadImage(srcImage, imageTag, registryID, RepoName string) (*ecr.Image, error) {
+	return ecrCli.UploadImageWithContext(context.Background(), srcImage, imageTag, registryID, RepoName)
+}
+
+// UploadImageWithContext takes the registry from the ARN of the repository
+// when registryID is empty.
+func (ecrCli *ECRClient) UploadImageWithContext(ctx context.Context, srcImage, imageTag, registryID, RepoName string) (*ecr.Image, error) {
+	repoName, repoRegistryID, err := ecrCli.nameAndAccountOf(aws.String(RepoName), "ecr", "repository")
+	if err != nil {
+		return nil, ecrCli.handleError("PutImage", err)
+	}
+
+	if repoRegistryID != nil && registryID != "" && *repoRegistryID != registryID {
+		msg := fmt.Sprintf("%s is not in registry %s", RepoName, registryID)
+
+		return nil, ecrCli.handleError("PutImage", awserr.New("InvalidParameterValue", msg, nil))
+	}
+
+	if registryID != "" {
+		repoRegistryID = aws.String(registryID)
+	}
+
 	input := &ecr.PutImageInput{
 		ImageManifest:  aws.String(srcImage),
 		ImageTag:       aws.String(imageTag),
-		RegistryId:     aws.String(registryID),
-		RepositoryName: aws.String(RepoName),
+		RegistryId:     repoRegistryID,
+		RepositoryName: repoName,
 	}
 
-	_, err := ecrCli.cli.PutImage(input)
+	resp, err := ecrCli.cli.PutImageWithContext(ctx, input)
 	if err != nil {
-		ecrCli.handleError(err)
-	}
-}
-
-func (ecrCli *ECRClient) handleError(err error) {
-	if aerr, ok := err.(awserr.Error); ok {
-		switch aerr.Code() {
-		case ecr.ErrCodeServerException:
-			fmt.Println(ecr.ErrCodeServerException, aerr.Error())
-		case ecr.ErrCodeInvalidParameterException:
-			fmt.Println(ecr.ErrCodeInvalidParameterException, aerr.Error())
-		case ecr.ErrCodeInvalidTagParameterException:
-			fmt.Println(ecr.ErrCodeInvalidTagParameterException, aerr.Error())
-		case ecr.ErrCodeTooManyTagsException:
-			fmt.Println(ecr.ErrCodeTooManyTagsException, aerr.Error())
-		case ecr.ErrCodeRepositoryAlreadyExistsException:
-			fmt.Println(ecr.ErrCodeRepositoryAlreadyExistsException, aerr.Error())
-		case ecr.ErrCodeLimitExceededException:
-			fmt.Println(ecr.ErrCodeLimitExceededException, aerr.Error())
-		case ecr.ErrCodeKmsException:
-			fmt.Println(ecr.ErrCodeKmsException, aerr.Error())
-		default:
-			fmt.Println(aerr.Error())
-		}
-	} else {
-		// Print the error, cast err to awserr.Error to get the Code and
-		// Message from an error.
-		fmt.Println(err.Error())
+		return nil, ecrCli.handleError("PutImage", err)
 	}
+
+	return resp.Image, nil
+}
+
+func (ecrCli *ECRClient) ListTagsForResource(arn *string) ([]*ecr.Tag, error) {
+	return ecrCli.ListTagsForResourceWithContext(context.Background(), arn)
+}
+
+func (ecrCli *ECRClient) ListTagsForResourceWithContext(ctx context.Context, arn *string) ([]*ecr.Tag, error) {
+	input := &ecr.ListTagsForResourceInput{
+		ResourceArn: arn,
+	}
+
+	resp, err := ecrCli.cli.ListTagsForResourceWithContext(ctx, input)
+	if err != nil {
+		return nil, ecrCli.handleError("ListTagsForResource", err)
+	}
+
+	return resp.Tags, nil
+}
+
+func (ecrCli *ECRClient) handleError(operation string, err error) error {
+	return ecrCli.logError(newError(ecr.ServiceName, operation, err))
 }
@@ -1,332 +1,419 @@
 package clients
 
 import (
-	"fmt"
+	"context"
+	"time"
 
-	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/aws/aws-sdk-go/service/ecs/ecsiface"
 )
 
-type ECSClient struct {
-	cli *ecs.ECS
+type ECSAPI interface {
+	ListClusters() ([]*ecs.Cluster, error)
+	ListClustersWithContext(ctx context.Context) ([]*ecs.Cluster, error)
+	ListClustersPages(pageSize int64, fn func(clusters []*ecs.Cluster) bool) error
+	ListClustersPagesWithContext(ctx context.Context, pageSize int64, fn func(clusters []*ecs.Cluster) bool) error
+	DescribeClusters(clusterArns []*string) ([]*ecs.Cluster, error)
+	DescribeClustersWithContext(ctx context.Context, clusterArns []*string) ([]*ecs.Cluster, error)
+	ListServicesByCluster(clusterName *string) ([]*ecs.Service, error)
+	ListServicesByClusterWithContext(ctx context.Context, clusterName *string) ([]*ecs.Service, error)
+	ListServicesByClusterPages(clusterName *string, pageSize int64, fn func(services []*ecs.Service) bool) error
+	ListServicesByClusterPagesWithContext(ctx context.Context, clusterName *string, pageSize int64, fn func(services []*ecs.Service) bool) error
+	DescribeServices(clusterName *string, serviceArns []*string) ([]*ecs.Service, error)
+	DescribeServicesWithContext(ctx context.Context, clusterName *string, serviceArns []*string) ([]*ecs.Service, error)
+	ListTasksByService(clusterName *string, serviceName *string) ([]*ecs.Task, error)
+	ListTasksByServiceWithContext(ctx context.Context, clusterName *string, serviceName *string) ([]*ecs.Task, error)
+	ListTasksByServicePages(clusterName *string, serviceName *string, pageSize int64, fn func(tasks []*ecs.Task) bool) error
+	ListTasksByServicePagesWithContext(ctx context.Context, clusterName *string, serviceName *string, pageSize int64, fn func(tasks []*ecs.Task) bool) error
+	DescribeTasks(clusterName *string, taskArns []*string) ([]*ecs.Task, error)
+	DescribeTasksWithContext(ctx context.Context, clusterName *string, taskArns []*string) ([]*ecs.Task, error)
+	ListTaskDefinitions() ([]*string, error)
+	ListTaskDefinitionsWithContext(ctx context.Context) ([]*string, error)
+	ListTaskDefinitionsPages(pageSize int64, fn func(definitionArns []*string) bool) error
+	ListTaskDefinitionsPagesWithContext(ctx context.Context, pageSize int64, fn func(definitionArns []*string) bool) error
+	DescribeTaskDefinition(taskDefArn *string) (*ecs.TaskDefinition, error)
+	DescribeTaskDefinitionWithContext(ctx context.Context, taskDefArn *string) (*ecs.TaskDefinition, error)
+	WaitForServiceStable(clusterName, serviceName *string, opts WaitOptions) (*ecs.Service, error)
+	WaitForServiceStableWithContext(ctx context.Context, clusterName, serviceName *string, opts WaitOptions) (*ecs.Service, error)
 }
 
-func NewECS(sess *session.Session) *ECSClient {
-	client := ecs.New(sess)
+var _ ECSAPI = (*ECSClient)(nil)
 
-	return &ECSClient{cli: client}
+type ECSClient struct {
+	logging
+	*owner
+	cli ecsiface.ECSAPI
 }
 
-func (ecsCli *ECSClient) ListClusters() []*ecs.Cluster {
-	input := &ecs.ListClustersInput{}
-
-	resp, err := ecsCli.cli.ListClusters(input)
-	if err != nil {
-		ecsCli.handleError(err)
-	}
-
-	clusterArns := resp.ClusterArns
-
-	for resp.NextToken != nil {
-		input = &ecs.ListClustersInput{NextToken: resp.NextToken}
+func init() {
+	Register("ecs", func(sess *session.Session) interface{} { return NewECS(sess) })
+}
 
-		resp, err := ecsCli.cli.ListClusters(input)
-		if err != nil {
-			ecsCli.handleError(err)
-		}
+func NewECS(sess *session.Session, cfgs ...*aws.Config) *ECSClient {
+	client := ecs.New(sess, cfgs...)
 
-		clusterArns = append(clusterArns, resp.ClusterArns...)
-	}
-
-	if len(clusterArns) == 0 {
-		return []*ecs.Cluster{}
-	}
+	return &ECSClient{logging: newLogging(sess), owner: newOwner(sess, client.Config), cli: client}
+}
 
-	return ecsCli.DescribeClusters(clusterArns)
+func NewECSFromAPI(api ecsiface.ECSAPI) *ECSClient {
+	return &ECSClient{logging: logging{logger: NopLogger}, owner: &owner{}, cli: api}
 }
 
-func (ecsCli *ECSClient) DescribeClusters(clusterArns []*string) []*ecs.Cluster {
-	total := len(clusterArns)
+func (ecsCli *ECSClient) ListClusters() ([]*ecs.Cluster, error) {
+	return ecsCli.ListClustersWithContext(context.Background())
+}
 
-	if total <= 0 {
-		return []*ecs.Cluster{}
-	}
+func (ecsCli *ECSClient) ListClustersWithContext(ctx context.Context) ([]*ecs.Cluster, error) {
+	clusters := []*ecs.Cluster{}
 
-	i := 0
-	batchStart := i * 100
+	err := ecsCli.ListClustersPagesWithContext(ctx, 0, func(page []*ecs.Cluster) bool {
+		clusters = append(clusters, page...)
 
-	batchEnd := (i + 1) * 100
-	if batchEnd > total {
-		batchEnd = total % 100
-	}
+		return true
+	})
 
-	input := &ecs.DescribeClustersInput{Clusters: clusterArns[batchStart:batchEnd]}
+	return clusters, err
+}
 
-	resp, err := ecsCli.cli.DescribeClusters(input)
-	if err != nil {
-		ecsCli.handleError(err)
-	}
+func (ecsCli *ECSClient) ListClustersPages(pageSize int64, fn func(clusters []*ecs.Cluster) bool) error {
+	return ecsCli.ListClustersPagesWithContext(context.Background(), pageSize, fn)
+}
 
-	clusters := resp.Clusters
-	i = 1
+// ListClustersPagesWithContext describes every page of clusters before
+// passing it to fn.
+func (ecsCli *ECSClient) ListClustersPagesWithContext(ctx context.Context, pageSize int64, fn func(clusters []*ecs.Cluster) bool) error {
+	input := &ecs.ListClustersInput{MaxResults: pageLimit(pageSize, 1, 100)}
 
 	for {
-		if i*100 >= total {
-			break
+		resp, err := ecsCli.cli.ListClustersWithContext(ctx, input)
+		if err != nil {
+			return ecsCli.handleError("ListClusters", err)
 		}
 
-		batchEnd = (i + 1) * 100
-		if batchEnd > total {
-			batchEnd = i*100 + total%100
-		}
+		if len(resp.ClusterArns) > 0 {
+			clusters, err := ecsCli.DescribeClustersWithContext(ctx, resp.ClusterArns)
+			if err != nil {
+				return err
+			}
 
-		input = &ecs.DescribeClustersInput{Clusters: clusterArns[batchStart:batchEnd]}
+			if !fn(clusters) {
+				return nil
+			}
+		}
 
-		resp, err = ecsCli.cli.DescribeClusters(input)
-		if err != nil {
-			ecsCli.handleError(err)
+		if resp.NextToken == nil {
+			return nil
 		}
 
-		clusters = append(clusters, resp.Clusters...)
-		i++
+		input.NextToken = resp.NextToken
 	}
-
-	return clusters
 }
 
-func (ecsCli *ECSClient) ListServicesByCluster(clusterName *string) []*ecs.Service {
-	input := &ecs.ListServicesInput{Cluster: clusterName}
+func (ecsCli *ECSClient) DescribeClusters(clusterArns []*string) ([]*ecs.Cluster, error) {
+	return ecsCli.DescribeClustersWithContext(context.Background(), clusterArns)
+}
 
-	resp, err := ecsCli.cli.ListServices(input)
-	if err != nil {
-		ecsCli.handleError(err)
-	}
+func (ecsCli *ECSClient) DescribeClustersWithContext(ctx context.Context, clusterArns []*string) ([]*ecs.Cluster, error) {
+	clusters := []*ecs.Cluster{}
 
-	serviceArns := resp.ServiceArns
+	for batchStart := 0; batchStart < len(clusterArns); batchStart += 100 {
+		batchEnd := batchStart + 100
+		if batchEnd > len(clusterArns) {
+			batchEnd = len(clusterArns)
+		}
 
-	for resp.NextToken != nil {
-		input = &ecs.ListServicesInput{
-			Cluster:   clusterName,
-			NextToken: resp.NextToken,
+		input := &ecs.DescribeClustersInput{
+			Clusters: clusterArns[batchStart:batchEnd],
+			Include:  aws.StringSlice([]string{ecs.ClusterFieldTags}),
 		}
 
-		resp, err = ecsCli.cli.ListServices(input)
+		resp, err := ecsCli.cli.DescribeClustersWithContext(ctx, input)
 		if err != nil {
-			ecsCli.handleError(err)
+			return clusters, ecsCli.handleError("DescribeClusters", err)
 		}
 
-		serviceArns = append(serviceArns, resp.ServiceArns...)
+		clusters = append(clusters, resp.Clusters...)
 	}
 
-	if len(serviceArns) == 0 {
-		return []*ecs.Service{}
-	}
+	return clusters, nil
+}
 
-	return ecsCli.DescribeServices(clusterName, serviceArns)
+func (ecsCli *ECSClient) ListServicesByCluster(clusterName *string) ([]*ecs.Service, error) {
+	return ecsCli.ListServicesByClusterWithContext(context.Background(), clusterName)
 }
 
-func (ecsCli *ECSClient) DescribeServices(clusterName *string, serviceArns []*string) []*ecs.Service {
-	total := len(serviceArns)
-	if total <= 0 {
-		return []*ecs.Service{}
-	}
+func (ecsCli *ECSClient) ListServicesByClusterWithContext(ctx context.Context, clusterName *string) ([]*ecs.Service, error) {
+	services := []*ecs.Service{}
 
-	i := 0
-	batchStart := i * 10
+	err := ecsCli.ListServicesByClusterPagesWithContext(ctx, clusterName, 0, func(page []*ecs.Service) bool {
+		services = append(services, page...)
 
-	batchEnd := (i + 1) * 10
-	if batchEnd > total {
-		batchEnd = total % 10
-	}
+		return true
+	})
 
-	input := &ecs.DescribeServicesInput{
-		Cluster:  clusterName,
-		Services: serviceArns[batchStart:batchEnd],
-	}
+	return services, err
+}
 
-	resp, err := ecsCli.cli.DescribeServices(input)
-	if err != nil {
-		ecsCli.handleError(err)
-	}
+func (ecsCli *ECSClient) ListServicesByClusterPages(clusterName *string, pageSize int64, fn func(services []*ecs.Service) bool) error {
+	return ecsCli.ListServicesByClusterPagesWithContext(context.Background(), clusterName, pageSize, fn)
+}
 
-	services := resp.Services
-	i = 1
+// ListServicesByClusterPagesWithContext describes every page of services before
+// passing it to fn.
+func (ecsCli *ECSClient) ListServicesByClusterPagesWithContext(ctx context.Context, clusterName *string, pageSize int64, fn func(services []*ecs.Service) bool) error {
+	input := &ecs.ListServicesInput{
+		Cluster:    clusterName,
+		MaxResults: pageLimit(pageSize, 1, 100),
+	}
 
 	for {
-		if i*10 >= total {
-			break
+		resp, err := ecsCli.cli.ListServicesWithContext(ctx, input)
+		if err != nil {
+			return ecsCli.handleError("ListServices", err)
 		}
 
-		batchEnd = (i + 1) * 10
-		if batchEnd > total {
-			batchEnd = i*10 + total%10
-		}
+		if len(resp.ServiceArns) > 0 {
+			services, err := ecsCli.DescribeServicesWithContext(ctx, clusterName, resp.ServiceArns)
+			if err != nil {
+				return err
+			}
 
-		input = &ecs.DescribeServicesInput{
-			Cluster:  clusterName,
-			Services: serviceArns[batchStart:batchEnd],
+			if !fn(services) {
+				return nil
+			}
 		}
 
-		resp, err = ecsCli.cli.DescribeServices(input)
-		if err != nil {
-			ecsCli.handleError(err)
+		if resp.NextToken == nil {
+			return nil
 		}
 
-		services = append(services, resp.Services...)
-		i++
+		input.NextToken = resp.NextToken
 	}
+}
 
-	return services
+func (ecsCli *ECSClient) DescribeServices(clusterName *string, serviceArns []*string) ([]*ecs.Service, error) {
+	return ecsCli.DescribeServicesWithContext(context.Background(), clusterName, serviceArns)
 }
 
-func (ecsCli *ECSClient) ListTasksByService(clusterName *string, serviceName *string) []*ecs.Task {
-	input := &ecs.ListTasksInput{
-		Cluster:     clusterName,
-		ServiceName: serviceName,
-	}
+func (ecsCli *ECSClient) DescribeServicesWithContext(ctx context.Context, clusterName *string, serviceArns []*string) ([]*ecs.Service, error) {
+	services := []*ecs.Service{}
 
-	resp, err := ecsCli.cli.ListTasks(input)
-	if err != nil {
-		ecsCli.handleError(err)
-	}
-
-	taskArns := resp.TaskArns
+	for batchStart := 0; batchStart < len(serviceArns); batchStart += 10 {
+		batchEnd := batchStart + 10
+		if batchEnd > len(serviceArns) {
+			batchEnd = len(serviceArns)
+		}
 
-	for resp.NextToken != nil {
-		input = &ecs.ListTasksInput{
-			NextToken:   resp.NextToken,
-			Cluster:     clusterName,
-			ServiceName: serviceName,
+		input := &ecs.DescribeServicesInput{
+			Cluster:  clusterName,
+			Services: serviceArns[batchStart:batchEnd],
+			Include:  aws.StringSlice([]string{ecs.ServiceFieldTags}),
 		}
 
-		resp, err = ecsCli.cli.ListTasks(input)
+		resp, err := ecsCli.cli.DescribeServicesWithContext(ctx, input)
 		if err != nil {
-			ecsCli.handleError(err)
+			return services, ecsCli.handleError("DescribeServices", err)
 		}
 
-		taskArns = append(taskArns, resp.TaskArns...)
+		services = append(services, resp.Services...)
 	}
 
-	if len(taskArns) == 0 {
-		return []*ecs.Task{}
-	}
+	return services, nil
+}
 
-	return ecsCli.DescribeTasks(clusterName, taskArns)
+func (ecsCli *ECSClient) ListTasksByService(clusterName *string, serviceName *string) ([]*ecs.Task, error) {
+	return ecsCli.ListTasksByServiceWithContext(context.Background(), clusterName, serviceName)
 }
 
-func (ecsCli *ECSClient) DescribeTasks(clusterName *string, taskArns []*string) []*ecs.Task {
-	total := len(taskArns)
-	if total <= 0 {
-		return []*ecs.Task{}
-	}
+func (ecsCli *ECSClient) ListTasksByServiceWithContext(ctx context.Context, clusterName *string, serviceName *string) ([]*ecs.Task, error) {
+	tasks := []*ecs.Task{}
 
-	i := 0
-	batchStart := i * 100
+	err := ecsCli.ListTasksByServicePagesWithContext(ctx, clusterName, serviceName, 0, func(page []*ecs.Task) bool {
+		tasks = append(tasks, page...)
 
-	batchEnd := (i + 1) * 100
-	if batchEnd > total {
-		batchEnd = total % 100
-	}
+		return true
+	})
 
-	input := &ecs.DescribeTasksInput{
-		Cluster: clusterName,
-		Tasks:   taskArns[batchStart:batchEnd],
-	}
+	return tasks, err
+}
+
+func (ecsCli *ECSClient) ListTasksByServicePages(clusterName *string, serviceName *string, pageSize int64, fn func(tasks []*ecs.Task) bool) error {
+	return ecsCli.ListTasksByServicePagesWithContext(context.Background(), clusterName, serviceName, pageSize, fn)
+}
 
-	resp, err := ecsCli.cli.DescribeTasks(input)
+// ListTasksByServicePagesWithContext describes every page of tasks before
+// passing it to fn.
+func (ecsCli *ECSClient) ListTasksByServicePagesWithContext(ctx context.Context, clusterName *string, serviceName *string, pageSize int64, fn func(tasks []*ecs.Task) bool) error {
+	serviceName, err := ecsCli.nameOf(ctx, serviceName, "ecs", "service")
 	if err != nil {
-		ecsCli.handleError(err)
+		return ecsCli.handleError("ListTasks", err)
 	}
 
-	tasks := resp.Tasks
-	i = 1
+	input := &ecs.ListTasksInput{
+		Cluster:     clusterName,
+		ServiceName: serviceName,
+		MaxResults:  pageLimit(pageSize, 1, 100),
+	}
 
 	for {
-		if i*100 >= total {
-			break
+		resp, err := ecsCli.cli.ListTasksWithContext(ctx, input)
+		if err != nil {
+			return ecsCli.handleError("ListTasks", err)
 		}
 
-		batchEnd = (i + 1) * 100
-		if batchEnd > total {
-			batchEnd = i*100 + total%100
+		if len(resp.TaskArns) > 0 {
+			tasks, err := ecsCli.DescribeTasksWithContext(ctx, clusterName, resp.TaskArns)
+			if err != nil {
+				return err
+			}
+
+			if !fn(tasks) {
+				return nil
+			}
 		}
 
-		input = &ecs.DescribeTasksInput{
+		if resp.NextToken == nil {
+			return nil
+		}
+
+		input.NextToken = resp.NextToken
+	}
+}
+
+func (ecsCli *ECSClient) DescribeTasks(clusterName *string, taskArns []*string) ([]*ecs.Task, error) {
+	return ecsCli.DescribeTasksWithContext(context.Background(), clusterName, taskArns)
+}
+
+func (ecsCli *ECSClient) DescribeTasksWithContext(ctx context.Context, clusterName *string, taskArns []*string) ([]*ecs.Task, error) {
+	tasks := []*ecs.Task{}
+
+	for batchStart := 0; batchStart < len(taskArns); batchStart += 100 {
+		batchEnd := batchStart + 100
+		if batchEnd > len(taskArns) {
+			batchEnd = len(taskArns)
+		}
+
+		input := &ecs.DescribeTasksInput{
 			Cluster: clusterName,
 			Tasks:   taskArns[batchStart:batchEnd],
 		}
 
-		resp, err = ecsCli.cli.DescribeTasks(input)
+		resp, err := ecsCli.cli.DescribeTasksWithContext(ctx, input)
 		if err != nil {
-			ecsCli.handleError(err)
+			return tasks, ecsCli.handleError("DescribeTasks", err)
 		}
 
 		tasks = append(tasks, resp.Tasks...)
-		i++
 	}
 
-	return tasks
+	return tasks, nil
 }
 
-func (ecsCli *ECSClient) ListTaskDefinitions() []*string {
-	input := &ecs.ListTaskDefinitionsInput{}
+func (ecsCli *ECSClient) ListTaskDefinitions() ([]*string, error) {
+	return ecsCli.ListTaskDefinitionsWithContext(context.Background())
+}
 
-	resp, err := ecsCli.cli.ListTaskDefinitions(input)
-	if err != nil {
-		ecsCli.handleError(err)
-	}
+func (ecsCli *ECSClient) ListTaskDefinitionsWithContext(ctx context.Context) ([]*string, error) {
+	definitionArns := []*string{}
 
-	definitionArns := resp.TaskDefinitionArns
+	err := ecsCli.ListTaskDefinitionsPagesWithContext(ctx, 0, func(page []*string) bool {
+		definitionArns = append(definitionArns, page...)
 
-	for resp.NextToken != nil {
-		input = &ecs.ListTaskDefinitionsInput{NextToken: resp.NextToken}
+		return true
+	})
 
-		resp, err = ecsCli.cli.ListTaskDefinitions(input)
+	return definitionArns, err
+}
+
+func (ecsCli *ECSClient) ListTaskDefinitionsPages(pageSize int64, fn func(definitionArns []*string) bool) error {
+	return ecsCli.ListTaskDefinitionsPagesWithContext(context.Background(), pageSize, fn)
+}
+
+func (ecsCli *ECSClient) ListTaskDefinitionsPagesWithContext(ctx context.Context, pageSize int64, fn func(definitionArns []*string) bool) error {
+	input := &ecs.ListTaskDefinitionsInput{MaxResults: pageLimit(pageSize, 1, 100)}
+
+	for {
+		resp, err := ecsCli.cli.ListTaskDefinitionsWithContext(ctx, input)
 		if err != nil {
-			ecsCli.handleError(err)
+			return ecsCli.handleError("ListTaskDefinitions", err)
 		}
 
-		definitionArns = append(definitionArns, resp.TaskDefinitionArns...)
-	}
+		if !fn(resp.TaskDefinitionArns) || resp.NextToken == nil {
+			return nil
+		}
 
-	if len(definitionArns) == 0 {
-		return []*string{}
+		input.NextToken = resp.NextToken
 	}
+}
 
-	return definitionArns
+func (ecsCli *ECSClient) DescribeTaskDefinition(taskDefArn *string) (*ecs.TaskDefinition, error) {
+	return ecsCli.DescribeTaskDefinitionWithContext(context.Background(), taskDefArn)
 }
 
-func (ecsCli *ECSClient) DescribeTaskDefinition(taskDefArn *string) *ecs.TaskDefinition {
+func (ecsCli *ECSClient) DescribeTaskDefinitionWithContext(ctx context.Context, taskDefArn *string) (*ecs.TaskDefinition, error) {
 	input := &ecs.DescribeTaskDefinitionInput{
 		TaskDefinition: taskDefArn,
 	}
 
-	resp, err := ecsCli.cli.DescribeTaskDefinition(input)
+	resp, err := ecsCli.cli.DescribeTaskDefinitionWithContext(ctx, input)
 	if err != nil {
-		ecsCli.handleError(err)
+		return nil, ecsCli.handleError("DescribeTaskDefinition", err)
 	}
 
-	return resp.TaskDefinition
-}
-
-func (ecsCli *ECSClient) handleError(err error) {
-	if aerr, ok := err.(awserr.Error); ok {
-		switch aerr.Code() {
-		case ecs.ErrCodeServerException:
-			fmt.Println(ecs.ErrCodeServerException, aerr.Error())
-		case ecs.ErrCodeClientException:
-			fmt.Println(ecs.ErrCodeClientException, aerr.Error())
-		case ecs.ErrCodeInvalidParameterException:
-			fmt.Println(ecs.ErrCodeInvalidParameterException, aerr.Error())
-		case ecs.ErrCodeClusterNotFoundException:
-			fmt.Println(ecs.ErrCodeClusterNotFoundException, aerr.Error())
-		default:
-			fmt.Println(aerr.Error())
-		}
-	} else {
-		// Print the error, cast err to awserr.Error to get the Code and
-		// Message from an error.
-		fmt.Println(err.Error())
+	return resp.TaskDefinition, nil
+}
+
+// Service states reported by WaitForServiceStable besides the status of the
+// service: active services are stable once they run their desired count of
+// tasks of a single deployment.
+const (
+	ServiceStateStable      = "STABLE"
+	ServiceStateStabilizing = "STABILIZING"
+)
+
+func (ecsCli *ECSClient) WaitForServiceStable(clusterName, serviceName *string, opts WaitOptions) (*ecs.Service, error) {
+	return ecsCli.WaitForServiceStableWithContext(context.Background(), clusterName, serviceName, opts)
+}
+
+// WaitForServiceStableWithContext polls the service every 15 seconds for up
+// to 10 minutes. Draining and inactive services end the wait with
+// ErrFailureState.
+func (ecsCli *ECSClient) WaitForServiceStableWithContext(ctx context.Context, clusterName, serviceName *string, opts WaitOptions) (*ecs.Service, error) {
+	var service *ecs.Service
+
+	w := &Waiter{
+		Resource: "ECS service " + aws.StringValue(serviceName),
+		State: func(ctx context.Context) (string, error) {
+			services, err := ecsCli.DescribeServicesWithContext(ctx, clusterName, []*string{serviceName})
+			if len(services) == 0 {
+				return resourceState(false, nil, err)
+			}
+
+			service = services[0]
+
+			if aws.StringValue(service.Status) != "ACTIVE" {
+				return resourceState(true, service.Status, err)
+			}
+
+			if len(service.Deployments) == 1 && aws.Int64Value(service.RunningCount) == aws.Int64Value(service.DesiredCount) {
+				return ServiceStateStable, nil
+			}
+
+			return ServiceStateStabilizing, nil
+		},
+		Success:     []string{ServiceStateStable},
+		Failure:     []string{"DRAINING", "INACTIVE", StateNotFound},
+		Retry:       []string{ServiceStateStabilizing},
+		WaitOptions: opts.withDefaults(WaitOptions{Timeout: 10 * time.Minute, Backoff: Backoff{Delay: 15 * time.Second}}),
 	}
+
+	_, err := w.Wait(ctx)
+
+	return service, err
+}
+
+func (ecsCli *ECSClient) handleError(operation string, err error) error {
+	return ecsCli.logError(newError(ecs.ServiceName, operation, err))
 }
@@ -1,73 +1,144 @@
 package clients
 
 import (
-	"fmt"
+	"context"
+	"time"
 
-	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/emr"
+	"github.com/aws/aws-sdk-go/service/emr/emriface"
 )
 
+type EMRAPI interface {
+	ListClusters(states []*string) ([]*emr.ClusterSummary, error)
+	ListClustersWithContext(ctx context.Context, states []*string) ([]*emr.ClusterSummary, error)
+	ListClustersPages(states []*string, pageSize int64, fn func(clusters []*emr.ClusterSummary) bool) error
+	ListClustersPagesWithContext(ctx context.Context, states []*string, pageSize int64, fn func(clusters []*emr.ClusterSummary) bool) error
+	DescribeCluster(id *string) (*emr.DescribeClusterOutput, error)
+	DescribeClusterWithContext(ctx context.Context, id *string) (*emr.DescribeClusterOutput, error)
+	WaitForClusterRunning(id *string, opts WaitOptions) (*emr.Cluster, error)
+	WaitForClusterRunningWithContext(ctx context.Context, id *string, opts WaitOptions) (*emr.Cluster, error)
+}
+
+var _ EMRAPI = (*EMRClient)(nil)
+
 type EMRClient struct {
-	cli *emr.EMR
+	logging
+	*owner
+	cli emriface.EMRAPI
+}
+
+func init() {
+	Register("emr", func(sess *session.Session) interface{} { return NewEMR(sess) })
 }
 
-func NewEMR(sess *session.Session) *EMRClient {
-	client := emr.New(sess)
+func NewEMR(sess *session.Session, cfgs ...*aws.Config) *EMRClient {
+	client := emr.New(sess, cfgs...)
 
-	return &EMRClient{cli: client}
+	return &EMRClient{logging: newLogging(sess), owner: newOwner(sess, client.Config), cli: client}
 }
 
-func (emrCli *EMRClient) ListClusters(states []*string) []*emr.ClusterSummary {
-	input := &emr.ListClustersInput{
-		ClusterStates: states,
-	}
+func NewEMRFromAPI(api emriface.EMRAPI) *EMRClient {
+	return &EMRClient{logging: logging{logger: NopLogger}, owner: &owner{}, cli: api}
+}
 
-	resp, err := emrCli.cli.ListClusters(input)
-	if err != nil {
-		emrCli.handleError(err)
-	}
+func (emrCli *EMRClient) ListClusters(states []*string) ([]*emr.ClusterSummary, error) {
+	return emrCli.ListClustersWithContext(context.Background(), states)
+}
+
+func (emrCli *EMRClient) ListClustersWithContext(ctx context.Context, states []*string) ([]*emr.ClusterSummary, error) {
+	var clusters []*emr.ClusterSummary
+
+	err := emrCli.ListClustersPagesWithContext(ctx, states, 0, func(page []*emr.ClusterSummary) bool {
+		clusters = append(clusters, page...)
 
-	clusters := resp.Clusters
+		return true
+	})
 
-	for resp.Marker != nil {
-		input = &emr.ListClustersInput{Marker: resp.Marker}
+	return clusters, err
+}
+
+func (emrCli *EMRClient) ListClustersPages(states []*string, pageSize int64, fn func(clusters []*emr.ClusterSummary) bool) error {
+	return emrCli.ListClustersPagesWithContext(context.Background(), states, pageSize, fn)
+}
 
-		resp, err = emrCli.cli.ListClusters(input)
+func (emrCli *EMRClient) ListClustersPagesWithContext(ctx context.Context, states []*string, pageSize int64, fn func(clusters []*emr.ClusterSummary) bool) error {
+	input := &emr.ListClustersInput{ClusterStates: states}
+
+	for {
+		resp, err := emrCli.cli.ListClustersWithContext(ctx, input)
 		if err != nil {
-			emrCli.handleError(err)
+			return emrCli.handleError("ListClusters", err)
+		}
+
+		if !fn(resp.Clusters) || resp.Marker == nil {
+			return nil
 		}
 
-		clusters = append(clusters, resp.Clusters...)
+		input.Marker = resp.Marker
 	}
+}
 
-	return clusters
+func (emrCli *EMRClient) DescribeCluster(id *string) (*emr.DescribeClusterOutput, error) {
+	return emrCli.DescribeClusterWithContext(context.Background(), id)
 }
 
-func (emrCli *EMRClient) DescribeCluster(id *string) *emr.DescribeClusterOutput {
+func (emrCli *EMRClient) DescribeClusterWithContext(ctx context.Context, id *string) (*emr.DescribeClusterOutput, error) {
+	id, err := emrCli.nameOf(ctx, id, "elasticmapreduce", "cluster")
+	if err != nil {
+		return nil, emrCli.handleError("DescribeCluster", err)
+	}
+
 	input := &emr.DescribeClusterInput{
 		ClusterId: id,
 	}
 
-	resp, err := emrCli.cli.DescribeCluster(input)
+	resp, err := emrCli.cli.DescribeClusterWithContext(ctx, input)
 	if err != nil {
-		emrCli.handleError(err)
+		return nil, emrCli.handleError("DescribeCluster", err)
 	}
 
-	return resp
+	return resp, nil
 }
 
-func (emrCli *EMRClient) handleError(err error) {
-	if aerr, ok := err.(awserr.Error); ok {
-		switch aerr.Code() {
-		case emr.ErrCodeInternalServerException:
-			fmt.Println(emr.ErrCodeInternalServerException, aerr.Error())
-		case emr.ErrCodeInvalidRequestException:
-			fmt.Println(emr.ErrCodeInvalidRequestException, aerr.Error())
-		default:
-			fmt.Println(aerr.Error())
-		}
-	} else {
-		fmt.Println(err.Error())
+func (emrCli *EMRClient) WaitForClusterRunning(id *string, opts WaitOptions) (*emr.Cluster, error) {
+	return emrCli.WaitForClusterRunningWithContext(context.Background(), id, opts)
+}
+
+// WaitForClusterRunningWithContext waits for the cluster to be running or
+// waiting for steps, polling every 30 seconds for up to an hour.
+func (emrCli *EMRClient) WaitForClusterRunningWithContext(ctx context.Context, id *string, opts WaitOptions) (*emr.Cluster, error) {
+	var cluster *emr.Cluster
+
+	w := &Waiter{
+		Resource: "EMR cluster " + aws.StringValue(id),
+		State: func(ctx context.Context) (string, error) {
+			resp, err := emrCli.DescribeClusterWithContext(ctx, id)
+			if resp == nil || resp.Cluster == nil {
+				return resourceState(false, nil, err)
+			}
+
+			cluster = resp.Cluster
+
+			if cluster.Status == nil {
+				return resourceState(true, nil, err)
+			}
+
+			return resourceState(true, cluster.Status.State, err)
+		},
+		Success: []string{emr.ClusterStateRunning, emr.ClusterStateWaiting},
+		Failure: []string{
+			emr.ClusterStateTerminating, emr.ClusterStateTerminated, emr.ClusterStateTerminatedWithErrors, StateNotFound,
+		},
+		WaitOptions: opts.withDefaults(WaitOptions{Timeout: time.Hour, Backoff: Backoff{Delay: 30 * time.Second}}),
 	}
+
+	_, err := w.Wait(ctx)
+
+	return cluster, err
+}
+
+func (emrCli *EMRClient) handleError(operation string, err error) error {
+	return emrCli.logError(newError(emr.ServiceName, operation, err))
 }
@@ -0,0 +1,372 @@
+package clients
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/athena"
+	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/aws/aws-sdk-go/service/cloudtrail"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/ecr"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/aws/aws-sdk-go/service/glue"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/aws/aws-sdk-go/service/redshift"
+	"github.com/aws/aws-sdk-go/service/resourcegroupstaggingapi"
+	"github.com/aws/aws-sdk-go/service/route53"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/ssm"
+)
+
+// Error is returned by every client method when the underlying call fails.
+// It wraps the original error, usually an awserr.Error, together with the
+// details needed to decide how to react to it.
+type Error struct {
+	Service    string
+	Operation  string
+	Code       string
+	Message    string
+	StatusCode int
+	RequestID  string
+	Retryable  bool
+	Err        error
+}
+
+func (e *Error) Error() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "%s %s", e.Service, e.Operation)
+
+	if e.Code != "" {
+		fmt.Fprintf(&b, ": %s", e.Code)
+	}
+
+	if e.Message != "" {
+		fmt.Fprintf(&b, ": %s", e.Message)
+	}
+
+	if e.StatusCode != 0 {
+		fmt.Fprintf(&b, " (status %d", e.StatusCode)
+
+		if e.RequestID != "" {
+			fmt.Fprintf(&b, ", request id %s", e.RequestID)
+		}
+
+		b.WriteString(")")
+	}
+
+	return b.String()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func newError(service, operation string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	e := &Error{
+		Service:   service,
+		Operation: operation,
+		Message:   err.Error(),
+		Retryable: request.IsErrorRetryable(err) || request.IsErrorThrottle(err),
+		Err:       err,
+	}
+
+	if aerr, ok := err.(awserr.Error); ok {
+		e.Code = aerr.Code()
+		e.Message = aerr.Message()
+
+		// Expose the context error of a cancelled call so that callers can
+		// match it with errors.Is(err, context.Canceled).
+		if aerr.Code() == request.CanceledErrorCode && aerr.OrigErr() != nil {
+			e.Err = aerr.OrigErr()
+		}
+	}
+
+	if rerr, ok := err.(awserr.RequestFailure); ok {
+		e.StatusCode = rerr.StatusCode()
+		e.RequestID = rerr.RequestID()
+	}
+
+	return e
+}
+
+type errorKind int
+
+const (
+	kindUnknown errorKind = iota
+	kindNotFound
+	kindAlreadyExists
+	kindThrottled
+	kindAccessDenied
+	kindLimitExceeded
+	kindInvalidInput
+)
+
+// commonErrorKinds holds the codes that mean the same thing in every service.
+var commonErrorKinds = map[string]errorKind{
+	"NotFound":                      kindNotFound,
+	"ResourceNotFoundException":     kindNotFound,
+	"AccessDenied":                  kindAccessDenied,
+	"AccessDeniedException":         kindAccessDenied,
+	"UnauthorizedOperation":         kindAccessDenied,
+	"UnrecognizedClientException":   kindAccessDenied,
+	"InvalidClientTokenId":          kindAccessDenied,
+	"ExpiredToken":                  kindAccessDenied,
+	"ExpiredTokenException":         kindAccessDenied,
+	"LimitExceededException":        kindLimitExceeded,
+	"ServiceQuotaExceededException": kindLimitExceeded,
+	"ValidationError":               kindInvalidInput,
+	"ValidationException":           kindInvalidInput,
+	"InvalidParameterValue":         kindInvalidInput,
+	"InvalidParameterException":     kindInvalidInput,
+	"InvalidParameterCombination":   kindInvalidInput,
+	"MissingParameter":              kindInvalidInput,
+	"InvalidInputException":         kindInvalidInput,
+}
+
+// serviceErrorKinds maps the codes of each wrapped service onto a common kind.
+var serviceErrorKinds = map[string]map[string]errorKind{
+	athena.ServiceName: {
+		athena.ErrCodeTooManyRequestsException: kindThrottled,
+		athena.ErrCodeInvalidRequestException:  kindInvalidInput,
+	},
+	autoscaling.ServiceName: {
+		autoscaling.ErrCodeAlreadyExistsFault: kindAlreadyExists,
+		autoscaling.ErrCodeLimitExceededFault: kindLimitExceeded,
+		autoscaling.ErrCodeInvalidNextToken:   kindInvalidInput,
+	},
+	cloudformation.ServiceName: {
+		cloudformation.ErrCodeAlreadyExistsException:         kindAlreadyExists,
+		cloudformation.ErrCodeNameAlreadyExistsException:     kindAlreadyExists,
+		cloudformation.ErrCodeChangeSetNotFoundException:     kindNotFound,
+		cloudformation.ErrCodeStackSetNotFoundException:      kindNotFound,
+		cloudformation.ErrCodeStackInstanceNotFoundException: kindNotFound,
+		cloudformation.ErrCodeTypeNotFoundException:          kindNotFound,
+	},
+	cloudtrail.ServiceName: {
+		cloudtrail.ErrCodeTrailNotFoundException:      kindNotFound,
+		cloudtrail.ErrCodeTrailAlreadyExistsException: kindAlreadyExists,
+		cloudtrail.ErrCodeInvalidTrailNameException:   kindInvalidInput,
+	},
+	dynamodb.ServiceName: {
+		dynamodb.ErrCodeTableNotFoundException:                   kindNotFound,
+		dynamodb.ErrCodeIndexNotFoundException:                   kindNotFound,
+		dynamodb.ErrCodeTableAlreadyExistsException:              kindAlreadyExists,
+		dynamodb.ErrCodeResourceInUseException:                   kindAlreadyExists,
+		dynamodb.ErrCodeProvisionedThroughputExceededException:   kindThrottled,
+		dynamodb.ErrCodeRequestLimitExceeded:                     kindThrottled,
+		dynamodb.ErrCodeItemCollectionSizeLimitExceededException: kindLimitExceeded,
+	},
+	ecr.ServiceName: {
+		ecr.ErrCodeRepositoryNotFoundException:       kindNotFound,
+		ecr.ErrCodeRepositoryPolicyNotFoundException: kindNotFound,
+		ecr.ErrCodeImageNotFoundException:            kindNotFound,
+		ecr.ErrCodeLifecyclePolicyNotFoundException:  kindNotFound,
+		ecr.ErrCodeRepositoryAlreadyExistsException:  kindAlreadyExists,
+		ecr.ErrCodeImageAlreadyExistsException:       kindAlreadyExists,
+		ecr.ErrCodeImageTagAlreadyExistsException:    kindAlreadyExists,
+		ecr.ErrCodeTooManyTagsException:              kindLimitExceeded,
+		ecr.ErrCodeInvalidTagParameterException:      kindInvalidInput,
+	},
+	ecs.ServiceName: {
+		ecs.ErrCodeClusterNotFoundException:        kindNotFound,
+		ecs.ErrCodeServiceNotFoundException:        kindNotFound,
+		ecs.ErrCodeTargetNotFoundException:         kindNotFound,
+		ecs.ErrCodeTaskSetNotFoundException:        kindNotFound,
+		ecs.ErrCodeAttributeLimitExceededException: kindLimitExceeded,
+	},
+	glue.ServiceName: {
+		glue.ErrCodeEntityNotFoundException:              kindNotFound,
+		glue.ErrCodeAlreadyExistsException:               kindAlreadyExists,
+		glue.ErrCodeResourceNumberLimitExceededException: kindLimitExceeded,
+	},
+	iam.ServiceName: {
+		iam.ErrCodeNoSuchEntityException:            kindNotFound,
+		iam.ErrCodeEntityAlreadyExistsException:     kindAlreadyExists,
+		iam.ErrCodeMalformedPolicyDocumentException: kindInvalidInput,
+	},
+	lambda.ServiceName: {
+		lambda.ErrCodeTooManyRequestsException:       kindThrottled,
+		lambda.ErrCodeEC2ThrottledException:          kindThrottled,
+		lambda.ErrCodeResourceConflictException:      kindAlreadyExists,
+		lambda.ErrCodeInvalidParameterValueException: kindInvalidInput,
+		lambda.ErrCodeInvalidRequestContentException: kindInvalidInput,
+		lambda.ErrCodeKMSAccessDeniedException:       kindAccessDenied,
+		lambda.ErrCodeEC2AccessDeniedException:       kindAccessDenied,
+	},
+	rds.ServiceName: {
+		rds.ErrCodeDBClusterNotFoundFault:               kindNotFound,
+		rds.ErrCodeDBClusterSnapshotNotFoundFault:       kindNotFound,
+		rds.ErrCodeDBInstanceNotFoundFault:              kindNotFound,
+		rds.ErrCodeDBSnapshotNotFoundFault:              kindNotFound,
+		rds.ErrCodeDBSubnetGroupNotFoundFault:           kindNotFound,
+		rds.ErrCodeDBParameterGroupNotFoundFault:        kindNotFound,
+		rds.ErrCodeDBClusterParameterGroupNotFoundFault: kindNotFound,
+		rds.ErrCodeOptionGroupNotFoundFault:             kindNotFound,
+		rds.ErrCodeGlobalClusterNotFoundFault:           kindNotFound,
+		rds.ErrCodeResourceNotFoundFault:                kindNotFound,
+		rds.ErrCodeDBClusterAlreadyExistsFault:          kindAlreadyExists,
+		rds.ErrCodeDBClusterSnapshotAlreadyExistsFault:  kindAlreadyExists,
+		rds.ErrCodeDBInstanceAlreadyExistsFault:         kindAlreadyExists,
+		rds.ErrCodeDBSnapshotAlreadyExistsFault:         kindAlreadyExists,
+		rds.ErrCodeDBClusterQuotaExceededFault:          kindLimitExceeded,
+		rds.ErrCodeInstanceQuotaExceededFault:           kindLimitExceeded,
+		rds.ErrCodeSnapshotQuotaExceededFault:           kindLimitExceeded,
+		rds.ErrCodeStorageQuotaExceededFault:            kindLimitExceeded,
+		rds.ErrCodeKMSKeyNotAccessibleFault:             kindAccessDenied,
+	},
+	redshift.ServiceName: {
+		redshift.ErrCodeClusterNotFoundFault:                   kindNotFound,
+		redshift.ErrCodeClusterAlreadyExistsFault:              kindAlreadyExists,
+		redshift.ErrCodeUnauthorizedOperation:                  kindAccessDenied,
+		redshift.ErrCodeDependentServiceRequestThrottlingFault: kindThrottled,
+	},
+	resourcegroupstaggingapi.ServiceName: {
+		resourcegroupstaggingapi.ErrCodeThrottledException:              kindThrottled,
+		resourcegroupstaggingapi.ErrCodePaginationTokenExpiredException: kindInvalidInput,
+	},
+	route53.ServiceName: {
+		route53.ErrCodeNoSuchHostedZone:         kindNotFound,
+		route53.ErrCodeNoSuchHealthCheck:        kindNotFound,
+		route53.ErrCodeNoSuchChange:             kindNotFound,
+		route53.ErrCodeHostedZoneNotFound:       kindNotFound,
+		route53.ErrCodeHostedZoneAlreadyExists:  kindAlreadyExists,
+		route53.ErrCodeHealthCheckAlreadyExists: kindAlreadyExists,
+		route53.ErrCodePriorRequestNotComplete:  kindThrottled,
+		route53.ErrCodeThrottlingException:      kindThrottled,
+		route53.ErrCodeInvalidChangeBatch:       kindInvalidInput,
+		route53.ErrCodeInvalidInput:             kindInvalidInput,
+		route53.ErrCodeLimitsExceeded:           kindLimitExceeded,
+	},
+	s3.ServiceName: {
+		s3.ErrCodeNoSuchBucket: kindNotFound,
+		s3.ErrCodeNoSuchKey:    kindNotFound,
+		s3.ErrCodeNoSuchUpload: kindNotFound,
+		"NoSuchBucketPolicy":   kindNotFound,
+		"NoSuchTagSet":         kindNotFound,
+		"ServerSideEncryptionConfigurationNotFoundError": kindNotFound,
+		s3.ErrCodeBucketAlreadyExists:                    kindAlreadyExists,
+		s3.ErrCodeBucketAlreadyOwnedByYou:                kindAlreadyExists,
+		"SlowDown":                                       kindThrottled,
+	},
+	secretsmanager.ServiceName: {
+		secretsmanager.ErrCodeResourceExistsException: kindAlreadyExists,
+		secretsmanager.ErrCodeInvalidRequestException: kindInvalidInput,
+		secretsmanager.ErrCodeDecryptionFailure:       kindAccessDenied,
+	},
+	sqs.ServiceName: {
+		sqs.ErrCodeQueueDoesNotExist:      kindNotFound,
+		sqs.ErrCodeQueueNameExists:        kindAlreadyExists,
+		sqs.ErrCodeOverLimit:              kindLimitExceeded,
+		sqs.ErrCodeInvalidMessageContents: kindInvalidInput,
+	},
+	ssm.ServiceName: {
+		ssm.ErrCodeParameterNotFound:        kindNotFound,
+		ssm.ErrCodeParameterVersionNotFound: kindNotFound,
+		ssm.ErrCodeParameterAlreadyExists:   kindAlreadyExists,
+		ssm.ErrCodeParameterLimitExceeded:   kindLimitExceeded,
+		ssm.ErrCodeTooManyUpdates:           kindThrottled,
+		ssm.ErrCodeInvalidKeyId:             kindInvalidInput,
+	},
+}
+
+func (e *Error) kind() errorKind {
+	if k, ok := serviceErrorKinds[e.Service][e.Code]; ok {
+		return k
+	}
+
+	if k, ok := commonErrorKinds[e.Code]; ok {
+		return k
+	}
+
+	switch {
+	case strings.HasSuffix(e.Code, ".NotFound"):
+		return kindNotFound
+	case strings.HasSuffix(e.Code, ".Duplicate"):
+		return kindAlreadyExists
+	case request.IsErrorThrottle(e.Err):
+		return kindThrottled
+	}
+
+	switch e.StatusCode {
+	case http.StatusNotFound:
+		return kindNotFound
+	case http.StatusForbidden:
+		return kindAccessDenied
+	case http.StatusTooManyRequests:
+		return kindThrottled
+	}
+
+	return kindUnknown
+}
+
+func errorKindOf(err error) errorKind {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.kind()
+	}
+
+	var aerr awserr.Error
+	if errors.As(err, &aerr) {
+		if e, ok := newError("", "", aerr).(*Error); ok {
+			return e.kind()
+		}
+	}
+
+	return kindUnknown
+}
+
+// IsNotFound reports whether err means the requested resource does not exist.
+func IsNotFound(err error) bool {
+	return errorKindOf(err) == kindNotFound
+}
+
+// IsAlreadyExists reports whether err means the resource to create exists already.
+func IsAlreadyExists(err error) bool {
+	return errorKindOf(err) == kindAlreadyExists
+}
+
+// IsThrottled reports whether err was caused by request throttling.
+func IsThrottled(err error) bool {
+	return errorKindOf(err) == kindThrottled
+}
+
+// IsAccessDenied reports whether err was caused by missing permissions or
+// invalid credentials.
+func IsAccessDenied(err error) bool {
+	return errorKindOf(err) == kindAccessDenied
+}
+
+// IsLimitExceeded reports whether err was caused by a service quota.
+func IsLimitExceeded(err error) bool {
+	return errorKindOf(err) == kindLimitExceeded
+}
+
+// IsInvalidInput reports whether err was caused by a rejected request parameter.
+func IsInvalidInput(err error) bool {
+	return errorKindOf(err) == kindInvalidInput
+}
+
+// IsRetryable reports whether the failed call may succeed if it is retried.
+func IsRetryable(err error) bool {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Retryable
+	}
+
+	return request.IsErrorRetryable(err) || request.IsErrorThrottle(err)
+}
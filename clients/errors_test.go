@@ -0,0 +1,154 @@
+package clients
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/athena"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/sqs"
+)
+
+func TestErrorString(t *testing.T) {
+	for _, tt := range []struct {
+		err  *Error
+		want string
+	}{
+		{&Error{Service: "iam", Operation: "GetRole"}, "iam GetRole"},
+		{&Error{Service: "iam", Operation: "GetRole", Message: "connection reset"}, "iam GetRole: connection reset"},
+		{
+			&Error{Service: "iam", Operation: "GetRole", Code: "NoSuchEntity", Message: "role deploy not found", StatusCode: 404},
+			"iam GetRole: NoSuchEntity: role deploy not found (status 404)",
+		},
+		{
+			&Error{Service: "s3", Operation: "GetObject", Code: "AccessDenied", Message: "denied", StatusCode: 403, RequestID: "R1"},
+			"s3 GetObject: AccessDenied: denied (status 403, request id R1)",
+		},
+	} {
+		if got := tt.err.Error(); got != tt.want {
+			t.Errorf("Error() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestNewError(t *testing.T) {
+	if err := newError("iam", "GetRole", nil); err != nil {
+		t.Fatalf("newError(nil) = %v, want nil", err)
+	}
+
+	failure := awserr.NewRequestFailure(awserr.New("NoSuchEntity", "role deploy not found", nil), http.StatusNotFound, "R1")
+
+	var e *Error
+	if !errors.As(newError("iam", "GetRole", failure), &e) {
+		t.Fatal("newError did not return an *Error")
+	}
+
+	want := Error{Service: "iam", Operation: "GetRole", Code: "NoSuchEntity", Message: "role deploy not found", StatusCode: 404, RequestID: "R1"}
+	if e.Service != want.Service || e.Operation != want.Operation || e.Code != want.Code || e.Message != want.Message ||
+		e.StatusCode != want.StatusCode || e.RequestID != want.RequestID || e.Retryable {
+		t.Errorf("newError = %+v, want %+v", e, want)
+	}
+
+	if e.Err != failure {
+		t.Errorf("Err = %v, want the original error", e.Err)
+	}
+
+	plain := errors.New("connection reset")
+	if err := newError("sqs", "ListQueues", plain); !errors.Is(err, plain) || err.Error() != "sqs ListQueues: connection reset" {
+		t.Errorf("newError of a plain error = %v", err)
+	}
+
+	canceled := awserr.New(request.CanceledErrorCode, "request context canceled", context.Canceled)
+	if err := newError("sqs", "ListQueues", canceled); !errors.Is(err, context.Canceled) {
+		t.Errorf("newError of a canceled call = %v, want it to match context.Canceled", err)
+	}
+
+	throttled := awserr.NewRequestFailure(awserr.New("Throttling", "rate exceeded", nil), http.StatusBadRequest, "R2")
+	if err := newError("iam", "ListRoles", throttled); !IsRetryable(err) {
+		t.Errorf("newError of a throttled call = %+v, want it retryable", err)
+	}
+}
+
+func TestErrorPredicates(t *testing.T) {
+	failure := func(service, code string, status int) error {
+		return newError(service, "Op", awserr.NewRequestFailure(awserr.New(code, code, nil), status, "R"))
+	}
+
+	const (
+		notFound = 1 << iota
+		accessDenied
+		throttled
+		alreadyExists
+	)
+
+	for _, tt := range []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"nil", nil, 0},
+		{"plain error", errors.New("boom"), 0},
+		{"service code", failure(iam.ServiceName, iam.ErrCodeNoSuchEntityException, 404), notFound},
+		{"service code of another service", failure(sqs.ServiceName, iam.ErrCodeNoSuchEntityException, 400), 0},
+		{"common code", failure(sqs.ServiceName, "ResourceNotFoundException", 400), notFound},
+		{"dotted code", failure(ec2.ServiceName, "InvalidInstanceID.NotFound", 400), notFound},
+		{"status only", failure(s3.ServiceName, "NotFound", 404), notFound},
+		{"unknown code with 404", failure(s3.ServiceName, "Gone", 404), notFound},
+		{"access denied", failure(s3.ServiceName, "AccessDenied", 403), accessDenied},
+		{"expired token", failure(sqs.ServiceName, "ExpiredToken", 400), accessDenied},
+		{"unknown code with 403", failure(ec2.ServiceName, "Forbidden", 403), accessDenied},
+		{"sdk throttle code", failure(iam.ServiceName, "Throttling", 400), throttled},
+		{"service throttle code", failure(dynamodb.ServiceName, dynamodb.ErrCodeProvisionedThroughputExceededException, 400), throttled},
+		{"athena throttle code", failure(athena.ServiceName, athena.ErrCodeTooManyRequestsException, 400), throttled},
+		{"status 429", failure(ec2.ServiceName, "TooMany", 429), throttled},
+		{"already exists", failure(sqs.ServiceName, sqs.ErrCodeQueueNameExists, 400), alreadyExists},
+		{"service code without the service", awserr.New(iam.ErrCodeNoSuchEntityException, "missing", nil), 0},
+		{"unwrapped common code", awserr.New("AccessDeniedException", "denied", nil), accessDenied},
+		{"wrapped *Error", fmt.Errorf("deploying: %w", failure(s3.ServiceName, s3.ErrCodeNoSuchBucket, 404)), notFound},
+	} {
+		for _, p := range []struct {
+			kind int
+			name string
+			is   func(error) bool
+		}{
+			{notFound, "IsNotFound", IsNotFound},
+			{accessDenied, "IsAccessDenied", IsAccessDenied},
+			{throttled, "IsThrottled", IsThrottled},
+			{alreadyExists, "IsAlreadyExists", IsAlreadyExists},
+		} {
+			if got, want := p.is(tt.err), tt.want&p.kind != 0; got != want {
+				t.Errorf("%s: %s = %v, want %v", tt.name, p.name, got, want)
+			}
+		}
+	}
+}
+
+func TestAthenaHandleErrorLogs(t *testing.T) {
+	var codes []string
+
+	athenaCli := NewAthenaFromAPI(nil)
+	athenaCli.SetLogger(LoggerFunc(func(level Level, msg string, keyvals ...interface{}) {
+		for i := 0; i+1 < len(keyvals); i += 2 {
+			if keyvals[i] == "code" {
+				codes = append(codes, fmt.Sprint(keyvals[i+1]))
+			}
+		}
+	}))
+
+	athenaCli.HandleError(nil)
+	athenaCli.HandleError(awserr.New(athena.ErrCodeInvalidRequestException, "bad query", nil))
+	// Errors of the client methods were logged when they were returned.
+	athenaCli.HandleError(newError(athena.ServiceName, "GetQueryExecution", errors.New("boom")))
+
+	if len(codes) != 1 || codes[0] != athena.ErrCodeInvalidRequestException {
+		t.Errorf("logged codes %v, want %s once", codes, athena.ErrCodeInvalidRequestException)
+	}
+}
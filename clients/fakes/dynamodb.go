@@ -0,0 +1,566 @@
+package fakes
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// DynamoDB is an in-memory dynamodbiface.DynamoDBAPI. Update expressions
+// support SET with value placeholders and REMOVE; condition expressions are
+// rejected.
+type DynamoDB struct {
+	dynamodbiface.DynamoDBAPI
+
+	mu     sync.Mutex
+	tables map[string]*table
+}
+
+type table struct {
+	desc     *dynamodb.TableDescription
+	hashKey  string
+	rangeKey string
+	items    map[string]map[string]*dynamodb.AttributeValue
+	tags     map[string]string
+}
+
+func NewDynamoDB() *DynamoDB {
+	return &DynamoDB{tables: map[string]*table{}}
+}
+
+// Items returns a copy of every item stored in the table.
+func (f *DynamoDB) Items(tableName string) []map[string]*dynamodb.AttributeValue {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	t, ok := f.tables[tableName]
+	if !ok {
+		return nil
+	}
+
+	keys := make([]string, 0, len(t.items))
+	for k := range t.items {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	items := make([]map[string]*dynamodb.AttributeValue, 0, len(keys))
+	for _, k := range keys {
+		items = append(items, copyItem(t.items[k]))
+	}
+
+	return items
+}
+
+// SetTags sets the tags returned by ListTagsOfResource for the table, which
+// must exist.
+func (f *DynamoDB) SetTags(tableName string, tags map[string]string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	t, err := f.table(aws.String(tableName))
+	if err != nil {
+		return err
+	}
+
+	t.tags = make(map[string]string, len(tags))
+
+	for k, v := range tags {
+		t.tags[k] = v
+	}
+
+	return nil
+}
+
+func copyItem(item map[string]*dynamodb.AttributeValue) map[string]*dynamodb.AttributeValue {
+	if item == nil {
+		return nil
+	}
+
+	copied := make(map[string]*dynamodb.AttributeValue, len(item))
+	for k, v := range item {
+		copied[k] = v
+	}
+
+	return copied
+}
+
+func validationError(format string, args ...interface{}) error {
+	return requestFailure(http.StatusBadRequest, "ValidationException", format, args...)
+}
+
+func (f *DynamoDB) table(name *string) (*table, error) {
+	t, ok := f.tables[aws.StringValue(name)]
+	if !ok {
+		return nil, requestFailure(http.StatusBadRequest, dynamodb.ErrCodeResourceNotFoundException,
+			"Requested resource not found: Table: %s not found", aws.StringValue(name))
+	}
+
+	return t, nil
+}
+
+// itemKey returns the storage key of the item identified by the key
+// attributes found in attrs.
+func (t *table) itemKey(attrs map[string]*dynamodb.AttributeValue, exact bool) (string, error) {
+	names := []string{t.hashKey}
+	if t.rangeKey != "" {
+		names = append(names, t.rangeKey)
+	}
+
+	if exact && len(attrs) != len(names) {
+		return "", validationError("The provided key element does not match the schema")
+	}
+
+	parts := make([]string, 0, len(names))
+
+	for _, name := range names {
+		av, ok := attrs[name]
+		if !ok || av == nil {
+			return "", validationError("The provided key element does not match the schema")
+		}
+
+		switch {
+		case av.S != nil:
+			parts = append(parts, "S:"+*av.S)
+		case av.N != nil:
+			parts = append(parts, "N:"+*av.N)
+		case av.B != nil:
+			parts = append(parts, "B:"+hex.EncodeToString(av.B))
+		default:
+			return "", validationError("The provided key element does not match the schema")
+		}
+	}
+
+	return strings.Join(parts, "\x00"), nil
+}
+
+func (f *DynamoDB) CreateTableWithContext(ctx aws.Context, input *dynamodb.CreateTableInput, opts ...request.Option) (*dynamodb.CreateTableOutput, error) {
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	name := aws.StringValue(input.TableName)
+	if _, ok := f.tables[name]; ok {
+		return nil, requestFailure(http.StatusBadRequest, dynamodb.ErrCodeResourceInUseException,
+			"Table already exists: %s", name)
+	}
+
+	t := &table{items: map[string]map[string]*dynamodb.AttributeValue{}}
+
+	for _, k := range input.KeySchema {
+		switch aws.StringValue(k.KeyType) {
+		case dynamodb.KeyTypeHash:
+			t.hashKey = aws.StringValue(k.AttributeName)
+		case dynamodb.KeyTypeRange:
+			t.rangeKey = aws.StringValue(k.AttributeName)
+		}
+	}
+
+	if t.hashKey == "" {
+		return nil, validationError("No Hash Key specified in schema. All Dynamo tables must have exactly one hash key")
+	}
+
+	t.desc = &dynamodb.TableDescription{
+		TableName:             aws.String(name),
+		TableArn:              aws.String(fmt.Sprintf("arn:aws:dynamodb:%s:%s:table/%s", Region, Account, name)),
+		TableStatus:           aws.String(dynamodb.TableStatusActive),
+		AttributeDefinitions:  input.AttributeDefinitions,
+		KeySchema:             input.KeySchema,
+		ProvisionedThroughput: &dynamodb.ProvisionedThroughputDescription{},
+		CreationDateTime:      aws.Time(time.Now()),
+		ItemCount:             aws.Int64(0),
+	}
+
+	if input.ProvisionedThroughput != nil {
+		t.desc.ProvisionedThroughput.ReadCapacityUnits = input.ProvisionedThroughput.ReadCapacityUnits
+		t.desc.ProvisionedThroughput.WriteCapacityUnits = input.ProvisionedThroughput.WriteCapacityUnits
+	}
+
+	f.tables[name] = t
+
+	return &dynamodb.CreateTableOutput{TableDescription: t.describe()}, nil
+}
+
+func (t *table) describe() *dynamodb.TableDescription {
+	desc := *t.desc
+	desc.ItemCount = aws.Int64(int64(len(t.items)))
+
+	return &desc
+}
+
+func (f *DynamoDB) DescribeTableWithContext(ctx aws.Context, input *dynamodb.DescribeTableInput, opts ...request.Option) (*dynamodb.DescribeTableOutput, error) {
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	t, err := f.table(input.TableName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dynamodb.DescribeTableOutput{Table: t.describe()}, nil
+}
+
+func (f *DynamoDB) DeleteTableWithContext(ctx aws.Context, input *dynamodb.DeleteTableInput, opts ...request.Option) (*dynamodb.DeleteTableOutput, error) {
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	t, err := f.table(input.TableName)
+	if err != nil {
+		return nil, err
+	}
+
+	delete(f.tables, aws.StringValue(input.TableName))
+
+	desc := t.describe()
+	desc.TableStatus = aws.String(dynamodb.TableStatusDeleting)
+
+	return &dynamodb.DeleteTableOutput{TableDescription: desc}, nil
+}
+
+func (f *DynamoDB) ListTablesWithContext(ctx aws.Context, input *dynamodb.ListTablesInput, opts ...request.Option) (*dynamodb.ListTablesOutput, error) {
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	names := make([]string, 0, len(f.tables))
+	for name := range f.tables {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	start := 0
+	if input.ExclusiveStartTableName != nil {
+		start = sort.SearchStrings(names, *input.ExclusiveStartTableName)
+		if start < len(names) && names[start] == *input.ExclusiveStartTableName {
+			start++
+		}
+	}
+
+	limit := int(aws.Int64Value(input.Limit))
+	if limit <= 0 {
+		limit = 100
+	}
+
+	end := len(names)
+	if start+limit < end {
+		end = start + limit
+	}
+
+	output := &dynamodb.ListTablesOutput{TableNames: aws.StringSlice(names[start:end])}
+	if end < len(names) {
+		output.LastEvaluatedTableName = aws.String(names[end-1])
+	}
+
+	return output, nil
+}
+
+// ListTagsOfResourceWithContext returns every tag in one page.
+func (f *DynamoDB) ListTagsOfResourceWithContext(ctx aws.Context, input *dynamodb.ListTagsOfResourceInput, opts ...request.Option) (*dynamodb.ListTagsOfResourceOutput, error) {
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	resourceARN := aws.StringValue(input.ResourceArn)
+
+	i := strings.Index(resourceARN, ":table/")
+	if !strings.HasPrefix(resourceARN, "arn:") || i < 0 {
+		return nil, validationError("Invalid TableArn: %s", resourceARN)
+	}
+
+	t, ok := f.tables[resourceARN[i+len(":table/"):]]
+	if !ok || aws.StringValue(t.desc.TableArn) != resourceARN {
+		return nil, requestFailure(http.StatusBadRequest, dynamodb.ErrCodeResourceNotFoundException,
+			"Requested resource not found: ResourceArn: %s not found", resourceARN)
+	}
+
+	keys := make([]string, 0, len(t.tags))
+	for k := range t.tags {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	output := &dynamodb.ListTagsOfResourceOutput{Tags: []*dynamodb.Tag{}}
+	for _, k := range keys {
+		output.Tags = append(output.Tags, &dynamodb.Tag{Key: aws.String(k), Value: aws.String(t.tags[k])})
+	}
+
+	return output, nil
+}
+
+func (f *DynamoDB) GetItemWithContext(ctx aws.Context, input *dynamodb.GetItemInput, opts ...request.Option) (*dynamodb.GetItemOutput, error) {
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	t, err := f.table(input.TableName)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := t.itemKey(input.Key, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dynamodb.GetItemOutput{Item: copyItem(t.items[key])}, nil
+}
+
+func (f *DynamoDB) PutItemWithContext(ctx aws.Context, input *dynamodb.PutItemInput, opts ...request.Option) (*dynamodb.PutItemOutput, error) {
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
+
+	if input.ConditionExpression != nil {
+		return nil, validationError("ConditionExpression is not supported by the fake")
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	t, err := f.table(input.TableName)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := t.itemKey(input.Item, false)
+	if err != nil {
+		return nil, err
+	}
+
+	output := &dynamodb.PutItemOutput{}
+	if aws.StringValue(input.ReturnValues) == dynamodb.ReturnValueAllOld {
+		output.Attributes = copyItem(t.items[key])
+	}
+
+	t.items[key] = copyItem(input.Item)
+
+	return output, nil
+}
+
+func (f *DynamoDB) DeleteItemWithContext(ctx aws.Context, input *dynamodb.DeleteItemInput, opts ...request.Option) (*dynamodb.DeleteItemOutput, error) {
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
+
+	if input.ConditionExpression != nil {
+		return nil, validationError("ConditionExpression is not supported by the fake")
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	t, err := f.table(input.TableName)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := t.itemKey(input.Key, true)
+	if err != nil {
+		return nil, err
+	}
+
+	output := &dynamodb.DeleteItemOutput{}
+	if aws.StringValue(input.ReturnValues) == dynamodb.ReturnValueAllOld {
+		output.Attributes = copyItem(t.items[key])
+	}
+
+	delete(t.items, key)
+
+	return output, nil
+}
+
+var updateClause = regexp.MustCompile(`(?i)\b(SET|REMOVE)\s`)
+
+func (f *DynamoDB) UpdateItemWithContext(ctx aws.Context, input *dynamodb.UpdateItemInput, opts ...request.Option) (*dynamodb.UpdateItemOutput, error) {
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
+
+	if input.ConditionExpression != nil {
+		return nil, validationError("ConditionExpression is not supported by the fake")
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	t, err := f.table(input.TableName)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := t.itemKey(input.Key, true)
+	if err != nil {
+		return nil, err
+	}
+
+	set, remove, err := parseUpdate(aws.StringValue(input.UpdateExpression),
+		input.ExpressionAttributeNames, input.ExpressionAttributeValues)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range append(remove, keysOf(set)...) {
+		if name == t.hashKey || name == t.rangeKey {
+			return nil, validationError("Cannot update attribute %s. This attribute is part of the key", name)
+		}
+	}
+
+	old := t.items[key]
+
+	item := copyItem(old)
+	if item == nil {
+		item = copyItem(input.Key)
+	}
+
+	for name, value := range set {
+		item[name] = value
+	}
+
+	for _, name := range remove {
+		delete(item, name)
+	}
+
+	t.items[key] = item
+
+	output := &dynamodb.UpdateItemOutput{}
+
+	switch aws.StringValue(input.ReturnValues) {
+	case dynamodb.ReturnValueAllNew:
+		output.Attributes = copyItem(item)
+	case dynamodb.ReturnValueAllOld:
+		output.Attributes = copyItem(old)
+	case dynamodb.ReturnValueUpdatedNew:
+		output.Attributes = pick(item, keysOf(set))
+	case dynamodb.ReturnValueUpdatedOld:
+		output.Attributes = pick(old, append(keysOf(set), remove...))
+	}
+
+	return output, nil
+}
+
+func keysOf(m map[string]*dynamodb.AttributeValue) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	return keys
+}
+
+func pick(item map[string]*dynamodb.AttributeValue, names []string) map[string]*dynamodb.AttributeValue {
+	picked := map[string]*dynamodb.AttributeValue{}
+
+	for _, name := range names {
+		if v, ok := item[name]; ok {
+			picked[name] = v
+		}
+	}
+
+	return picked
+}
+
+// parseUpdate understands "SET a = :x, #b = :y REMOVE c" style expressions.
+func parseUpdate(expr string, names map[string]*string,
+	values map[string]*dynamodb.AttributeValue) (map[string]*dynamodb.AttributeValue, []string, error) {
+	set := map[string]*dynamodb.AttributeValue{}
+	remove := []string{}
+
+	resolve := func(name string) (string, error) {
+		name = strings.TrimSpace(name)
+		if strings.HasPrefix(name, "#") {
+			resolved, ok := names[name]
+			if !ok {
+				return "", validationError("An expression attribute name used in the document path is not defined; attribute name: %s", name)
+			}
+
+			return aws.StringValue(resolved), nil
+		}
+
+		if name == "" || strings.ContainsAny(name, ".[]() ") {
+			return "", validationError("Unsupported attribute path in the fake: %q", name)
+		}
+
+		return name, nil
+	}
+
+	bounds := updateClause.FindAllStringSubmatchIndex(expr, -1)
+	if len(bounds) == 0 || strings.TrimSpace(expr[:bounds[0][0]]) != "" {
+		return nil, nil, validationError("Invalid UpdateExpression: %q", expr)
+	}
+
+	for i, b := range bounds {
+		end := len(expr)
+		if i+1 < len(bounds) {
+			end = bounds[i+1][0]
+		}
+
+		keyword := strings.ToUpper(expr[b[2]:b[3]])
+
+		for _, action := range strings.Split(expr[b[1]:end], ",") {
+			if keyword == "REMOVE" {
+				name, err := resolve(action)
+				if err != nil {
+					return nil, nil, err
+				}
+
+				remove = append(remove, name)
+
+				continue
+			}
+
+			parts := strings.SplitN(action, "=", 2)
+			if len(parts) != 2 {
+				return nil, nil, validationError("Invalid UpdateExpression: %q", expr)
+			}
+
+			name, err := resolve(parts[0])
+			if err != nil {
+				return nil, nil, err
+			}
+
+			placeholder := strings.TrimSpace(parts[1])
+
+			value, ok := values[placeholder]
+			if !ok {
+				return nil, nil, validationError("An expression attribute value used in expression is not defined; attribute value: %s", placeholder)
+			}
+
+			set[name] = value
+		}
+	}
+
+	return set, remove, nil
+}
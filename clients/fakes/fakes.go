@@ -0,0 +1,82 @@
+// Package fakes provides stateful in-memory implementations of the SDK
+// service interfaces behind the most used clients, so code built on the
+// clients can be unit tested offline:
+//
+//	s3Cli := clients.NewS3FromAPI(fakes.NewS3())
+//
+// Each fake embeds its SDK interface and only implements the operations the
+// clients call. Any other operation panics. Failures are reported with the
+// same error codes and status codes as the real service, so the clients'
+// error predicates behave as they do against AWS.
+package fakes
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+)
+
+// Account and Region appear in the ARNs and URLs the fakes generate.
+const (
+	Account = "123456789012"
+	Region  = "us-east-1"
+)
+
+var seq uint64
+
+func nextSeq() uint64 {
+	return atomic.AddUint64(&seq, 1)
+}
+
+func nextID(prefix string) string {
+	return fmt.Sprintf("%s-%012d", prefix, nextSeq())
+}
+
+func requestFailure(status int, code, format string, args ...interface{}) error {
+	return awserr.NewRequestFailure(awserr.New(code, fmt.Sprintf(format, args...), nil), status, nextID("fake"))
+}
+
+// checkContext fails the same way the SDK does when ctx is already done.
+func checkContext(ctx aws.Context) error {
+	if ctx == nil {
+		return nil
+	}
+
+	if err := ctx.Err(); err != nil {
+		return awserr.New(request.CanceledErrorCode, "request context canceled", err)
+	}
+
+	return nil
+}
+
+// page returns the window of a sorted key list starting after the
+// pagination token, and the token of the next page.
+func page(keys []string, token *string, limit int) ([]string, *string) {
+	sort.Strings(keys)
+
+	start := 0
+	if token != nil {
+		start, _ = strconv.Atoi(*token)
+	}
+
+	if start > len(keys) {
+		start = len(keys)
+	}
+
+	end := len(keys)
+	if limit > 0 && start+limit < end {
+		end = start + limit
+	}
+
+	var next *string
+	if end < len(keys) {
+		next = aws.String(strconv.Itoa(end))
+	}
+
+	return keys[start:end], next
+}
@@ -0,0 +1,484 @@
+package fakes
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+)
+
+// S3 is an in-memory s3iface.S3API holding buckets and objects.
+type S3 struct {
+	s3iface.S3API
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+type bucket struct {
+	created    time.Time
+	region     string
+	policy     *string
+	encryption *s3.ServerSideEncryptionConfiguration
+	tags       map[string]string
+	objects    map[string]*object
+}
+
+type object struct {
+	body     []byte
+	etag     string
+	acl      string
+	modified time.Time
+}
+
+func NewS3() *S3 {
+	return &S3{buckets: map[string]*bucket{}}
+}
+
+// AddBucket creates an empty bucket unless it already exists.
+func (f *S3) AddBucket(name string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.addBucket(name)
+}
+
+func (f *S3) addBucket(name string) *bucket {
+	b, ok := f.buckets[name]
+	if !ok {
+		b = &bucket{created: time.Now(), region: Region, objects: map[string]*object{}}
+		f.buckets[name] = b
+	}
+
+	return b
+}
+
+// AddObject stores body under key, creating the bucket when needed.
+func (f *S3) AddObject(bucketName, key string, body []byte) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.addBucket(bucketName).objects[key] = newObject(body, s3.ObjectCannedACLPrivate)
+}
+
+// Object returns the body stored under key.
+func (f *S3) Object(bucketName, key string) ([]byte, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	b, ok := f.buckets[bucketName]
+	if !ok {
+		return nil, false
+	}
+
+	o, ok := b.objects[key]
+	if !ok {
+		return nil, false
+	}
+
+	return append([]byte(nil), o.body...), true
+}
+
+// SetBucketPolicy sets the policy document returned by GetBucketPolicy.
+func (f *S3) SetBucketPolicy(bucketName, policy string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.addBucket(bucketName).policy = aws.String(policy)
+}
+
+// SetBucketEncryption sets the configuration returned by GetBucketEncryption.
+func (f *S3) SetBucketEncryption(bucketName string, config *s3.ServerSideEncryptionConfiguration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.addBucket(bucketName).encryption = config
+}
+
+// SetBucketTagging sets the tags returned by GetBucketTagging; no tags make
+// it fail with NoSuchTagSet, as S3 does.
+func (f *S3) SetBucketTagging(bucketName string, tags map[string]string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	b := f.addBucket(bucketName)
+	b.tags = make(map[string]string, len(tags))
+
+	for k, v := range tags {
+		b.tags[k] = v
+	}
+}
+
+// SetBucketRegion sets the region returned by GetBucketLocation, Region
+// by default.
+func (f *S3) SetBucketRegion(bucketName, region string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.addBucket(bucketName).region = region
+}
+
+func newObject(body []byte, acl string) *object {
+	sum := md5.Sum(body)
+
+	return &object{
+		body:     append([]byte(nil), body...),
+		etag:     `"` + hex.EncodeToString(sum[:]) + `"`,
+		acl:      acl,
+		modified: time.Now(),
+	}
+}
+
+func (f *S3) bucket(name *string) (*bucket, error) {
+	b, ok := f.buckets[aws.StringValue(name)]
+	if !ok {
+		return nil, requestFailure(http.StatusNotFound, s3.ErrCodeNoSuchBucket, "The specified bucket does not exist")
+	}
+
+	return b, nil
+}
+
+func (f *S3) object(bucketName, key *string) (*object, error) {
+	b, err := f.bucket(bucketName)
+	if err != nil {
+		return nil, err
+	}
+
+	o, ok := b.objects[aws.StringValue(key)]
+	if !ok {
+		return nil, requestFailure(http.StatusNotFound, s3.ErrCodeNoSuchKey, "The specified key does not exist.")
+	}
+
+	return o, nil
+}
+
+func (f *S3) ListBucketsWithContext(ctx aws.Context, input *s3.ListBucketsInput, opts ...request.Option) (*s3.ListBucketsOutput, error) {
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	names := make([]string, 0, len(f.buckets))
+	for name := range f.buckets {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	output := &s3.ListBucketsOutput{
+		Owner: &s3.Owner{ID: aws.String(Account)},
+	}
+
+	for _, name := range names {
+		output.Buckets = append(output.Buckets, &s3.Bucket{
+			Name:         aws.String(name),
+			CreationDate: aws.Time(f.buckets[name].created),
+		})
+	}
+
+	return output, nil
+}
+
+func (f *S3) GetBucketPolicyWithContext(ctx aws.Context, input *s3.GetBucketPolicyInput, opts ...request.Option) (*s3.GetBucketPolicyOutput, error) {
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	b, err := f.bucket(input.Bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	if b.policy == nil {
+		return nil, requestFailure(http.StatusNotFound, "NoSuchBucketPolicy", "The bucket policy does not exist")
+	}
+
+	return &s3.GetBucketPolicyOutput{Policy: aws.String(*b.policy)}, nil
+}
+
+func (f *S3) GetBucketEncryptionWithContext(ctx aws.Context, input *s3.GetBucketEncryptionInput, opts ...request.Option) (*s3.GetBucketEncryptionOutput, error) {
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	b, err := f.bucket(input.Bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	if b.encryption == nil {
+		return nil, requestFailure(http.StatusNotFound, "ServerSideEncryptionConfigurationNotFoundError",
+			"The server side encryption configuration was not found")
+	}
+
+	return &s3.GetBucketEncryptionOutput{ServerSideEncryptionConfiguration: b.encryption}, nil
+}
+
+func (f *S3) GetBucketTaggingWithContext(ctx aws.Context, input *s3.GetBucketTaggingInput, opts ...request.Option) (*s3.GetBucketTaggingOutput, error) {
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	b, err := f.bucket(input.Bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(b.tags) == 0 {
+		return nil, requestFailure(http.StatusNotFound, "NoSuchTagSet", "The TagSet does not exist")
+	}
+
+	keys := make([]string, 0, len(b.tags))
+	for k := range b.tags {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	output := &s3.GetBucketTaggingOutput{}
+	for _, k := range keys {
+		output.TagSet = append(output.TagSet, &s3.Tag{Key: aws.String(k), Value: aws.String(b.tags[k])})
+	}
+
+	return output, nil
+}
+
+// GetBucketLocationWithContext returns no location constraint for buckets
+// in us-east-1, as S3 does.
+func (f *S3) GetBucketLocationWithContext(ctx aws.Context, input *s3.GetBucketLocationInput, opts ...request.Option) (*s3.GetBucketLocationOutput, error) {
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	b, err := f.bucket(input.Bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	output := &s3.GetBucketLocationOutput{}
+	if b.region != "us-east-1" {
+		output.LocationConstraint = aws.String(b.region)
+	}
+
+	return output, nil
+}
+
+func (f *S3) HeadObjectWithContext(ctx aws.Context, input *s3.HeadObjectInput, opts ...request.Option) (*s3.HeadObjectOutput, error) {
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	o, err := f.object(input.Bucket, input.Key)
+	if err != nil {
+		// HEAD responses have no body, so S3 cannot say which one is missing.
+		return nil, requestFailure(http.StatusNotFound, "NotFound", "Not Found")
+	}
+
+	return &s3.HeadObjectOutput{
+		ContentLength: aws.Int64(int64(len(o.body))),
+		ETag:          aws.String(o.etag),
+		LastModified:  aws.Time(o.modified),
+	}, nil
+}
+
+func (f *S3) ListObjectsV2WithContext(ctx aws.Context, input *s3.ListObjectsV2Input, opts ...request.Option) (*s3.ListObjectsV2Output, error) {
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	b, err := f.bucket(input.Bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := aws.StringValue(input.Prefix)
+	delimiter := aws.StringValue(input.Delimiter)
+
+	entries := []string{}
+	prefixes := map[string]bool{}
+
+	for key := range b.objects {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+
+		if delimiter != "" {
+			if i := strings.Index(key[len(prefix):], delimiter); i >= 0 {
+				common := key[:len(prefix)+i+len(delimiter)]
+				if !prefixes[common] {
+					prefixes[common] = true
+					entries = append(entries, common)
+				}
+
+				continue
+			}
+		}
+
+		entries = append(entries, key)
+	}
+
+	maxKeys := int(aws.Int64Value(input.MaxKeys))
+	if maxKeys <= 0 {
+		maxKeys = 1000
+	}
+
+	window, next := page(entries, input.ContinuationToken, maxKeys)
+
+	output := &s3.ListObjectsV2Output{
+		Name:                  input.Bucket,
+		Prefix:                input.Prefix,
+		Delimiter:             input.Delimiter,
+		MaxKeys:               aws.Int64(int64(maxKeys)),
+		KeyCount:              aws.Int64(int64(len(window))),
+		ContinuationToken:     input.ContinuationToken,
+		IsTruncated:           aws.Bool(next != nil),
+		NextContinuationToken: next,
+	}
+
+	for _, entry := range window {
+		if prefixes[entry] {
+			output.CommonPrefixes = append(output.CommonPrefixes, &s3.CommonPrefix{Prefix: aws.String(entry)})
+
+			continue
+		}
+
+		o := b.objects[entry]
+		output.Contents = append(output.Contents, &s3.Object{
+			Key:          aws.String(entry),
+			Size:         aws.Int64(int64(len(o.body))),
+			ETag:         aws.String(o.etag),
+			LastModified: aws.Time(o.modified),
+			StorageClass: aws.String(s3.ObjectStorageClassStandard),
+		})
+	}
+
+	return output, nil
+}
+
+func (f *S3) GetObjectAclWithContext(ctx aws.Context, input *s3.GetObjectAclInput, opts ...request.Option) (*s3.GetObjectAclOutput, error) {
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	o, err := f.object(input.Bucket, input.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	owner := &s3.Owner{ID: aws.String(Account)}
+	grants := []*s3.Grant{{
+		Grantee:    &s3.Grantee{ID: owner.ID, Type: aws.String(s3.TypeCanonicalUser)},
+		Permission: aws.String(s3.PermissionFullControl),
+	}}
+
+	group := func(uri, permission string) *s3.Grant {
+		return &s3.Grant{
+			Grantee:    &s3.Grantee{URI: aws.String(uri), Type: aws.String(s3.TypeGroup)},
+			Permission: aws.String(permission),
+		}
+	}
+
+	const allUsers = "http://acs.amazonaws.com/groups/global/AllUsers"
+
+	switch o.acl {
+	case s3.ObjectCannedACLPublicRead:
+		grants = append(grants, group(allUsers, s3.PermissionRead))
+	case s3.ObjectCannedACLPublicReadWrite:
+		grants = append(grants, group(allUsers, s3.PermissionRead), group(allUsers, s3.PermissionWrite))
+	case s3.ObjectCannedACLAuthenticatedRead:
+		grants = append(grants, group("http://acs.amazonaws.com/groups/global/AuthenticatedUsers", s3.PermissionRead))
+	}
+
+	return &s3.GetObjectAclOutput{Owner: owner, Grants: grants}, nil
+}
+
+func (f *S3) PutObjectAclWithContext(ctx aws.Context, input *s3.PutObjectAclInput, opts ...request.Option) (*s3.PutObjectAclOutput, error) {
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	o, err := f.object(input.Bucket, input.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	o.acl = aws.StringValue(input.ACL)
+
+	return &s3.PutObjectAclOutput{}, nil
+}
+
+func (f *S3) CopyObjectWithContext(ctx aws.Context, input *s3.CopyObjectInput, opts ...request.Option) (*s3.CopyObjectOutput, error) {
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
+
+	source, err := url.PathUnescape(strings.TrimPrefix(aws.StringValue(input.CopySource), "/"))
+	if err != nil {
+		return nil, requestFailure(http.StatusBadRequest, "InvalidArgument", "Invalid copy source encoding")
+	}
+
+	parts := strings.SplitN(source, "/", 2)
+	if len(parts) != 2 {
+		return nil, requestFailure(http.StatusBadRequest, "InvalidArgument", "Invalid copy source object key")
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	src, err := f.object(aws.String(parts[0]), aws.String(parts[1]))
+	if err != nil {
+		return nil, err
+	}
+
+	dst, err := f.bucket(input.Bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	acl := aws.StringValue(input.ACL)
+	if acl == "" {
+		acl = s3.ObjectCannedACLPrivate
+	}
+
+	o := newObject(src.body, acl)
+	dst.objects[aws.StringValue(input.Key)] = o
+
+	return &s3.CopyObjectOutput{
+		CopyObjectResult: &s3.CopyObjectResult{ETag: aws.String(o.etag), LastModified: aws.Time(o.modified)},
+	}, nil
+}
@@ -0,0 +1,288 @@
+package fakes
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/aws/aws-sdk-go/service/secretsmanager/secretsmanageriface"
+)
+
+// SecretsManager is an in-memory secretsmanageriface.SecretsManagerAPI.
+// Secrets can be addressed by name or ARN.
+type SecretsManager struct {
+	secretsmanageriface.SecretsManagerAPI
+
+	// PageSize bounds the number of secrets per ListSecrets page when the
+	// request does not set MaxResults.
+	PageSize int
+
+	mu      sync.Mutex
+	secrets map[string]*secret
+}
+
+type secret struct {
+	arn         string
+	name        string
+	description *string
+	value       *string
+	binary      []byte
+	versionID   string
+	created     time.Time
+	changed     time.Time
+}
+
+func NewSecretsManager() *SecretsManager {
+	return &SecretsManager{PageSize: 100, secrets: map[string]*secret{}}
+}
+
+// SetSecret stores a string secret, creating it when needed.
+func (f *SecretsManager) SetSecret(name, value string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	s, ok := f.secrets[name]
+	if !ok {
+		s = f.create(name)
+	}
+
+	s.setValue(aws.String(value), nil)
+}
+
+func (f *SecretsManager) create(name string) *secret {
+	now := time.Now()
+	s := &secret{
+		arn:     fmt.Sprintf("arn:aws:secretsmanager:%s:%s:secret:%s-%06x", Region, Account, name, nextSeq()),
+		name:    name,
+		created: now,
+		changed: now,
+	}
+	f.secrets[name] = s
+
+	return s
+}
+
+func (s *secret) setValue(value *string, binary []byte) {
+	s.value = value
+	s.binary = append([]byte(nil), binary...)
+	s.versionID = nextID("00000000-0000-0000-0000")
+	s.changed = time.Now()
+}
+
+func (f *SecretsManager) lookup(id *string) (*secret, error) {
+	key := aws.StringValue(id)
+
+	if s, ok := f.secrets[key]; ok {
+		return s, nil
+	}
+
+	if strings.HasPrefix(key, "arn:") {
+		for _, s := range f.secrets {
+			if s.arn == key {
+				return s, nil
+			}
+		}
+	}
+
+	return nil, requestFailure(http.StatusBadRequest, secretsmanager.ErrCodeResourceNotFoundException,
+		"Secrets Manager can't find the specified secret.")
+}
+
+func (f *SecretsManager) GetSecretValueWithContext(ctx aws.Context, input *secretsmanager.GetSecretValueInput, opts ...request.Option) (*secretsmanager.GetSecretValueOutput, error) {
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	s, err := f.lookup(input.SecretId)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.value == nil && s.binary == nil {
+		return nil, requestFailure(http.StatusBadRequest, secretsmanager.ErrCodeResourceNotFoundException,
+			"Secrets Manager can't find the specified secret value for staging label: AWSCURRENT")
+	}
+
+	return &secretsmanager.GetSecretValueOutput{
+		ARN:           aws.String(s.arn),
+		Name:          aws.String(s.name),
+		SecretString:  s.value,
+		SecretBinary:  append([]byte(nil), s.binary...),
+		VersionId:     aws.String(s.versionID),
+		VersionStages: aws.StringSlice([]string{"AWSCURRENT"}),
+		CreatedDate:   aws.Time(s.changed),
+	}, nil
+}
+
+func (f *SecretsManager) CreateSecretWithContext(ctx aws.Context, input *secretsmanager.CreateSecretInput, opts ...request.Option) (*secretsmanager.CreateSecretOutput, error) {
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	name := aws.StringValue(input.Name)
+	if _, ok := f.secrets[name]; ok {
+		return nil, requestFailure(http.StatusBadRequest, secretsmanager.ErrCodeResourceExistsException,
+			"The operation failed because the secret %s already exists.", name)
+	}
+
+	s := f.create(name)
+	s.description = input.Description
+
+	output := &secretsmanager.CreateSecretOutput{ARN: aws.String(s.arn), Name: aws.String(s.name)}
+
+	if input.SecretString != nil || input.SecretBinary != nil {
+		s.setValue(input.SecretString, input.SecretBinary)
+		output.VersionId = aws.String(s.versionID)
+	}
+
+	return output, nil
+}
+
+func (f *SecretsManager) PutSecretValueWithContext(ctx aws.Context, input *secretsmanager.PutSecretValueInput, opts ...request.Option) (*secretsmanager.PutSecretValueOutput, error) {
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	s, err := f.lookup(input.SecretId)
+	if err != nil {
+		return nil, err
+	}
+
+	s.setValue(input.SecretString, input.SecretBinary)
+
+	return &secretsmanager.PutSecretValueOutput{
+		ARN:           aws.String(s.arn),
+		Name:          aws.String(s.name),
+		VersionId:     aws.String(s.versionID),
+		VersionStages: aws.StringSlice([]string{"AWSCURRENT"}),
+	}, nil
+}
+
+func (f *SecretsManager) UpdateSecretWithContext(ctx aws.Context, input *secretsmanager.UpdateSecretInput, opts ...request.Option) (*secretsmanager.UpdateSecretOutput, error) {
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	s, err := f.lookup(input.SecretId)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Description != nil {
+		s.description = input.Description
+		s.changed = time.Now()
+	}
+
+	output := &secretsmanager.UpdateSecretOutput{ARN: aws.String(s.arn), Name: aws.String(s.name)}
+
+	if input.SecretString != nil || input.SecretBinary != nil {
+		s.setValue(input.SecretString, input.SecretBinary)
+		output.VersionId = aws.String(s.versionID)
+	}
+
+	return output, nil
+}
+
+func (f *SecretsManager) DescribeSecretWithContext(ctx aws.Context, input *secretsmanager.DescribeSecretInput, opts ...request.Option) (*secretsmanager.DescribeSecretOutput, error) {
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	s, err := f.lookup(input.SecretId)
+	if err != nil {
+		return nil, err
+	}
+
+	output := &secretsmanager.DescribeSecretOutput{
+		ARN:                aws.String(s.arn),
+		Name:               aws.String(s.name),
+		Description:        s.description,
+		CreatedDate:        aws.Time(s.created),
+		LastChangedDate:    aws.Time(s.changed),
+		VersionIdsToStages: map[string][]*string{},
+	}
+
+	if s.versionID != "" {
+		output.VersionIdsToStages[s.versionID] = aws.StringSlice([]string{"AWSCURRENT"})
+	}
+
+	return output, nil
+}
+
+func (f *SecretsManager) ListSecretsWithContext(ctx aws.Context, input *secretsmanager.ListSecretsInput, opts ...request.Option) (*secretsmanager.ListSecretsOutput, error) {
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	names := make([]string, 0, len(f.secrets))
+	for name := range f.secrets {
+		names = append(names, name)
+	}
+
+	limit := int(aws.Int64Value(input.MaxResults))
+	if limit <= 0 {
+		limit = f.PageSize
+	}
+
+	window, next := page(names, input.NextToken, limit)
+
+	output := &secretsmanager.ListSecretsOutput{NextToken: next}
+
+	for _, name := range window {
+		s := f.secrets[name]
+		output.SecretList = append(output.SecretList, &secretsmanager.SecretListEntry{
+			ARN:             aws.String(s.arn),
+			Name:            aws.String(s.name),
+			Description:     s.description,
+			CreatedDate:     aws.Time(s.created),
+			LastChangedDate: aws.Time(s.changed),
+		})
+	}
+
+	return output, nil
+}
+
+func (f *SecretsManager) DeleteSecretWithContext(ctx aws.Context, input *secretsmanager.DeleteSecretInput, opts ...request.Option) (*secretsmanager.DeleteSecretOutput, error) {
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	s, err := f.lookup(input.SecretId)
+	if err != nil {
+		return nil, err
+	}
+
+	delete(f.secrets, s.name)
+
+	return &secretsmanager.DeleteSecretOutput{
+		ARN:          aws.String(s.arn),
+		Name:         aws.String(s.name),
+		DeletionDate: aws.Time(time.Now()),
+	}, nil
+}
@@ -0,0 +1,335 @@
+package fakes
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
+)
+
+// SQS is an in-memory sqsiface.SQSAPI. Received messages stay invisible for
+// the visibility timeout and are redelivered unless deleted. ReceiveMessage
+// never blocks, whatever the requested wait time.
+type SQS struct {
+	sqsiface.SQSAPI
+
+	// Now is the clock used for visibility timeouts; it defaults to time.Now.
+	Now func() time.Time
+
+	mu     sync.Mutex
+	queues map[string]*queue
+}
+
+type queue struct {
+	name       string
+	url        string
+	attributes map[string]*string
+	messages   []*message
+}
+
+type message struct {
+	id             string
+	body           string
+	md5            string
+	receipt        string
+	receiveCount   int
+	invisibleUntil time.Time
+}
+
+const defaultVisibilityTimeout = 30 * time.Second
+
+func NewSQS() *SQS {
+	return &SQS{Now: time.Now, queues: map[string]*queue{}}
+}
+
+func (f *SQS) now() time.Time {
+	if f.Now == nil {
+		return time.Now()
+	}
+
+	return f.Now()
+}
+
+func queueURL(name string) string {
+	return fmt.Sprintf("https://sqs.%s.amazonaws.com/%s/%s", Region, Account, name)
+}
+
+func (f *SQS) queue(url *string) (*queue, error) {
+	q, ok := f.queues[aws.StringValue(url)]
+	if !ok {
+		return nil, requestFailure(http.StatusBadRequest, sqs.ErrCodeQueueDoesNotExist,
+			"The specified queue does not exist for this wsdl version.")
+	}
+
+	return q, nil
+}
+
+func (q *queue) visibilityTimeout(override *int64) time.Duration {
+	if override != nil {
+		return time.Duration(*override) * time.Second
+	}
+
+	if v, ok := q.attributes[sqs.QueueAttributeNameVisibilityTimeout]; ok {
+		if seconds, err := strconv.Atoi(aws.StringValue(v)); err == nil {
+			return time.Duration(seconds) * time.Second
+		}
+	}
+
+	return defaultVisibilityTimeout
+}
+
+func (q *queue) send(body string) *message {
+	sum := md5.Sum([]byte(body))
+	m := &message{
+		id:   nextID("00000000-0000-0000-0000"),
+		body: body,
+		md5:  hex.EncodeToString(sum[:]),
+	}
+	q.messages = append(q.messages, m)
+
+	return m
+}
+
+func (f *SQS) CreateQueueWithContext(ctx aws.Context, input *sqs.CreateQueueInput, opts ...request.Option) (*sqs.CreateQueueOutput, error) {
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	name := aws.StringValue(input.QueueName)
+	if name == "" {
+		return nil, requestFailure(http.StatusBadRequest, "InvalidParameterValue", "Queue name cannot be empty")
+	}
+
+	url := queueURL(name)
+
+	if q, ok := f.queues[url]; ok {
+		for k, v := range input.Attributes {
+			if aws.StringValue(q.attributes[k]) != aws.StringValue(v) {
+				return nil, requestFailure(http.StatusBadRequest, sqs.ErrCodeQueueNameExists,
+					"A queue already exists with the same name and a different value for attribute %s", k)
+			}
+		}
+
+		return &sqs.CreateQueueOutput{QueueUrl: aws.String(url)}, nil
+	}
+
+	attributes := map[string]*string{}
+	for k, v := range input.Attributes {
+		attributes[k] = v
+	}
+
+	f.queues[url] = &queue{name: name, url: url, attributes: attributes}
+
+	return &sqs.CreateQueueOutput{QueueUrl: aws.String(url)}, nil
+}
+
+func (f *SQS) GetQueueUrlWithContext(ctx aws.Context, input *sqs.GetQueueUrlInput, opts ...request.Option) (*sqs.GetQueueUrlOutput, error) {
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	q, err := f.queue(aws.String(queueURL(aws.StringValue(input.QueueName))))
+	if err != nil {
+		return nil, err
+	}
+
+	return &sqs.GetQueueUrlOutput{QueueUrl: aws.String(q.url)}, nil
+}
+
+func (f *SQS) DeleteQueueWithContext(ctx aws.Context, input *sqs.DeleteQueueInput, opts ...request.Option) (*sqs.DeleteQueueOutput, error) {
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	q, err := f.queue(input.QueueUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	delete(f.queues, q.url)
+
+	return &sqs.DeleteQueueOutput{}, nil
+}
+
+func (f *SQS) SendMessageWithContext(ctx aws.Context, input *sqs.SendMessageInput, opts ...request.Option) (*sqs.SendMessageOutput, error) {
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	q, err := f.queue(input.QueueUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	if aws.StringValue(input.MessageBody) == "" {
+		return nil, requestFailure(http.StatusBadRequest, "MissingParameter",
+			"The request must contain the parameter MessageBody.")
+	}
+
+	m := q.send(aws.StringValue(input.MessageBody))
+
+	return &sqs.SendMessageOutput{MessageId: aws.String(m.id), MD5OfMessageBody: aws.String(m.md5)}, nil
+}
+
+func (f *SQS) SendMessageBatchWithContext(ctx aws.Context, input *sqs.SendMessageBatchInput, opts ...request.Option) (*sqs.SendMessageBatchOutput, error) {
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	q, err := f.queue(input.QueueUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case len(input.Entries) == 0:
+		return nil, requestFailure(http.StatusBadRequest, sqs.ErrCodeEmptyBatchRequest,
+			"There should be at least one SendMessageBatchRequestEntry in the request.")
+	case len(input.Entries) > 10:
+		return nil, requestFailure(http.StatusBadRequest, sqs.ErrCodeTooManyEntriesInBatchRequest,
+			"Maximum number of entries per request are 10. You have sent %d.", len(input.Entries))
+	}
+
+	ids := map[string]bool{}
+
+	for _, entry := range input.Entries {
+		id := aws.StringValue(entry.Id)
+		if ids[id] {
+			return nil, requestFailure(http.StatusBadRequest, sqs.ErrCodeBatchEntryIdsNotDistinct,
+				"Id %s repeated.", id)
+		}
+
+		ids[id] = true
+	}
+
+	output := &sqs.SendMessageBatchOutput{
+		Successful: []*sqs.SendMessageBatchResultEntry{},
+		Failed:     []*sqs.BatchResultErrorEntry{},
+	}
+
+	for _, entry := range input.Entries {
+		if aws.StringValue(entry.MessageBody) == "" {
+			output.Failed = append(output.Failed, &sqs.BatchResultErrorEntry{
+				Id:          entry.Id,
+				Code:        aws.String("MissingParameter"),
+				Message:     aws.String("The request must contain the parameter MessageBody."),
+				SenderFault: aws.Bool(true),
+			})
+
+			continue
+		}
+
+		m := q.send(aws.StringValue(entry.MessageBody))
+		output.Successful = append(output.Successful, &sqs.SendMessageBatchResultEntry{
+			Id:               entry.Id,
+			MessageId:        aws.String(m.id),
+			MD5OfMessageBody: aws.String(m.md5),
+		})
+	}
+
+	return output, nil
+}
+
+func (f *SQS) ReceiveMessageWithContext(ctx aws.Context, input *sqs.ReceiveMessageInput, opts ...request.Option) (*sqs.ReceiveMessageOutput, error) {
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	q, err := f.queue(input.QueueUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	max := int(aws.Int64Value(input.MaxNumberOfMessages))
+
+	switch {
+	case max == 0:
+		max = 1
+	case max < 1 || max > 10:
+		return nil, requestFailure(http.StatusBadRequest, "InvalidParameterValue",
+			"Value %d for parameter MaxNumberOfMessages is invalid. Reason: Must be between 1 and 10, if provided.", max)
+	}
+
+	now := f.now()
+	timeout := q.visibilityTimeout(input.VisibilityTimeout)
+	output := &sqs.ReceiveMessageOutput{Messages: []*sqs.Message{}}
+
+	for _, m := range q.messages {
+		if len(output.Messages) == max {
+			break
+		}
+
+		if now.Before(m.invisibleUntil) {
+			continue
+		}
+
+		m.receiveCount++
+		m.receipt = nextID(m.id)
+		m.invisibleUntil = now.Add(timeout)
+
+		output.Messages = append(output.Messages, &sqs.Message{
+			MessageId:     aws.String(m.id),
+			ReceiptHandle: aws.String(m.receipt),
+			Body:          aws.String(m.body),
+			MD5OfBody:     aws.String(m.md5),
+			Attributes: map[string]*string{
+				sqs.MessageSystemAttributeNameApproximateReceiveCount: aws.String(strconv.Itoa(m.receiveCount)),
+			},
+		})
+	}
+
+	return output, nil
+}
+
+func (f *SQS) DeleteMessageWithContext(ctx aws.Context, input *sqs.DeleteMessageInput, opts ...request.Option) (*sqs.DeleteMessageOutput, error) {
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	q, err := f.queue(input.QueueUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	receipt := aws.StringValue(input.ReceiptHandle)
+
+	for i, m := range q.messages {
+		if m.receipt != "" && m.receipt == receipt {
+			q.messages = append(q.messages[:i], q.messages[i+1:]...)
+
+			return &sqs.DeleteMessageOutput{}, nil
+		}
+	}
+
+	return nil, requestFailure(http.StatusBadRequest, sqs.ErrCodeReceiptHandleIsInvalid,
+		"The input receipt handle %q is not a valid receipt handle.", receipt)
+}
@@ -0,0 +1,126 @@
+package fakes
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
+)
+
+// SSM is an in-memory ssmiface.SSMAPI holding Parameter Store parameters.
+type SSM struct {
+	ssmiface.SSMAPI
+
+	mu     sync.Mutex
+	params map[string]*ssm.Parameter
+}
+
+func NewSSM() *SSM {
+	return &SSM{params: map[string]*ssm.Parameter{}}
+}
+
+// SetParameter stores a String parameter, overwriting any previous value.
+func (f *SSM) SetParameter(name, value string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.put(name, value, ssm.ParameterTypeString)
+}
+
+func (f *SSM) put(name, value, paramType string) *ssm.Parameter {
+	var version int64 = 1
+	if prev, ok := f.params[name]; ok {
+		version = aws.Int64Value(prev.Version) + 1
+	}
+
+	p := &ssm.Parameter{
+		ARN:              aws.String(fmt.Sprintf("arn:aws:ssm:%s:%s:parameter/%s", Region, Account, trimSlash(name))),
+		Name:             aws.String(name),
+		Value:            aws.String(value),
+		Type:             aws.String(paramType),
+		Version:          aws.Int64(version),
+		DataType:         aws.String("text"),
+		LastModifiedDate: aws.Time(time.Now()),
+	}
+	f.params[name] = p
+
+	return p
+}
+
+func trimSlash(name string) string {
+	if len(name) > 0 && name[0] == '/' {
+		return name[1:]
+	}
+
+	return name
+}
+
+func (f *SSM) GetParameterWithContext(ctx aws.Context, input *ssm.GetParameterInput, opts ...request.Option) (*ssm.GetParameterOutput, error) {
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	p, ok := f.params[aws.StringValue(input.Name)]
+	if !ok {
+		return nil, requestFailure(http.StatusBadRequest, ssm.ErrCodeParameterNotFound, "")
+	}
+
+	copied := *p
+
+	return &ssm.GetParameterOutput{Parameter: &copied}, nil
+}
+
+func (f *SSM) PutParameterWithContext(ctx aws.Context, input *ssm.PutParameterInput, opts ...request.Option) (*ssm.PutParameterOutput, error) {
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	name := aws.StringValue(input.Name)
+
+	prev, exists := f.params[name]
+	if exists && !aws.BoolValue(input.Overwrite) {
+		return nil, requestFailure(http.StatusBadRequest, ssm.ErrCodeParameterAlreadyExists, "The parameter already exists.")
+	}
+
+	paramType := aws.StringValue(input.Type)
+	if paramType == "" {
+		if !exists {
+			return nil, requestFailure(http.StatusBadRequest, "ValidationException", "A parameter type is required when you create a parameter.")
+		}
+
+		paramType = aws.StringValue(prev.Type)
+	}
+
+	p := f.put(name, aws.StringValue(input.Value), paramType)
+
+	return &ssm.PutParameterOutput{Version: p.Version, Tier: aws.String(ssm.ParameterTierStandard)}, nil
+}
+
+func (f *SSM) DeleteParameterWithContext(ctx aws.Context, input *ssm.DeleteParameterInput, opts ...request.Option) (*ssm.DeleteParameterOutput, error) {
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	name := aws.StringValue(input.Name)
+	if _, ok := f.params[name]; !ok {
+		return nil, requestFailure(http.StatusBadRequest, ssm.ErrCodeParameterNotFound, "")
+	}
+
+	delete(f.params, name)
+
+	return &ssm.DeleteParameterOutput{}, nil
+}
@@ -0,0 +1,29 @@
+package fakes
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/aws/aws-sdk-go/service/sts/stsiface"
+)
+
+// STS is an stsiface.STSAPI whose caller is a user of Account.
+type STS struct {
+	stsiface.STSAPI
+}
+
+func NewSTS() *STS {
+	return &STS{}
+}
+
+func (f *STS) GetCallerIdentityWithContext(ctx aws.Context, input *sts.GetCallerIdentityInput, opts ...request.Option) (*sts.GetCallerIdentityOutput, error) {
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
+
+	return &sts.GetCallerIdentityOutput{
+		Account: aws.String(Account),
+		Arn:     aws.String("arn:aws:iam::" + Account + ":user/fake"),
+		UserId:  aws.String("AIDAFAKEUSER"),
+	}, nil
+}
@@ -1,167 +1,272 @@
 package clients
 
 import (
-	"fmt"
+	"context"
 
-	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/glue"
+	"github.com/aws/aws-sdk-go/service/glue/glueiface"
 )
 
+type GlueAPI interface {
+	ListDatabases() ([]*glue.Database, error)
+	ListDatabasesWithContext(ctx context.Context) ([]*glue.Database, error)
+	ListDatabasesPages(pageSize int64, fn func(databases []*glue.Database) bool) error
+	ListDatabasesPagesWithContext(ctx context.Context, pageSize int64, fn func(databases []*glue.Database) bool) error
+	ListTables(dbName *string) ([]*glue.TableData, error)
+	ListTablesWithContext(ctx context.Context, dbName *string) ([]*glue.TableData, error)
+	ListTablesPages(dbName *string, pageSize int64, fn func(tables []*glue.TableData) bool) error
+	ListTablesPagesWithContext(ctx context.Context, dbName *string, pageSize int64, fn func(tables []*glue.TableData) bool) error
+	ListCrawlers() ([]*glue.Crawler, error)
+	ListCrawlersWithContext(ctx context.Context) ([]*glue.Crawler, error)
+	ListCrawlersPages(pageSize int64, fn func(crawlers []*glue.Crawler) bool) error
+	ListCrawlersPagesWithContext(ctx context.Context, pageSize int64, fn func(crawlers []*glue.Crawler) bool) error
+	ListClassifiers() ([]*glue.Classifier, error)
+	ListClassifiersWithContext(ctx context.Context) ([]*glue.Classifier, error)
+	ListClassifiersPages(pageSize int64, fn func(classifiers []*glue.Classifier) bool) error
+	ListClassifiersPagesWithContext(ctx context.Context, pageSize int64, fn func(classifiers []*glue.Classifier) bool) error
+	ListTriggers() ([]*glue.Trigger, error)
+	ListTriggersWithContext(ctx context.Context) ([]*glue.Trigger, error)
+	ListTriggersPages(pageSize int64, fn func(triggers []*glue.Trigger) bool) error
+	ListTriggersPagesWithContext(ctx context.Context, pageSize int64, fn func(triggers []*glue.Trigger) bool) error
+	GetTags(resourceARN *string) (map[string]*string, error)
+	GetTagsWithContext(ctx context.Context, resourceARN *string) (map[string]*string, error)
+}
+
+var _ GlueAPI = (*GlueClient)(nil)
+
 type GlueClient struct {
-	cli *glue.Glue
+	logging
+	*owner
+	cli glueiface.GlueAPI
+}
+
+func init() {
+	Register("glue", func(sess *session.Session) interface{} { return NewGlue(sess) })
 }
 
-func NewGlue(sess *session.Session) *GlueClient {
-	client := glue.New(sess)
+func NewGlue(sess *session.Session, cfgs ...*aws.Config) *GlueClient {
+	client := glue.New(sess, cfgs...)
 
-	return &GlueClient{cli: client}
+	return &GlueClient{logging: newLogging(sess), owner: newOwner(sess, client.Config), cli: client}
 }
 
-func (glueCli *GlueClient) ListDatabases() []*glue.Database {
-	input := &glue.GetDatabasesInput{}
+func NewGlueFromAPI(api glueiface.GlueAPI) *GlueClient {
+	return &GlueClient{logging: logging{logger: NopLogger}, owner: &owner{}, cli: api}
+}
 
-	resp, err := glueCli.cli.GetDatabases(input)
-	if err != nil {
-		glueCli.handleError(err)
-	}
+func (glueCli *GlueClient) ListDatabases() ([]*glue.Database, error) {
+	return glueCli.ListDatabasesWithContext(context.Background())
+}
 
-	databases := resp.DatabaseList
+func (glueCli *GlueClient) ListDatabasesWithContext(ctx context.Context) ([]*glue.Database, error) {
+	var databases []*glue.Database
 
-	for resp.NextToken != nil {
-		input = &glue.GetDatabasesInput{NextToken: resp.NextToken}
+	err := glueCli.ListDatabasesPagesWithContext(ctx, 0, func(page []*glue.Database) bool {
+		databases = append(databases, page...)
 
-		resp, err = glueCli.cli.GetDatabases(input)
+		return true
+	})
+
+	return databases, err
+}
+
+func (glueCli *GlueClient) ListDatabasesPages(pageSize int64, fn func(databases []*glue.Database) bool) error {
+	return glueCli.ListDatabasesPagesWithContext(context.Background(), pageSize, fn)
+}
+
+func (glueCli *GlueClient) ListDatabasesPagesWithContext(ctx context.Context, pageSize int64, fn func(databases []*glue.Database) bool) error {
+	input := &glue.GetDatabasesInput{MaxResults: pageLimit(pageSize, 1, 100)}
+
+	for {
+		resp, err := glueCli.cli.GetDatabasesWithContext(ctx, input)
 		if err != nil {
-			glueCli.handleError(err)
+			return glueCli.handleError("GetDatabases", err)
+		}
+
+		if !fn(resp.DatabaseList) || resp.NextToken == nil {
+			return nil
 		}
 
-		databases = append(databases, resp.DatabaseList...)
+		input.NextToken = resp.NextToken
 	}
+}
 
-	return databases
+func (glueCli *GlueClient) ListTables(dbName *string) ([]*glue.TableData, error) {
+	return glueCli.ListTablesWithContext(context.Background(), dbName)
 }
 
-func (glueCli *GlueClient) ListTables(dbName *string) []*glue.TableData {
-	input := &glue.GetTablesInput{
-		DatabaseName: dbName,
-	}
+func (glueCli *GlueClient) ListTablesWithContext(ctx context.Context, dbName *string) ([]*glue.TableData, error) {
+	var tables []*glue.TableData
+
+	err := glueCli.ListTablesPagesWithContext(ctx, dbName, 0, func(page []*glue.TableData) bool {
+		tables = append(tables, page...)
+
+		return true
+	})
+
+	return tables, err
+}
+
+func (glueCli *GlueClient) ListTablesPages(dbName *string, pageSize int64, fn func(tables []*glue.TableData) bool) error {
+	return glueCli.ListTablesPagesWithContext(context.Background(), dbName, pageSize, fn)
+}
 
-	resp, err := glueCli.cli.GetTables(input)
+func (glueCli *GlueClient) ListTablesPagesWithContext(ctx context.Context, dbName *string, pageSize int64, fn func(tables []*glue.TableData) bool) error {
+	dbName, catalogID, err := glueCli.nameAndAccountOf(dbName, "glue", "database")
 	if err != nil {
-		glueCli.handleError(err)
+		return glueCli.handleError("GetTables", err)
 	}
 
-	tables := resp.TableList
+	input := &glue.GetTablesInput{
+		CatalogId:    catalogID,
+		DatabaseName: dbName,
+		MaxResults:   pageLimit(pageSize, 1, 100),
+	}
 
-	for resp.NextToken != nil {
-		input = &glue.GetTablesInput{
-			DatabaseName: dbName,
-			NextToken:    resp.NextToken,
+	for {
+		resp, err := glueCli.cli.GetTablesWithContext(ctx, input)
+		if err != nil {
+			return glueCli.handleError("GetTables", err)
 		}
 
-		resp, err = glueCli.cli.GetTables(input)
-		if err != nil {
-			glueCli.handleError(err)
+		if !fn(resp.TableList) || resp.NextToken == nil {
+			return nil
 		}
 
-		tables = append(tables, resp.TableList...)
+		input.NextToken = resp.NextToken
 	}
+}
 
-	return tables
+func (glueCli *GlueClient) ListCrawlers() ([]*glue.Crawler, error) {
+	return glueCli.ListCrawlersWithContext(context.Background())
 }
 
-func (glueCli *GlueClient) ListCrawlers() []*glue.Crawler {
-	input := &glue.GetCrawlersInput{}
+func (glueCli *GlueClient) ListCrawlersWithContext(ctx context.Context) ([]*glue.Crawler, error) {
+	var crawlers []*glue.Crawler
 
-	resp, err := glueCli.cli.GetCrawlers(input)
-	if err != nil {
-		glueCli.handleError(err)
-	}
+	err := glueCli.ListCrawlersPagesWithContext(ctx, 0, func(page []*glue.Crawler) bool {
+		crawlers = append(crawlers, page...)
 
-	crawlers := resp.Crawlers
+		return true
+	})
 
-	for resp.NextToken != nil {
-		input = &glue.GetCrawlersInput{NextToken: resp.NextToken}
+	return crawlers, err
+}
+
+func (glueCli *GlueClient) ListCrawlersPages(pageSize int64, fn func(crawlers []*glue.Crawler) bool) error {
+	return glueCli.ListCrawlersPagesWithContext(context.Background(), pageSize, fn)
+}
 
-		resp, err = glueCli.cli.GetCrawlers(input)
+func (glueCli *GlueClient) ListCrawlersPagesWithContext(ctx context.Context, pageSize int64, fn func(crawlers []*glue.Crawler) bool) error {
+	input := &glue.GetCrawlersInput{MaxResults: pageLimit(pageSize, 1, 1000)}
+
+	for {
+		resp, err := glueCli.cli.GetCrawlersWithContext(ctx, input)
 		if err != nil {
-			glueCli.handleError(err)
+			return glueCli.handleError("GetCrawlers", err)
 		}
 
-		crawlers = append(crawlers, resp.Crawlers...)
+		if !fn(resp.Crawlers) || resp.NextToken == nil {
+			return nil
+		}
+
+		input.NextToken = resp.NextToken
 	}
+}
 
-	return crawlers
+func (glueCli *GlueClient) ListClassifiers() ([]*glue.Classifier, error) {
+	return glueCli.ListClassifiersWithContext(context.Background())
 }
 
-func (glueCli *GlueClient) ListClassifiers() []*glue.Classifier {
-	input := &glue.GetClassifiersInput{}
+func (glueCli *GlueClient) ListClassifiersWithContext(ctx context.Context) ([]*glue.Classifier, error) {
+	var classifiers []*glue.Classifier
 
-	resp, err := glueCli.cli.GetClassifiers(input)
-	if err != nil {
-		glueCli.handleError(err)
-	}
+	err := glueCli.ListClassifiersPagesWithContext(ctx, 0, func(page []*glue.Classifier) bool {
+		classifiers = append(classifiers, page...)
 
-	classifiers := resp.Classifiers
+		return true
+	})
 
-	for resp.NextToken != nil {
-		input = &glue.GetClassifiersInput{NextToken: resp.NextToken}
+	return classifiers, err
+}
+
+func (glueCli *GlueClient) ListClassifiersPages(pageSize int64, fn func(classifiers []*glue.Classifier) bool) error {
+	return glueCli.ListClassifiersPagesWithContext(context.Background(), pageSize, fn)
+}
+
+func (glueCli *GlueClient) ListClassifiersPagesWithContext(ctx context.Context, pageSize int64, fn func(classifiers []*glue.Classifier) bool) error {
+	input := &glue.GetClassifiersInput{MaxResults: pageLimit(pageSize, 1, 1000)}
 
-		resp, err = glueCli.cli.GetClassifiers(input)
+	for {
+		resp, err := glueCli.cli.GetClassifiersWithContext(ctx, input)
 		if err != nil {
-			glueCli.handleError(err)
+			return glueCli.handleError("GetClassifiers", err)
 		}
 
-		classifiers = append(classifiers, resp.Classifiers...)
+		if !fn(resp.Classifiers) || resp.NextToken == nil {
+			return nil
+		}
+
+		input.NextToken = resp.NextToken
 	}
+}
 
-	return classifiers
+func (glueCli *GlueClient) ListTriggers() ([]*glue.Trigger, error) {
+	return glueCli.ListTriggersWithContext(context.Background())
 }
 
-func (glueCli *GlueClient) ListTriggers() []*glue.Trigger {
-	input := &glue.GetTriggersInput{}
+func (glueCli *GlueClient) ListTriggersWithContext(ctx context.Context) ([]*glue.Trigger, error) {
+	var triggers []*glue.Trigger
 
-	resp, err := glueCli.cli.GetTriggers(input)
-	if err != nil {
-		glueCli.handleError(err)
-	}
+	err := glueCli.ListTriggersPagesWithContext(ctx, 0, func(page []*glue.Trigger) bool {
+		triggers = append(triggers, page...)
+
+		return true
+	})
 
-	triggers := resp.Triggers
+	return triggers, err
+}
+
+func (glueCli *GlueClient) ListTriggersPages(pageSize int64, fn func(triggers []*glue.Trigger) bool) error {
+	return glueCli.ListTriggersPagesWithContext(context.Background(), pageSize, fn)
+}
 
-	for resp.NextToken != nil {
-		input = &glue.GetTriggersInput{NextToken: resp.NextToken}
+func (glueCli *GlueClient) ListTriggersPagesWithContext(ctx context.Context, pageSize int64, fn func(triggers []*glue.Trigger) bool) error {
+	input := &glue.GetTriggersInput{MaxResults: pageLimit(pageSize, 1, 200)}
 
-		resp, err = glueCli.cli.GetTriggers(input)
+	for {
+		resp, err := glueCli.cli.GetTriggersWithContext(ctx, input)
 		if err != nil {
-			glueCli.handleError(err)
+			return glueCli.handleError("GetTriggers", err)
 		}
 
-		triggers = append(triggers, resp.Triggers...)
+		if !fn(resp.Triggers) || resp.NextToken == nil {
+			return nil
+		}
+
+		input.NextToken = resp.NextToken
 	}
+}
 
-	return triggers
-}
-
-func (glueCli *GlueClient) handleError(err error) {
-	if aerr, ok := err.(awserr.Error); ok {
-		switch aerr.Code() {
-		case glue.ErrCodeInvalidInputException:
-			fmt.Println(glue.ErrCodeInvalidInputException, aerr.Error())
-		case glue.ErrCodeEntityNotFoundException:
-			fmt.Println(glue.ErrCodeEntityNotFoundException, aerr.Error())
-		case glue.ErrCodeInternalServiceException:
-			fmt.Println(glue.ErrCodeInternalServiceException, aerr.Error())
-		case glue.ErrCodeOperationTimeoutException:
-			fmt.Println(glue.ErrCodeOperationTimeoutException, aerr.Error())
-		case glue.ErrCodeEncryptionException:
-			fmt.Println(glue.ErrCodeEncryptionException, aerr.Error())
-		default:
-			fmt.Println(aerr.Error())
-		}
-	} else {
-		// Print the error, cast err to awserr.Error to get the Code and
-		// Message from an error.
-		fmt.Println(err.Error())
+func (glueCli *GlueClient) GetTags(resourceARN *string) (map[string]*string, error) {
+	return glueCli.GetTagsWithContext(context.Background(), resourceARN)
+}
+
+func (glueCli *GlueClient) GetTagsWithContext(ctx context.Context, resourceARN *string) (map[string]*string, error) {
+	input := &glue.GetTagsInput{
+		ResourceArn: resourceARN,
+	}
+
+	resp, err := glueCli.cli.GetTagsWithContext(ctx, input)
+	if err != nil {
+		return nil, glueCli.handleError("GetTags", err)
 	}
+
+	return resp.Tags, nil
+}
+
+func (glueCli *GlueClient) handleError(operation string, err error) error {
+	return glueCli.logError(newError(glue.ServiceName, operation, err))
 }
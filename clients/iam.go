@@ -1,465 +1,828 @@
 package clients
 
 import (
-	"fmt"
+	"context"
 
-	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/iam/iamiface"
 )
 
+type IAMAPI interface {
+	ListUsers() ([]*iam.User, error)
+	ListUsersWithContext(ctx context.Context) ([]*iam.User, error)
+	ListUsersPages(pageSize int64, fn func(users []*iam.User) bool) error
+	ListUsersPagesWithContext(ctx context.Context, pageSize int64, fn func(users []*iam.User) bool) error
+	GetUserPolicy(userName *string, policyName *string) (*string, error)
+	GetUserPolicyWithContext(ctx context.Context, userName *string, policyName *string) (*string, error)
+	ListUserPolicies(userName *string) ([]*string, error)
+	ListUserPoliciesWithContext(ctx context.Context, userName *string) ([]*string, error)
+	ListUserPoliciesPages(userName *string, pageSize int64, fn func(policyNames []*string) bool) error
+	ListUserPoliciesPagesWithContext(ctx context.Context, userName *string, pageSize int64, fn func(policyNames []*string) bool) error
+	ListAttachedUserPolicies(userName *string) ([]*iam.AttachedPolicy, error)
+	ListAttachedUserPoliciesWithContext(ctx context.Context, userName *string) ([]*iam.AttachedPolicy, error)
+	ListAttachedUserPoliciesPages(userName *string, pageSize int64, fn func(attachedPolicies []*iam.AttachedPolicy) bool) error
+	ListAttachedUserPoliciesPagesWithContext(ctx context.Context, userName *string, pageSize int64, fn func(attachedPolicies []*iam.AttachedPolicy) bool) error
+	ListGroupsForUser(userName *string) ([]*iam.Group, error)
+	ListGroupsForUserWithContext(ctx context.Context, userName *string) ([]*iam.Group, error)
+	ListGroupsForUserPages(userName *string, pageSize int64, fn func(groups []*iam.Group) bool) error
+	ListGroupsForUserPagesWithContext(ctx context.Context, userName *string, pageSize int64, fn func(groups []*iam.Group) bool) error
+	ListGroups() ([]*iam.Group, error)
+	ListGroupsWithContext(ctx context.Context) ([]*iam.Group, error)
+	ListGroupsPages(pageSize int64, fn func(groups []*iam.Group) bool) error
+	ListGroupsPagesWithContext(ctx context.Context, pageSize int64, fn func(groups []*iam.Group) bool) error
+	ListGroupPolicies(groupName *string) ([]*string, error)
+	ListGroupPoliciesWithContext(ctx context.Context, groupName *string) ([]*string, error)
+	ListGroupPoliciesPages(groupName *string, pageSize int64, fn func(policyNames []*string) bool) error
+	ListGroupPoliciesPagesWithContext(ctx context.Context, groupName *string, pageSize int64, fn func(policyNames []*string) bool) error
+	GetGroupPolicy(groupName *string, policyName *string) (*string, error)
+	GetGroupPolicyWithContext(ctx context.Context, groupName *string, policyName *string) (*string, error)
+	ListAttachedGroupPolicies(groupName *string) ([]*iam.AttachedPolicy, error)
+	ListAttachedGroupPoliciesWithContext(ctx context.Context, groupName *string) ([]*iam.AttachedPolicy, error)
+	ListAttachedGroupPoliciesPages(groupName *string, pageSize int64, fn func(attachedPolicies []*iam.AttachedPolicy) bool) error
+	ListAttachedGroupPoliciesPagesWithContext(ctx context.Context, groupName *string, pageSize int64, fn func(attachedPolicies []*iam.AttachedPolicy) bool) error
+	ListRoles() ([]*iam.Role, error)
+	ListRolesWithContext(ctx context.Context) ([]*iam.Role, error)
+	ListRolesPages(pageSize int64, fn func(roles []*iam.Role) bool) error
+	ListRolesPagesWithContext(ctx context.Context, pageSize int64, fn func(roles []*iam.Role) bool) error
+	ListRolePolicies(roleName *string) ([]*string, error)
+	ListRolePoliciesWithContext(ctx context.Context, roleName *string) ([]*string, error)
+	ListRolePoliciesPages(roleName *string, pageSize int64, fn func(policyNames []*string) bool) error
+	ListRolePoliciesPagesWithContext(ctx context.Context, roleName *string, pageSize int64, fn func(policyNames []*string) bool) error
+	ListRoleTags(roleName *string) ([]*iam.Tag, error)
+	ListRoleTagsWithContext(ctx context.Context, roleName *string) ([]*iam.Tag, error)
+	ListRoleTagsPages(roleName *string, pageSize int64, fn func(tags []*iam.Tag) bool) error
+	ListRoleTagsPagesWithContext(ctx context.Context, roleName *string, pageSize int64, fn func(tags []*iam.Tag) bool) error
+	GetRolePolicy(roleName *string, policyName *string) (*string, error)
+	GetRolePolicyWithContext(ctx context.Context, roleName *string, policyName *string) (*string, error)
+	ListAttachedRolePolicies(roleName *string) ([]*iam.AttachedPolicy, error)
+	ListAttachedRolePoliciesWithContext(ctx context.Context, roleName *string) ([]*iam.AttachedPolicy, error)
+	ListAttachedRolePoliciesPages(roleName *string, pageSize int64, fn func(attachedPolicies []*iam.AttachedPolicy) bool) error
+	ListAttachedRolePoliciesPagesWithContext(ctx context.Context, roleName *string, pageSize int64, fn func(attachedPolicies []*iam.AttachedPolicy) bool) error
+	GetRole(name *string) (*iam.Role, error)
+	GetRoleWithContext(ctx context.Context, name *string) (*iam.Role, error)
+	ListPolicies() ([]*iam.Policy, error)
+	ListPoliciesWithContext(ctx context.Context) ([]*iam.Policy, error)
+	ListPoliciesPages(pageSize int64, fn func(policies []*iam.Policy) bool) error
+	ListPoliciesPagesWithContext(ctx context.Context, pageSize int64, fn func(policies []*iam.Policy) bool) error
+	GetPolicyVersion(policyArn *string, verID *string) (*iam.PolicyVersion, error)
+	GetPolicyVersionWithContext(ctx context.Context, policyArn *string, verID *string) (*iam.PolicyVersion, error)
+	GetPolicy(policyArn *string) (*iam.GetPolicyOutput, error)
+	GetPolicyWithContext(ctx context.Context, policyArn *string) (*iam.GetPolicyOutput, error)
+	CreateRole(name *string, path *string, assumeRolePolicyDocument *string) (*iam.Role, error)
+	CreateRoleWithContext(ctx context.Context, name *string, path *string, assumeRolePolicyDocument *string) (*iam.Role, error)
+	DeleteRole(name *string) error
+	DeleteRoleWithContext(ctx context.Context, name *string) error
+	AttachRolePolicy(roleName *string, policyArn *string) error
+	AttachRolePolicyWithContext(ctx context.Context, roleName *string, policyArn *string) error
+	DetachRolePolicy(roleName *string, policyArn *string) error
+	DetachRolePolicyWithContext(ctx context.Context, roleName *string, policyArn *string) error
+}
+
+var _ IAMAPI = (*IAMClient)(nil)
+
 type IAMClient struct {
-	cli *iam.IAM
+	logging
+	*owner
+	cli iamiface.IAMAPI
 }
 
-func NewIAM(sess *session.Session) *IAMClient {
-	client := iam.New(sess)
+func init() {
+	Register("iam", func(sess *session.Session) interface{} { return NewIAM(sess) })
+}
 
-	return &IAMClient{cli: client}
+func NewIAM(sess *session.Session, cfgs ...*aws.Config) *IAMClient {
+	client := iam.New(sess, cfgs...)
+
+	return &IAMClient{logging: newLogging(sess), owner: newOwner(sess, client.Config), cli: client}
 }
 
-func (iamCli *IAMClient) ListUsers() []*iam.User {
-	input := &iam.ListUsersInput{}
+func NewIAMFromAPI(api iamiface.IAMAPI) *IAMClient {
+	return &IAMClient{logging: logging{logger: NopLogger}, owner: &owner{}, cli: api}
+}
 
-	resp, err := iamCli.cli.ListUsers(input)
-	if err != nil {
-		iamCli.handleError(err)
-	}
+func (iamCli *IAMClient) ListUsers() ([]*iam.User, error) {
+	return iamCli.ListUsersWithContext(context.Background())
+}
+
+func (iamCli *IAMClient) ListUsersWithContext(ctx context.Context) ([]*iam.User, error) {
+	var users []*iam.User
+
+	err := iamCli.ListUsersPagesWithContext(ctx, 0, func(page []*iam.User) bool {
+		users = append(users, page...)
 
-	users := resp.Users
+		return true
+	})
 
-	for *resp.IsTruncated {
-		input = &iam.ListUsersInput{Marker: resp.Marker}
+	return users, err
+}
+
+func (iamCli *IAMClient) ListUsersPages(pageSize int64, fn func(users []*iam.User) bool) error {
+	return iamCli.ListUsersPagesWithContext(context.Background(), pageSize, fn)
+}
 
-		resp, err = iamCli.cli.ListUsers(input)
+func (iamCli *IAMClient) ListUsersPagesWithContext(ctx context.Context, pageSize int64, fn func(users []*iam.User) bool) error {
+	input := &iam.ListUsersInput{MaxItems: pageLimit(pageSize, 1, 1000)}
+
+	for {
+		resp, err := iamCli.cli.ListUsersWithContext(ctx, input)
 		if err != nil {
-			iamCli.handleError(err)
+			return iamCli.handleError("ListUsers", err)
+		}
+
+		if !fn(resp.Users) || !aws.BoolValue(resp.IsTruncated) {
+			return nil
 		}
 
-		users = append(users, resp.Users...)
+		input.Marker = resp.Marker
 	}
+}
 
-	return users
+func (iamCli *IAMClient) GetUserPolicy(userName *string, policyName *string) (*string, error) {
+	return iamCli.GetUserPolicyWithContext(context.Background(), userName, policyName)
 }
 
-func (iamCli *IAMClient) GetUserPolicy(userName *string, policyName *string) *string {
+func (iamCli *IAMClient) GetUserPolicyWithContext(ctx context.Context, userName *string, policyName *string) (*string, error) {
+	userName, err := iamCli.nameOf(ctx, userName, "iam", "user")
+	if err != nil {
+		return nil, iamCli.handleError("GetUserPolicy", err)
+	}
+
 	input := &iam.GetUserPolicyInput{
 		UserName:   userName,
 		PolicyName: policyName,
 	}
 
-	resp, err := iamCli.cli.GetUserPolicy(input)
+	resp, err := iamCli.cli.GetUserPolicyWithContext(ctx, input)
 	if err != nil {
-		iamCli.handleError(err)
+		return nil, iamCli.handleError("GetUserPolicy", err)
 	}
 
-	return resp.PolicyDocument
+	return resp.PolicyDocument, nil
 }
 
-func (iamCli *IAMClient) ListUserPolicies(userName *string) []*string {
-	input := &iam.ListUserPoliciesInput{UserName: userName}
+func (iamCli *IAMClient) ListUserPolicies(userName *string) ([]*string, error) {
+	return iamCli.ListUserPoliciesWithContext(context.Background(), userName)
+}
+
+func (iamCli *IAMClient) ListUserPoliciesWithContext(ctx context.Context, userName *string) ([]*string, error) {
+	var policyNames []*string
+
+	err := iamCli.ListUserPoliciesPagesWithContext(ctx, userName, 0, func(page []*string) bool {
+		policyNames = append(policyNames, page...)
+
+		return true
+	})
+
+	return policyNames, err
+}
 
-	resp, err := iamCli.cli.ListUserPolicies(input)
+func (iamCli *IAMClient) ListUserPoliciesPages(userName *string, pageSize int64, fn func(policyNames []*string) bool) error {
+	return iamCli.ListUserPoliciesPagesWithContext(context.Background(), userName, pageSize, fn)
+}
+
+func (iamCli *IAMClient) ListUserPoliciesPagesWithContext(ctx context.Context, userName *string, pageSize int64, fn func(policyNames []*string) bool) error {
+	userName, err := iamCli.nameOf(ctx, userName, "iam", "user")
 	if err != nil {
-		iamCli.handleError(err)
+		return iamCli.handleError("ListUserPolicies", err)
 	}
 
-	policyNames := resp.PolicyNames
+	input := &iam.ListUserPoliciesInput{
+		UserName: userName,
+		MaxItems: pageLimit(pageSize, 1, 1000),
+	}
 
-	for *resp.IsTruncated {
-		input = &iam.ListUserPoliciesInput{
-			Marker:   resp.Marker,
-			UserName: userName,
+	for {
+		resp, err := iamCli.cli.ListUserPoliciesWithContext(ctx, input)
+		if err != nil {
+			return iamCli.handleError("ListUserPolicies", err)
 		}
 
-		resp, err = iamCli.cli.ListUserPolicies(input)
-		if err != nil {
-			iamCli.handleError(err)
+		if !fn(resp.PolicyNames) || !aws.BoolValue(resp.IsTruncated) {
+			return nil
 		}
 
-		policyNames = append(policyNames, resp.PolicyNames...)
+		input.Marker = resp.Marker
 	}
+}
 
-	return policyNames
+func (iamCli *IAMClient) ListAttachedUserPolicies(userName *string) ([]*iam.AttachedPolicy, error) {
+	return iamCli.ListAttachedUserPoliciesWithContext(context.Background(), userName)
 }
 
-func (iamCli *IAMClient) ListAttachedUserPolicies(userName *string) []*iam.AttachedPolicy {
-	input := &iam.ListAttachedUserPoliciesInput{
-		UserName: userName,
-	}
+func (iamCli *IAMClient) ListAttachedUserPoliciesWithContext(ctx context.Context, userName *string) ([]*iam.AttachedPolicy, error) {
+	var attachedPolicies []*iam.AttachedPolicy
+
+	err := iamCli.ListAttachedUserPoliciesPagesWithContext(ctx, userName, 0, func(page []*iam.AttachedPolicy) bool {
+		attachedPolicies = append(attachedPolicies, page...)
+
+		return true
+	})
+
+	return attachedPolicies, err
+}
+
+func (iamCli *IAMClient) ListAttachedUserPoliciesPages(userName *string, pageSize int64, fn func(attachedPolicies []*iam.AttachedPolicy) bool) error {
+	return iamCli.ListAttachedUserPoliciesPagesWithContext(context.Background(), userName, pageSize, fn)
+}
 
-	resp, err := iamCli.cli.ListAttachedUserPolicies(input)
+func (iamCli *IAMClient) ListAttachedUserPoliciesPagesWithContext(ctx context.Context, userName *string, pageSize int64, fn func(attachedPolicies []*iam.AttachedPolicy) bool) error {
+	userName, err := iamCli.nameOf(ctx, userName, "iam", "user")
 	if err != nil {
-		iamCli.handleError(err)
+		return iamCli.handleError("ListAttachedUserPolicies", err)
 	}
 
-	attachedPolicies := resp.AttachedPolicies
-
-	for *resp.IsTruncated {
-		input = &iam.ListAttachedUserPoliciesInput{Marker: resp.Marker}
+	input := &iam.ListAttachedUserPoliciesInput{
+		UserName: userName,
+		MaxItems: pageLimit(pageSize, 1, 1000),
+	}
 
-		resp, err = iamCli.cli.ListAttachedUserPolicies(input)
+	for {
+		resp, err := iamCli.cli.ListAttachedUserPoliciesWithContext(ctx, input)
 		if err != nil {
-			iamCli.handleError(err)
+			return iamCli.handleError("ListAttachedUserPolicies", err)
 		}
 
-		attachedPolicies = append(attachedPolicies, resp.AttachedPolicies...)
+		if !fn(resp.AttachedPolicies) || !aws.BoolValue(resp.IsTruncated) {
+			return nil
+		}
+
+		input.Marker = resp.Marker
 	}
+}
 
-	return attachedPolicies
+func (iamCli *IAMClient) ListGroupsForUser(userName *string) ([]*iam.Group, error) {
+	return iamCli.ListGroupsForUserWithContext(context.Background(), userName)
 }
 
-func (iamCli *IAMClient) ListGroupsForUser(userName *string) []*iam.Group {
+func (iamCli *IAMClient) ListGroupsForUserWithContext(ctx context.Context, userName *string) ([]*iam.Group, error) {
+	var groups []*iam.Group
+
+	err := iamCli.ListGroupsForUserPagesWithContext(ctx, userName, 0, func(page []*iam.Group) bool {
+		groups = append(groups, page...)
+
+		return true
+	})
+
+	return groups, err
+}
+
+func (iamCli *IAMClient) ListGroupsForUserPages(userName *string, pageSize int64, fn func(groups []*iam.Group) bool) error {
+	return iamCli.ListGroupsForUserPagesWithContext(context.Background(), userName, pageSize, fn)
+}
+
+func (iamCli *IAMClient) ListGroupsForUserPagesWithContext(ctx context.Context, userName *string, pageSize int64, fn func(groups []*iam.Group) bool) error {
+	userName, err := iamCli.nameOf(ctx, userName, "iam", "user")
+	if err != nil {
+		return iamCli.handleError("ListGroupsForUser", err)
+	}
+
 	input := &iam.ListGroupsForUserInput{
 		UserName: userName,
+		MaxItems: pageLimit(pageSize, 1, 1000),
 	}
 
-	resp, err := iamCli.cli.ListGroupsForUser(input)
-	if err != nil {
-		iamCli.handleError(err)
+	for {
+		resp, err := iamCli.cli.ListGroupsForUserWithContext(ctx, input)
+		if err != nil {
+			return iamCli.handleError("ListGroupsForUser", err)
+		}
+
+		if !fn(resp.Groups) || !aws.BoolValue(resp.IsTruncated) {
+			return nil
+		}
+
+		input.Marker = resp.Marker
 	}
+}
 
-	return resp.Groups
+func (iamCli *IAMClient) ListGroups() ([]*iam.Group, error) {
+	return iamCli.ListGroupsWithContext(context.Background())
 }
 
-func (iamCli *IAMClient) ListGroups() []*iam.Group {
-	input := &iam.ListGroupsInput{}
+func (iamCli *IAMClient) ListGroupsWithContext(ctx context.Context) ([]*iam.Group, error) {
+	var groups []*iam.Group
 
-	resp, err := iamCli.cli.ListGroups(input)
-	if err != nil {
-		iamCli.handleError(err)
-	}
+	err := iamCli.ListGroupsPagesWithContext(ctx, 0, func(page []*iam.Group) bool {
+		groups = append(groups, page...)
+
+		return true
+	})
 
-	groups := resp.Groups
+	return groups, err
+}
+
+func (iamCli *IAMClient) ListGroupsPages(pageSize int64, fn func(groups []*iam.Group) bool) error {
+	return iamCli.ListGroupsPagesWithContext(context.Background(), pageSize, fn)
+}
 
-	for *resp.IsTruncated {
-		input = &iam.ListGroupsInput{Marker: resp.Marker}
+func (iamCli *IAMClient) ListGroupsPagesWithContext(ctx context.Context, pageSize int64, fn func(groups []*iam.Group) bool) error {
+	input := &iam.ListGroupsInput{MaxItems: pageLimit(pageSize, 1, 1000)}
 
-		resp, err = iamCli.cli.ListGroups(input)
+	for {
+		resp, err := iamCli.cli.ListGroupsWithContext(ctx, input)
 		if err != nil {
-			iamCli.handleError(err)
+			return iamCli.handleError("ListGroups", err)
 		}
 
-		groups = append(groups, resp.Groups...)
+		if !fn(resp.Groups) || !aws.BoolValue(resp.IsTruncated) {
+			return nil
+		}
+
+		input.Marker = resp.Marker
 	}
+}
+
+func (iamCli *IAMClient) ListGroupPolicies(groupName *string) ([]*string, error) {
+	return iamCli.ListGroupPoliciesWithContext(context.Background(), groupName)
+}
+
+func (iamCli *IAMClient) ListGroupPoliciesWithContext(ctx context.Context, groupName *string) ([]*string, error) {
+	var policyNames []*string
 
-	return groups
+	err := iamCli.ListGroupPoliciesPagesWithContext(ctx, groupName, 0, func(page []*string) bool {
+		policyNames = append(policyNames, page...)
+
+		return true
+	})
+
+	return policyNames, err
 }
 
-func (iamCli *IAMClient) ListGroupPolicies(groupName *string) []*string {
-	input := &iam.ListGroupPoliciesInput{GroupName: groupName}
+func (iamCli *IAMClient) ListGroupPoliciesPages(groupName *string, pageSize int64, fn func(policyNames []*string) bool) error {
+	return iamCli.ListGroupPoliciesPagesWithContext(context.Background(), groupName, pageSize, fn)
+}
 
-	resp, err := iamCli.cli.ListGroupPolicies(input)
+func (iamCli *IAMClient) ListGroupPoliciesPagesWithContext(ctx context.Context, groupName *string, pageSize int64, fn func(policyNames []*string) bool) error {
+	groupName, err := iamCli.nameOf(ctx, groupName, "iam", "group")
 	if err != nil {
-		iamCli.handleError(err)
+		return iamCli.handleError("ListGroupPolicies", err)
 	}
 
-	policyNames := resp.PolicyNames
+	input := &iam.ListGroupPoliciesInput{
+		GroupName: groupName,
+		MaxItems:  pageLimit(pageSize, 1, 1000),
+	}
 
-	for *resp.IsTruncated {
-		input = &iam.ListGroupPoliciesInput{
-			Marker:    resp.Marker,
-			GroupName: groupName,
+	for {
+		resp, err := iamCli.cli.ListGroupPoliciesWithContext(ctx, input)
+		if err != nil {
+			return iamCli.handleError("ListGroupPolicies", err)
 		}
 
-		resp, err = iamCli.cli.ListGroupPolicies(input)
-		if err != nil {
-			iamCli.handleError(err)
+		if !fn(resp.PolicyNames) || !aws.BoolValue(resp.IsTruncated) {
+			return nil
 		}
 
-		policyNames = append(policyNames, resp.PolicyNames...)
+		input.Marker = resp.Marker
 	}
+}
 
-	return policyNames
+func (iamCli *IAMClient) GetGroupPolicy(groupName *string, policyName *string) (*string, error) {
+	return iamCli.GetGroupPolicyWithContext(context.Background(), groupName, policyName)
 }
 
-func (iamCli *IAMClient) GetGroupPolicy(groupName *string, policyName *string) *string {
+func (iamCli *IAMClient) GetGroupPolicyWithContext(ctx context.Context, groupName *string, policyName *string) (*string, error) {
+	groupName, err := iamCli.nameOf(ctx, groupName, "iam", "group")
+	if err != nil {
+		return nil, iamCli.handleError("GetGroupPolicy", err)
+	}
+
 	input := &iam.GetGroupPolicyInput{
 		GroupName:  groupName,
 		PolicyName: policyName,
 	}
 
-	resp, err := iamCli.cli.GetGroupPolicy(input)
+	resp, err := iamCli.cli.GetGroupPolicyWithContext(ctx, input)
 	if err != nil {
-		iamCli.handleError(err)
+		return nil, iamCli.handleError("GetGroupPolicy", err)
 	}
 
-	return resp.PolicyDocument
+	return resp.PolicyDocument, nil
+}
+
+func (iamCli *IAMClient) ListAttachedGroupPolicies(groupName *string) ([]*iam.AttachedPolicy, error) {
+	return iamCli.ListAttachedGroupPoliciesWithContext(context.Background(), groupName)
 }
 
-func (iamCli *IAMClient) ListAttachedGroupPolicies(groupName *string) []*iam.AttachedPolicy {
+func (iamCli *IAMClient) ListAttachedGroupPoliciesWithContext(ctx context.Context, groupName *string) ([]*iam.AttachedPolicy, error) {
+	var attachedPolicies []*iam.AttachedPolicy
+
+	err := iamCli.ListAttachedGroupPoliciesPagesWithContext(ctx, groupName, 0, func(page []*iam.AttachedPolicy) bool {
+		attachedPolicies = append(attachedPolicies, page...)
+
+		return true
+	})
+
+	return attachedPolicies, err
+}
+
+func (iamCli *IAMClient) ListAttachedGroupPoliciesPages(groupName *string, pageSize int64, fn func(attachedPolicies []*iam.AttachedPolicy) bool) error {
+	return iamCli.ListAttachedGroupPoliciesPagesWithContext(context.Background(), groupName, pageSize, fn)
+}
+
+func (iamCli *IAMClient) ListAttachedGroupPoliciesPagesWithContext(ctx context.Context, groupName *string, pageSize int64, fn func(attachedPolicies []*iam.AttachedPolicy) bool) error {
+	groupName, err := iamCli.nameOf(ctx, groupName, "iam", "group")
+	if err != nil {
+		return iamCli.handleError("ListAttachedGroupPolicies", err)
+	}
+
 	input := &iam.ListAttachedGroupPoliciesInput{
 		GroupName: groupName,
+		MaxItems:  pageLimit(pageSize, 1, 1000),
 	}
 
-	resp, err := iamCli.cli.ListAttachedGroupPolicies(input)
-	if err != nil {
-		iamCli.handleError(err)
+	for {
+		resp, err := iamCli.cli.ListAttachedGroupPoliciesWithContext(ctx, input)
+		if err != nil {
+			return iamCli.handleError("ListAttachedGroupPolicies", err)
+		}
+
+		if !fn(resp.AttachedPolicies) || !aws.BoolValue(resp.IsTruncated) {
+			return nil
+		}
+
+		input.Marker = resp.Marker
 	}
+}
+
+func (iamCli *IAMClient) ListRoles() ([]*iam.Role, error) {
+	return iamCli.ListRolesWithContext(context.Background())
+}
+
+func (iamCli *IAMClient) ListRolesWithContext(ctx context.Context) ([]*iam.Role, error) {
+	var roles []*iam.Role
 
-	attachedPolicies := resp.AttachedPolicies
+	err := iamCli.ListRolesPagesWithContext(ctx, 0, func(page []*iam.Role) bool {
+		roles = append(roles, page...)
 
-	for *resp.IsTruncated {
-		input = &iam.ListAttachedGroupPoliciesInput{Marker: resp.Marker}
+		return true
+	})
 
-		resp, err = iamCli.cli.ListAttachedGroupPolicies(input)
+	return roles, err
+}
+
+func (iamCli *IAMClient) ListRolesPages(pageSize int64, fn func(roles []*iam.Role) bool) error {
+	return iamCli.ListRolesPagesWithContext(context.Background(), pageSize, fn)
+}
+
+func (iamCli *IAMClient) ListRolesPagesWithContext(ctx context.Context, pageSize int64, fn func(roles []*iam.Role) bool) error {
+	input := &iam.ListRolesInput{MaxItems: pageLimit(pageSize, 1, 1000)}
+
+	for {
+		resp, err := iamCli.cli.ListRolesWithContext(ctx, input)
 		if err != nil {
-			iamCli.handleError(err)
+			return iamCli.handleError("ListRoles", err)
+		}
+
+		if !fn(resp.Roles) || !aws.BoolValue(resp.IsTruncated) {
+			return nil
 		}
 
-		attachedPolicies = append(attachedPolicies, resp.AttachedPolicies...)
+		input.Marker = resp.Marker
 	}
+}
 
-	return attachedPolicies
+func (iamCli *IAMClient) ListRolePolicies(roleName *string) ([]*string, error) {
+	return iamCli.ListRolePoliciesWithContext(context.Background(), roleName)
 }
 
-func (iamCli *IAMClient) ListRoles() []*iam.Role {
-	input := &iam.ListRolesInput{}
+func (iamCli *IAMClient) ListRolePoliciesWithContext(ctx context.Context, roleName *string) ([]*string, error) {
+	var policyNames []*string
+
+	err := iamCli.ListRolePoliciesPagesWithContext(ctx, roleName, 0, func(page []*string) bool {
+		policyNames = append(policyNames, page...)
+
+		return true
+	})
+
+	return policyNames, err
+}
+
+func (iamCli *IAMClient) ListRolePoliciesPages(roleName *string, pageSize int64, fn func(policyNames []*string) bool) error {
+	return iamCli.ListRolePoliciesPagesWithContext(context.Background(), roleName, pageSize, fn)
+}
 
-	resp, err := iamCli.cli.ListRoles(input)
+func (iamCli *IAMClient) ListRolePoliciesPagesWithContext(ctx context.Context, roleName *string, pageSize int64, fn func(policyNames []*string) bool) error {
+	roleName, err := iamCli.nameOf(ctx, roleName, "iam", "role")
 	if err != nil {
-		iamCli.handleError(err)
+		return iamCli.handleError("ListRolePolicies", err)
 	}
 
-	roles := resp.Roles
-
-	for *resp.IsTruncated {
-		input = &iam.ListRolesInput{Marker: resp.Marker}
+	input := &iam.ListRolePoliciesInput{
+		RoleName: roleName,
+		MaxItems: pageLimit(pageSize, 1, 1000),
+	}
 
-		resp, err = iamCli.cli.ListRoles(input)
+	for {
+		resp, err := iamCli.cli.ListRolePoliciesWithContext(ctx, input)
 		if err != nil {
-			iamCli.handleError(err)
+			return iamCli.handleError("ListRolePolicies", err)
+		}
+
+		if !fn(resp.PolicyNames) || !aws.BoolValue(resp.IsTruncated) {
+			return nil
 		}
 
-		roles = append(roles, resp.Roles...)
+		input.Marker = resp.Marker
 	}
+}
 
-	return roles
+func (iamCli *IAMClient) ListRoleTags(roleName *string) ([]*iam.Tag, error) {
+	return iamCli.ListRoleTagsWithContext(context.Background(), roleName)
 }
 
-func (iamCli *IAMClient) ListRolePolicies(roleName *string) []*string {
-	input := &iam.ListRolePoliciesInput{
-		RoleName: roleName,
-	}
+func (iamCli *IAMClient) ListRoleTagsWithContext(ctx context.Context, roleName *string) ([]*iam.Tag, error) {
+	var tags []*iam.Tag
 
-	resp, err := iamCli.cli.ListRolePolicies(input)
+	err := iamCli.ListRoleTagsPagesWithContext(ctx, roleName, 0, func(page []*iam.Tag) bool {
+		tags = append(tags, page...)
+
+		return true
+	})
+
+	return tags, err
+}
+
+func (iamCli *IAMClient) ListRoleTagsPages(roleName *string, pageSize int64, fn func(tags []*iam.Tag) bool) error {
+	return iamCli.ListRoleTagsPagesWithContext(context.Background(), roleName, pageSize, fn)
+}
+
+func (iamCli *IAMClient) ListRoleTagsPagesWithContext(ctx context.Context, roleName *string, pageSize int64, fn func(tags []*iam.Tag) bool) error {
+	roleName, err := iamCli.nameOf(ctx, roleName, "iam", "role")
 	if err != nil {
-		iamCli.handleError(err)
+		return iamCli.handleError("ListRoleTags", err)
 	}
 
-	policyNames := resp.PolicyNames
-
-	for *resp.IsTruncated {
-		input = &iam.ListRolePoliciesInput{Marker: resp.Marker}
+	input := &iam.ListRoleTagsInput{
+		RoleName: roleName,
+		MaxItems: pageLimit(pageSize, 1, 1000),
+	}
 
-		resp, err = iamCli.cli.ListRolePolicies(input)
+	for {
+		resp, err := iamCli.cli.ListRoleTagsWithContext(ctx, input)
 		if err != nil {
-			iamCli.handleError(err)
+			return iamCli.handleError("ListRoleTags", err)
 		}
 
-		policyNames = append(policyNames, resp.PolicyNames...)
+		if !fn(resp.Tags) || !aws.BoolValue(resp.IsTruncated) {
+			return nil
+		}
+
+		input.Marker = resp.Marker
 	}
+}
 
-	return policyNames
+func (iamCli *IAMClient) GetRolePolicy(roleName *string, policyName *string) (*string, error) {
+	return iamCli.GetRolePolicyWithContext(context.Background(), roleName, policyName)
 }
 
-func (iamCli *IAMClient) GetRolePolicy(roleName *string, policyName *string) *string {
+func (iamCli *IAMClient) GetRolePolicyWithContext(ctx context.Context, roleName *string, policyName *string) (*string, error) {
+	roleName, err := iamCli.nameOf(ctx, roleName, "iam", "role")
+	if err != nil {
+		return nil, iamCli.handleError("GetRolePolicy", err)
+	}
+
 	input := &iam.GetRolePolicyInput{
 		RoleName:   roleName,
 		PolicyName: policyName,
 	}
 
-	resp, err := iamCli.cli.GetRolePolicy(input)
+	resp, err := iamCli.cli.GetRolePolicyWithContext(ctx, input)
 	if err != nil {
-		iamCli.handleError(err)
+		return nil, iamCli.handleError("GetRolePolicy", err)
 	}
 
-	return resp.PolicyDocument
+	return resp.PolicyDocument, nil
 }
 
-func (iamCli *IAMClient) ListAttachedRolePolicies(roleName *string) []*iam.AttachedPolicy {
-	input := &iam.ListAttachedRolePoliciesInput{
-		RoleName: roleName,
-	}
+func (iamCli *IAMClient) ListAttachedRolePolicies(roleName *string) ([]*iam.AttachedPolicy, error) {
+	return iamCli.ListAttachedRolePoliciesWithContext(context.Background(), roleName)
+}
+
+func (iamCli *IAMClient) ListAttachedRolePoliciesWithContext(ctx context.Context, roleName *string) ([]*iam.AttachedPolicy, error) {
+	var attachedPolicies []*iam.AttachedPolicy
+
+	err := iamCli.ListAttachedRolePoliciesPagesWithContext(ctx, roleName, 0, func(page []*iam.AttachedPolicy) bool {
+		attachedPolicies = append(attachedPolicies, page...)
+
+		return true
+	})
 
-	resp, err := iamCli.cli.ListAttachedRolePolicies(input)
+	return attachedPolicies, err
+}
+
+func (iamCli *IAMClient) ListAttachedRolePoliciesPages(roleName *string, pageSize int64, fn func(attachedPolicies []*iam.AttachedPolicy) bool) error {
+	return iamCli.ListAttachedRolePoliciesPagesWithContext(context.Background(), roleName, pageSize, fn)
+}
+
+func (iamCli *IAMClient) ListAttachedRolePoliciesPagesWithContext(ctx context.Context, roleName *string, pageSize int64, fn func(attachedPolicies []*iam.AttachedPolicy) bool) error {
+	roleName, err := iamCli.nameOf(ctx, roleName, "iam", "role")
 	if err != nil {
-		iamCli.handleError(err)
+		return iamCli.handleError("ListAttachedRolePolicies", err)
 	}
 
-	attachedPolicies := resp.AttachedPolicies
-
-	for *resp.IsTruncated {
-		input = &iam.ListAttachedRolePoliciesInput{Marker: resp.Marker}
+	input := &iam.ListAttachedRolePoliciesInput{
+		RoleName: roleName,
+		MaxItems: pageLimit(pageSize, 1, 1000),
+	}
 
-		resp, err = iamCli.cli.ListAttachedRolePolicies(input)
+	for {
+		resp, err := iamCli.cli.ListAttachedRolePoliciesWithContext(ctx, input)
 		if err != nil {
-			iamCli.handleError(err)
+			return iamCli.handleError("ListAttachedRolePolicies", err)
+		}
+
+		if !fn(resp.AttachedPolicies) || !aws.BoolValue(resp.IsTruncated) {
+			return nil
 		}
 
-		attachedPolicies = append(attachedPolicies, resp.AttachedPolicies...)
+		input.Marker = resp.Marker
 	}
+}
 
-	return attachedPolicies
+func (iamCli *IAMClient) GetRole(name *string) (*iam.Role, error) {
+	return iamCli.GetRoleWithContext(context.Background(), name)
 }
 
-func (iamCli *IAMClient) GetRole(name *string) *iam.Role {
+func (iamCli *IAMClient) GetRoleWithContext(ctx context.Context, name *string) (*iam.Role, error) {
+	name, err := iamCli.nameOf(ctx, name, "iam", "role")
+	if err != nil {
+		return nil, iamCli.handleError("GetRole", err)
+	}
+
 	input := &iam.GetRoleInput{
 		RoleName: name,
 	}
 
-	resp, err := iamCli.cli.GetRole(input)
+	resp, err := iamCli.cli.GetRoleWithContext(ctx, input)
 	if err != nil {
-		iamCli.handleError(err)
-
-		return nil
+		return nil, iamCli.handleError("GetRole", err)
 	}
 
-	return resp.Role
+	return resp.Role, nil
 }
 
-func (iamCli *IAMClient) ListPolicies() []*iam.Policy {
-	input := &iam.ListPoliciesInput{}
+func (iamCli *IAMClient) ListPolicies() ([]*iam.Policy, error) {
+	return iamCli.ListPoliciesWithContext(context.Background())
+}
 
-	resp, err := iamCli.cli.ListPolicies(input)
-	if err != nil {
-		iamCli.handleError(err)
-	}
+func (iamCli *IAMClient) ListPoliciesWithContext(ctx context.Context) ([]*iam.Policy, error) {
+	var policies []*iam.Policy
 
-	policies := resp.Policies
+	err := iamCli.ListPoliciesPagesWithContext(ctx, 0, func(page []*iam.Policy) bool {
+		policies = append(policies, page...)
 
-	for *resp.IsTruncated {
-		input = &iam.ListPoliciesInput{Marker: resp.Marker}
+		return true
+	})
 
-		resp, err = iamCli.cli.ListPolicies(input)
+	return policies, err
+}
+
+func (iamCli *IAMClient) ListPoliciesPages(pageSize int64, fn func(policies []*iam.Policy) bool) error {
+	return iamCli.ListPoliciesPagesWithContext(context.Background(), pageSize, fn)
+}
+
+func (iamCli *IAMClient) ListPoliciesPagesWithContext(ctx context.Context, pageSize int64, fn func(policies []*iam.Policy) bool) error {
+	input := &iam.ListPoliciesInput{MaxItems: pageLimit(pageSize, 1, 1000)}
+
+	for {
+		resp, err := iamCli.cli.ListPoliciesWithContext(ctx, input)
 		if err != nil {
-			iamCli.handleError(err)
+			return iamCli.handleError("ListPolicies", err)
+		}
+
+		if !fn(resp.Policies) || !aws.BoolValue(resp.IsTruncated) {
+			return nil
 		}
 
-		policies = append(policies, resp.Policies...)
+		input.Marker = resp.Marker
 	}
+}
 
-	return policies
+func (iamCli *IAMClient) GetPolicyVersion(policyArn *string, verID *string) (*iam.PolicyVersion, error) {
+	return iamCli.GetPolicyVersionWithContext(context.Background(), policyArn, verID)
 }
 
-func (iamCli *IAMClient) GetPolicyVersion(policyArn *string, verID *string) *iam.PolicyVersion {
+func (iamCli *IAMClient) GetPolicyVersionWithContext(ctx context.Context, policyArn *string, verID *string) (*iam.PolicyVersion, error) {
 	input := &iam.GetPolicyVersionInput{
 		PolicyArn: policyArn,
 		VersionId: verID,
 	}
 
-	resp, err := iamCli.cli.GetPolicyVersion(input)
+	resp, err := iamCli.cli.GetPolicyVersionWithContext(ctx, input)
 	if err != nil {
-		iamCli.handleError(err)
+		return nil, iamCli.handleError("GetPolicyVersion", err)
 	}
 
-	return resp.PolicyVersion
+	return resp.PolicyVersion, nil
+}
+
+func (iamCli *IAMClient) GetPolicy(policyArn *string) (*iam.GetPolicyOutput, error) {
+	return iamCli.GetPolicyWithContext(context.Background(), policyArn)
 }
 
-func (iamCli *IAMClient) GetPolicy(policyArn *string) *iam.GetPolicyOutput {
+func (iamCli *IAMClient) GetPolicyWithContext(ctx context.Context, policyArn *string) (*iam.GetPolicyOutput, error) {
 	input := &iam.GetPolicyInput{
 		PolicyArn: policyArn,
 	}
 
-	resp, err := iamCli.cli.GetPolicy(input)
+	resp, err := iamCli.cli.GetPolicyWithContext(ctx, input)
 	if err != nil {
-		iamCli.handleError(err)
+		return nil, iamCli.handleError("GetPolicy", err)
 	}
 
-	return resp
+	return resp, nil
 }
 
 func (iamCli *IAMClient) CreateRole(name *string, path *string, assumeRolePolicyDocument *string) (*iam.Role, error) {
+	return iamCli.CreateRoleWithContext(context.Background(), name, path, assumeRolePolicyDocument)
+}
+
+func (iamCli *IAMClient) CreateRoleWithContext(ctx context.Context, name *string, path *string, assumeRolePolicyDocument *string) (*iam.Role, error) {
 	input := &iam.CreateRoleInput{
 		RoleName:                 name,
 		Path:                     path,
 		AssumeRolePolicyDocument: assumeRolePolicyDocument,
 	}
 
-	resp, err := iamCli.cli.CreateRole(input)
+	resp, err := iamCli.cli.CreateRoleWithContext(ctx, input)
 	if err != nil {
-		iamCli.handleError(err)
-
-		return nil, err
+		return nil, iamCli.handleError("CreateRole", err)
 	}
 
 	return resp.Role, nil
 }
 
 func (iamCli *IAMClient) DeleteRole(name *string) error {
+	return iamCli.DeleteRoleWithContext(context.Background(), name)
+}
+
+func (iamCli *IAMClient) DeleteRoleWithContext(ctx context.Context, name *string) error {
+	name, err := iamCli.nameOf(ctx, name, "iam", "role")
+	if err != nil {
+		return iamCli.handleError("DeleteRole", err)
+	}
+
 	input := &iam.DeleteRoleInput{
 		RoleName: name,
 	}
 
-	_, err := iamCli.cli.DeleteRole(input)
+	_, err = iamCli.cli.DeleteRoleWithContext(ctx, input)
 	if err != nil {
-		iamCli.handleError(err)
-
-		return err
+		return iamCli.handleError("DeleteRole", err)
 	}
 
 	return nil
 }
 
 func (iamCli *IAMClient) AttachRolePolicy(roleName *string, policyArn *string) error {
+	return iamCli.AttachRolePolicyWithContext(context.Background(), roleName, policyArn)
+}
+
+func (iamCli *IAMClient) AttachRolePolicyWithContext(ctx context.Context, roleName *string, policyArn *string) error {
+	roleName, err := iamCli.nameOf(ctx, roleName, "iam", "role")
+	if err != nil {
+		return iamCli.handleError("AttachRolePolicy", err)
+	}
+
 	input := &iam.AttachRolePolicyInput{
 		RoleName:  roleName,
 		PolicyArn: policyArn,
 	}
 
-	_, err := iamCli.cli.AttachRolePolicy(input)
+	_, err = iamCli.cli.AttachRolePolicyWithContext(ctx, input)
 	if err != nil {
-		iamCli.handleError(err)
-
-		return err
+		return iamCli.handleError("AttachRolePolicy", err)
 	}
 
 	return nil
 }
 
 func (iamCli *IAMClient) DetachRolePolicy(roleName *string, policyArn *string) error {
+	return iamCli.DetachRolePolicyWithContext(context.Background(), roleName, policyArn)
+}
+
+func (iamCli *IAMClient) DetachRolePolicyWithContext(ctx context.Context, roleName *string, policyArn *string) error {
+	roleName, err := iamCli.nameOf(ctx, roleName, "iam", "role")
+	if err != nil {
+		return iamCli.handleError("DetachRolePolicy", err)
+	}
+
 	input := &iam.DetachRolePolicyInput{
 		RoleName:  roleName,
 		PolicyArn: policyArn,
 	}
 
-	_, err := iamCli.cli.DetachRolePolicy(input)
+	_, err = iamCli.cli.DetachRolePolicyWithContext(ctx, input)
 	if err != nil {
-		iamCli.handleError(err)
-
-		return err
+		return iamCli.handleError("DetachRolePolicy", err)
 	}
 
 	return nil
 }
 
-func (iamCli *IAMClient) handleError(err error) {
-	if aerr, ok := err.(awserr.Error); ok {
-		switch aerr.Code() {
-		case iam.ErrCodeLimitExceededException:
-			fmt.Println(iam.ErrCodeLimitExceededException, aerr.Error())
-		case iam.ErrCodeInvalidInputException:
-			fmt.Println(iam.ErrCodeInvalidInputException, aerr.Error())
-		case iam.ErrCodeEntityAlreadyExistsException:
-			fmt.Println(iam.ErrCodeEntityAlreadyExistsException, aerr.Error())
-		case iam.ErrCodeDeleteConflictException:
-			fmt.Println(iam.ErrCodeDeleteConflictException, aerr.Error())
-		case iam.ErrCodeNoSuchEntityException:
-			fmt.Println(iam.ErrCodeNoSuchEntityException, aerr.Error())
-		case iam.ErrCodeMalformedPolicyDocumentException:
-			fmt.Println(iam.ErrCodeMalformedPolicyDocumentException, aerr.Error())
-		case iam.ErrCodeUnmodifiableEntityException:
-			fmt.Println(iam.ErrCodeUnmodifiableEntityException, aerr.Error())
-		case iam.ErrCodeConcurrentModificationException:
-			fmt.Println(iam.ErrCodeConcurrentModificationException, aerr.Error())
-		case iam.ErrCodePolicyNotAttachableException:
-			fmt.Println(iam.ErrCodePolicyNotAttachableException, aerr.Error())
-		case iam.ErrCodeServiceFailureException:
-			fmt.Println(iam.ErrCodeServiceFailureException, aerr.Error())
-		default:
-			fmt.Println(aerr.Error())
-		}
-	} else {
-		fmt.Println(err.Error())
-	}
+func (iamCli *IAMClient) handleError(operation string, err error) error {
+	return iamCli.logError(newError(iam.ServiceName, operation, err))
 }
@@ -0,0 +1,26 @@
+package clients_test
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/mwlng/aws-go-clients/clients"
+)
+
+func TestIAMListPolicies(t *testing.T) {
+	policies, err := clients.NewIAM(recordedSession(t, "list_policies")).ListPolicies()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"AdministratorAccess", "ReadOnlyAccess", "deploy-artifacts"}
+	if len(policies) != len(want) {
+		t.Fatalf("ListPolicies returned %d policies, want %d", len(policies), len(want))
+	}
+
+	for i, policy := range policies {
+		if name := aws.StringValue(policy.PolicyName); name != want[i] {
+			t.Errorf("policy %d is %q, want %q", i, name, want[i])
+		}
+	}
+}
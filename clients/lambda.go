@@ -1,56 +1,119 @@
 package clients
 
 import (
-	"fmt"
+	"context"
 
 	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/aws/aws-sdk-go/service/lambda/lambdaiface"
 )
 
+type LambdaAPI interface {
+	Invoke(functionName string, payload []byte, invocationType string) (*int64, error)
+	InvokeWithContext(ctx context.Context, functionName string, payload []byte, invocationType string) (*int64, error)
+	ListFunctions() ([]*lambda.FunctionConfiguration, error)
+	ListFunctionsWithContext(ctx context.Context) ([]*lambda.FunctionConfiguration, error)
+	ListFunctionsPages(pageSize int64, fn func(functions []*lambda.FunctionConfiguration) bool) error
+	ListFunctionsPagesWithContext(ctx context.Context, pageSize int64, fn func(functions []*lambda.FunctionConfiguration) bool) error
+	ListTags(functionArn *string) (map[string]*string, error)
+	ListTagsWithContext(ctx context.Context, functionArn *string) (map[string]*string, error)
+}
+
+var _ LambdaAPI = (*LambdaClient)(nil)
+
 type LambdaClient struct {
-	cli *lambda.Lambda
+	logging
+	cli lambdaiface.LambdaAPI
+}
+
+func init() {
+	Register("lambda", func(sess *session.Session) interface{} { return NewLambda(sess) })
 }
 
-func NewLambda(sess *session.Session) *LambdaClient {
-	client := lambda.New(sess)
+func NewLambda(sess *session.Session, cfgs ...*aws.Config) *LambdaClient {
+	client := lambda.New(sess, cfgs...)
+
+	return &LambdaClient{logging: newLogging(sess), cli: client}
+}
+
+func NewLambdaFromAPI(api lambdaiface.LambdaAPI) *LambdaClient {
+	return &LambdaClient{logging: logging{logger: NopLogger}, cli: api}
+}
 
-	return &LambdaClient{cli: client}
+func (lambdaCli *LambdaClient) Invoke(functionName string, payload []byte, invocationType string) (*int64, error) {
+	return lambdaCli.InvokeWithContext(context.Background(), functionName, payload, invocationType)
 }
 
-func (lambdaCli *LambdaClient) Invoke(functionName string, payload []byte, invocationType string) *int64 {
+func (lambdaCli *LambdaClient) InvokeWithContext(ctx context.Context, functionName string, payload []byte, invocationType string) (*int64, error) {
 	input := &lambda.InvokeInput{
 		FunctionName:   aws.String(functionName),
 		InvocationType: aws.String(invocationType),
 		Payload:        payload,
 	}
 
-	output, err := lambdaCli.cli.Invoke(input)
+	output, err := lambdaCli.cli.InvokeWithContext(ctx, input)
 	if err != nil {
-		lambdaCli.handleError(err)
+		return nil, lambdaCli.handleError("Invoke", err)
 	}
 
-	return output.StatusCode
-}
-
-func (lambdaCli *LambdaClient) handleError(err error) {
-	if aerr, ok := err.(awserr.Error); ok {
-		switch aerr.Code() {
-		case lambda.ErrCodeInvalidParameterValueException:
-			fmt.Println(lambda.ErrCodeInvalidParameterValueException, aerr.Error())
-		case lambda.ErrCodeResourceConflictException:
-			fmt.Println(lambda.ErrCodeResourceConflictException, aerr.Error())
-		case lambda.ErrCodeResourceNotFoundException:
-			fmt.Println(lambda.ErrCodeResourceNotFoundException, aerr.Error())
-		case lambda.ErrCodeTooManyRequestsException:
-			fmt.Println(lambda.ErrCodeTooManyRequestsException, aerr.Error())
-		case lambda.ErrCodeServiceException:
-			fmt.Println(lambda.ErrCodeServiceException, aerr.Error())
-		default:
-			fmt.Println(aerr.Error())
+	return output.StatusCode, nil
+}
+
+func (lambdaCli *LambdaClient) ListFunctions() ([]*lambda.FunctionConfiguration, error) {
+	return lambdaCli.ListFunctionsWithContext(context.Background())
+}
+
+func (lambdaCli *LambdaClient) ListFunctionsWithContext(ctx context.Context) ([]*lambda.FunctionConfiguration, error) {
+	functions := []*lambda.FunctionConfiguration{}
+
+	err := lambdaCli.ListFunctionsPagesWithContext(ctx, 0, func(page []*lambda.FunctionConfiguration) bool {
+		functions = append(functions, page...)
+
+		return true
+	})
+
+	return functions, err
+}
+
+func (lambdaCli *LambdaClient) ListFunctionsPages(pageSize int64, fn func(functions []*lambda.FunctionConfiguration) bool) error {
+	return lambdaCli.ListFunctionsPagesWithContext(context.Background(), pageSize, fn)
+}
+
+func (lambdaCli *LambdaClient) ListFunctionsPagesWithContext(ctx context.Context, pageSize int64, fn func(functions []*lambda.FunctionConfiguration) bool) error {
+	input := &lambda.ListFunctionsInput{MaxItems: pageLimit(pageSize, 1, 50)}
+
+	for {
+		resp, err := lambdaCli.cli.ListFunctionsWithContext(ctx, input)
+		if err != nil {
+			return lambdaCli.handleError("ListFunctions", err)
+		}
+
+		if !fn(resp.Functions) || resp.NextMarker == nil {
+			return nil
 		}
-	} else {
-		fmt.Println(err.Error())
+
+		input.Marker = resp.NextMarker
 	}
 }
+
+func (lambdaCli *LambdaClient) ListTags(functionArn *string) (map[string]*string, error) {
+	return lambdaCli.ListTagsWithContext(context.Background(), functionArn)
+}
+
+func (lambdaCli *LambdaClient) ListTagsWithContext(ctx context.Context, functionArn *string) (map[string]*string, error) {
+	input := &lambda.ListTagsInput{
+		Resource: functionArn,
+	}
+
+	resp, err := lambdaCli.cli.ListTagsWithContext(ctx, input)
+	if err != nil {
+		return nil, lambdaCli.handleError("ListTags", err)
+	}
+
+	return resp.Tags, nil
+}
+
+func (lambdaCli *LambdaClient) handleError(operation string, err error) error {
+	return lambdaCli.logError(newError(lambda.ServiceName, operation, err))
+}
@@ -0,0 +1,183 @@
+package clients
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+)
+
+// Level is the severity of a log record.
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "debug"
+	case LevelInfo:
+		return "info"
+	case LevelWarn:
+		return "warn"
+	case LevelError:
+		return "error"
+	default:
+		return fmt.Sprintf("level(%d)", int(l))
+	}
+}
+
+// Logger receives the records emitted by the clients. The keyvals are
+// alternating key/value pairs, the same convention log/slog uses.
+type Logger interface {
+	Log(level Level, msg string, keyvals ...interface{})
+}
+
+// LoggerFunc adapts an ordinary function to the Logger interface.
+type LoggerFunc func(level Level, msg string, keyvals ...interface{})
+
+func (f LoggerFunc) Log(level Level, msg string, keyvals ...interface{}) {
+	f(level, msg, keyvals...)
+}
+
+// NopLogger discards every record. It is the default logger of every client.
+var NopLogger Logger = LoggerFunc(func(Level, string, ...interface{}) {})
+
+type stdLogger struct {
+	logger *log.Logger
+}
+
+// NewStdLogger returns a Logger writing logfmt style lines to a standard
+// library logger. A nil logger writes to the standard logger of package log.
+func NewStdLogger(logger *log.Logger) Logger {
+	return &stdLogger{logger: logger}
+}
+
+func (l *stdLogger) Log(level Level, msg string, keyvals ...interface{}) {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "level=%s msg=%q", level, msg)
+
+	for i := 0; i < len(keyvals); i += 2 {
+		var value interface{} = "(MISSING)"
+		if i+1 < len(keyvals) {
+			value = keyvals[i+1]
+		}
+
+		fmt.Fprintf(&b, " %v=", keyvals[i])
+
+		if s := fmt.Sprint(value); strings.ContainsAny(s, " =\"") {
+			fmt.Fprintf(&b, "%q", s)
+		} else {
+			b.WriteString(s)
+		}
+	}
+
+	if l.logger == nil {
+		log.Print(b.String())
+
+		return
+	}
+
+	l.logger.Print(b.String())
+}
+
+// KeyValueLogger is the method set of structured loggers such as
+// *slog.Logger, which satisfies it directly.
+type KeyValueLogger interface {
+	Debug(msg string, args ...interface{})
+	Info(msg string, args ...interface{})
+	Warn(msg string, args ...interface{})
+	Error(msg string, args ...interface{})
+}
+
+type keyValueLogger struct {
+	logger KeyValueLogger
+}
+
+// NewKeyValueLogger returns a Logger forwarding records to a structured
+// key/value logger.
+func NewKeyValueLogger(logger KeyValueLogger) Logger {
+	return &keyValueLogger{logger: logger}
+}
+
+func (l *keyValueLogger) Log(level Level, msg string, keyvals ...interface{}) {
+	switch level {
+	case LevelDebug:
+		l.logger.Debug(msg, keyvals...)
+	case LevelInfo:
+		l.logger.Info(msg, keyvals...)
+	case LevelWarn:
+		l.logger.Warn(msg, keyvals...)
+	default:
+		l.logger.Error(msg, keyvals...)
+	}
+}
+
+var sessionLoggers sync.Map
+
+// SetSessionLogger sets the logger used by every client created from sess
+// afterwards. Clients created before the call keep their logger.
+func SetSessionLogger(sess *session.Session, logger Logger) {
+	if logger == nil {
+		sessionLoggers.Delete(sess)
+
+		return
+	}
+
+	sessionLoggers.Store(sess, logger)
+}
+
+// logging is embedded by every client to hold its logger.
+type logging struct {
+	logger Logger
+}
+
+func newLogging(sess *session.Session) logging {
+	if logger, ok := sessionLoggers.Load(sess); ok {
+		return logging{logger: logger.(Logger)}
+	}
+
+	return logging{logger: NopLogger}
+}
+
+// SetLogger replaces the logger of the client. A nil logger disables logging.
+func (l *logging) SetLogger(logger Logger) {
+	if logger == nil {
+		logger = NopLogger
+	}
+
+	l.logger = logger
+}
+
+// logError records err, as returned by newError, and returns it unchanged.
+func (l *logging) logError(err error) error {
+	e, ok := err.(*Error)
+	if !ok || l.logger == nil {
+		return err
+	}
+
+	keyvals := []interface{}{
+		"service", e.Service,
+		"operation", e.Operation,
+		"code", e.Code,
+		"request_id", e.RequestID,
+	}
+
+	if e.StatusCode != 0 {
+		keyvals = append(keyvals, "status", e.StatusCode)
+	}
+
+	keyvals = append(keyvals, "retryable", e.Retryable, "error", e.Message)
+
+	l.logger.Log(LevelError, "aws request failed", keyvals...)
+
+	return err
+}
@@ -0,0 +1,38 @@
+package clients
+
+import (
+	"strconv"
+
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+// pageLimit turns the page size hint of a Pages method into the limit of the
+// request. The Pages methods call fn with every page of results until fn
+// returns false or the results are exhausted, and return the error of the
+// page that could not be fetched, if any. pageSize zero keeps the default of
+// the service, other values are brought within the range the operation
+// accepts, and operations without a page size ignore it.
+func pageLimit(pageSize, min, max int64) *int64 {
+	if pageSize <= 0 {
+		return nil
+	}
+
+	if pageSize < min {
+		pageSize = min
+	}
+
+	if pageSize > max {
+		pageSize = max
+	}
+
+	return aws.Int64(pageSize)
+}
+
+func pageLimitString(pageSize, min, max int64) *string {
+	limit := pageLimit(pageSize, min, max)
+	if limit == nil {
+		return nil
+	}
+
+	return aws.String(strconv.FormatInt(*limit, 10))
+}
@@ -1,40 +1,123 @@
 package clients
 
 import (
-	"fmt"
+	"context"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/aws/aws-sdk-go/service/rds/rdsiface"
 )
 
+type RDSAPI interface {
+	CreateClusterSnapshot(clusterID, snapshotID string, tags []*rds.Tag) (*rds.DBClusterSnapshot, error)
+	CreateClusterSnapshotWithContext(ctx context.Context, clusterID, snapshotID string, tags []*rds.Tag) (*rds.DBClusterSnapshot, error)
+	CopyClusterSnapshot(region, srcSnapshotID, tgtSnapshotID, kmsKeyID string) (*rds.DBClusterSnapshot, error)
+	CopyClusterSnapshotWithContext(ctx context.Context, region, srcSnapshotID, tgtSnapshotID, kmsKeyID string) (*rds.DBClusterSnapshot, error)
+	DescribeClusterSnapshot(clusterID, snapshotID string) (*rds.DBClusterSnapshot, error)
+	DescribeClusterSnapshotWithContext(ctx context.Context, clusterID, snapshotID string) (*rds.DBClusterSnapshot, error)
+	DeleteClusterSnapshot(snapshotID string) (*rds.DeleteDBClusterSnapshotOutput, error)
+	DeleteClusterSnapshotWithContext(ctx context.Context, snapshotID string) (*rds.DeleteDBClusterSnapshotOutput, error)
+	CreateDBInstance(input *rds.CreateDBInstanceInput) (*rds.DBInstance, error)
+	CreateDBInstanceWithContext(ctx context.Context, input *rds.CreateDBInstanceInput) (*rds.DBInstance, error)
+	DescribeClusterDBInstances(dbClusterID string) ([]*rds.DBInstance, error)
+	DescribeClusterDBInstancesWithContext(ctx context.Context, dbClusterID string) ([]*rds.DBInstance, error)
+	DescribeDBInstance(dbInstanceID string) (*rds.DBInstance, error)
+	DescribeDBInstanceWithContext(ctx context.Context, dbInstanceID string) (*rds.DBInstance, error)
+	DeleteDBInstance(dbInstanceID, finalSnapshotID string, skipFinalSnapshot bool) (*rds.DBInstance, error)
+	DeleteDBInstanceWithContext(ctx context.Context, dbInstanceID, finalSnapshotID string, skipFinalSnapshot bool) (*rds.DBInstance, error)
+	CreateDBSnapshot(instanceID, snapshotID string, tags []*rds.Tag) (*rds.DBSnapshot, error)
+	CreateDBSnapshotWithContext(ctx context.Context, instanceID, snapshotID string, tags []*rds.Tag) (*rds.DBSnapshot, error)
+	CopyDBSnapshot(region, srcSnapshotID, tgtSnapshotID, kmsKeyID string) (*rds.DBSnapshot, error)
+	CopyDBSnapshotWithContext(ctx context.Context, region, srcSnapshotID, tgtSnapshotID, kmsKeyID string) (*rds.DBSnapshot, error)
+	DescribeDBSnapshot(instanceID, snapshotID string) (*rds.DBSnapshot, error)
+	DescribeDBSnapshotWithContext(ctx context.Context, instanceID, snapshotID string) (*rds.DBSnapshot, error)
+	DeleteDBSnapshot(snapshotID string) (*rds.DeleteDBSnapshotOutput, error)
+	DeleteDBSnapshotWithContext(ctx context.Context, snapshotID string) (*rds.DeleteDBSnapshotOutput, error)
+	DescribeDBCluster(dbClusterIdentifier string) (*rds.DBCluster, error)
+	DescribeDBClusterWithContext(ctx context.Context, dbClusterIdentifier string) (*rds.DBCluster, error)
+	ListDBClusters() ([]*rds.DBCluster, error)
+	ListDBClustersWithContext(ctx context.Context) ([]*rds.DBCluster, error)
+	ListDBClustersPages(pageSize int64, fn func(clusters []*rds.DBCluster) bool) error
+	ListDBClustersPagesWithContext(ctx context.Context, pageSize int64, fn func(clusters []*rds.DBCluster) bool) error
+	ListDBInstances() ([]*rds.DBInstance, error)
+	ListDBInstancesWithContext(ctx context.Context) ([]*rds.DBInstance, error)
+	ListDBInstancesPages(pageSize int64, fn func(instances []*rds.DBInstance) bool) error
+	ListDBInstancesPagesWithContext(ctx context.Context, pageSize int64, fn func(instances []*rds.DBInstance) bool) error
+	ListAllDBClusterSnapshots(snapshotType string) ([]*rds.DBClusterSnapshot, error)
+	ListAllDBClusterSnapshotsWithContext(ctx context.Context, snapshotType string) ([]*rds.DBClusterSnapshot, error)
+	ListAllDBClusterSnapshotsPages(snapshotType string, pageSize int64, fn func(snapshots []*rds.DBClusterSnapshot) bool) error
+	ListAllDBClusterSnapshotsPagesWithContext(ctx context.Context, snapshotType string, pageSize int64, fn func(snapshots []*rds.DBClusterSnapshot) bool) error
+	ListDBClusterSnapshots(clusterID, snapshotType string) ([]*rds.DBClusterSnapshot, error)
+	ListDBClusterSnapshotsWithContext(ctx context.Context, clusterID, snapshotType string) ([]*rds.DBClusterSnapshot, error)
+	ListDBClusterSnapshotsPages(clusterID, snapshotType string, pageSize int64, fn func(snapshots []*rds.DBClusterSnapshot) bool) error
+	ListDBClusterSnapshotsPagesWithContext(ctx context.Context, clusterID, snapshotType string, pageSize int64, fn func(snapshots []*rds.DBClusterSnapshot) bool) error
+	DeleteCluster(clusterID, finalSnapshotID string) (*rds.DeleteDBClusterOutput, error)
+	DeleteClusterWithContext(ctx context.Context, clusterID, finalSnapshotID string) (*rds.DeleteDBClusterOutput, error)
+	RestoreDClusterFromSnapshot(input *rds.RestoreDBClusterFromSnapshotInput) (*rds.DBCluster, error)
+	RestoreDClusterFromSnapshotWithContext(ctx context.Context, input *rds.RestoreDBClusterFromSnapshotInput) (*rds.DBCluster, error)
+	WaitForClusterSnapshotAvailable(clusterID, snapshotID string, opts WaitOptions) (*rds.DBClusterSnapshot, error)
+	WaitForClusterSnapshotAvailableWithContext(ctx context.Context, clusterID, snapshotID string, opts WaitOptions) (*rds.DBClusterSnapshot, error)
+	WaitForDBSnapshotAvailable(instanceID, snapshotID string, opts WaitOptions) (*rds.DBSnapshot, error)
+	WaitForDBSnapshotAvailableWithContext(ctx context.Context, instanceID, snapshotID string, opts WaitOptions) (*rds.DBSnapshot, error)
+	WaitForDBInstanceAvailable(dbInstanceID string, opts WaitOptions) (*rds.DBInstance, error)
+	WaitForDBInstanceAvailableWithContext(ctx context.Context, dbInstanceID string, opts WaitOptions) (*rds.DBInstance, error)
+	WaitForDBClusterAvailable(dbClusterIdentifier string, opts WaitOptions) (*rds.DBCluster, error)
+	WaitForDBClusterAvailableWithContext(ctx context.Context, dbClusterIdentifier string, opts WaitOptions) (*rds.DBCluster, error)
+}
+
+var _ RDSAPI = (*RDSClient)(nil)
+
 type RDSClient struct {
-	cli *rds.RDS
+	logging
+	*owner
+	cli rdsiface.RDSAPI
 }
 
-func NewRDS(sess *session.Session) *RDSClient {
-	client := rds.New(sess)
+func init() {
+	Register("rds", func(sess *session.Session) interface{} { return NewRDS(sess) })
+}
+
+func NewRDS(sess *session.Session, cfgs ...*aws.Config) *RDSClient {
+	client := rds.New(sess, cfgs...)
 
-	return &RDSClient{cli: client}
+	return &RDSClient{logging: newLogging(sess), owner: newOwner(sess, client.Config), cli: client}
 }
 
-func (rdsCli *RDSClient) CreateClusterSnapshot(clusterID, snapshotID string, tags []*rds.Tag) *rds.DBClusterSnapshot {
+func NewRDSFromAPI(api rdsiface.RDSAPI) *RDSClient {
+	return &RDSClient{logging: logging{logger: NopLogger}, owner: &owner{}, cli: api}
+}
+
+func (rdsCli *RDSClient) CreateClusterSnapshot(clusterID, snapshotID string, tags []*rds.Tag) (*rds.DBClusterSnapshot, error) {
+	return rdsCli.CreateClusterSnapshotWithContext(context.Background(), clusterID, snapshotID, tags)
+}
+
+func (rdsCli *RDSClient) CreateClusterSnapshotWithContext(ctx context.Context, clusterID, snapshotID string, tags []*rds.Tag) (*rds.DBClusterSnapshot, error) {
+	clusterName, err := rdsCli.nameOf(ctx, aws.String(clusterID), "rds", "cluster")
+	if err != nil {
+		return nil, rdsCli.handleError("CreateDBClusterSnapshot", err)
+	}
+
 	input := &rds.CreateDBClusterSnapshotInput{
-		DBClusterIdentifier:         aws.String(clusterID),
+		DBClusterIdentifier:         clusterName,
 		DBClusterSnapshotIdentifier: aws.String(snapshotID),
 		Tags:                        tags,
 	}
 
-	resp, err := rdsCli.cli.CreateDBClusterSnapshot(input)
+	resp, err := rdsCli.cli.CreateDBClusterSnapshotWithContext(ctx, input)
 	if err != nil {
-		rdsCli.handleError(err)
+		return nil, rdsCli.handleError("CreateDBClusterSnapshot", err)
 	}
 
-	return resp.DBClusterSnapshot
+	return resp.DBClusterSnapshot, nil
 }
 
-func (rdsCli *RDSClient) CopyClusterSnapshot(region, srcSnapshotID, tgtSnapshotID, kmsKeyID string) *rds.DBClusterSnapshot {
+func (rdsCli *RDSClient) CopyClusterSnapshot(region, srcSnapshotID, tgtSnapshotID, kmsKeyID string) (*rds.DBClusterSnapshot, error) {
+	return rdsCli.CopyClusterSnapshotWithContext(context.Background(), region, srcSnapshotID, tgtSnapshotID, kmsKeyID)
+}
+
+func (rdsCli *RDSClient) CopyClusterSnapshotWithContext(ctx context.Context, region, srcSnapshotID, tgtSnapshotID, kmsKeyID string) (*rds.DBClusterSnapshot, error) {
 	var input *rds.CopyDBClusterSnapshotInput
 	if kmsKeyID == "" {
 		input = &rds.CopyDBClusterSnapshotInput{
@@ -53,56 +136,76 @@ func (rdsCli *RDSClient) CopyClusterSnapshot(region, srcSnapshotID, tgtSnapshotI
 		}
 	}
 
-	resp, err := rdsCli.cli.CopyDBClusterSnapshot(input)
+	resp, err := rdsCli.cli.CopyDBClusterSnapshotWithContext(ctx, input)
 	if err != nil {
-		rdsCli.handleError(err)
+		return nil, rdsCli.handleError("CopyDBClusterSnapshot", err)
 	}
 
-	return resp.DBClusterSnapshot
+	return resp.DBClusterSnapshot, nil
+}
+
+func (rdsCli *RDSClient) DescribeClusterSnapshot(clusterID, snapshotID string) (*rds.DBClusterSnapshot, error) {
+	return rdsCli.DescribeClusterSnapshotWithContext(context.Background(), clusterID, snapshotID)
 }
 
-func (rdsCli *RDSClient) DescribeClusterSnapshot(clusterID, snapshotID string) *rds.DBClusterSnapshot {
+func (rdsCli *RDSClient) DescribeClusterSnapshotWithContext(ctx context.Context, clusterID, snapshotID string) (*rds.DBClusterSnapshot, error) {
 	input := &rds.DescribeDBClusterSnapshotsInput{
 		DBClusterIdentifier:         aws.String(clusterID),
 		DBClusterSnapshotIdentifier: aws.String(snapshotID),
 	}
 
-	resp, err := rdsCli.cli.DescribeDBClusterSnapshots(input)
+	resp, err := rdsCli.cli.DescribeDBClusterSnapshotsWithContext(ctx, input)
 	if err != nil {
-		rdsCli.handleError(err)
+		return nil, rdsCli.handleError("DescribeDBClusterSnapshots", err)
 	}
 
 	if len(resp.DBClusterSnapshots) > 0 {
-		return resp.DBClusterSnapshots[0]
+		return resp.DBClusterSnapshots[0], nil
 	}
 
-	return nil
+	return nil, nil
+}
+
+func (rdsCli *RDSClient) DeleteClusterSnapshot(snapshotID string) (*rds.DeleteDBClusterSnapshotOutput, error) {
+	return rdsCli.DeleteClusterSnapshotWithContext(context.Background(), snapshotID)
 }
 
-func (rdsCli *RDSClient) DeleteClusterSnapshot(snapshotID string) *rds.DeleteDBClusterSnapshotOutput {
+func (rdsCli *RDSClient) DeleteClusterSnapshotWithContext(ctx context.Context, snapshotID string) (*rds.DeleteDBClusterSnapshotOutput, error) {
+	snapshotName, err := rdsCli.nameOf(ctx, aws.String(snapshotID), "rds", "cluster-snapshot")
+	if err != nil {
+		return nil, rdsCli.handleError("DeleteDBClusterSnapshot", err)
+	}
+
 	input := &rds.DeleteDBClusterSnapshotInput{
-		DBClusterSnapshotIdentifier: aws.String(snapshotID),
+		DBClusterSnapshotIdentifier: snapshotName,
 	}
 
-	resp, err := rdsCli.cli.DeleteDBClusterSnapshot(input)
+	resp, err := rdsCli.cli.DeleteDBClusterSnapshotWithContext(ctx, input)
 	if err != nil {
-		rdsCli.handleError(err)
+		return nil, rdsCli.handleError("DeleteDBClusterSnapshot", err)
 	}
 
-	return resp
+	return resp, nil
 }
 
-func (rdsCli *RDSClient) CreateDBInstance(input *rds.CreateDBInstanceInput) *rds.DBInstance {
-	resp, err := rdsCli.cli.CreateDBInstance(input)
+func (rdsCli *RDSClient) CreateDBInstance(input *rds.CreateDBInstanceInput) (*rds.DBInstance, error) {
+	return rdsCli.CreateDBInstanceWithContext(context.Background(), input)
+}
+
+func (rdsCli *RDSClient) CreateDBInstanceWithContext(ctx context.Context, input *rds.CreateDBInstanceInput) (*rds.DBInstance, error) {
+	resp, err := rdsCli.cli.CreateDBInstanceWithContext(ctx, input)
 	if err != nil {
-		rdsCli.handleError(err)
-		return nil
+		return nil, rdsCli.handleError("CreateDBInstance", err)
 	}
 
-	return resp.DBInstance
+	return resp.DBInstance, nil
+}
+
+func (rdsCli *RDSClient) DescribeClusterDBInstances(dbClusterID string) ([]*rds.DBInstance, error) {
+	return rdsCli.DescribeClusterDBInstancesWithContext(context.Background(), dbClusterID)
 }
 
-func (rdsCli *RDSClient) DescribeClusterDBInstances(dbClusterID string) []*rds.DBInstance {
+func (rdsCli *RDSClient) DescribeClusterDBInstancesWithContext(ctx context.Context, dbClusterID string) ([]*rds.DBInstance, error) {
 	input := &rds.DescribeDBInstancesInput{
 		Filters: append([]*rds.Filter{},
 			&rds.Filter{
@@ -112,67 +215,95 @@ func (rdsCli *RDSClient) DescribeClusterDBInstances(dbClusterID string) []*rds.D
 		),
 	}
 
-	resp, err := rdsCli.cli.DescribeDBInstances(input)
+	resp, err := rdsCli.cli.DescribeDBInstancesWithContext(ctx, input)
 	if err != nil {
-		rdsCli.handleError(err)
-		return nil
+		return nil, rdsCli.handleError("DescribeDBInstances", err)
 	}
 
-	return resp.DBInstances
+	return resp.DBInstances, nil
+}
+
+func (rdsCli *RDSClient) DescribeDBInstance(dbInstanceID string) (*rds.DBInstance, error) {
+	return rdsCli.DescribeDBInstanceWithContext(context.Background(), dbInstanceID)
 }
 
-func (rdsCli *RDSClient) DescribeDBInstance(dbInstanceID string) *rds.DBInstance {
+func (rdsCli *RDSClient) DescribeDBInstanceWithContext(ctx context.Context, dbInstanceID string) (*rds.DBInstance, error) {
 	input := &rds.DescribeDBInstancesInput{
 		DBInstanceIdentifier: aws.String(dbInstanceID),
 	}
 
-	resp, err := rdsCli.cli.DescribeDBInstances(input)
+	resp, err := rdsCli.cli.DescribeDBInstancesWithContext(ctx, input)
 	if err != nil {
-		rdsCli.handleError(err)
-		return nil
+		return nil, rdsCli.handleError("DescribeDBInstances", err)
+	}
+
+	if len(resp.DBInstances) > 0 {
+		return resp.DBInstances[0], nil
 	}
 
-	return resp.DBInstances[0]
+	return nil, nil
 }
 
-func (rdsCli *RDSClient) DeleteDBInstance(dbInstanceID, finalSnapshotID string, skipFinalSnapshot bool) *rds.DBInstance {
+func (rdsCli *RDSClient) DeleteDBInstance(dbInstanceID, finalSnapshotID string, skipFinalSnapshot bool) (*rds.DBInstance, error) {
+	return rdsCli.DeleteDBInstanceWithContext(context.Background(), dbInstanceID, finalSnapshotID, skipFinalSnapshot)
+}
+
+func (rdsCli *RDSClient) DeleteDBInstanceWithContext(ctx context.Context, dbInstanceID, finalSnapshotID string, skipFinalSnapshot bool) (*rds.DBInstance, error) {
+	instanceName, err := rdsCli.nameOf(ctx, aws.String(dbInstanceID), "rds", "db")
+	if err != nil {
+		return nil, rdsCli.handleError("DeleteDBInstance", err)
+	}
+
 	input := &rds.DeleteDBInstanceInput{
-		DBInstanceIdentifier:      aws.String(dbInstanceID),
+		DBInstanceIdentifier:      instanceName,
 		FinalDBSnapshotIdentifier: aws.String(finalSnapshotID),
 		SkipFinalSnapshot:         aws.Bool(skipFinalSnapshot),
 	}
 	if skipFinalSnapshot {
+
 		input = &rds.DeleteDBInstanceInput{
-			DBInstanceIdentifier: aws.String(dbInstanceID),
+			DBInstanceIdentifier: instanceName,
 			SkipFinalSnapshot:    aws.Bool(skipFinalSnapshot),
 		}
 	}
 
-	resp, err := rdsCli.cli.DeleteDBInstance(input)
+	resp, err := rdsCli.cli.DeleteDBInstanceWithContext(ctx, input)
 	if err != nil {
-		rdsCli.handleError(err)
-		return nil
+		return nil, rdsCli.handleError("DeleteDBInstance", err)
 	}
 
-	return resp.DBInstance
+	return resp.DBInstance, nil
+}
+
+func (rdsCli *RDSClient) CreateDBSnapshot(instanceID, snapshotID string, tags []*rds.Tag) (*rds.DBSnapshot, error) {
+	return rdsCli.CreateDBSnapshotWithContext(context.Background(), instanceID, snapshotID, tags)
 }
 
-func (rdsCli *RDSClient) CreateDBSnapshot(instanceID, snapshotID string, tags []*rds.Tag) *rds.DBSnapshot {
+func (rdsCli *RDSClient) CreateDBSnapshotWithContext(ctx context.Context, instanceID, snapshotID string, tags []*rds.Tag) (*rds.DBSnapshot, error) {
+	instanceName, err := rdsCli.nameOf(ctx, aws.String(instanceID), "rds", "db")
+	if err != nil {
+		return nil, rdsCli.handleError("CreateDBSnapshot", err)
+	}
+
 	input := &rds.CreateDBSnapshotInput{
-		DBInstanceIdentifier: aws.String(instanceID),
+		DBInstanceIdentifier: instanceName,
 		DBSnapshotIdentifier: aws.String(snapshotID),
 		Tags:                 tags,
 	}
 
-	resp, err := rdsCli.cli.CreateDBSnapshot(input)
+	resp, err := rdsCli.cli.CreateDBSnapshotWithContext(ctx, input)
 	if err != nil {
-		rdsCli.handleError(err)
+		return nil, rdsCli.handleError("CreateDBSnapshot", err)
 	}
 
-	return resp.DBSnapshot
+	return resp.DBSnapshot, nil
 }
 
-func (rdsCli *RDSClient) CopyDBSnapshot(region, srcSnapshotID, tgtSnapshotID, kmsKeyID string) *rds.DBSnapshot {
+func (rdsCli *RDSClient) CopyDBSnapshot(region, srcSnapshotID, tgtSnapshotID, kmsKeyID string) (*rds.DBSnapshot, error) {
+	return rdsCli.CopyDBSnapshotWithContext(context.Background(), region, srcSnapshotID, tgtSnapshotID, kmsKeyID)
+}
+
+func (rdsCli *RDSClient) CopyDBSnapshotWithContext(ctx context.Context, region, srcSnapshotID, tgtSnapshotID, kmsKeyID string) (*rds.DBSnapshot, error) {
 	var input *rds.CopyDBSnapshotInput
 	if kmsKeyID == "" {
 		input = &rds.CopyDBSnapshotInput{
@@ -191,286 +322,402 @@ func (rdsCli *RDSClient) CopyDBSnapshot(region, srcSnapshotID, tgtSnapshotID, km
 		}
 	}
 
-	resp, err := rdsCli.cli.CopyDBSnapshot(input)
-
+	resp, err := rdsCli.cli.CopyDBSnapshotWithContext(ctx, input)
 	if err != nil {
-		rdsCli.handleError(err)
+		return nil, rdsCli.handleError("CopyDBSnapshot", err)
 	}
 
-	return resp.DBSnapshot
+	return resp.DBSnapshot, nil
 }
 
-func (rdsCli *RDSClient) DescribeDBSnapshot(instanceID, snapshotID string) *rds.DBSnapshot {
+func (rdsCli *RDSClient) DescribeDBSnapshot(instanceID, snapshotID string) (*rds.DBSnapshot, error) {
+	return rdsCli.DescribeDBSnapshotWithContext(context.Background(), instanceID, snapshotID)
+}
+
+func (rdsCli *RDSClient) DescribeDBSnapshotWithContext(ctx context.Context, instanceID, snapshotID string) (*rds.DBSnapshot, error) {
 	input := &rds.DescribeDBSnapshotsInput{
 		DBInstanceIdentifier: aws.String(instanceID),
 		DBSnapshotIdentifier: aws.String(snapshotID),
 	}
 
-	resp, err := rdsCli.cli.DescribeDBSnapshots(input)
+	resp, err := rdsCli.cli.DescribeDBSnapshotsWithContext(ctx, input)
 	if err != nil {
-		rdsCli.handleError(err)
+		return nil, rdsCli.handleError("DescribeDBSnapshots", err)
 	}
 
 	if len(resp.DBSnapshots) > 0 {
-		return resp.DBSnapshots[0]
+		return resp.DBSnapshots[0], nil
 	}
 
-	return nil
+	return nil, nil
 }
 
-func (rdsCli *RDSClient) DeleteDBSnapshot(snapshotID string) *rds.DeleteDBSnapshotOutput {
+func (rdsCli *RDSClient) DeleteDBSnapshot(snapshotID string) (*rds.DeleteDBSnapshotOutput, error) {
+	return rdsCli.DeleteDBSnapshotWithContext(context.Background(), snapshotID)
+}
+
+func (rdsCli *RDSClient) DeleteDBSnapshotWithContext(ctx context.Context, snapshotID string) (*rds.DeleteDBSnapshotOutput, error) {
+	snapshotName, err := rdsCli.nameOf(ctx, aws.String(snapshotID), "rds", "snapshot")
+	if err != nil {
+		return nil, rdsCli.handleError("DeleteDBSnapshot", err)
+	}
+
 	input := &rds.DeleteDBSnapshotInput{
-		DBSnapshotIdentifier: aws.String(snapshotID),
+		DBSnapshotIdentifier: snapshotName,
 	}
 
-	resp, err := rdsCli.cli.DeleteDBSnapshot(input)
+	resp, err := rdsCli.cli.DeleteDBSnapshotWithContext(ctx, input)
 	if err != nil {
-		rdsCli.handleError(err)
+		return nil, rdsCli.handleError("DeleteDBSnapshot", err)
 	}
 
-	return resp
+	return resp, nil
+}
+
+func (rdsCli *RDSClient) DescribeDBCluster(dbClusterIdentifier string) (*rds.DBCluster, error) {
+	return rdsCli.DescribeDBClusterWithContext(context.Background(), dbClusterIdentifier)
 }
 
-func (rdsCli *RDSClient) DescribeDBCluster(dbClusterIdentifier string) *rds.DBCluster {
+func (rdsCli *RDSClient) DescribeDBClusterWithContext(ctx context.Context, dbClusterIdentifier string) (*rds.DBCluster, error) {
 	input := &rds.DescribeDBClustersInput{
 		DBClusterIdentifier: &dbClusterIdentifier,
 	}
 
-	resp, err := rdsCli.cli.DescribeDBClusters(input)
+	resp, err := rdsCli.cli.DescribeDBClustersWithContext(ctx, input)
 	if err != nil {
-		rdsCli.handleError(err)
-		return nil
+		return nil, rdsCli.handleError("DescribeDBClusters", err)
 	}
 
-	return resp.DBClusters[0]
+	if len(resp.DBClusters) > 0 {
+		return resp.DBClusters[0], nil
+	}
+
+	return nil, nil
 }
 
-func (rdsCli *RDSClient) ListDBClusters() []*rds.DBCluster {
-	input := &rds.DescribeDBClustersInput{}
+func (rdsCli *RDSClient) ListDBClusters() ([]*rds.DBCluster, error) {
+	return rdsCli.ListDBClustersWithContext(context.Background())
+}
 
-	resp, err := rdsCli.cli.DescribeDBClusters(input)
-	if err != nil {
-		rdsCli.handleError(err)
-	}
+func (rdsCli *RDSClient) ListDBClustersWithContext(ctx context.Context) ([]*rds.DBCluster, error) {
+	var clusters []*rds.DBCluster
 
-	clusters := resp.DBClusters
+	err := rdsCli.ListDBClustersPagesWithContext(ctx, 0, func(page []*rds.DBCluster) bool {
+		clusters = append(clusters, page...)
 
-	for resp.Marker != nil {
-		input = &rds.DescribeDBClustersInput{
-			Marker: resp.Marker,
-		}
+		return true
+	})
+
+	return clusters, err
+}
+
+func (rdsCli *RDSClient) ListDBClustersPages(pageSize int64, fn func(clusters []*rds.DBCluster) bool) error {
+	return rdsCli.ListDBClustersPagesWithContext(context.Background(), pageSize, fn)
+}
 
-		resp, err = rdsCli.cli.DescribeDBClusters(input)
+func (rdsCli *RDSClient) ListDBClustersPagesWithContext(ctx context.Context, pageSize int64, fn func(clusters []*rds.DBCluster) bool) error {
+	input := &rds.DescribeDBClustersInput{MaxRecords: pageLimit(pageSize, 20, 100)}
+
+	for {
+		resp, err := rdsCli.cli.DescribeDBClustersWithContext(ctx, input)
 		if err != nil {
-			rdsCli.handleError(err)
+			return rdsCli.handleError("DescribeDBClusters", err)
+		}
+
+		if !fn(resp.DBClusters) || resp.Marker == nil {
+			return nil
 		}
 
-		clusters = append(clusters, resp.DBClusters...)
+		input.Marker = resp.Marker
 	}
+}
 
-	return clusters
+func (rdsCli *RDSClient) ListDBInstances() ([]*rds.DBInstance, error) {
+	return rdsCli.ListDBInstancesWithContext(context.Background())
 }
 
-func (rdsCli *RDSClient) ListDBInstances() []*rds.DBInstance {
-	input := &rds.DescribeDBInstancesInput{}
+func (rdsCli *RDSClient) ListDBInstancesWithContext(ctx context.Context) ([]*rds.DBInstance, error) {
+	var instances []*rds.DBInstance
 
-	resp, err := rdsCli.cli.DescribeDBInstances(input)
-	if err != nil {
-		rdsCli.handleError(err)
-	}
+	err := rdsCli.ListDBInstancesPagesWithContext(ctx, 0, func(page []*rds.DBInstance) bool {
+		instances = append(instances, page...)
+
+		return true
+	})
 
-	instances := resp.DBInstances
+	return instances, err
+}
+
+func (rdsCli *RDSClient) ListDBInstancesPages(pageSize int64, fn func(instances []*rds.DBInstance) bool) error {
+	return rdsCli.ListDBInstancesPagesWithContext(context.Background(), pageSize, fn)
+}
 
-	for resp.Marker != nil {
-		input = &rds.DescribeDBInstancesInput{Marker: resp.Marker}
+func (rdsCli *RDSClient) ListDBInstancesPagesWithContext(ctx context.Context, pageSize int64, fn func(instances []*rds.DBInstance) bool) error {
+	input := &rds.DescribeDBInstancesInput{MaxRecords: pageLimit(pageSize, 20, 100)}
 
-		resp, err = rdsCli.cli.DescribeDBInstances(input)
+	for {
+		resp, err := rdsCli.cli.DescribeDBInstancesWithContext(ctx, input)
 		if err != nil {
-			rdsCli.handleError(err)
+			return rdsCli.handleError("DescribeDBInstances", err)
 		}
 
-		instances = append(instances, resp.DBInstances...)
+		if !fn(resp.DBInstances) || resp.Marker == nil {
+			return nil
+		}
+
+		input.Marker = resp.Marker
 	}
+}
+
+func (rdsCli *RDSClient) ListAllDBClusterSnapshots(snapshotType string) ([]*rds.DBClusterSnapshot, error) {
+	return rdsCli.ListAllDBClusterSnapshotsWithContext(context.Background(), snapshotType)
+}
 
-	return instances
+func (rdsCli *RDSClient) ListAllDBClusterSnapshotsWithContext(ctx context.Context, snapshotType string) ([]*rds.DBClusterSnapshot, error) {
+	var snapshots []*rds.DBClusterSnapshot
+
+	err := rdsCli.ListAllDBClusterSnapshotsPagesWithContext(ctx, snapshotType, 0, func(page []*rds.DBClusterSnapshot) bool {
+		snapshots = append(snapshots, page...)
+
+		return true
+	})
+
+	return snapshots, err
 }
 
-func (rdsCli *RDSClient) ListAllDBClusterSnapshots(snapshotType string) []*rds.DBClusterSnapshot {
+func (rdsCli *RDSClient) ListAllDBClusterSnapshotsPages(snapshotType string, pageSize int64, fn func(snapshots []*rds.DBClusterSnapshot) bool) error {
+	return rdsCli.ListAllDBClusterSnapshotsPagesWithContext(context.Background(), snapshotType, pageSize, fn)
+}
+
+func (rdsCli *RDSClient) ListAllDBClusterSnapshotsPagesWithContext(ctx context.Context, snapshotType string, pageSize int64, fn func(snapshots []*rds.DBClusterSnapshot) bool) error {
 	input := &rds.DescribeDBClusterSnapshotsInput{
 		SnapshotType: aws.String(snapshotType),
+		MaxRecords:   pageLimit(pageSize, 20, 100),
 	}
 
-	resp, err := rdsCli.cli.DescribeDBClusterSnapshots(input)
-	if err != nil {
-		rdsCli.handleError(err)
-	}
-
-	snapshots := resp.DBClusterSnapshots
-
-	for resp.Marker != nil {
-		input = &rds.DescribeDBClusterSnapshotsInput{
-			SnapshotType: aws.String(snapshotType),
-			Marker:       resp.Marker,
+	for {
+		resp, err := rdsCli.cli.DescribeDBClusterSnapshotsWithContext(ctx, input)
+		if err != nil {
+			return rdsCli.handleError("DescribeDBClusterSnapshots", err)
 		}
 
-		resp, err := rdsCli.cli.DescribeDBClusterSnapshots(input)
-		if err != nil {
-			rdsCli.handleError(err)
+		if !fn(resp.DBClusterSnapshots) || resp.Marker == nil {
+			return nil
 		}
 
-		snapshots = append(snapshots, resp.DBClusterSnapshots...)
+		input.Marker = resp.Marker
 	}
+}
+
+func (rdsCli *RDSClient) ListDBClusterSnapshots(clusterID, snapshotType string) ([]*rds.DBClusterSnapshot, error) {
+	return rdsCli.ListDBClusterSnapshotsWithContext(context.Background(), clusterID, snapshotType)
+}
+
+func (rdsCli *RDSClient) ListDBClusterSnapshotsWithContext(ctx context.Context, clusterID, snapshotType string) ([]*rds.DBClusterSnapshot, error) {
+	var snapshots []*rds.DBClusterSnapshot
+
+	err := rdsCli.ListDBClusterSnapshotsPagesWithContext(ctx, clusterID, snapshotType, 0, func(page []*rds.DBClusterSnapshot) bool {
+		snapshots = append(snapshots, page...)
 
-	return snapshots
+		return true
+	})
+
+	return snapshots, err
+}
+
+func (rdsCli *RDSClient) ListDBClusterSnapshotsPages(clusterID, snapshotType string, pageSize int64, fn func(snapshots []*rds.DBClusterSnapshot) bool) error {
+	return rdsCli.ListDBClusterSnapshotsPagesWithContext(context.Background(), clusterID, snapshotType, pageSize, fn)
 }
 
-func (rdsCli *RDSClient) ListDBClusterSnapshots(clusterID, snapshotType string) []*rds.DBClusterSnapshot {
+func (rdsCli *RDSClient) ListDBClusterSnapshotsPagesWithContext(ctx context.Context, clusterID, snapshotType string, pageSize int64, fn func(snapshots []*rds.DBClusterSnapshot) bool) error {
 	input := &rds.DescribeDBClusterSnapshotsInput{
 		DBClusterIdentifier: aws.String(clusterID),
 		SnapshotType:        aws.String(snapshotType),
+		MaxRecords:          pageLimit(pageSize, 20, 100),
 	}
 
-	resp, err := rdsCli.cli.DescribeDBClusterSnapshots(input)
-	if err != nil {
-		rdsCli.handleError(err)
-	}
-
-	snapshots := resp.DBClusterSnapshots
-
-	for resp.Marker != nil {
-		input = &rds.DescribeDBClusterSnapshotsInput{
-			DBClusterIdentifier: aws.String(clusterID),
-			SnapshotType:        aws.String(snapshotType),
-			Marker:              resp.Marker,
+	for {
+		resp, err := rdsCli.cli.DescribeDBClusterSnapshotsWithContext(ctx, input)
+		if err != nil {
+			return rdsCli.handleError("DescribeDBClusterSnapshots", err)
 		}
 
-		resp, err := rdsCli.cli.DescribeDBClusterSnapshots(input)
-		if err != nil {
-			rdsCli.handleError(err)
+		if !fn(resp.DBClusterSnapshots) || resp.Marker == nil {
+			return nil
 		}
 
-		snapshots = append(snapshots, resp.DBClusterSnapshots...)
+		input.Marker = resp.Marker
 	}
+}
 
-	return snapshots
+func (rdsCli *RDSClient) DeleteCluster(clusterID, finalSnapshotID string) (*rds.DeleteDBClusterOutput, error) {
+	return rdsCli.DeleteClusterWithContext(context.Background(), clusterID, finalSnapshotID)
 }
 
-func (rdsCli *RDSClient) DeleteCluster(clusterID, finalSnapshotID string) *rds.DeleteDBClusterOutput {
+func (rdsCli *RDSClient) DeleteClusterWithContext(ctx context.Context, clusterID, finalSnapshotID string) (*rds.DeleteDBClusterOutput, error) {
+	clusterName, err := rdsCli.nameOf(ctx, aws.String(clusterID), "rds", "cluster")
+	if err != nil {
+		return nil, rdsCli.handleError("DeleteDBCluster", err)
+	}
+
 	input := &rds.DeleteDBClusterInput{
-		DBClusterIdentifier: aws.String(clusterID),
+		DBClusterIdentifier: clusterName,
 		SkipFinalSnapshot:   aws.Bool(true),
 	}
 	if len(finalSnapshotID) > 0 {
+
 		input = &rds.DeleteDBClusterInput{
-			DBClusterIdentifier:       aws.String(clusterID),
+			DBClusterIdentifier:       clusterName,
 			FinalDBSnapshotIdentifier: aws.String(finalSnapshotID),
 			SkipFinalSnapshot:         aws.Bool(false),
 		}
 	}
 
-	result, err := rdsCli.cli.DeleteDBCluster(input)
+	result, err := rdsCli.cli.DeleteDBClusterWithContext(ctx, input)
 	if err != nil {
-		handleError(err)
+		return nil, rdsCli.handleError("DeleteDBCluster", err)
 	}
 
-	return result
+	return result, nil
 }
 
-func (rdsCli *RDSClient) RestoreDClusterFromSnapshot(input *rds.RestoreDBClusterFromSnapshotInput) *rds.DBCluster {
-	resp, err := rdsCli.cli.RestoreDBClusterFromSnapshot(input)
+func (rdsCli *RDSClient) RestoreDClusterFromSnapshot(input *rds.RestoreDBClusterFromSnapshotInput) (*rds.DBCluster, error) {
+	return rdsCli.RestoreDClusterFromSnapshotWithContext(context.Background(), input)
+}
+
+func (rdsCli *RDSClient) RestoreDClusterFromSnapshotWithContext(ctx context.Context, input *rds.RestoreDBClusterFromSnapshotInput) (*rds.DBCluster, error) {
+	resp, err := rdsCli.cli.RestoreDBClusterFromSnapshotWithContext(ctx, input)
 	if err != nil {
-		rdsCli.handleError(err)
-		return nil
-	}
-
-	return resp.DBCluster
-}
-
-func (rdsCli *RDSClient) handleError(err error) {
-	if aerr, ok := err.(awserr.Error); ok {
-		switch aerr.Code() {
-		case rds.ErrCodeDBSnapshotAlreadyExistsFault:
-			fmt.Println(rds.ErrCodeDBSnapshotAlreadyExistsFault, aerr.Error())
-		case rds.ErrCodeDBSnapshotNotFoundFault:
-			fmt.Println(rds.ErrCodeDBSnapshotNotFoundFault, aerr.Error())
-		case rds.ErrCodeInvalidDBSnapshotStateFault:
-			fmt.Println(rds.ErrCodeInvalidDBSnapshotStateFault, aerr.Error())
-		case rds.ErrCodeSnapshotQuotaExceededFault:
-			fmt.Println(rds.ErrCodeSnapshotQuotaExceededFault, aerr.Error())
-		case rds.ErrCodeKMSKeyNotAccessibleFault:
-			fmt.Println(rds.ErrCodeKMSKeyNotAccessibleFault, aerr.Error())
-		case rds.ErrCodeCustomAvailabilityZoneNotFoundFault:
-			fmt.Println(rds.ErrCodeCustomAvailabilityZoneNotFoundFault, aerr.Error())
-		case rds.ErrCodeDBClusterAlreadyExistsFault:
-			fmt.Println(rds.ErrCodeDBClusterAlreadyExistsFault, aerr.Error())
-		case rds.ErrCodeDBClusterQuotaExceededFault:
-			fmt.Println(rds.ErrCodeDBClusterQuotaExceededFault, aerr.Error())
-		case rds.ErrCodeStorageQuotaExceededFault:
-			fmt.Println(rds.ErrCodeStorageQuotaExceededFault, aerr.Error())
-		case rds.ErrCodeDBSubnetGroupNotFoundFault:
-			fmt.Println(rds.ErrCodeDBSubnetGroupNotFoundFault, aerr.Error())
-		case rds.ErrCodeDBClusterSnapshotNotFoundFault:
-			fmt.Println(rds.ErrCodeDBClusterSnapshotNotFoundFault, aerr.Error())
-		case rds.ErrCodeInsufficientDBClusterCapacityFault:
-			fmt.Println(rds.ErrCodeInsufficientDBClusterCapacityFault, aerr.Error())
-		case rds.ErrCodeInsufficientStorageClusterCapacityFault:
-			fmt.Println(rds.ErrCodeInsufficientStorageClusterCapacityFault, aerr.Error())
-		case rds.ErrCodeInvalidDBClusterSnapshotStateFault:
-			fmt.Println(rds.ErrCodeInvalidDBClusterSnapshotStateFault, aerr.Error())
-		case rds.ErrCodeInvalidVPCNetworkStateFault:
-			fmt.Println(rds.ErrCodeInvalidVPCNetworkStateFault, aerr.Error())
-		case rds.ErrCodeInvalidRestoreFault:
-			fmt.Println(rds.ErrCodeInvalidRestoreFault, aerr.Error())
-		case rds.ErrCodeInvalidSubnet:
-			fmt.Println(rds.ErrCodeInvalidSubnet, aerr.Error())
-		case rds.ErrCodeOptionGroupNotFoundFault:
-			fmt.Println(rds.ErrCodeOptionGroupNotFoundFault, aerr.Error())
-		case rds.ErrCodeDomainNotFoundFault:
-			fmt.Println(rds.ErrCodeDomainNotFoundFault, aerr.Error())
-		case rds.ErrCodeDBClusterParameterGroupNotFoundFault:
-			fmt.Println(rds.ErrCodeDBClusterParameterGroupNotFoundFault, aerr.Error())
-		case rds.ErrCodeInvalidDBInstanceStateFault:
-			fmt.Println(rds.ErrCodeInvalidDBInstanceStateFault, aerr.Error())
-		case rds.ErrCodeInvalidDBClusterStateFault:
-			fmt.Println(rds.ErrCodeInvalidDBClusterStateFault, aerr.Error())
-		case rds.ErrCodeInvalidDBSubnetGroupStateFault:
-			fmt.Println(rds.ErrCodeInvalidDBSubnetGroupStateFault, aerr.Error())
-		case rds.ErrCodeDBClusterNotFoundFault:
-			fmt.Println(rds.ErrCodeDBClusterNotFoundFault, aerr.Error())
-		case rds.ErrCodeDBInstanceNotFoundFault:
-			fmt.Println(rds.ErrCodeDBInstanceNotFoundFault, aerr.Error())
-		case rds.ErrCodeDBSubnetGroupDoesNotCoverEnoughAZs:
-			fmt.Println(rds.ErrCodeDBSubnetGroupDoesNotCoverEnoughAZs, aerr.Error())
-		case rds.ErrCodeGlobalClusterNotFoundFault:
-			fmt.Println(rds.ErrCodeGlobalClusterNotFoundFault, aerr.Error())
-		case rds.ErrCodeInvalidGlobalClusterStateFault:
-			fmt.Println(rds.ErrCodeInvalidGlobalClusterStateFault, aerr.Error())
-		case rds.ErrCodeDBInstanceAlreadyExistsFault:
-			fmt.Println(rds.ErrCodeDBInstanceAlreadyExistsFault, aerr.Error())
-		case rds.ErrCodeInsufficientDBInstanceCapacityFault:
-			fmt.Println(rds.ErrCodeInsufficientDBInstanceCapacityFault, aerr.Error())
-		case rds.ErrCodeDBParameterGroupNotFoundFault:
-			fmt.Println(rds.ErrCodeDBParameterGroupNotFoundFault, aerr.Error())
-		case rds.ErrCodeDBSecurityGroupNotFoundFault:
-			fmt.Println(rds.ErrCodeDBSecurityGroupNotFoundFault, aerr.Error())
-		case rds.ErrCodeInstanceQuotaExceededFault:
-			fmt.Println(rds.ErrCodeInstanceQuotaExceededFault, aerr.Error())
-		case rds.ErrCodeProvisionedIopsNotAvailableInAZFault:
-			fmt.Println(rds.ErrCodeProvisionedIopsNotAvailableInAZFault, aerr.Error())
-		case rds.ErrCodeStorageTypeNotSupportedFault:
-			fmt.Println(rds.ErrCodeStorageTypeNotSupportedFault, aerr.Error())
-		case rds.ErrCodeAuthorizationNotFoundFault:
-			fmt.Println(rds.ErrCodeAuthorizationNotFoundFault, aerr.Error())
-		case rds.ErrCodeBackupPolicyNotFoundFault:
-			fmt.Println(rds.ErrCodeBackupPolicyNotFoundFault, aerr.Error())
-		case rds.ErrCodeCertificateNotFoundFault:
-			fmt.Println(rds.ErrCodeCertificateNotFoundFault, aerr.Error())
-		default:
-			fmt.Println(aerr.Error())
-		}
-	} else {
-		// Print the error, cast err to awserr.Error to get the Code and
-		// Message from an error.
-		fmt.Println(err.Error())
+		return nil, rdsCli.handleError("RestoreDBClusterFromSnapshot", err)
 	}
+
+	return resp.DBCluster, nil
+}
+
+// rdsWaitOptions poll every 30 seconds for up to 2 hours, since snapshots
+// of large databases take long.
+var rdsWaitOptions = WaitOptions{Timeout: 2 * time.Hour, Backoff: Backoff{Delay: 30 * time.Second}}
+
+var rdsFailureStates = []string{
+	"deleted", "deleting", "failed", "incompatible-restore", "incompatible-parameters", StateNotFound,
+}
+
+func (rdsCli *RDSClient) WaitForClusterSnapshotAvailable(clusterID, snapshotID string, opts WaitOptions) (*rds.DBClusterSnapshot, error) {
+	return rdsCli.WaitForClusterSnapshotAvailableWithContext(context.Background(), clusterID, snapshotID, opts)
+}
+
+func (rdsCli *RDSClient) WaitForClusterSnapshotAvailableWithContext(ctx context.Context, clusterID, snapshotID string, opts WaitOptions) (*rds.DBClusterSnapshot, error) {
+	var snapshot *rds.DBClusterSnapshot
+
+	w := &Waiter{
+		Resource: "RDS cluster snapshot " + snapshotID,
+		State: func(ctx context.Context) (string, error) {
+			var err error
+
+			snapshot, err = rdsCli.DescribeClusterSnapshotWithContext(ctx, clusterID, snapshotID)
+			if snapshot == nil {
+				return resourceState(false, nil, err)
+			}
+
+			return resourceState(true, snapshot.Status, err)
+		},
+		Success:     []string{"available"},
+		Failure:     rdsFailureStates,
+		WaitOptions: opts.withDefaults(rdsWaitOptions),
+	}
+
+	_, err := w.Wait(ctx)
+
+	return snapshot, err
+}
+
+func (rdsCli *RDSClient) WaitForDBSnapshotAvailable(instanceID, snapshotID string, opts WaitOptions) (*rds.DBSnapshot, error) {
+	return rdsCli.WaitForDBSnapshotAvailableWithContext(context.Background(), instanceID, snapshotID, opts)
+}
+
+func (rdsCli *RDSClient) WaitForDBSnapshotAvailableWithContext(ctx context.Context, instanceID, snapshotID string, opts WaitOptions) (*rds.DBSnapshot, error) {
+	var snapshot *rds.DBSnapshot
+
+	w := &Waiter{
+		Resource: "RDS snapshot " + snapshotID,
+		State: func(ctx context.Context) (string, error) {
+			var err error
+
+			snapshot, err = rdsCli.DescribeDBSnapshotWithContext(ctx, instanceID, snapshotID)
+			if snapshot == nil {
+				return resourceState(false, nil, err)
+			}
+
+			return resourceState(true, snapshot.Status, err)
+		},
+		Success:     []string{"available"},
+		Failure:     rdsFailureStates,
+		WaitOptions: opts.withDefaults(rdsWaitOptions),
+	}
+
+	_, err := w.Wait(ctx)
+
+	return snapshot, err
+}
+
+func (rdsCli *RDSClient) WaitForDBInstanceAvailable(dbInstanceID string, opts WaitOptions) (*rds.DBInstance, error) {
+	return rdsCli.WaitForDBInstanceAvailableWithContext(context.Background(), dbInstanceID, opts)
+}
+
+func (rdsCli *RDSClient) WaitForDBInstanceAvailableWithContext(ctx context.Context, dbInstanceID string, opts WaitOptions) (*rds.DBInstance, error) {
+	var instance *rds.DBInstance
+
+	w := &Waiter{
+		Resource: "RDS instance " + dbInstanceID,
+		State: func(ctx context.Context) (string, error) {
+			var err error
+
+			instance, err = rdsCli.DescribeDBInstanceWithContext(ctx, dbInstanceID)
+			if instance == nil {
+				return resourceState(false, nil, err)
+			}
+
+			return resourceState(true, instance.DBInstanceStatus, err)
+		},
+		Success:     []string{"available"},
+		Failure:     rdsFailureStates,
+		WaitOptions: opts.withDefaults(rdsWaitOptions),
+	}
+
+	_, err := w.Wait(ctx)
+
+	return instance, err
+}
+
+func (rdsCli *RDSClient) WaitForDBClusterAvailable(dbClusterIdentifier string, opts WaitOptions) (*rds.DBCluster, error) {
+	return rdsCli.WaitForDBClusterAvailableWithContext(context.Background(), dbClusterIdentifier, opts)
+}
+
+func (rdsCli *RDSClient) WaitForDBClusterAvailableWithContext(ctx context.Context, dbClusterIdentifier string, opts WaitOptions) (*rds.DBCluster, error) {
+	var cluster *rds.DBCluster
+
+	w := &Waiter{
+		Resource: "RDS cluster " + dbClusterIdentifier,
+		State: func(ctx context.Context) (string, error) {
+			var err error
+
+			cluster, err = rdsCli.DescribeDBClusterWithContext(ctx, dbClusterIdentifier)
+			if cluster == nil {
+				return resourceState(false, nil, err)
+			}
+
+			return resourceState(true, cluster.Status, err)
+		},
+		Success:     []string{"available"},
+		Failure:     rdsFailureStates,
+		WaitOptions: opts.withDefaults(rdsWaitOptions),
+	}
+
+	_, err := w.Wait(ctx)
+
+	return cluster, err
+}
+
+func (rdsCli *RDSClient) handleError(operation string, err error) error {
+	return rdsCli.logError(newError(rds.ServiceName, operation, err))
 }
@@ -0,0 +1,57 @@
+package clients_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/mwlng/aws-go-clients/recorder"
+	"github.com/mwlng/aws-go-clients/service"
+)
+
+// recordedSession returns a session replaying the cassette testdata/<name>.json.
+// With RECORD set, the calls reach AWS with the default credentials and the
+// cassette is recorded again.
+func recordedSession(t *testing.T, name string) *session.Session {
+	t.Helper()
+
+	mode := recorder.Replay
+	if os.Getenv("RECORD") != "" {
+		mode = recorder.Record
+	}
+
+	rec, err := recorder.New(filepath.Join("testdata", name+".json"), recorder.Options{Mode: mode})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		if err := rec.Save(); err != nil {
+			t.Error(err)
+		}
+
+		if unused := rec.Unused(); mode == recorder.Replay && len(unused) > 0 {
+			t.Errorf("%d recorded interactions were not replayed", len(unused))
+		}
+	})
+
+	// The SDK loads AWS_CA_BUNDLE into an *http.Transport only, and the
+	// recorder's client has none.
+	if bundle, ok := os.LookupEnv("AWS_CA_BUNDLE"); ok {
+		os.Unsetenv("AWS_CA_BUNDLE")
+		t.Cleanup(func() { os.Setenv("AWS_CA_BUNDLE", bundle) })
+	}
+
+	svc := service.Service{Region: "us-east-1", HTTPClient: rec.Client()}
+	if mode == recorder.Replay {
+		svc.AccessKey, svc.SecretKey = "test", "test"
+	}
+
+	sess, err := svc.NewSessionE()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return sess
+}
@@ -1,52 +1,74 @@
 package clients
 
 import (
-	"fmt"
+	"context"
 
-	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/redshift"
+	"github.com/aws/aws-sdk-go/service/redshift/redshiftiface"
 )
 
+type RedShiftAPI interface {
+	GetClusterCreds(clusterID *string, dbUser *string, dbGroup *[]*string, dbName *string) (*redshift.GetClusterCredentialsOutput, error)
+	GetClusterCredsWithContext(ctx context.Context, clusterID *string, dbUser *string, dbGroup *[]*string, dbName *string) (*redshift.GetClusterCredentialsOutput, error)
+}
+
+var _ RedShiftAPI = (*RedShiftClient)(nil)
+
 type RedShiftClient struct {
-	cli *redshift.Redshift
+	logging
+	*owner
+	cli redshiftiface.RedshiftAPI
+}
+
+func init() {
+	Register("redshift", func(sess *session.Session) interface{} { return NewRedShift(sess) })
 }
 
-func NewRedShift(sess *session.Session) *RedShiftClient {
-	client := redshift.New(sess)
+func NewRedShift(sess *session.Session, cfgs ...*aws.Config) *RedShiftClient {
+	client := redshift.New(sess, cfgs...)
+
+	return &RedShiftClient{logging: newLogging(sess), owner: newOwner(sess, client.Config), cli: client}
+}
 
-	return &RedShiftClient{cli: client}
+func NewRedShiftFromAPI(api redshiftiface.RedshiftAPI) *RedShiftClient {
+	return &RedShiftClient{logging: logging{logger: NopLogger}, owner: &owner{}, cli: api}
 }
 
 func (rsCli *RedShiftClient) GetClusterCreds(clusterID *string,
 	dbUser *string,
 	dbGroup *[]*string,
-	dbName *string) *redshift.GetClusterCredentialsOutput {
-	input := &redshift.GetClusterCredentialsInput{}
+	dbName *string) (*redshift.GetClusterCredentialsOutput, error) {
+	return rsCli.GetClusterCredsWithContext(context.Background(), clusterID, dbUser, dbGroup, dbName)
+}
 
-	resp, err := rsCli.cli.GetClusterCredentials(input)
+func (rsCli *RedShiftClient) GetClusterCredsWithContext(ctx context.Context, clusterID *string,
+	dbUser *string,
+	dbGroup *[]*string,
+	dbName *string) (*redshift.GetClusterCredentialsOutput, error) {
+	clusterID, err := rsCli.nameOf(ctx, clusterID, "redshift", "cluster")
 	if err != nil {
-		rsCli.handleError(err)
+		return nil, rsCli.handleError("GetClusterCredentials", err)
+	}
 
-		return nil
+	input := &redshift.GetClusterCredentialsInput{
+		ClusterIdentifier: clusterID,
+		DbUser:            dbUser,
+		DbName:            dbName,
+	}
+	if dbGroup != nil {
+		input.DbGroups = *dbGroup
 	}
 
-	return resp
-}
-
-func (rsCli *RedShiftClient) handleError(err error) {
-	if aerr, ok := err.(awserr.Error); ok {
-		switch aerr.Code() {
-		case redshift.ErrCodeClusterNotFoundFault:
-			fmt.Println(redshift.ErrCodeClusterNotFoundFault, aerr.Error())
-		case redshift.ErrCodeUnsupportedOperationFault:
-			fmt.Println(redshift.ErrCodeClusterNotFoundFault, aerr.Error())
-		default:
-			fmt.Println(aerr.Error())
-		}
-	} else {
-		// Print the error, cast err to awserr.Error to get the Code and
-		// Message from an error.
-		fmt.Println(err.Error())
+	resp, err := rsCli.cli.GetClusterCredentialsWithContext(ctx, input)
+	if err != nil {
+		return nil, rsCli.handleError("GetClusterCredentials", err)
 	}
+
+	return resp, nil
+}
+
+func (rsCli *RedShiftClient) handleError(operation string, err error) error {
+	return rsCli.logError(newError(redshift.ServiceName, operation, err))
 }
@@ -1,101 +1,259 @@
 package clients
 
 import (
+	"context"
 	"fmt"
+	"strings"
 
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/route53"
+	"github.com/aws/aws-sdk-go/service/route53/route53iface"
 )
 
+type R53API interface {
+	ListHostedZones() ([]*route53.HostedZone, error)
+	ListHostedZonesWithContext(ctx context.Context) ([]*route53.HostedZone, error)
+	ListHostedZonesPages(pageSize int64, fn func(zones []*route53.HostedZone) bool) error
+	ListHostedZonesPagesWithContext(ctx context.Context, pageSize int64, fn func(zones []*route53.HostedZone) bool) error
+	ListTagsForHostedZones(hostedZoneIDs []*string) (map[string][]*route53.Tag, error)
+	ListTagsForHostedZonesWithContext(ctx context.Context, hostedZoneIDs []*string) (map[string][]*route53.Tag, error)
+	ListResourceRecordSets(hostedZoneID *string) ([]*route53.ResourceRecordSet, error)
+	ListResourceRecordSetsWithContext(ctx context.Context, hostedZoneID *string) ([]*route53.ResourceRecordSet, error)
+	ListResourceRecordSetsPages(hostedZoneID *string, pageSize int64, fn func(records []*route53.ResourceRecordSet) bool) error
+	ListResourceRecordSetsPagesWithContext(ctx context.Context, hostedZoneID *string, pageSize int64, fn func(records []*route53.ResourceRecordSet) bool) error
+	ListGeoLocations() ([]*route53.GeoLocationDetails, error)
+	ListGeoLocationsWithContext(ctx context.Context) ([]*route53.GeoLocationDetails, error)
+	ListGeoLocationsPages(pageSize int64, fn func(locations []*route53.GeoLocationDetails) bool) error
+	ListGeoLocationsPagesWithContext(ctx context.Context, pageSize int64, fn func(locations []*route53.GeoLocationDetails) bool) error
+	GetResourceRecordSet(name *string, hostedZoneID *string) (*route53.ResourceRecordSet, error)
+	GetResourceRecordSetWithContext(ctx context.Context, name *string, hostedZoneID *string) (*route53.ResourceRecordSet, error)
+	ChangeResourceRecordSets(recordSets []*route53.ResourceRecordSet, action *string, hostedZoneID *string, changeComment *string) (*route53.ChangeResourceRecordSetsOutput, error)
+	ChangeResourceRecordSetsWithContext(ctx context.Context, recordSets []*route53.ResourceRecordSet, action *string, hostedZoneID *string, changeComment *string) (*route53.ChangeResourceRecordSetsOutput, error)
+}
+
+var _ R53API = (*R53Client)(nil)
+
 type R53Client struct {
-	cli *route53.Route53
+	logging
+	*owner
+	cli route53iface.Route53API
+}
+
+func init() {
+	Register("route53", func(sess *session.Session) interface{} { return NewR53(sess) })
 }
 
-func NewR53(sess *session.Session) *R53Client {
-	client := route53.New(sess)
+func NewR53(sess *session.Session, cfgs ...*aws.Config) *R53Client {
+	client := route53.New(sess, cfgs...)
 
-	return &R53Client{cli: client}
+	return &R53Client{logging: newLogging(sess), owner: newOwner(sess, client.Config), cli: client}
 }
 
-func (r53Cli *R53Client) ListHostedZones() []*route53.HostedZone {
-	input := &route53.ListHostedZonesInput{}
+func NewR53FromAPI(api route53iface.Route53API) *R53Client {
+	return &R53Client{logging: logging{logger: NopLogger}, owner: &owner{}, cli: api}
+}
 
-	resp, err := r53Cli.cli.ListHostedZones(input)
-	if err != nil {
-		r53Cli.handleError(err)
-	}
+func (r53Cli *R53Client) ListHostedZones() ([]*route53.HostedZone, error) {
+	return r53Cli.ListHostedZonesWithContext(context.Background())
+}
+
+func (r53Cli *R53Client) ListHostedZonesWithContext(ctx context.Context) ([]*route53.HostedZone, error) {
+	var zones []*route53.HostedZone
 
-	zones := resp.HostedZones
+	err := r53Cli.ListHostedZonesPagesWithContext(ctx, 0, func(page []*route53.HostedZone) bool {
+		zones = append(zones, page...)
 
-	for *resp.IsTruncated {
-		input = &route53.ListHostedZonesInput{Marker: resp.Marker}
+		return true
+	})
 
-		resp, err = r53Cli.cli.ListHostedZones(input)
+	return zones, err
+}
+
+func (r53Cli *R53Client) ListHostedZonesPages(pageSize int64, fn func(zones []*route53.HostedZone) bool) error {
+	return r53Cli.ListHostedZonesPagesWithContext(context.Background(), pageSize, fn)
+}
+
+func (r53Cli *R53Client) ListHostedZonesPagesWithContext(ctx context.Context, pageSize int64, fn func(zones []*route53.HostedZone) bool) error {
+	input := &route53.ListHostedZonesInput{MaxItems: pageLimitString(pageSize, 1, 100)}
+
+	for {
+		resp, err := r53Cli.cli.ListHostedZonesWithContext(ctx, input)
 		if err != nil {
-			r53Cli.handleError(err)
+			return r53Cli.handleError("ListHostedZones", err)
 		}
 
-		zones = append(zones, resp.HostedZones...)
+		if !fn(resp.HostedZones) || !aws.BoolValue(resp.IsTruncated) {
+			return nil
+		}
+
+		input.Marker = resp.NextMarker
 	}
+}
 
-	return zones
+// ListTagsForHostedZones returns the tags of the hosted zones, keyed by zone
+// ID without the "/hostedzone/" prefix. The zones are asked for ten at a
+// time, the most the API accepts.
+func (r53Cli *R53Client) ListTagsForHostedZones(hostedZoneIDs []*string) (map[string][]*route53.Tag, error) {
+	return r53Cli.ListTagsForHostedZonesWithContext(context.Background(), hostedZoneIDs)
 }
 
-func (r53Cli *R53Client) ListResourceRecordSets(hostedZoneID *string) []*route53.ResourceRecordSet {
-	input := &route53.ListResourceRecordSetsInput{HostedZoneId: hostedZoneID}
+func (r53Cli *R53Client) ListTagsForHostedZonesWithContext(ctx context.Context, hostedZoneIDs []*string) (map[string][]*route53.Tag, error) {
+	ids := make([]*string, 0, len(hostedZoneIDs))
+	for _, id := range hostedZoneIDs {
+		id, err := r53Cli.nameOf(ctx, id, "route53", "hostedzone")
+		if err != nil {
+			return nil, r53Cli.handleError("ListTagsForResources", err)
+		}
 
-	resp, err := r53Cli.cli.ListResourceRecordSets(input)
-	if err != nil {
-		r53Cli.handleError(err)
-		return nil
+		ids = append(ids, aws.String(strings.TrimPrefix(aws.StringValue(id), "/hostedzone/")))
 	}
 
-	records := resp.ResourceRecordSets
+	tags := map[string][]*route53.Tag{}
 
-	for *resp.IsTruncated {
-		input = &route53.ListResourceRecordSetsInput{
-			HostedZoneId:    hostedZoneID,
-			StartRecordName: resp.NextRecordName,
+	for start := 0; start < len(ids); start += 10 {
+		end := start + 10
+		if end > len(ids) {
+			end = len(ids)
 		}
 
-		resp, err = r53Cli.cli.ListResourceRecordSets(input)
+		input := &route53.ListTagsForResourcesInput{
+			ResourceType: aws.String(route53.TagResourceTypeHostedzone),
+			ResourceIds:  ids[start:end],
+		}
+
+		resp, err := r53Cli.cli.ListTagsForResourcesWithContext(ctx, input)
 		if err != nil {
-			r53Cli.handleError(err)
-			break
+			return nil, r53Cli.handleError("ListTagsForResources", err)
 		}
 
-		records = append(records, resp.ResourceRecordSets...)
+		for _, set := range resp.ResourceTagSets {
+			tags[aws.StringValue(set.ResourceId)] = set.Tags
+		}
 	}
 
-	return records
+	return tags, nil
+}
+
+func (r53Cli *R53Client) ListResourceRecordSets(hostedZoneID *string) ([]*route53.ResourceRecordSet, error) {
+	return r53Cli.ListResourceRecordSetsWithContext(context.Background(), hostedZoneID)
+}
+
+func (r53Cli *R53Client) ListResourceRecordSetsWithContext(ctx context.Context, hostedZoneID *string) ([]*route53.ResourceRecordSet, error) {
+	var records []*route53.ResourceRecordSet
+
+	err := r53Cli.ListResourceRecordSetsPagesWithContext(ctx, hostedZoneID, 0, func(page []*route53.ResourceRecordSet) bool {
+		records = append(records, page...)
+
+		return true
+	})
+
+	return records, err
 }
 
-func (r53Cli *R53Client) ListGeoLocations() []*route53.GeoLocationDetails {
-	input := &route53.ListGeoLocationsInput{}
+func (r53Cli *R53Client) ListResourceRecordSetsPages(hostedZoneID *string, pageSize int64, fn func(records []*route53.ResourceRecordSet) bool) error {
+	return r53Cli.ListResourceRecordSetsPagesWithContext(context.Background(), hostedZoneID, pageSize, fn)
+}
 
-	resp, err := r53Cli.cli.ListGeoLocations(input)
+func (r53Cli *R53Client) ListResourceRecordSetsPagesWithContext(ctx context.Context, hostedZoneID *string, pageSize int64, fn func(records []*route53.ResourceRecordSet) bool) error {
+	hostedZoneID, err := r53Cli.nameOf(ctx, hostedZoneID, "route53", "hostedzone")
 	if err != nil {
-		r53Cli.handleError(err)
+		return r53Cli.handleError("ListResourceRecordSets", err)
+	}
+
+	input := &route53.ListResourceRecordSetsInput{
+		HostedZoneId: hostedZoneID,
+		MaxItems:     pageLimitString(pageSize, 1, 300),
+	}
+
+	for {
+		resp, err := r53Cli.cli.ListResourceRecordSetsWithContext(ctx, input)
+		if err != nil {
+			return r53Cli.handleError("ListResourceRecordSets", err)
+		}
+
+		if !fn(resp.ResourceRecordSets) || !aws.BoolValue(resp.IsTruncated) {
+			return nil
+		}
+
+		input.StartRecordName = resp.NextRecordName
+		input.StartRecordType = resp.NextRecordType
+		input.StartRecordIdentifier = resp.NextRecordIdentifier
 	}
+}
+
+func (r53Cli *R53Client) ListGeoLocations() ([]*route53.GeoLocationDetails, error) {
+	return r53Cli.ListGeoLocationsWithContext(context.Background())
+}
+
+func (r53Cli *R53Client) ListGeoLocationsWithContext(ctx context.Context) ([]*route53.GeoLocationDetails, error) {
+	var locations []*route53.GeoLocationDetails
+
+	err := r53Cli.ListGeoLocationsPagesWithContext(ctx, 0, func(page []*route53.GeoLocationDetails) bool {
+		locations = append(locations, page...)
+
+		return true
+	})
 
-	return resp.GeoLocationDetailsList
+	return locations, err
 }
 
-func (r53Cli *R53Client) GetResourceRecordSet(name *string, hostedZoneID *string) *route53.ResourceRecordSet {
-	recordSets := r53Cli.ListResourceRecordSets(hostedZoneID)
+func (r53Cli *R53Client) ListGeoLocationsPages(pageSize int64, fn func(locations []*route53.GeoLocationDetails) bool) error {
+	return r53Cli.ListGeoLocationsPagesWithContext(context.Background(), pageSize, fn)
+}
+
+func (r53Cli *R53Client) ListGeoLocationsPagesWithContext(ctx context.Context, pageSize int64, fn func(locations []*route53.GeoLocationDetails) bool) error {
+	input := &route53.ListGeoLocationsInput{MaxItems: pageLimitString(pageSize, 1, 100)}
+
+	for {
+		resp, err := r53Cli.cli.ListGeoLocationsWithContext(ctx, input)
+		if err != nil {
+			return r53Cli.handleError("ListGeoLocations", err)
+		}
+
+		if !fn(resp.GeoLocationDetailsList) || !aws.BoolValue(resp.IsTruncated) {
+			return nil
+		}
+
+		input.StartContinentCode = resp.NextContinentCode
+		input.StartCountryCode = resp.NextCountryCode
+		input.StartSubdivisionCode = resp.NextSubdivisionCode
+	}
+}
+
+func (r53Cli *R53Client) GetResourceRecordSet(name *string, hostedZoneID *string) (*route53.ResourceRecordSet, error) {
+	return r53Cli.GetResourceRecordSetWithContext(context.Background(), name, hostedZoneID)
+}
+
+func (r53Cli *R53Client) GetResourceRecordSetWithContext(ctx context.Context, name *string, hostedZoneID *string) (*route53.ResourceRecordSet, error) {
+	if name == nil {
+		return nil, r53Cli.handleError("ListResourceRecordSets", awserr.New("MissingParameter", "record set name is required", nil))
+	}
+
+	recordSets, err := r53Cli.ListResourceRecordSetsWithContext(ctx, hostedZoneID)
+	if err != nil {
+		return nil, err
+	}
+
 	for _, recordSet := range recordSets {
-		recordSetName := recordSet.Name
-		if *recordSetName == *name {
-			return recordSet
+		if aws.StringValue(recordSet.Name) == *name {
+			return recordSet, nil
 		}
 	}
 
-	return nil
+	msg := fmt.Sprintf("no record set named %s in hosted zone %s", *name, aws.StringValue(hostedZoneID))
+
+	return nil, r53Cli.handleError("ListResourceRecordSets", awserr.New("NotFound", msg, nil))
 }
 
 func (r53Cli *R53Client) ChangeResourceRecordSets(recordSets []*route53.ResourceRecordSet, action *string,
-	hostedZoneID *string, changeComment *string) *route53.ChangeResourceRecordSetsOutput {
+	hostedZoneID *string, changeComment *string) (*route53.ChangeResourceRecordSetsOutput, error) {
+	return r53Cli.ChangeResourceRecordSetsWithContext(context.Background(), recordSets, action, hostedZoneID, changeComment)
+}
+
+func (r53Cli *R53Client) ChangeResourceRecordSetsWithContext(ctx context.Context, recordSets []*route53.ResourceRecordSet, action *string,
+	hostedZoneID *string, changeComment *string) (*route53.ChangeResourceRecordSetsOutput, error) {
 	changes := []*route53.Change{}
 
 	for _, recordSet := range recordSets {
@@ -108,44 +266,30 @@ func (r53Cli *R53Client) ChangeResourceRecordSets(recordSets []*route53.Resource
 	}
 
 	if len(changes) > 0 {
+		zoneID, err := r53Cli.nameOf(ctx, hostedZoneID, "route53", "hostedzone")
+		if err != nil {
+			return nil, r53Cli.handleError("ChangeResourceRecordSets", err)
+		}
+
 		input := &route53.ChangeResourceRecordSetsInput{
 			ChangeBatch: &route53.ChangeBatch{
 				Changes: changes,
 				Comment: changeComment,
 			},
-			HostedZoneId: hostedZoneID,
+			HostedZoneId: zoneID,
 		}
 
-		resp, err := r53Cli.cli.ChangeResourceRecordSets(input)
+		resp, err := r53Cli.cli.ChangeResourceRecordSetsWithContext(ctx, input)
 		if err != nil {
-			handleError(err)
+			return nil, r53Cli.handleError("ChangeResourceRecordSets", err)
 		}
 
-		return resp
+		return resp, nil
 	}
 
-	return nil
-}
-
-func (r53Cli *R53Client) handleError(err error) {
-	if aerr, ok := err.(awserr.Error); ok {
-		switch aerr.Code() {
-		case route53.ErrCodeNoSuchHostedZone:
-			fmt.Println(route53.ErrCodeNoSuchHostedZone, aerr.Error())
-		case route53.ErrCodeNoSuchHealthCheck:
-			fmt.Println(route53.ErrCodeNoSuchHealthCheck, aerr.Error())
-		case route53.ErrCodeInvalidChangeBatch:
-			fmt.Println(route53.ErrCodeInvalidChangeBatch, aerr.Error())
-		case route53.ErrCodeInvalidInput:
-			fmt.Println(route53.ErrCodeInvalidInput, aerr.Error())
-		case route53.ErrCodePriorRequestNotComplete:
-			fmt.Println(route53.ErrCodePriorRequestNotComplete, aerr.Error())
-		default:
-			fmt.Println(aerr.Error())
-		}
-	} else {
-		// Print the error, cast err to awserr.Error to get the Code and
-		// Message from an error.
-		fmt.Println(err.Error())
-	}
+	return nil, nil
+}
+
+func (r53Cli *R53Client) handleError(operation string, err error) error {
+	return r53Cli.logError(newError(route53.ServiceName, operation, err))
 }
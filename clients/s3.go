@@ -1,61 +1,120 @@
 package clients
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
 )
 
+type S3API interface {
+	ListBuckets() (*s3.ListBucketsOutput, error)
+	ListBucketsWithContext(ctx context.Context) (*s3.ListBucketsOutput, error)
+	GetBucketPolicy(input *s3.GetBucketPolicyInput) (*s3.GetBucketPolicyOutput, error)
+	GetBucketPolicyWithContext(ctx context.Context, input *s3.GetBucketPolicyInput) (*s3.GetBucketPolicyOutput, error)
+	HeadObject(bucket *string, key *string) (*s3.HeadObjectOutput, error)
+	HeadObjectWithContext(ctx context.Context, bucket *string, key *string) (*s3.HeadObjectOutput, error)
+	ListObjects(bucket *string, pathPrefix *string, continuationToken *string) (*string, []*s3.Object, error)
+	ListObjectsWithContext(ctx context.Context, bucket *string, pathPrefix *string, continuationToken *string) (*string, []*s3.Object, error)
+	ListObjectsPages(bucket *string, pathPrefix *string, pageSize int64, fn func(objects []*s3.Object) bool) error
+	ListObjectsPagesWithContext(ctx context.Context, bucket *string, pathPrefix *string, pageSize int64, fn func(objects []*s3.Object) bool) error
+	ListCommonPrefixes(bucket *string, pathPrefix *string, continuationToken *string) (*string, []*s3.CommonPrefix, error)
+	ListCommonPrefixesWithContext(ctx context.Context, bucket *string, pathPrefix *string, continuationToken *string) (*string, []*s3.CommonPrefix, error)
+	ListCommonPrefixesPages(bucket *string, pathPrefix *string, pageSize int64, fn func(prefixes []*s3.CommonPrefix) bool) error
+	ListCommonPrefixesPagesWithContext(ctx context.Context, bucket *string, pathPrefix *string, pageSize int64, fn func(prefixes []*s3.CommonPrefix) bool) error
+	ListDirectory(bucket *string, pathPrefix *string) ([]*s3.CommonPrefix, []*s3.Object, error)
+	ListDirectoryWithContext(ctx context.Context, bucket *string, pathPrefix *string) ([]*s3.CommonPrefix, []*s3.Object, error)
+	ListDirectoryPages(bucket *string, pathPrefix *string, pageSize int64, fn func(prefixes []*s3.CommonPrefix, objects []*s3.Object) bool) error
+	ListDirectoryPagesWithContext(ctx context.Context, bucket *string, pathPrefix *string, pageSize int64, fn func(prefixes []*s3.CommonPrefix, objects []*s3.Object) bool) error
+	GetObjectACL(bucket *string, key *string) (*s3.GetObjectAclOutput, error)
+	GetObjectACLWithContext(ctx context.Context, bucket *string, key *string) (*s3.GetObjectAclOutput, error)
+	PutObjectACL(bucket *string, key *string, acl *string) error
+	PutObjectACLWithContext(ctx context.Context, bucket *string, key *string, acl *string) error
+	CopyObject(srcBucket *string, tgtBucket *string, srcKey *string, tgtKey *string) error
+	CopyObjectWithContext(ctx context.Context, srcBucket *string, tgtBucket *string, srcKey *string, tgtKey *string) error
+	GetBucketSSEConfiguration(bucket *string) (*s3.ServerSideEncryptionConfiguration, error)
+	GetBucketSSEConfigurationWithContext(ctx context.Context, bucket *string) (*s3.ServerSideEncryptionConfiguration, error)
+	GetBucketTagging(bucket *string) ([]*s3.Tag, error)
+	GetBucketTaggingWithContext(ctx context.Context, bucket *string) ([]*s3.Tag, error)
+	GetBucketRegion(bucket *string) (string, error)
+	GetBucketRegionWithContext(ctx context.Context, bucket *string) (string, error)
+}
+
+var _ S3API = (*S3Client)(nil)
+
 type S3Client struct {
-	cli *s3.S3
+	logging
+	cli s3iface.S3API
+}
+
+func init() {
+	Register("s3", func(sess *session.Session) interface{} { return NewS3(sess) })
+}
+
+func NewS3(sess *session.Session, cfgs ...*aws.Config) *S3Client {
+	client := s3.New(sess, cfgs...)
+
+	return &S3Client{logging: newLogging(sess), cli: client}
 }
 
-func NewS3(sess *session.Session) *S3Client {
-	client := s3.New(sess)
+func NewS3FromAPI(api s3iface.S3API) *S3Client {
+	return &S3Client{logging: logging{logger: NopLogger}, cli: api}
+}
 
-	return &S3Client{cli: client}
+func (s3Cli *S3Client) ListBuckets() (*s3.ListBucketsOutput, error) {
+	return s3Cli.ListBucketsWithContext(context.Background())
 }
 
-func (s3Cli *S3Client) ListBuckets() *s3.ListBucketsOutput {
+func (s3Cli *S3Client) ListBucketsWithContext(ctx context.Context) (*s3.ListBucketsOutput, error) {
 	input := &s3.ListBucketsInput{}
 
-	resp, err := s3Cli.cli.ListBuckets(input)
+	resp, err := s3Cli.cli.ListBucketsWithContext(ctx, input)
 	if err != nil {
-		s3Cli.handleError(err)
+		return nil, s3Cli.handleError("ListBuckets", err)
 	}
 
-	return resp
+	return resp, nil
 }
 
-func (s3Cli *S3Client) GetBucketPolicy(input *s3.GetBucketPolicyInput) *s3.GetBucketPolicyOutput {
-	resp, err := s3Cli.cli.GetBucketPolicy(input)
+func (s3Cli *S3Client) GetBucketPolicy(input *s3.GetBucketPolicyInput) (*s3.GetBucketPolicyOutput, error) {
+	return s3Cli.GetBucketPolicyWithContext(context.Background(), input)
+}
+
+func (s3Cli *S3Client) GetBucketPolicyWithContext(ctx context.Context, input *s3.GetBucketPolicyInput) (*s3.GetBucketPolicyOutput, error) {
+	resp, err := s3Cli.cli.GetBucketPolicyWithContext(ctx, input)
 	if err != nil {
-		s3Cli.handleError(err)
+		return nil, s3Cli.handleError("GetBucketPolicy", err)
 	}
 
-	return resp
+	return resp, nil
 }
 
-func (s3Cli *S3Client) HeadObject(bucket *string, key *string) *s3.HeadObjectOutput {
+func (s3Cli *S3Client) HeadObject(bucket *string, key *string) (*s3.HeadObjectOutput, error) {
+	return s3Cli.HeadObjectWithContext(context.Background(), bucket, key)
+}
+
+func (s3Cli *S3Client) HeadObjectWithContext(ctx context.Context, bucket *string, key *string) (*s3.HeadObjectOutput, error) {
 	input := &s3.HeadObjectInput{
 		Bucket: bucket,
 		Key:    key,
 	}
 
-	resp, err := s3Cli.cli.HeadObject(input)
+	resp, err := s3Cli.cli.HeadObjectWithContext(ctx, input)
 	if err != nil {
-		s3Cli.handleError(err)
-
-		return nil
+		return nil, s3Cli.handleError("HeadObject", err)
 	}
 
-	return resp
+	return resp, nil
+}
+
+func (s3Cli *S3Client) ListObjects(bucket *string, pathPrefix *string, continuationToken *string) (*string, []*s3.Object, error) {
+	return s3Cli.ListObjectsWithContext(context.Background(), bucket, pathPrefix, continuationToken)
 }
 
-func (s3Cli *S3Client) ListObjects(bucket *string, pathPrefix *string, continuationToken *string) (*string, []*s3.Object) {
+func (s3Cli *S3Client) ListObjectsWithContext(ctx context.Context, bucket *string, pathPrefix *string, continuationToken *string) (*string, []*s3.Object, error) {
 	var input *s3.ListObjectsV2Input
 	if continuationToken == nil {
 		input = &s3.ListObjectsV2Input{
@@ -70,22 +129,49 @@ func (s3Cli *S3Client) ListObjects(bucket *string, pathPrefix *string, continuat
 		}
 	}
 
-	resp, err := s3Cli.cli.ListObjectsV2(input)
+	resp, err := s3Cli.cli.ListObjectsV2WithContext(ctx, input)
 	if err != nil {
-		s3Cli.handleError(err)
-
-		return nil, nil
+		return nil, nil, s3Cli.handleError("ListObjectsV2", err)
 	}
 
 	var nextToken *string
-	if *resp.IsTruncated {
+	if aws.BoolValue(resp.IsTruncated) {
 		nextToken = resp.NextContinuationToken
 	}
 
-	return nextToken, resp.Contents
+	return nextToken, resp.Contents, nil
 }
 
-func (s3Cli *S3Client) ListCommonPrefixes(bucket *string, pathPrefix *string, continuationToken *string) (*string, []*s3.CommonPrefix) {
+func (s3Cli *S3Client) ListObjectsPages(bucket *string, pathPrefix *string, pageSize int64, fn func(objects []*s3.Object) bool) error {
+	return s3Cli.ListObjectsPagesWithContext(context.Background(), bucket, pathPrefix, pageSize, fn)
+}
+
+func (s3Cli *S3Client) ListObjectsPagesWithContext(ctx context.Context, bucket *string, pathPrefix *string, pageSize int64, fn func(objects []*s3.Object) bool) error {
+	input := &s3.ListObjectsV2Input{
+		Bucket:  bucket,
+		Prefix:  pathPrefix,
+		MaxKeys: pageLimit(pageSize, 1, 1000),
+	}
+
+	for {
+		resp, err := s3Cli.cli.ListObjectsV2WithContext(ctx, input)
+		if err != nil {
+			return s3Cli.handleError("ListObjectsV2", err)
+		}
+
+		if !fn(resp.Contents) || !aws.BoolValue(resp.IsTruncated) {
+			return nil
+		}
+
+		input.ContinuationToken = resp.NextContinuationToken
+	}
+}
+
+func (s3Cli *S3Client) ListCommonPrefixes(bucket *string, pathPrefix *string, continuationToken *string) (*string, []*s3.CommonPrefix, error) {
+	return s3Cli.ListCommonPrefixesWithContext(context.Background(), bucket, pathPrefix, continuationToken)
+}
+
+func (s3Cli *S3Client) ListCommonPrefixesWithContext(ctx context.Context, bucket *string, pathPrefix *string, continuationToken *string) (*string, []*s3.CommonPrefix, error) {
 	var input *s3.ListObjectsV2Input
 
 	delimiter := "/"
@@ -105,50 +191,137 @@ func (s3Cli *S3Client) ListCommonPrefixes(bucket *string, pathPrefix *string, co
 		}
 	}
 
-	resp, err := s3Cli.cli.ListObjectsV2(input)
+	resp, err := s3Cli.cli.ListObjectsV2WithContext(ctx, input)
 	if err != nil {
-		s3Cli.handleError(err)
-
-		return nil, nil
+		return nil, nil, s3Cli.handleError("ListObjectsV2", err)
 	}
 
 	var nextToken *string
-	if *resp.IsTruncated {
+	if aws.BoolValue(resp.IsTruncated) {
 		nextToken = resp.NextContinuationToken
 	}
 
-	return nextToken, resp.CommonPrefixes
+	return nextToken, resp.CommonPrefixes, nil
+}
+
+func (s3Cli *S3Client) ListCommonPrefixesPages(bucket *string, pathPrefix *string, pageSize int64, fn func(prefixes []*s3.CommonPrefix) bool) error {
+	return s3Cli.ListCommonPrefixesPagesWithContext(context.Background(), bucket, pathPrefix, pageSize, fn)
+}
+
+func (s3Cli *S3Client) ListCommonPrefixesPagesWithContext(ctx context.Context, bucket *string, pathPrefix *string, pageSize int64, fn func(prefixes []*s3.CommonPrefix) bool) error {
+	input := &s3.ListObjectsV2Input{
+		Bucket:    bucket,
+		Prefix:    pathPrefix,
+		Delimiter: aws.String("/"),
+		MaxKeys:   pageLimit(pageSize, 1, 1000),
+	}
+
+	for {
+		resp, err := s3Cli.cli.ListObjectsV2WithContext(ctx, input)
+		if err != nil {
+			return s3Cli.handleError("ListObjectsV2", err)
+		}
+
+		if !fn(resp.CommonPrefixes) || !aws.BoolValue(resp.IsTruncated) {
+			return nil
+		}
+
+		input.ContinuationToken = resp.NextContinuationToken
+	}
+}
+
+// ListDirectory lists the prefixes and the objects right under pathPrefix,
+// like a directory listing, with "/" as the delimiter.
+func (s3Cli *S3Client) ListDirectory(bucket *string, pathPrefix *string) ([]*s3.CommonPrefix, []*s3.Object, error) {
+	return s3Cli.ListDirectoryWithContext(context.Background(), bucket, pathPrefix)
+}
+
+func (s3Cli *S3Client) ListDirectoryWithContext(ctx context.Context, bucket *string, pathPrefix *string) ([]*s3.CommonPrefix, []*s3.Object, error) {
+	var (
+		prefixes []*s3.CommonPrefix
+		objects  []*s3.Object
+	)
+
+	err := s3Cli.ListDirectoryPagesWithContext(ctx, bucket, pathPrefix, 0, func(pagePrefixes []*s3.CommonPrefix, pageObjects []*s3.Object) bool {
+		prefixes = append(prefixes, pagePrefixes...)
+		objects = append(objects, pageObjects...)
+
+		return true
+	})
+
+	return prefixes, objects, err
+}
+
+func (s3Cli *S3Client) ListDirectoryPages(bucket *string, pathPrefix *string, pageSize int64, fn func(prefixes []*s3.CommonPrefix, objects []*s3.Object) bool) error {
+	return s3Cli.ListDirectoryPagesWithContext(context.Background(), bucket, pathPrefix, pageSize, fn)
+}
+
+func (s3Cli *S3Client) ListDirectoryPagesWithContext(ctx context.Context, bucket *string, pathPrefix *string, pageSize int64, fn func(prefixes []*s3.CommonPrefix, objects []*s3.Object) bool) error {
+	input := &s3.ListObjectsV2Input{
+		Bucket:    bucket,
+		Prefix:    pathPrefix,
+		Delimiter: aws.String("/"),
+		MaxKeys:   pageLimit(pageSize, 1, 1000),
+	}
+
+	for {
+		resp, err := s3Cli.cli.ListObjectsV2WithContext(ctx, input)
+		if err != nil {
+			return s3Cli.handleError("ListObjectsV2", err)
+		}
+
+		if !fn(resp.CommonPrefixes, resp.Contents) || !aws.BoolValue(resp.IsTruncated) {
+			return nil
+		}
+
+		input.ContinuationToken = resp.NextContinuationToken
+	}
 }
 
-func (s3Cli *S3Client) GetObjectACL(bucket *string, key *string) *s3.GetObjectAclOutput {
+func (s3Cli *S3Client) GetObjectACL(bucket *string, key *string) (*s3.GetObjectAclOutput, error) {
+	return s3Cli.GetObjectACLWithContext(context.Background(), bucket, key)
+}
+
+func (s3Cli *S3Client) GetObjectACLWithContext(ctx context.Context, bucket *string, key *string) (*s3.GetObjectAclOutput, error) {
 	input := &s3.GetObjectAclInput{
 		Bucket: bucket,
 		Key:    key,
 	}
 
-	resp, err := s3Cli.cli.GetObjectAcl(input)
+	resp, err := s3Cli.cli.GetObjectAclWithContext(ctx, input)
 	if err != nil {
-		s3Cli.handleError(err)
+		return nil, s3Cli.handleError("GetObjectAcl", err)
 	}
 
-	return resp
+	return resp, nil
 }
 
-func (s3Cli *S3Client) PutObjectACL(bucket *string, key *string, acl *string) {
+func (s3Cli *S3Client) PutObjectACL(bucket *string, key *string, acl *string) error {
+	return s3Cli.PutObjectACLWithContext(context.Background(), bucket, key, acl)
+}
+
+func (s3Cli *S3Client) PutObjectACLWithContext(ctx context.Context, bucket *string, key *string, acl *string) error {
 	input := &s3.PutObjectAclInput{
 		Bucket: bucket,
 		Key:    key,
 		ACL:    acl,
 	}
 
-	_, err := s3Cli.cli.PutObjectAcl(input)
+	_, err := s3Cli.cli.PutObjectAclWithContext(ctx, input)
 	if err != nil {
-		s3Cli.handleError(err)
+		return s3Cli.handleError("PutObjectAcl", err)
 	}
+
+	return nil
 }
 
 func (s3Cli *S3Client) CopyObject(srcBucket *string, tgtBucket *string,
-	srcKey *string, tgtKey *string) {
+	srcKey *string, tgtKey *string) error {
+	return s3Cli.CopyObjectWithContext(context.Background(), srcBucket, tgtBucket, srcKey, tgtKey)
+}
+
+func (s3Cli *S3Client) CopyObjectWithContext(ctx context.Context, srcBucket *string, tgtBucket *string,
+	srcKey *string, tgtKey *string) error {
 	input := &s3.CopyObjectInput{
 		ACL:        aws.String("bucket-owner-full-control"),
 		CopySource: aws.String(fmt.Sprintf("/%s/%s", *srcBucket, *srcKey)),
@@ -156,37 +329,69 @@ func (s3Cli *S3Client) CopyObject(srcBucket *string, tgtBucket *string,
 		Key:        tgtKey,
 	}
 
-	_, err := s3Cli.cli.CopyObject(input)
+	_, err := s3Cli.cli.CopyObjectWithContext(ctx, input)
 	if err != nil {
-		s3Cli.handleError(err)
+		return s3Cli.handleError("CopyObject", err)
 	}
+
+	return nil
 }
 
-func (s3Cli *S3Client) GetBucketSSEConfiguration(bucket *string) *s3.ServerSideEncryptionConfiguration {
+func (s3Cli *S3Client) GetBucketSSEConfiguration(bucket *string) (*s3.ServerSideEncryptionConfiguration, error) {
+	return s3Cli.GetBucketSSEConfigurationWithContext(context.Background(), bucket)
+}
+
+func (s3Cli *S3Client) GetBucketSSEConfigurationWithContext(ctx context.Context, bucket *string) (*s3.ServerSideEncryptionConfiguration, error) {
 	input := &s3.GetBucketEncryptionInput{
 		Bucket: bucket,
 	}
 
-	output, err := s3Cli.cli.GetBucketEncryption(input)
+	output, err := s3Cli.cli.GetBucketEncryptionWithContext(ctx, input)
+	if err != nil {
+		return nil, s3Cli.handleError("GetBucketEncryption", err)
+	}
+
+	return output.ServerSideEncryptionConfiguration, nil
+}
+
+func (s3Cli *S3Client) GetBucketTagging(bucket *string) ([]*s3.Tag, error) {
+	return s3Cli.GetBucketTaggingWithContext(context.Background(), bucket)
+}
+
+// GetBucketTaggingWithContext returns the tags of bucket. A bucket without
+// tags fails with the NoSuchTagSet code, which IsNotFound matches.
+func (s3Cli *S3Client) GetBucketTaggingWithContext(ctx context.Context, bucket *string) ([]*s3.Tag, error) {
+	input := &s3.GetBucketTaggingInput{
+		Bucket: bucket,
+	}
 
+	output, err := s3Cli.cli.GetBucketTaggingWithContext(ctx, input)
 	if err != nil {
-		s3Cli.handleError(err)
+		return nil, s3Cli.handleError("GetBucketTagging", err)
 	}
 
-	return output.ServerSideEncryptionConfiguration
+	return output.TagSet, nil
 }
 
-func (s3Cli *S3Client) handleError(err error) {
-	if aerr, ok := err.(awserr.Error); ok {
-		switch aerr.Code() {
-		case s3.ErrCodeNoSuchBucket:
-			fmt.Println(s3.ErrCodeNoSuchBucket, aerr.Error())
-		default:
-			fmt.Println(aerr.Error())
-		}
-	} else {
-		// Print the error, cast err to awserr.Error to get the Code and
-		// Message from an error.
-		fmt.Println(err.Error())
+func (s3Cli *S3Client) GetBucketRegion(bucket *string) (string, error) {
+	return s3Cli.GetBucketRegionWithContext(context.Background(), bucket)
+}
+
+// GetBucketRegionWithContext returns the region of bucket, translating the
+// legacy location constraints of GetBucketLocation.
+func (s3Cli *S3Client) GetBucketRegionWithContext(ctx context.Context, bucket *string) (string, error) {
+	input := &s3.GetBucketLocationInput{
+		Bucket: bucket,
 	}
+
+	output, err := s3Cli.cli.GetBucketLocationWithContext(ctx, input)
+	if err != nil {
+		return "", s3Cli.handleError("GetBucketLocation", err)
+	}
+
+	return s3.NormalizeBucketLocation(aws.StringValue(output.LocationConstraint)), nil
+}
+
+func (s3Cli *S3Client) handleError(operation string, err error) error {
+	return s3Cli.logError(newError(s3.ServiceName, operation, err))
 }
@@ -0,0 +1,48 @@
+package clients_test
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/mwlng/aws-go-clients/clients"
+	"github.com/mwlng/aws-go-clients/clients/fakes"
+)
+
+func TestS3ListDirectory(t *testing.T) {
+	api := fakes.NewS3()
+	for _, key := range []string{"logs/a.log", "logs/2020/b.log", "logs/2020/c.log", "logs/2021/d.log", "other.txt"} {
+		api.AddObject("bucket", key, []byte("x"))
+	}
+
+	prefixes, objects, err := clients.NewS3FromAPI(api).ListDirectory(aws.String("bucket"), aws.String("logs/"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var gotPrefixes, gotObjects []string
+	for _, p := range prefixes {
+		gotPrefixes = append(gotPrefixes, aws.StringValue(p.Prefix))
+	}
+
+	for _, o := range objects {
+		gotObjects = append(gotObjects, aws.StringValue(o.Key))
+	}
+
+	if len(gotPrefixes) != 2 || gotPrefixes[0] != "logs/2020/" || gotPrefixes[1] != "logs/2021/" {
+		t.Errorf("prefixes = %q, want each subdirectory once", gotPrefixes)
+	}
+
+	if len(gotObjects) != 1 || gotObjects[0] != "logs/a.log" {
+		t.Errorf("objects = %q, want the objects right under the prefix", gotObjects)
+	}
+}
+
+func TestS3GetBucketTaggingWithoutTags(t *testing.T) {
+	api := fakes.NewS3()
+	api.AddBucket("bucket")
+
+	_, err := clients.NewS3FromAPI(api).GetBucketTagging(aws.String("bucket"))
+	if !clients.IsNotFound(err) {
+		t.Errorf("GetBucketTagging error = %v, want a not found error", err)
+	}
+}
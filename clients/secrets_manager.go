@@ -1,146 +1,183 @@
 package clients
 
 import (
-	"fmt"
+	"context"
 
 	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/aws/aws-sdk-go/service/secretsmanager/secretsmanageriface"
 )
 
+type SecretsManagerAPI interface {
+	GetSecret(name string) (string, error)
+	GetSecretWithContext(ctx context.Context, name string) (string, error)
+	CreateSecret(name, value string) (*secretsmanager.CreateSecretOutput, error)
+	CreateSecretWithContext(ctx context.Context, name, value string) (*secretsmanager.CreateSecretOutput, error)
+	PutSecret(name, value string) (*secretsmanager.PutSecretValueOutput, error)
+	PutSecretWithContext(ctx context.Context, name, value string) (*secretsmanager.PutSecretValueOutput, error)
+	UpdateSecret(name, value string) (*secretsmanager.UpdateSecretOutput, error)
+	UpdateSecretWithContext(ctx context.Context, name, value string) (*secretsmanager.UpdateSecretOutput, error)
+	ListAllSecrets() ([]*secretsmanager.SecretListEntry, error)
+	ListAllSecretsWithContext(ctx context.Context) ([]*secretsmanager.SecretListEntry, error)
+	ListAllSecretsPages(pageSize int64, fn func(secrets []*secretsmanager.SecretListEntry) bool) error
+	ListAllSecretsPagesWithContext(ctx context.Context, pageSize int64, fn func(secrets []*secretsmanager.SecretListEntry) bool) error
+	DescribeSecret(secretID string) (*secretsmanager.DescribeSecretOutput, error)
+	DescribeSecretWithContext(ctx context.Context, secretID string) (*secretsmanager.DescribeSecretOutput, error)
+}
+
+var _ SecretsManagerAPI = (*SecretsManagerClient)(nil)
+
 type SecretsManagerClient struct {
-	cli *secretsmanager.SecretsManager
+	logging
+	cli secretsmanageriface.SecretsManagerAPI
+}
+
+func init() {
+	Register("secretsmanager", func(sess *session.Session) interface{} { return NewSecretsManager(sess) })
 }
 
-func NewSecretsManager(sess *session.Session) *SecretsManagerClient {
-	client := secretsmanager.New(sess)
+func NewSecretsManager(sess *session.Session, cfgs ...*aws.Config) *SecretsManagerClient {
+	client := secretsmanager.New(sess, cfgs...)
 
-	return &SecretsManagerClient{cli: client}
+	return &SecretsManagerClient{logging: newLogging(sess), cli: client}
 }
 
-func (smCli *SecretsManagerClient) GetSecret(name string) string {
+func NewSecretsManagerFromAPI(api secretsmanageriface.SecretsManagerAPI) *SecretsManagerClient {
+	return &SecretsManagerClient{logging: logging{logger: NopLogger}, cli: api}
+}
+
+func (smCli *SecretsManagerClient) GetSecret(name string) (string, error) {
+	return smCli.GetSecretWithContext(context.Background(), name)
+}
+
+func (smCli *SecretsManagerClient) GetSecretWithContext(ctx context.Context, name string) (string, error) {
 	input := &secretsmanager.GetSecretValueInput{
 		SecretId:     aws.String(name),
 		VersionId:    nil,
 		VersionStage: nil,
 	}
 
-	resp, err := smCli.cli.GetSecretValue(input)
+	resp, err := smCli.cli.GetSecretValueWithContext(ctx, input)
 	if err != nil {
-		smCli.handleError(err)
-
-		return ""
+		return "", smCli.handleError("GetSecretValue", err)
 	}
 
 	if resp.SecretString != nil {
-		return *resp.SecretString
+		return *resp.SecretString, nil
 	}
 
-	return ""
+	return "", nil
 }
 
-func (smCli *SecretsManagerClient) CreateSecret(name, value string) {
+func (smCli *SecretsManagerClient) CreateSecret(name, value string) (*secretsmanager.CreateSecretOutput, error) {
+	return smCli.CreateSecretWithContext(context.Background(), name, value)
+}
+
+func (smCli *SecretsManagerClient) CreateSecretWithContext(ctx context.Context, name, value string) (*secretsmanager.CreateSecretOutput, error) {
 	input := &secretsmanager.CreateSecretInput{
 		Name:         aws.String(name),
 		SecretString: aws.String(value),
 	}
 
-	_, err := smCli.cli.CreateSecret(input)
+	resp, err := smCli.cli.CreateSecretWithContext(ctx, input)
 	if err != nil {
-		smCli.handleError(err)
+		return nil, smCli.handleError("CreateSecret", err)
 	}
+
+	return resp, nil
+}
+
+func (smCli *SecretsManagerClient) PutSecret(name, value string) (*secretsmanager.PutSecretValueOutput, error) {
+	return smCli.PutSecretWithContext(context.Background(), name, value)
 }
 
-func (smCli *SecretsManagerClient) PutSecret(name, value string) {
+func (smCli *SecretsManagerClient) PutSecretWithContext(ctx context.Context, name, value string) (*secretsmanager.PutSecretValueOutput, error) {
 	input := &secretsmanager.PutSecretValueInput{
 		SecretId:     aws.String(name),
 		SecretString: aws.String(value),
 	}
 
-	_, err := smCli.cli.PutSecretValue(input)
+	resp, err := smCli.cli.PutSecretValueWithContext(ctx, input)
 	if err != nil {
-		smCli.handleError(err)
+		return nil, smCli.handleError("PutSecretValue", err)
 	}
+
+	return resp, nil
+}
+
+func (smCli *SecretsManagerClient) UpdateSecret(name, value string) (*secretsmanager.UpdateSecretOutput, error) {
+	return smCli.UpdateSecretWithContext(context.Background(), name, value)
 }
 
-func (smCli *SecretsManagerClient) UpdateSecret(name, value string) {
+func (smCli *SecretsManagerClient) UpdateSecretWithContext(ctx context.Context, name, value string) (*secretsmanager.UpdateSecretOutput, error) {
 	input := &secretsmanager.UpdateSecretInput{
 		SecretId:     aws.String(name),
 		SecretString: aws.String(value),
 	}
 
-	_, err := smCli.cli.UpdateSecret(input)
+	resp, err := smCli.cli.UpdateSecretWithContext(ctx, input)
 	if err != nil {
-		smCli.handleError(err)
+		return nil, smCli.handleError("UpdateSecret", err)
 	}
+
+	return resp, nil
 }
 
-func (smCli *SecretsManagerClient) ListAllSecrets() []*secretsmanager.SecretListEntry {
+func (smCli *SecretsManagerClient) ListAllSecrets() ([]*secretsmanager.SecretListEntry, error) {
+	return smCli.ListAllSecretsWithContext(context.Background())
+}
+
+func (smCli *SecretsManagerClient) ListAllSecretsWithContext(ctx context.Context) ([]*secretsmanager.SecretListEntry, error) {
 	secrets := []*secretsmanager.SecretListEntry{}
-	input := &secretsmanager.ListSecretsInput{}
-	resp, err := smCli.cli.ListSecrets(input)
-	if err != nil {
-		smCli.handleError(err)
-		return secrets
-	}
 
-	secrets = append(secrets, resp.SecretList...)
+	err := smCli.ListAllSecretsPagesWithContext(ctx, 0, func(page []*secretsmanager.SecretListEntry) bool {
+		secrets = append(secrets, page...)
 
-	for resp.NextToken != nil {
-		input = &secretsmanager.ListSecretsInput{
-			NextToken: resp.NextToken,
-		}
+		return true
+	})
+
+	return secrets, err
+}
+
+func (smCli *SecretsManagerClient) ListAllSecretsPages(pageSize int64, fn func(secrets []*secretsmanager.SecretListEntry) bool) error {
+	return smCli.ListAllSecretsPagesWithContext(context.Background(), pageSize, fn)
+}
 
-		resp, err = smCli.cli.ListSecrets(input)
+func (smCli *SecretsManagerClient) ListAllSecretsPagesWithContext(ctx context.Context, pageSize int64, fn func(secrets []*secretsmanager.SecretListEntry) bool) error {
+	input := &secretsmanager.ListSecretsInput{MaxResults: pageLimit(pageSize, 1, 100)}
+
+	for {
+		resp, err := smCli.cli.ListSecretsWithContext(ctx, input)
 		if err != nil {
-			smCli.handleError(err)
-			return secrets
+			return smCli.handleError("ListSecrets", err)
+		}
+
+		if !fn(resp.SecretList) || resp.NextToken == nil {
+			return nil
 		}
 
-		secrets = append(secrets, resp.SecretList...)
+		input.NextToken = resp.NextToken
 	}
+}
 
-	return secrets
+func (smCli *SecretsManagerClient) DescribeSecret(secretID string) (*secretsmanager.DescribeSecretOutput, error) {
+	return smCli.DescribeSecretWithContext(context.Background(), secretID)
 }
 
-func (smCli *SecretsManagerClient) DescribeSecret(secretID string) *secretsmanager.DescribeSecretOutput {
+func (smCli *SecretsManagerClient) DescribeSecretWithContext(ctx context.Context, secretID string) (*secretsmanager.DescribeSecretOutput, error) {
 	input := &secretsmanager.DescribeSecretInput{
 		SecretId: aws.String(secretID),
 	}
 
-	resp, err := smCli.cli.DescribeSecret(input)
+	resp, err := smCli.cli.DescribeSecretWithContext(ctx, input)
 	if err != nil {
-		smCli.handleError(err)
-
-		return nil
+		return nil, smCli.handleError("DescribeSecret", err)
 	}
 
-	return resp
-}
-
-func (smCli *SecretsManagerClient) handleError(err error) {
-
-	if aerr, ok := err.(awserr.Error); ok {
-		switch aerr.Code() {
-		case secretsmanager.ErrCodeInvalidParameterException:
-			fmt.Println(secretsmanager.ErrCodeInvalidParameterException, aerr.Error())
-		case secretsmanager.ErrCodeInvalidRequestException:
-			fmt.Println(secretsmanager.ErrCodeInvalidRequestException, aerr.Error())
-		case secretsmanager.ErrCodeLimitExceededException:
-			fmt.Println(secretsmanager.ErrCodeLimitExceededException, aerr.Error())
-		case secretsmanager.ErrCodeEncryptionFailure:
-			fmt.Println(secretsmanager.ErrCodeEncryptionFailure, aerr.Error())
-		case secretsmanager.ErrCodeResourceExistsException:
-			fmt.Println(secretsmanager.ErrCodeResourceExistsException, aerr.Error())
-		case secretsmanager.ErrCodeResourceNotFoundException:
-			fmt.Println(secretsmanager.ErrCodeResourceNotFoundException, aerr.Error())
-		case secretsmanager.ErrCodeInternalServiceError:
-			fmt.Println(secretsmanager.ErrCodeInternalServiceError, aerr.Error())
-		default:
-			fmt.Println(aerr.Error())
-		}
-	} else {
-		fmt.Println(err.Error())
-	}
+	return resp, nil
+}
+
+func (smCli *SecretsManagerClient) handleError(operation string, err error) error {
+	return smCli.logError(newError(secretsmanager.ServiceName, operation, err))
 }
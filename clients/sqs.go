@@ -1,90 +1,185 @@
 package clients
 
 import (
-	"fmt"
+	"context"
 
-	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
-	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
+	"github.com/mwlng/aws-go-clients/arn"
 )
 
+type SQSAPI interface {
+	CreateQueue(name string, attributes map[string]*string) (*sqs.CreateQueueOutput, error)
+	CreateQueueWithContext(ctx context.Context, name string, attributes map[string]*string) (*sqs.CreateQueueOutput, error)
+	GetQueueURL(name string) (string, error)
+	GetQueueURLWithContext(ctx context.Context, name string) (string, error)
+	DeleteQueue(queueURL string) (*sqs.DeleteQueueOutput, error)
+	DeleteQueueWithContext(ctx context.Context, queueURL string) (*sqs.DeleteQueueOutput, error)
+	ReceiveMessage(queueURL string, maxMessages, waitTimeSeconds int64) (*sqs.ReceiveMessageOutput, error)
+	ReceiveMessageWithContext(ctx context.Context, queueURL string, maxMessages, waitTimeSeconds int64) (*sqs.ReceiveMessageOutput, error)
+	DeleteMessage(queueURL, receiptHandle string) error
+	DeleteMessageWithContext(ctx context.Context, queueURL, receiptHandle string) error
+	SendMessage(queueURL, body string) (*sqs.SendMessageOutput, error)
+	SendMessageWithContext(ctx context.Context, queueURL, body string) (*sqs.SendMessageOutput, error)
+	SendMessageBatch(queueURL string, entries []*sqs.SendMessageBatchRequestEntry) (*sqs.SendMessageBatchOutput, error)
+	SendMessageBatchWithContext(ctx context.Context, queueURL string, entries []*sqs.SendMessageBatchRequestEntry) (*sqs.SendMessageBatchOutput, error)
+}
+
+var _ SQSAPI = (*SQSClient)(nil)
+
 type SQSClient struct {
-	cli *sqs.SQS
+	logging
+	cli sqsiface.SQSAPI
 }
 
-func NewSQS(sess *session.Session) *SQSClient {
-	client := sqs.New(sess)
+func init() {
+	Register("sqs", func(sess *session.Session) interface{} { return NewSQS(sess) })
+}
+
+func NewSQS(sess *session.Session, cfgs ...*aws.Config) *SQSClient {
+	client := sqs.New(sess, cfgs...)
 
-	return &SQSClient{cli: client}
+	return &SQSClient{logging: newLogging(sess), cli: client}
 }
 
-func (sqsCli *SQSClient) CreateQueue() *sqs.CreateQueueOutput {
-	input := &sqs.CreateQueueInput{}
+func NewSQSFromAPI(api sqsiface.SQSAPI) *SQSClient {
+	return &SQSClient{logging: logging{logger: NopLogger}, cli: api}
+}
 
-	resp, err := sqsCli.cli.CreateQueue(input)
+func (sqsCli *SQSClient) CreateQueue(name string, attributes map[string]*string) (*sqs.CreateQueueOutput, error) {
+	return sqsCli.CreateQueueWithContext(context.Background(), name, attributes)
+}
+
+func (sqsCli *SQSClient) CreateQueueWithContext(ctx context.Context, name string, attributes map[string]*string) (*sqs.CreateQueueOutput, error) {
+	input := &sqs.CreateQueueInput{
+		QueueName:  aws.String(name),
+		Attributes: attributes,
+	}
+
+	resp, err := sqsCli.cli.CreateQueueWithContext(ctx, input)
 	if err != nil {
-		sqsCli.handleError(err)
+		return nil, sqsCli.handleError("CreateQueue", err)
 	}
 
-	return resp
+	return resp, nil
+}
+
+func (sqsCli *SQSClient) GetQueueURL(name string) (string, error) {
+	return sqsCli.GetQueueURLWithContext(context.Background(), name)
 }
 
-func (sqsCli *SQSClient) DeleteQueue() *sqs.DeleteQueueOutput {
-	input := &sqs.DeleteQueueInput{}
+// GetQueueURLWithContext also takes the ARN of a queue, which may belong to
+// another account.
+func (sqsCli *SQSClient) GetQueueURLWithContext(ctx context.Context, name string) (string, error) {
+	input := &sqs.GetQueueUrlInput{
+		QueueName: aws.String(name),
+	}
+
+	if a, err := arn.Parse(name); err == nil && a.Service == sqs.ServiceName {
+		input.QueueName = aws.String(a.Name())
+		input.QueueOwnerAWSAccountId = aws.String(a.AccountID)
+	}
 
-	resp, err := sqsCli.cli.DeleteQueue(input)
+	resp, err := sqsCli.cli.GetQueueUrlWithContext(ctx, input)
 	if err != nil {
-		sqsCli.handleError(err)
+		return "", sqsCli.handleError("GetQueueUrl", err)
 	}
 
-	return resp
+	return aws.StringValue(resp.QueueUrl), nil
 }
 
-func (sqsCli *SQSClient) ReceiveMessage() *sqs.ReceiveMessageOutput {
-	input := &sqs.ReceiveMessageInput{}
+func (sqsCli *SQSClient) DeleteQueue(queueURL string) (*sqs.DeleteQueueOutput, error) {
+	return sqsCli.DeleteQueueWithContext(context.Background(), queueURL)
+}
 
-	resp, err := sqsCli.cli.ReceiveMessage(input)
+func (sqsCli *SQSClient) DeleteQueueWithContext(ctx context.Context, queueURL string) (*sqs.DeleteQueueOutput, error) {
+	input := &sqs.DeleteQueueInput{
+		QueueUrl: aws.String(queueURL),
+	}
+
+	resp, err := sqsCli.cli.DeleteQueueWithContext(ctx, input)
 	if err != nil {
-		sqsCli.handleError(err)
+		return nil, sqsCli.handleError("DeleteQueue", err)
 	}
 
-	return resp
+	return resp, nil
 }
 
-func (sqsCli *SQSClient) SendMessage() *sqs.SendMessageOutput {
-	input := &sqs.SendMessageInput{}
+func (sqsCli *SQSClient) ReceiveMessage(queueURL string, maxMessages, waitTimeSeconds int64) (*sqs.ReceiveMessageOutput, error) {
+	return sqsCli.ReceiveMessageWithContext(context.Background(), queueURL, maxMessages, waitTimeSeconds)
+}
 
-	resp, err := sqsCli.cli.SendMessage(input)
+func (sqsCli *SQSClient) ReceiveMessageWithContext(ctx context.Context, queueURL string, maxMessages, waitTimeSeconds int64) (*sqs.ReceiveMessageOutput, error) {
+	input := &sqs.ReceiveMessageInput{
+		QueueUrl:            aws.String(queueURL),
+		MaxNumberOfMessages: aws.Int64(maxMessages),
+		WaitTimeSeconds:     aws.Int64(waitTimeSeconds),
+	}
+
+	resp, err := sqsCli.cli.ReceiveMessageWithContext(ctx, input)
 	if err != nil {
-		sqsCli.handleError(err)
+		return nil, sqsCli.handleError("ReceiveMessage", err)
 	}
 
-	return resp
+	return resp, nil
 }
 
-func (sqsCli *SQSClient) SendMessageBatch() *sqs.SendMessageBatchOutput {
-	input := &sqs.SendMessageBatchInput{}
+func (sqsCli *SQSClient) DeleteMessage(queueURL, receiptHandle string) error {
+	return sqsCli.DeleteMessageWithContext(context.Background(), queueURL, receiptHandle)
+}
 
-	resp, err := sqsCli.cli.SendMessageBatch(input)
+func (sqsCli *SQSClient) DeleteMessageWithContext(ctx context.Context, queueURL, receiptHandle string) error {
+	input := &sqs.DeleteMessageInput{
+		QueueUrl:      aws.String(queueURL),
+		ReceiptHandle: aws.String(receiptHandle),
+	}
+
+	_, err := sqsCli.cli.DeleteMessageWithContext(ctx, input)
 	if err != nil {
-		sqsCli.handleError(err)
+		return sqsCli.handleError("DeleteMessage", err)
 	}
 
-	return resp
-}
-
-func (sqsCli *SQSClient) handleError(err error) {
-	if aerr, ok := err.(awserr.Error); ok {
-		switch aerr.Code() {
-		case s3.ErrCodeNoSuchBucket:
-			fmt.Println(s3.ErrCodeNoSuchBucket, aerr.Error())
-		default:
-			fmt.Println(aerr.Error())
-		}
-	} else {
-		// Print the error, cast err to awserr.Error to get the Code and
-		// Message from an error.
-		fmt.Println(err.Error())
+	return nil
+}
+
+func (sqsCli *SQSClient) SendMessage(queueURL, body string) (*sqs.SendMessageOutput, error) {
+	return sqsCli.SendMessageWithContext(context.Background(), queueURL, body)
+}
+
+func (sqsCli *SQSClient) SendMessageWithContext(ctx context.Context, queueURL, body string) (*sqs.SendMessageOutput, error) {
+	input := &sqs.SendMessageInput{
+		QueueUrl:    aws.String(queueURL),
+		MessageBody: aws.String(body),
 	}
+
+	resp, err := sqsCli.cli.SendMessageWithContext(ctx, input)
+	if err != nil {
+		return nil, sqsCli.handleError("SendMessage", err)
+	}
+
+	return resp, nil
+}
+
+func (sqsCli *SQSClient) SendMessageBatch(queueURL string, entries []*sqs.SendMessageBatchRequestEntry) (*sqs.SendMessageBatchOutput, error) {
+	return sqsCli.SendMessageBatchWithContext(context.Background(), queueURL, entries)
+}
+
+func (sqsCli *SQSClient) SendMessageBatchWithContext(ctx context.Context, queueURL string, entries []*sqs.SendMessageBatchRequestEntry) (*sqs.SendMessageBatchOutput, error) {
+	input := &sqs.SendMessageBatchInput{
+		QueueUrl: aws.String(queueURL),
+		Entries:  entries,
+	}
+
+	resp, err := sqsCli.cli.SendMessageBatchWithContext(ctx, input)
+	if err != nil {
+		return nil, sqsCli.handleError("SendMessageBatch", err)
+	}
+
+	return resp, nil
+}
+
+func (sqsCli *SQSClient) handleError(operation string, err error) error {
+	return sqsCli.logError(newError(sqs.ServiceName, operation, err))
 }
@@ -1,57 +1,64 @@
 package clients
 
 import (
-	"fmt"
+	"context"
 
 	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
 )
 
+type SSMAPI interface {
+	GetParameter(name string) (string, error)
+	GetParameterWithContext(ctx context.Context, name string) (string, error)
+}
+
+var _ SSMAPI = (*SSMClient)(nil)
+
 type SSMClient struct {
-	cli *ssm.SSM
+	logging
+	*owner
+	cli ssmiface.SSMAPI
+}
+
+func init() {
+	Register("ssm", func(sess *session.Session) interface{} { return NewSSM(sess) })
+}
+
+func NewSSM(sess *session.Session, cfgs ...*aws.Config) *SSMClient {
+	client := ssm.New(sess, cfgs...)
+
+	return &SSMClient{logging: newLogging(sess), owner: newOwner(sess, client.Config), cli: client}
 }
 
-func NewSSM(sess *session.Session) *SSMClient {
-	client := ssm.New(sess)
+func NewSSMFromAPI(api ssmiface.SSMAPI) *SSMClient {
+	return &SSMClient{logging: logging{logger: NopLogger}, owner: &owner{}, cli: api}
+}
 
-	return &SSMClient{cli: client}
+func (ssmCli *SSMClient) GetParameter(name string) (string, error) {
+	return ssmCli.GetParameterWithContext(context.Background(), name)
 }
 
-func (ssmCli *SSMClient) GetParameter(name string) string {
+func (ssmCli *SSMClient) GetParameterWithContext(ctx context.Context, name string) (string, error) {
+	parameterName, err := ssmCli.nameOf(ctx, aws.String(name), "ssm", "parameter")
+	if err != nil {
+		return "", ssmCli.handleError("GetParameter", err)
+	}
+
 	input := &ssm.GetParameterInput{
-		Name:           &name,
+		Name:           parameterName,
 		WithDecryption: aws.Bool(true),
 	}
 
-	resp, err := ssmCli.cli.GetParameter(input)
+	resp, err := ssmCli.cli.GetParameterWithContext(ctx, input)
 	if err != nil {
-		ssmCli.handleError(err)
-
-		return ""
+		return "", ssmCli.handleError("GetParameter", err)
 	}
 
-	return *resp.Parameter.Value
-}
-
-func (ssmCli *SSMClient) handleError(err error) {
-	if aerr, ok := err.(awserr.Error); ok {
-		switch aerr.Code() {
-		case ssm.ErrCodeInternalServerError:
-			fmt.Println(ssm.ErrCodeInternalServerError, aerr.Error())
-		case ssm.ErrCodeInvalidKeyId:
-			fmt.Println(ssm.ErrCodeInvalidKeyId, aerr.Error())
-		case ssm.ErrCodeParameterNotFound:
-			fmt.Println(ssm.ErrCodeParameterNotFound, aerr.Error())
-		case ssm.ErrCodeParameterVersionNotFound:
-			fmt.Println(ssm.ErrCodeParameterVersionNotFound, aerr.Error())
-		default:
-			fmt.Println(aerr.Error())
-		}
-	} else {
-		// Print the error, cast err to awserr.Error to get the Code and
-		// Message from an error.
-		fmt.Println(err.Error())
-	}
+	return aws.StringValue(resp.Parameter.Value), nil
+}
+
+func (ssmCli *SSMClient) handleError(operation string, err error) error {
+	return ssmCli.logError(newError(ssm.ServiceName, operation, err))
 }
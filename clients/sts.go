@@ -1,87 +1,125 @@
 package clients
 
 import (
-	"fmt"
+	"context"
 
-	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/aws/aws-sdk-go/service/sts/stsiface"
 )
 
+type STSAPI interface {
+	GetCallerID() (string, string, string, error)
+	GetCallerIDWithContext(ctx context.Context) (string, string, string, error)
+	GetSessionCredsWithoutMfa(duration *int64) (*sts.Credentials, error)
+	GetSessionCredsWithoutMfaWithContext(ctx context.Context, duration *int64) (*sts.Credentials, error)
+	GetSessionCredsWithMfa(mfaSN *string, tokenCode *string, duration *int64) (*sts.Credentials, error)
+	GetSessionCredsWithMfaWithContext(ctx context.Context, mfaSN *string, tokenCode *string, duration *int64) (*sts.Credentials, error)
+	AssumeRoleWithoutMfa(roleArn *string, duration *int64, roleSessName *string) (*sts.Credentials, error)
+	AssumeRoleWithoutMfaWithContext(ctx context.Context, roleArn *string, duration *int64, roleSessName *string) (*sts.Credentials, error)
+	AssumeRoleWithMfa(roleArn *string, duration *int64, roleSessName *string, mfaSN *string, tokenCode *string) (*sts.Credentials, error)
+	AssumeRoleWithMfaWithContext(ctx context.Context, roleArn *string, duration *int64, roleSessName *string, mfaSN *string, tokenCode *string) (*sts.Credentials, error)
+}
+
+var _ STSAPI = (*STSClient)(nil)
+
 type STSClient struct {
-	cli *sts.STS
+	logging
+	cli stsiface.STSAPI
 }
 
-func NewSTS(sess *session.Session) *STSClient {
-	client := sts.New(sess)
+func init() {
+	Register("sts", func(sess *session.Session) interface{} { return NewSTS(sess) })
+}
+
+func NewSTS(sess *session.Session, cfgs ...*aws.Config) *STSClient {
+	client := sts.New(sess, cfgs...)
+
+	return &STSClient{logging: newLogging(sess), cli: client}
+}
 
-	return &STSClient{cli: client}
+func NewSTSFromAPI(api stsiface.STSAPI) *STSClient {
+	return &STSClient{logging: logging{logger: NopLogger}, cli: api}
 }
 
-func (stsCli *STSClient) GetCallerID() (string, string, string) {
+func (stsCli *STSClient) GetCallerID() (string, string, string, error) {
+	return stsCli.GetCallerIDWithContext(context.Background())
+}
+
+func (stsCli *STSClient) GetCallerIDWithContext(ctx context.Context) (string, string, string, error) {
 	input := &sts.GetCallerIdentityInput{}
 
-	resp, err := stsCli.cli.GetCallerIdentity(input)
+	resp, err := stsCli.cli.GetCallerIdentityWithContext(ctx, input)
 	if err != nil {
-		stsCli.handleError(err)
-
-		return "", "", ""
+		return "", "", "", stsCli.handleError("GetCallerIdentity", err)
 	}
 
-	return *resp.Account, *resp.UserId, *resp.Arn
+	return aws.StringValue(resp.Account), aws.StringValue(resp.UserId), aws.StringValue(resp.Arn), nil
 }
 
-func (stsCli *STSClient) GetSessionCredsWithoutMfa(duration *int64) *sts.Credentials {
+func (stsCli *STSClient) GetSessionCredsWithoutMfa(duration *int64) (*sts.Credentials, error) {
+	return stsCli.GetSessionCredsWithoutMfaWithContext(context.Background(), duration)
+}
+
+func (stsCli *STSClient) GetSessionCredsWithoutMfaWithContext(ctx context.Context, duration *int64) (*sts.Credentials, error) {
 	input := &sts.GetSessionTokenInput{
 		DurationSeconds: duration,
 	}
 
-	resp, err := stsCli.cli.GetSessionToken(input)
+	resp, err := stsCli.cli.GetSessionTokenWithContext(ctx, input)
 	if err != nil {
-		stsCli.handleError(err)
-
-		return nil
+		return nil, stsCli.handleError("GetSessionToken", err)
 	}
 
-	return resp.Credentials
+	return resp.Credentials, nil
 }
 
-func (stsCli *STSClient) GetSessionCredsWithMfa(mfaSN *string, tokenCode *string, duration *int64) *sts.Credentials {
+func (stsCli *STSClient) GetSessionCredsWithMfa(mfaSN *string, tokenCode *string, duration *int64) (*sts.Credentials, error) {
+	return stsCli.GetSessionCredsWithMfaWithContext(context.Background(), mfaSN, tokenCode, duration)
+}
+
+func (stsCli *STSClient) GetSessionCredsWithMfaWithContext(ctx context.Context, mfaSN *string, tokenCode *string, duration *int64) (*sts.Credentials, error) {
 	input := &sts.GetSessionTokenInput{
 		SerialNumber:    mfaSN,
 		TokenCode:       tokenCode,
 		DurationSeconds: duration,
 	}
 
-	resp, err := stsCli.cli.GetSessionToken(input)
+	resp, err := stsCli.cli.GetSessionTokenWithContext(ctx, input)
 	if err != nil {
-		stsCli.handleError(err)
-
-		return nil
+		return nil, stsCli.handleError("GetSessionToken", err)
 	}
 
-	return resp.Credentials
+	return resp.Credentials, nil
 }
 
-func (stsCli *STSClient) AssumeRoleWithoutMfa(roleArn *string, duration *int64, roleSessName *string) *sts.Credentials {
+func (stsCli *STSClient) AssumeRoleWithoutMfa(roleArn *string, duration *int64, roleSessName *string) (*sts.Credentials, error) {
+	return stsCli.AssumeRoleWithoutMfaWithContext(context.Background(), roleArn, duration, roleSessName)
+}
+
+func (stsCli *STSClient) AssumeRoleWithoutMfaWithContext(ctx context.Context, roleArn *string, duration *int64, roleSessName *string) (*sts.Credentials, error) {
 	input := &sts.AssumeRoleInput{
 		RoleArn:         roleArn,
 		DurationSeconds: duration,
 		RoleSessionName: roleSessName,
 	}
 
-	resp, err := stsCli.cli.AssumeRole(input)
+	resp, err := stsCli.cli.AssumeRoleWithContext(ctx, input)
 	if err != nil {
-		stsCli.handleError(err)
-
-		return nil
+		return nil, stsCli.handleError("AssumeRole", err)
 	}
 
-	return resp.Credentials
+	return resp.Credentials, nil
 }
 
 func (stsCli *STSClient) AssumeRoleWithMfa(roleArn *string, duration *int64, roleSessName *string,
-	mfaSN *string, tokenCode *string) *sts.Credentials {
+	mfaSN *string, tokenCode *string) (*sts.Credentials, error) {
+	return stsCli.AssumeRoleWithMfaWithContext(context.Background(), roleArn, duration, roleSessName, mfaSN, tokenCode)
+}
+
+func (stsCli *STSClient) AssumeRoleWithMfaWithContext(ctx context.Context, roleArn *string, duration *int64, roleSessName *string,
+	mfaSN *string, tokenCode *string) (*sts.Credentials, error) {
 	input := &sts.AssumeRoleInput{
 		RoleArn:         roleArn,
 		DurationSeconds: duration,
@@ -90,27 +128,14 @@ func (stsCli *STSClient) AssumeRoleWithMfa(roleArn *string, duration *int64, rol
 		TokenCode:       tokenCode,
 	}
 
-	resp, err := stsCli.cli.AssumeRole(input)
+	resp, err := stsCli.cli.AssumeRoleWithContext(ctx, input)
 	if err != nil {
-		stsCli.handleError(err)
-
-		return nil
+		return nil, stsCli.handleError("AssumeRole", err)
 	}
 
-	return resp.Credentials
-}
-
-func (stsCli *STSClient) handleError(err error) {
-	if aerr, ok := err.(awserr.Error); ok {
-		switch aerr.Code() {
-		case sts.ErrCodeRegionDisabledException:
-			fmt.Println(sts.ErrCodeRegionDisabledException, aerr.Error())
-		default:
-			fmt.Println(aerr.Error())
-		}
-	} else {
-		// Print the error, cast err to awserr.Error to get the Code and
-		// Message from an error.
-		fmt.Println(err.Error())
-	}
+	return resp.Credentials, nil
+}
+
+func (stsCli *STSClient) handleError(operation string, err error) error {
+	return stsCli.logError(newError(sts.ServiceName, operation, err))
 }
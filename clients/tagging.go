@@ -0,0 +1,88 @@
+package clients
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/resourcegroupstaggingapi"
+	"github.com/aws/aws-sdk-go/service/resourcegroupstaggingapi/resourcegroupstaggingapiiface"
+)
+
+type TaggingAPI interface {
+	GetResources(resourceTypes []string, tagFilters []*resourcegroupstaggingapi.TagFilter) ([]*resourcegroupstaggingapi.ResourceTagMapping, error)
+	GetResourcesWithContext(ctx context.Context, resourceTypes []string, tagFilters []*resourcegroupstaggingapi.TagFilter) ([]*resourcegroupstaggingapi.ResourceTagMapping, error)
+	GetResourcesPages(resourceTypes []string, tagFilters []*resourcegroupstaggingapi.TagFilter, pageSize int64, fn func(mappings []*resourcegroupstaggingapi.ResourceTagMapping) bool) error
+	GetResourcesPagesWithContext(ctx context.Context, resourceTypes []string, tagFilters []*resourcegroupstaggingapi.TagFilter, pageSize int64, fn func(mappings []*resourcegroupstaggingapi.ResourceTagMapping) bool) error
+}
+
+var _ TaggingAPI = (*TaggingClient)(nil)
+
+// TaggingClient wraps the Resource Groups Tagging API, which lists the
+// tagged resources of every service at once.
+type TaggingClient struct {
+	logging
+	cli resourcegroupstaggingapiiface.ResourceGroupsTaggingAPIAPI
+}
+
+func init() {
+	Register("tagging", func(sess *session.Session) interface{} { return NewTagging(sess) })
+}
+
+func NewTagging(sess *session.Session, cfgs ...*aws.Config) *TaggingClient {
+	client := resourcegroupstaggingapi.New(sess, cfgs...)
+
+	return &TaggingClient{logging: newLogging(sess), cli: client}
+}
+
+func NewTaggingFromAPI(api resourcegroupstaggingapiiface.ResourceGroupsTaggingAPIAPI) *TaggingClient {
+	return &TaggingClient{logging: logging{logger: NopLogger}, cli: api}
+}
+
+func (taggingCli *TaggingClient) GetResources(resourceTypes []string, tagFilters []*resourcegroupstaggingapi.TagFilter) ([]*resourcegroupstaggingapi.ResourceTagMapping, error) {
+	return taggingCli.GetResourcesWithContext(context.Background(), resourceTypes, tagFilters)
+}
+
+func (taggingCli *TaggingClient) GetResourcesWithContext(ctx context.Context, resourceTypes []string, tagFilters []*resourcegroupstaggingapi.TagFilter) ([]*resourcegroupstaggingapi.ResourceTagMapping, error) {
+	mappings := []*resourcegroupstaggingapi.ResourceTagMapping{}
+
+	err := taggingCli.GetResourcesPagesWithContext(ctx, resourceTypes, tagFilters, 0, func(page []*resourcegroupstaggingapi.ResourceTagMapping) bool {
+		mappings = append(mappings, page...)
+
+		return true
+	})
+
+	return mappings, err
+}
+
+func (taggingCli *TaggingClient) GetResourcesPages(resourceTypes []string, tagFilters []*resourcegroupstaggingapi.TagFilter, pageSize int64, fn func(mappings []*resourcegroupstaggingapi.ResourceTagMapping) bool) error {
+	return taggingCli.GetResourcesPagesWithContext(context.Background(), resourceTypes, tagFilters, pageSize, fn)
+}
+
+// GetResourcesPagesWithContext lists the resources of resourceTypes, such as
+// "ec2:instance" or "s3", that match tagFilters. Resources that never had a
+// tag are not returned.
+func (taggingCli *TaggingClient) GetResourcesPagesWithContext(ctx context.Context, resourceTypes []string, tagFilters []*resourcegroupstaggingapi.TagFilter, pageSize int64, fn func(mappings []*resourcegroupstaggingapi.ResourceTagMapping) bool) error {
+	input := &resourcegroupstaggingapi.GetResourcesInput{
+		ResourceTypeFilters: aws.StringSlice(resourceTypes),
+		TagFilters:          tagFilters,
+		ResourcesPerPage:    pageLimit(pageSize, 1, 100),
+	}
+
+	for {
+		resp, err := taggingCli.cli.GetResourcesWithContext(ctx, input)
+		if err != nil {
+			return taggingCli.handleError("GetResources", err)
+		}
+
+		if !fn(resp.ResourceTagMappingList) || aws.StringValue(resp.PaginationToken) == "" {
+			return nil
+		}
+
+		input.PaginationToken = resp.PaginationToken
+	}
+}
+
+func (taggingCli *TaggingClient) handleError(operation string, err error) error {
+	return taggingCli.logError(newError(resourcegroupstaggingapi.ServiceName, operation, err))
+}
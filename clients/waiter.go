@@ -0,0 +1,243 @@
+package clients
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/mwlng/aws-go-clients/service"
+)
+
+// Waiter polls a resource until it reaches one of the Success or Failure
+// states. The WaitFor methods of the clients are built on it; callers may
+// build their own:
+//
+//	w := &clients.Waiter{
+//		Resource: "Glue crawler " + name,
+//		State: func(ctx context.Context) (string, error) {
+//			out, err := api.GetCrawlerWithContext(ctx, &glue.GetCrawlerInput{Name: aws.String(name)})
+//			if err != nil {
+//				return "", err
+//			}
+//			return aws.StringValue(out.Crawler.State), nil
+//		},
+//		Success:     []string{glue.CrawlerStateReady},
+//		Retry:       []string{glue.CrawlerStateRunning, glue.CrawlerStateStopping},
+//		WaitOptions: clients.WaitOptions{Timeout: time.Hour},
+//	}
+//	state, err := w.Wait(ctx)
+type Waiter struct {
+	// Resource names what is waited for in errors and progress reports,
+	// such as "RDS cluster snapshot nightly".
+	Resource string
+	// State polls the resource. Retryable errors, such as throttling, are
+	// polled again after the next delay; other errors end the wait.
+	State func(ctx context.Context) (string, error)
+	// Success and Failure states end the wait. When Retry is empty, every
+	// other state is waited out; otherwise states in none of the three end
+	// the wait with ErrUnexpectedState.
+	Success []string
+	Failure []string
+	Retry   []string
+	WaitOptions
+}
+
+// WaitOptions tune how long and how often a Waiter polls. The zero fields
+// of the options passed to the WaitFor methods keep their defaults.
+type WaitOptions struct {
+	// Timeout bounds the whole wait; zero waits as long as the context.
+	Timeout time.Duration
+	Backoff Backoff
+	// Progress is called with the first state polled and on every change
+	// of state.
+	Progress func(WaitProgress)
+}
+
+// Backoff spaces the polls of a Waiter: the first poll is followed by
+// Delay, and every later delay is Multiplier times the previous one, up to
+// MaxDelay. A Multiplier of zero keeps the delay constant.
+type Backoff struct {
+	Delay      time.Duration
+	MaxDelay   time.Duration
+	Multiplier float64
+}
+
+// WaitProgress reports a change of state to WaitOptions.Progress.
+type WaitProgress struct {
+	Resource string
+	State    string
+	// Previous is empty for the first state polled.
+	Previous string
+	Attempt  int
+	Elapsed  time.Duration
+}
+
+// StateNotFound is the state the WaitFor methods report for resources that
+// do not exist.
+const StateNotFound = "not-found"
+
+const defaultWaitDelay = 5 * time.Second
+
+var (
+	ErrWaitTimeout     = errors.New("timed out")
+	ErrFailureState    = errors.New("reached a failure state")
+	ErrUnexpectedState = errors.New("reached an unexpected state")
+)
+
+// WaitError is returned by waits that do not end in a success state. Err is
+// ErrWaitTimeout, ErrFailureState, ErrUnexpectedState, the error of the
+// context, or the error of the last poll.
+type WaitError struct {
+	Resource string
+	// State is the last state polled, empty if none was.
+	State    string
+	Attempts int
+	Err      error
+}
+
+func (e *WaitError) Error() string {
+	msg := fmt.Sprintf("clients: waiting for %s: %v", e.Resource, e.Err)
+	if e.State != "" {
+		msg += fmt.Sprintf(" (state %s)", e.State)
+	}
+
+	return msg
+}
+
+func (e *WaitError) Unwrap() error {
+	return e.Err
+}
+
+// Wait polls the resource until it reaches a Success state, which it
+// returns, or until the wait fails with a *WaitError. The polls bypass the
+// ResponseCache of the session, whose responses would hide the changes
+// waited for.
+func (w *Waiter) Wait(ctx context.Context) (string, error) {
+	parent := ctx
+	ctx = service.WithoutResponseCache(ctx)
+
+	if w.Timeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, w.Timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	delay := w.Backoff.Delay
+
+	if delay <= 0 {
+		delay = defaultWaitDelay
+	}
+
+	var state string
+
+	for attempt := 1; ; attempt++ {
+		current, err := w.State(ctx)
+
+		switch {
+		case err == nil:
+			if (attempt == 1 || current != state) && w.Progress != nil {
+				w.Progress(WaitProgress{
+					Resource: w.Resource,
+					State:    current,
+					Previous: state,
+					Attempt:  attempt,
+					Elapsed:  time.Since(start),
+				})
+			}
+
+			state = current
+
+			switch {
+			case contains(w.Success, state):
+				return state, nil
+			case contains(w.Failure, state):
+				return state, w.fail(state, attempt, ErrFailureState)
+			case len(w.Retry) > 0 && !contains(w.Retry, state):
+				return state, w.fail(state, attempt, ErrUnexpectedState)
+			}
+		case ctx.Err() != nil:
+			// The poll was cut short by the end of the wait.
+		case !IsRetryable(err):
+			return state, w.fail(state, attempt, err)
+		}
+
+		timer := time.NewTimer(delay)
+
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+
+			err := ctx.Err()
+			if parent.Err() == nil {
+				err = ErrWaitTimeout
+			}
+
+			return state, w.fail(state, attempt, err)
+		}
+
+		delay = w.Backoff.next(delay)
+	}
+}
+
+func (w *Waiter) fail(state string, attempts int, err error) error {
+	return &WaitError{Resource: w.Resource, State: state, Attempts: attempts, Err: err}
+}
+
+func (b Backoff) next(delay time.Duration) time.Duration {
+	if b.Multiplier > 0 {
+		delay = time.Duration(float64(delay) * b.Multiplier)
+	}
+
+	if b.MaxDelay > 0 && delay > b.MaxDelay {
+		delay = b.MaxDelay
+	}
+
+	return delay
+}
+
+// withDefaults fills the zero fields of opts from defaults.
+func (opts WaitOptions) withDefaults(defaults WaitOptions) WaitOptions {
+	if opts.Timeout == 0 {
+		opts.Timeout = defaults.Timeout
+	}
+
+	if opts.Backoff.Delay == 0 {
+		opts.Backoff.Delay = defaults.Backoff.Delay
+	}
+
+	if opts.Backoff.MaxDelay == 0 {
+		opts.Backoff.MaxDelay = defaults.Backoff.MaxDelay
+	}
+
+	if opts.Backoff.Multiplier == 0 {
+		opts.Backoff.Multiplier = defaults.Backoff.Multiplier
+	}
+
+	return opts
+}
+
+// resourceState returns the state of a described resource, and
+// StateNotFound when the description came back empty or failed because the
+// resource does not exist.
+func resourceState(found bool, state *string, err error) (string, error) {
+	if IsNotFound(err) || (err == nil && !found) {
+		return StateNotFound, nil
+	}
+
+	return aws.StringValue(state), err
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,164 @@
+package clients_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mwlng/aws-go-clients/clients"
+	"github.com/mwlng/aws-go-clients/service"
+)
+
+// states returns a State polling the states in turn, then the last one.
+func states(polled ...string) func(ctx context.Context) (string, error) {
+	i := 0
+
+	return func(ctx context.Context) (string, error) {
+		state := polled[i]
+		if i < len(polled)-1 {
+			i++
+		}
+
+		return state, nil
+	}
+}
+
+var fastPolls = clients.WaitOptions{Timeout: 5 * time.Second, Backoff: clients.Backoff{Delay: time.Millisecond}}
+
+func TestWaiterSuccess(t *testing.T) {
+	var progress []clients.WaitProgress
+
+	opts := fastPolls
+	opts.Progress = func(p clients.WaitProgress) { progress = append(progress, p) }
+
+	w := &clients.Waiter{
+		Resource:    "test",
+		State:       states("creating", "creating", "available"),
+		Success:     []string{"available"},
+		Failure:     []string{"failed"},
+		WaitOptions: opts,
+	}
+
+	state, err := w.Wait(context.Background())
+	if err != nil || state != "available" {
+		t.Fatalf("Wait = %q, %v; want available", state, err)
+	}
+
+	if len(progress) != 2 || progress[0].State != "creating" || progress[1].Previous != "creating" || progress[1].Attempt != 3 {
+		t.Errorf("progress = %+v, want the first state and the change at attempt 3", progress)
+	}
+}
+
+func TestWaiterFailureState(t *testing.T) {
+	w := &clients.Waiter{
+		Resource:    "test",
+		State:       states("creating", "failed"),
+		Success:     []string{"available"},
+		Failure:     []string{"failed"},
+		WaitOptions: fastPolls,
+	}
+
+	state, err := w.Wait(context.Background())
+
+	var werr *clients.WaitError
+	if !errors.As(err, &werr) || !errors.Is(err, clients.ErrFailureState) {
+		t.Fatalf("Wait error = %v, want a *WaitError for ErrFailureState", err)
+	}
+
+	if state != "failed" || werr.State != "failed" || werr.Attempts != 2 {
+		t.Errorf("Wait = %q, %+v; want state failed after 2 attempts", state, werr)
+	}
+}
+
+func TestWaiterUnexpectedState(t *testing.T) {
+	w := &clients.Waiter{
+		Resource:    "test",
+		State:       states("creating", "deleting"),
+		Success:     []string{"available"},
+		Retry:       []string{"creating"},
+		WaitOptions: fastPolls,
+	}
+
+	if _, err := w.Wait(context.Background()); !errors.Is(err, clients.ErrUnexpectedState) {
+		t.Errorf("Wait error = %v, want ErrUnexpectedState", err)
+	}
+}
+
+func TestWaiterTimeout(t *testing.T) {
+	w := &clients.Waiter{
+		Resource: "test",
+		State:    states("creating"),
+		Success:  []string{"available"},
+		WaitOptions: clients.WaitOptions{
+			Timeout: 20 * time.Millisecond,
+			Backoff: clients.Backoff{Delay: time.Millisecond},
+		},
+	}
+
+	state, err := w.Wait(context.Background())
+	if !errors.Is(err, clients.ErrWaitTimeout) || state != "creating" {
+		t.Errorf("Wait = %q, %v; want ErrWaitTimeout in state creating", state, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	w.Timeout = time.Minute
+	if _, err := w.Wait(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Wait with a canceled context = %v, want context.Canceled", err)
+	}
+}
+
+func TestWaiterBypassesResponseCache(t *testing.T) {
+	var calls int32
+
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status := "creating"
+		if atomic.AddInt32(&calls, 1) > 2 {
+			status = "available"
+		}
+
+		w.Header().Set("Content-Type", "text/xml")
+		w.Write([]byte(`<DescribeDBInstancesResponse><DescribeDBInstancesResult><DBInstances><DBInstance>` +
+			`<DBInstanceIdentifier>db</DBInstanceIdentifier><DBInstanceStatus>` + status + `</DBInstanceStatus>` +
+			`</DBInstance></DBInstances></DescribeDBInstancesResult>` +
+			`<ResponseMetadata><RequestId>r</RequestId></ResponseMetadata></DescribeDBInstancesResponse>`))
+	}))
+	defer api.Close()
+
+	cache := service.NewResponseCache(nil, time.Hour)
+	cs := clients.NewClientSet(&service.Service{
+		Region:        "us-east-1",
+		AccessKey:     "AKID",
+		SecretKey:     "SECRET",
+		Endpoint:      api.URL,
+		ResponseCache: cache,
+	})
+
+	if _, err := cs.RDS().DescribeDBInstance("db"); err != nil {
+		t.Fatal(err)
+	}
+
+	instance, err := cs.RDS().WaitForDBInstanceAvailable("db", fastPolls)
+	if err != nil {
+		t.Fatalf("WaitForDBInstanceAvailable: %v", err)
+	}
+
+	if status := *instance.DBInstanceStatus; status != "available" {
+		t.Errorf("status = %s, want available", status)
+	}
+
+	// The polls refreshed the cache for the callers that still use it.
+	instance, err = cs.RDS().DescribeDBInstance("db")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if status, n := *instance.DBInstanceStatus, atomic.LoadInt32(&calls); status != "available" || n != 3 {
+		t.Errorf("cached status = %s after %d calls, want available after 3", status, n)
+	}
+}
@@ -0,0 +1,332 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/mwlng/aws-go-clients/clients"
+	"github.com/mwlng/aws-go-clients/inventory"
+	"github.com/mwlng/aws-go-clients/tagsearch"
+)
+
+type command struct {
+	// name is the service and command, such as "ec2 instances".
+	name    string
+	args    string
+	summary string
+	// local commands run without a session, and get a nil ClientSet.
+	local bool
+	// partial commands may return a result worth printing with an error.
+	partial bool
+	run     func(ctx context.Context, cs *clients.ClientSet, args []string) (interface{}, error)
+}
+
+var commands = []command{
+	{name: "ec2 instances", summary: "list the EC2 instances", run: ec2Instances},
+	{name: "s3 ls", args: "[bucket[/prefix]]", summary: "list the buckets, or the objects and prefixes under prefix", run: s3List},
+	{name: "ecs clusters", summary: "list the ECS clusters", run: ecsClusters},
+	{name: "ecs services", args: "<cluster>", summary: "list the services of an ECS cluster", run: ecsServices},
+	{name: "rds snapshots", args: "[--cluster id] [--type type]", summary: "list the DB cluster snapshots, manual ones by default", run: rdsSnapshots},
+	{name: "r53 zones", summary: "list the Route 53 hosted zones", run: r53Zones},
+	{name: "r53 records", args: "<zone-id>", summary: "list the records of a hosted zone", run: r53Records},
+	{name: "secrets get", args: "<name>", summary: "print the value of a Secrets Manager secret", run: secretsGet},
+	{name: "ssm get", args: "<name>", summary: "print the decrypted value of an SSM parameter", run: ssmGet},
+	{name: "iam roles", summary: "list the IAM roles", run: iamRoles},
+	{name: "sts whoami", summary: "print the account, user ID and ARN of the credentials", run: stsWhoami},
+	{name: "inventory snapshot", args: "[--types type,...]", summary: "print a JSON snapshot of the resources of the account and region", partial: true, run: inventorySnapshot},
+	{name: "inventory diff", args: "<before> <after>", summary: "print the resources added, removed and changed between two snapshots", local: true, run: inventoryDiff},
+	{name: "tags find", args: "[--services service,...] [--source auto|tagging|services] <query>", summary: "list the resources whose tags match a query such as 'team=payments OR !cost-center'", partial: true, run: tagsFind},
+}
+
+// lookup finds the command named by the first two arguments and returns it
+// with the remaining ones.
+func lookup(args []string) (*command, []string, error) {
+	if len(args) < 2 {
+		return nil, nil, usagef("missing command")
+	}
+
+	name := args[0] + " " + args[1]
+
+	for i := range commands {
+		if commands[i].name == name {
+			return &commands[i], args[2:], nil
+		}
+	}
+
+	return nil, nil, usagef("unknown command %q", name)
+}
+
+func exactArgs(args []string, n int, usage string) error {
+	if len(args) != n {
+		return usagef("usage: %s", usage)
+	}
+
+	return nil
+}
+
+func ec2Instances(ctx context.Context, cs *clients.ClientSet, args []string) (interface{}, error) {
+	if err := exactArgs(args, 0, "ec2 instances"); err != nil {
+		return nil, err
+	}
+
+	return cs.EC2().ListAllInstancesWithContext(ctx)
+}
+
+func s3List(ctx context.Context, cs *clients.ClientSet, args []string) (interface{}, error) {
+	if len(args) > 1 {
+		return nil, usagef("usage: s3 ls [bucket[/prefix]]")
+	}
+
+	if len(args) == 0 {
+		resp, err := cs.S3().ListBucketsWithContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		return resp.Buckets, nil
+	}
+
+	path := strings.TrimPrefix(args[0], "s3://")
+	bucket, prefix := path, ""
+
+	if i := strings.Index(path, "/"); i >= 0 {
+		bucket, prefix = path[:i], path[i+1:]
+	}
+
+	prefixes, objects, err := cs.S3().ListDirectoryWithContext(ctx, aws.String(bucket), aws.String(prefix))
+	if err != nil {
+		return nil, err
+	}
+
+	// Prefixes are listed first, as objects with only a key, so a single
+	// table shows both.
+	listing := make([]*s3.Object, 0, len(prefixes)+len(objects))
+
+	for _, p := range prefixes {
+		listing = append(listing, &s3.Object{Key: p.Prefix})
+	}
+
+	return append(listing, objects...), nil
+}
+
+func ecsClusters(ctx context.Context, cs *clients.ClientSet, args []string) (interface{}, error) {
+	if err := exactArgs(args, 0, "ecs clusters"); err != nil {
+		return nil, err
+	}
+
+	return cs.ECS().ListClustersWithContext(ctx)
+}
+
+func ecsServices(ctx context.Context, cs *clients.ClientSet, args []string) (interface{}, error) {
+	if err := exactArgs(args, 1, "ecs services <cluster>"); err != nil {
+		return nil, err
+	}
+
+	return cs.ECS().ListServicesByClusterWithContext(ctx, aws.String(args[0]))
+}
+
+func rdsSnapshots(ctx context.Context, cs *clients.ClientSet, args []string) (interface{}, error) {
+	fs := flag.NewFlagSet("rds snapshots", flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	cluster := fs.String("cluster", "", "")
+	snapshotType := fs.String("type", "manual", "")
+
+	if err := fs.Parse(args); err != nil || fs.NArg() != 0 {
+		return nil, usagef("usage: rds snapshots [--cluster id] [--type automated|manual|shared|public|awsbackup]")
+	}
+
+	if *cluster == "" {
+		return cs.RDS().ListAllDBClusterSnapshotsWithContext(ctx, *snapshotType)
+	}
+
+	return cs.RDS().ListDBClusterSnapshotsWithContext(ctx, *cluster, *snapshotType)
+}
+
+func r53Zones(ctx context.Context, cs *clients.ClientSet, args []string) (interface{}, error) {
+	if err := exactArgs(args, 0, "r53 zones"); err != nil {
+		return nil, err
+	}
+
+	return cs.R53().ListHostedZonesWithContext(ctx)
+}
+
+func r53Records(ctx context.Context, cs *clients.ClientSet, args []string) (interface{}, error) {
+	if err := exactArgs(args, 1, "r53 records <zone-id>"); err != nil {
+		return nil, err
+	}
+
+	// Accept the IDs printed by "r53 zones", which carry a prefix.
+	zoneID := strings.TrimPrefix(args[0], "/hostedzone/")
+
+	return cs.R53().ListResourceRecordSetsWithContext(ctx, aws.String(zoneID))
+}
+
+func secretsGet(ctx context.Context, cs *clients.ClientSet, args []string) (interface{}, error) {
+	if err := exactArgs(args, 1, "secrets get <name>"); err != nil {
+		return nil, err
+	}
+
+	return cs.SecretsManager().GetSecretWithContext(ctx, args[0])
+}
+
+func ssmGet(ctx context.Context, cs *clients.ClientSet, args []string) (interface{}, error) {
+	if err := exactArgs(args, 1, "ssm get <name>"); err != nil {
+		return nil, err
+	}
+
+	return cs.SSM().GetParameterWithContext(ctx, args[0])
+}
+
+func iamRoles(ctx context.Context, cs *clients.ClientSet, args []string) (interface{}, error) {
+	if err := exactArgs(args, 0, "iam roles"); err != nil {
+		return nil, err
+	}
+
+	return cs.IAM().ListRolesWithContext(ctx)
+}
+
+// callerIdentity names its fields after sts.GetCallerIdentityOutput, for
+// the --columns of the shell scripts written against the AWS CLI.
+type callerIdentity struct {
+	Account string
+	UserId  string //nolint:golint,stylecheck
+	Arn     string
+}
+
+func stsWhoami(ctx context.Context, cs *clients.ClientSet, args []string) (interface{}, error) {
+	if err := exactArgs(args, 0, "sts whoami"); err != nil {
+		return nil, err
+	}
+
+	account, userID, arn, err := cs.STS().GetCallerIDWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return callerIdentity{Account: account, UserId: userID, Arn: arn}, nil
+}
+
+func inventorySnapshot(ctx context.Context, cs *clients.ClientSet, args []string) (interface{}, error) {
+	fs := flag.NewFlagSet("inventory snapshot", flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	types := fs.String("types", "", "")
+
+	if err := fs.Parse(args); err != nil || fs.NArg() != 0 {
+		return nil, usagef("usage: inventory snapshot [--types %s]", strings.Join(inventory.Types(), ","))
+	}
+
+	opts := inventory.Options{}
+	if *types != "" {
+		opts.Types = strings.Split(*types, ",")
+	}
+
+	for _, typ := range opts.Types {
+		if !contains(inventory.Types(), typ) {
+			return nil, usagef("unknown resource type %q; known types: %s", typ, strings.Join(inventory.Types(), ","))
+		}
+	}
+
+	snap, err := inventory.Collect(ctx, cs, opts)
+	if snap == nil {
+		return nil, err
+	}
+
+	data, jerr := snap.JSON()
+	if jerr != nil {
+		return nil, jerr
+	}
+
+	return append(data, '\n'), err
+}
+
+func inventoryDiff(ctx context.Context, cs *clients.ClientSet, args []string) (interface{}, error) {
+	if err := exactArgs(args, 2, "inventory diff <before> <after>"); err != nil {
+		return nil, err
+	}
+
+	before, err := inventory.Load(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	after, err := inventory.Load(args[1])
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.MarshalIndent(inventory.Compare(before, after), "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append(data, '\n'), nil
+}
+
+var tagSources = map[string]tagsearch.Source{
+	"auto":     tagsearch.Auto,
+	"tagging":  tagsearch.TaggingAPI,
+	"services": tagsearch.ServiceAPIs,
+}
+
+func tagsFind(ctx context.Context, cs *clients.ClientSet, args []string) (interface{}, error) {
+	fs := flag.NewFlagSet("tags find", flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	services := fs.String("services", "", "")
+	source := fs.String("source", "auto", "")
+
+	if err := fs.Parse(args); err != nil || fs.NArg() != 1 {
+		return nil, usagef("usage: tags find [--services %s] [--source auto|tagging|services] <query>", strings.Join(tagsearch.Services, ","))
+	}
+
+	query, err := tagsearch.Parse(fs.Arg(0))
+	if err != nil {
+		return nil, usagef("%v", err)
+	}
+
+	opts := tagsearch.Options{}
+
+	if opts.Source, err = parseSource(*source); err != nil {
+		return nil, err
+	}
+
+	if *services != "" {
+		opts.Services = strings.Split(*services, ",")
+	}
+
+	for _, service := range opts.Services {
+		if !contains(tagsearch.Services, service) {
+			return nil, usagef("unknown service %q; known services: %s", service, strings.Join(tagsearch.Services, ","))
+		}
+	}
+
+	resources, err := tagsearch.Search(ctx, cs, query, opts)
+	if resources == nil {
+		return nil, err
+	}
+
+	return resources, err
+}
+
+func parseSource(s string) (tagsearch.Source, error) {
+	source, ok := tagSources[s]
+	if !ok {
+		return 0, usagef("unknown source %q; known sources: auto, tagging, services", s)
+	}
+
+	return source, nil
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,272 @@
+// Command awsc runs the wrappers of the clients package from the shell:
+//
+//	awsc --profile prod --region us-east-1 ec2 instances
+//	awsc --role-arn arn:aws:iam::444455556666:role/readonly s3 ls my-bucket/logs/
+//
+// Results are printed to standard output as JSON, or in the --output format
+// with the --columns of the format package; values fetched by the get
+// commands are printed as they are. Run awsc without arguments for the list of commands
+// and exit codes.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/mwlng/aws-go-clients/clients"
+	"github.com/mwlng/aws-go-clients/format"
+	"github.com/mwlng/aws-go-clients/service"
+)
+
+// Exit codes, so scripts can tell a missing resource from a broken setup.
+const (
+	exitOK           = 0
+	exitError        = 1
+	exitUsage        = 2
+	exitConfig       = 3
+	exitNotFound     = 4
+	exitAccessDenied = 5
+	exitThrottled    = 6
+	exitInterrupted  = 130
+)
+
+// usageError is returned for bad command lines.
+type usageError struct {
+	msg string
+}
+
+func (e *usageError) Error() string {
+	return e.msg
+}
+
+func usagef(format string, args ...interface{}) error {
+	return &usageError{msg: fmt.Sprintf(format, args...)}
+}
+
+type globalFlags struct {
+	env      string
+	profile  string
+	region   string
+	roleARN  string
+	endpoint string
+	output   string
+	columns  string
+}
+
+func main() {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+
+	go func() {
+		<-interrupts
+		cancel()
+	}()
+
+	code := run(ctx, os.Args[1:], os.Stdout, os.Stderr)
+	cancel()
+
+	os.Exit(code)
+}
+
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	var g globalFlags
+
+	fs := flag.NewFlagSet("awsc", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(&g.env, "env", "", "`environment` of the environments file to start from, overridden by the flags below")
+	fs.StringVar(&g.profile, "profile", "", "shared config `profile` to use")
+	fs.StringVar(&g.region, "region", "", "AWS `region`, the region of the profile when empty")
+	fs.StringVar(&g.roleARN, "role-arn", "", "`ARN` of a role to assume before running the command")
+	fs.StringVar(&g.endpoint, "endpoint", "", "custom endpoint `URL` for every service, such as LocalStack")
+	fs.StringVar(&g.output, "output", string(format.JSON), "output `format`: table, json, ndjson, csv or yaml")
+	fs.StringVar(&g.columns, "columns", "", "comma separated field `paths` to print, such as InstanceId,State.Name,Tags[Name]")
+	fs.Usage = func() { printUsage(fs) }
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+
+		return exitUsage
+	}
+
+	cmd, cmdArgs, err := lookup(fs.Args())
+	if err != nil {
+		fmt.Fprintf(stderr, "awsc: %v\n", err)
+		fs.Usage()
+
+		return exitUsage
+	}
+
+	output, err := format.ParseFormat(g.output)
+	if err != nil {
+		fmt.Fprintf(stderr, "awsc: %v\n", err)
+
+		return exitUsage
+	}
+
+	opts := format.Options{Format: output, Columns: format.ParseColumns(g.columns)}
+
+	var cs *clients.ClientSet
+
+	if !cmd.local {
+		svc, err := newService(g)
+		if err == nil {
+			_, err = svc.NewSessionE()
+		}
+
+		if err != nil {
+			fmt.Fprintf(stderr, "awsc: %v\n", err)
+
+			return exitCode(ctx, err)
+		}
+
+		cs = clients.NewClientSet(svc)
+	}
+
+	result, err := cmd.run(ctx, cs, cmdArgs)
+	if result != nil && (err == nil || cmd.partial) {
+		if werr := write(stdout, result, opts); werr != nil {
+			fmt.Fprintf(stderr, "awsc: %v\n", werr)
+
+			return exitUsage
+		}
+	}
+
+	if err != nil {
+		fmt.Fprintf(stderr, "awsc: %s: %v\n", cmd.name, err)
+
+		return exitCode(ctx, err)
+	}
+
+	return exitOK
+}
+
+// newService returns the configuration of --env, or a default one, with the
+// settings of the other flags. --role-arn is assumed after the roles of the
+// environment.
+func newService(g globalFlags) (*service.Service, error) {
+	svc := &service.Service{}
+
+	if g.env != "" {
+		var err error
+		if svc, err = service.FromEnvironment(g.env); err != nil {
+			return nil, err
+		}
+	}
+
+	if g.profile != "" {
+		svc.Profile = g.profile
+		svc.AccessKey, svc.SecretKey, svc.SessToken = "", "", ""
+	}
+
+	if g.region != "" {
+		svc.Region = g.region
+	}
+
+	if g.endpoint != "" {
+		svc.Endpoint = g.endpoint
+	}
+
+	if g.roleARN != "" {
+		svc.Roles = append(svc.Roles, service.Role{ARN: g.roleARN})
+	}
+
+	return svc, nil
+}
+
+// write prints strings as they are, followed by a newline, documents
+// rendered by the command as they are, and anything else in the format of
+// opts. It fails mostly on bad columns, hence the usage exit code.
+func write(w io.Writer, result interface{}, opts format.Options) error {
+	switch result := result.(type) {
+	case string:
+		_, err := fmt.Fprintln(w, result)
+
+		return err
+	case []byte:
+		_, err := w.Write(result)
+
+		return err
+	}
+
+	return format.Write(w, result, opts)
+}
+
+func exitCode(ctx context.Context, err error) int {
+	var uerr *usageError
+
+	switch {
+	case errors.As(err, &uerr):
+		return exitUsage
+	case ctx.Err() != nil:
+		return exitInterrupted
+	case errors.Is(err, service.ErrInvalidConfig), isCredentialError(err):
+		return exitConfig
+	case clients.IsNotFound(err):
+		return exitNotFound
+	case clients.IsAccessDenied(err):
+		return exitAccessDenied
+	case clients.IsThrottled(err):
+		return exitThrottled
+	}
+
+	return exitError
+}
+
+// isCredentialError reports whether err means no credentials could be
+// found, as opposed to credentials AWS rejected.
+func isCredentialError(err error) bool {
+	var cerr *clients.Error
+	if errors.As(err, &cerr) {
+		return cerr.Code == "NoCredentialProviders" || cerr.Code == "SharedCredsLoad"
+	}
+
+	return false
+}
+
+func printUsage(fs *flag.FlagSet) {
+	w := fs.Output()
+
+	fmt.Fprintf(w, "Usage: awsc [flags] <service> <command> [args]\n\nFlags:\n")
+	fs.PrintDefaults()
+
+	fmt.Fprintf(w, "\nCommands:\n")
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	for _, cmd := range commands {
+		fmt.Fprintf(tw, "  %s\t%s\n", strings.TrimSpace(cmd.name+" "+cmd.args), cmd.summary)
+	}
+
+	tw.Flush()
+
+	fmt.Fprintf(w, "\nExit codes:\n")
+
+	for _, c := range []struct {
+		code int
+		desc string
+	}{
+		{exitOK, "success"},
+		{exitError, "error"},
+		{exitUsage, "usage error"},
+		{exitConfig, "invalid configuration or missing credentials"},
+		{exitNotFound, "resource not found"},
+		{exitAccessDenied, "access denied or credentials rejected"},
+		{exitThrottled, "throttled"},
+		{exitInterrupted, "interrupted"},
+	} {
+		fmt.Fprintf(tw, "  %d\t%s\n", c.code, c.desc)
+	}
+
+	tw.Flush()
+}
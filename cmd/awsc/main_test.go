@@ -0,0 +1,173 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/mwlng/aws-go-clients/clients"
+	"github.com/mwlng/aws-go-clients/service"
+)
+
+// setenv sets the environment variables for the test.
+func setenv(t *testing.T, vars map[string]string) {
+	t.Helper()
+
+	for name, value := range vars {
+		old, ok := os.LookupEnv(name)
+		os.Setenv(name, value)
+
+		t.Cleanup(func() {
+			if ok {
+				os.Setenv(name, old)
+			} else {
+				os.Unsetenv(name)
+			}
+		})
+	}
+}
+
+// fakeCommand adds the local command "test fail", which returns err.
+func fakeCommand(t *testing.T, err error) {
+	t.Helper()
+
+	saved := commands
+	commands = append(commands[:len(commands):len(commands)], command{
+		name:  "test fail",
+		local: true,
+		run: func(ctx context.Context, cs *clients.ClientSet, args []string) (interface{}, error) {
+			if err != nil {
+				return nil, err
+			}
+
+			return "done", nil
+		},
+	})
+
+	t.Cleanup(func() { commands = saved })
+}
+
+func TestRunExitCodes(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for _, tt := range []struct {
+		name string
+		ctx  context.Context
+		err  error
+		want int
+	}{
+		{"success", context.Background(), nil, exitOK},
+		{"error", context.Background(), errors.New("boom"), exitError},
+		{"usage", context.Background(), usagef("usage: test fail"), exitUsage},
+		{"config", context.Background(), fmt.Errorf("loading: %w", service.ErrInvalidConfig), exitConfig},
+		{"missing credentials", context.Background(), &clients.Error{Service: "sts", Code: "NoCredentialProviders"}, exitConfig},
+		{"not found", context.Background(), &clients.Error{Service: ssm.ServiceName, Code: ssm.ErrCodeParameterNotFound, StatusCode: 400}, exitNotFound},
+		{"access denied", context.Background(), &clients.Error{Service: "s3", Code: "AccessDenied", StatusCode: 403}, exitAccessDenied},
+		{"rejected credentials", context.Background(), &clients.Error{Service: "sts", Code: "InvalidClientTokenId", StatusCode: 403}, exitAccessDenied},
+		{
+			"throttled", context.Background(),
+			&clients.Error{Service: "ec2", Code: "RequestLimitExceeded", Err: awserr.New("RequestLimitExceeded", "slow down", nil)},
+			exitThrottled,
+		},
+		{"interrupted", canceled, &clients.Error{Service: "ec2", Code: "RequestCanceled", Err: context.Canceled}, exitInterrupted},
+		// An interrupted command line mistake is still a usage error.
+		{"interrupted usage", canceled, usagef("usage: test fail"), exitUsage},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeCommand(t, tt.err)
+
+			var stdout, stderr bytes.Buffer
+
+			if code := run(tt.ctx, []string{"test", "fail"}, &stdout, &stderr); code != tt.want {
+				t.Errorf("run = %d, want %d; stderr: %s", code, tt.want, stderr.String())
+			}
+
+			if tt.err == nil && stdout.String() != "done\n" {
+				t.Errorf("stdout = %q, want the result", stdout.String())
+			}
+
+			if tt.err != nil && !strings.Contains(stderr.String(), tt.err.Error()) {
+				t.Errorf("stderr = %q, want the error", stderr.String())
+			}
+		})
+	}
+}
+
+func TestRunUsageErrors(t *testing.T) {
+	for _, args := range [][]string{
+		nil,
+		{"ec2"},
+		{"ec2", "volumes"},
+		{"--no-such-flag", "ec2", "instances"},
+		{"--output", "xml", "inventory", "diff", "a", "b"},
+		{"inventory", "diff", "a"},
+	} {
+		var stdout, stderr bytes.Buffer
+
+		if code := run(context.Background(), args, &stdout, &stderr); code != exitUsage {
+			t.Errorf("run %q = %d, want %d; stderr: %s", args, code, exitUsage, stderr.String())
+		}
+	}
+
+	if code := run(context.Background(), []string{"-h"}, ioutil.Discard, ioutil.Discard); code != exitOK {
+		t.Errorf("run -h = %d, want %d", code, exitOK)
+	}
+}
+
+func TestRunUnknownEnvironment(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "environments.yaml")
+	if err := ioutil.WriteFile(path, []byte("environments:\n  dev: {region: us-east-1}\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	setenv(t, map[string]string{"AWS_ENVIRONMENTS_FILE": path})
+
+	var stderr bytes.Buffer
+
+	if code := run(context.Background(), []string{"--env", "prod", "sts", "whoami"}, ioutil.Discard, &stderr); code != exitConfig {
+		t.Errorf("run = %d, want %d; stderr: %s", code, exitConfig, stderr.String())
+	}
+}
+
+func TestRunAPIErrors(t *testing.T) {
+	for _, tt := range []struct {
+		status int
+		body   string
+		want   int
+	}{
+		{http.StatusOK, `{"Parameter":{"Name":"/app/db","Value":"secret"}}`, exitOK},
+		{http.StatusBadRequest, `{"__type":"ParameterNotFound","message":"no parameter /app/db"}`, exitNotFound},
+		{http.StatusBadRequest, `{"__type":"AccessDeniedException","message":"not allowed"}`, exitAccessDenied},
+	} {
+		api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+			w.WriteHeader(tt.status)
+			w.Write([]byte(tt.body))
+		}))
+		defer api.Close()
+
+		setenv(t, map[string]string{"AWS_ACCESS_KEY_ID": "AKID", "AWS_SECRET_ACCESS_KEY": "SECRET", "AWS_PROFILE": ""})
+
+		var stdout, stderr bytes.Buffer
+
+		code := run(context.Background(), []string{"--endpoint", api.URL, "--region", "us-east-1", "ssm", "get", "/app/db"}, &stdout, &stderr)
+		if code != tt.want {
+			t.Errorf("run with %s = %d, want %d; stderr: %s", tt.body, code, tt.want, stderr.String())
+		}
+
+		if tt.want == exitOK && stdout.String() != "secret\n" {
+			t.Errorf("stdout = %q, want the parameter value", stdout.String())
+		}
+	}
+}
@@ -0,0 +1,286 @@
+// Package fleet runs the same function against many accounts and regions.
+package fleet
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mwlng/aws-go-clients/clients"
+	"github.com/mwlng/aws-go-clients/service"
+)
+
+const defaultConcurrency = 10
+
+// Target is an account and region to run against. The account is reached
+// through a shared config profile, or by assuming RoleARN with the base
+// credentials of the Options.
+type Target struct {
+	Profile string
+	RoleARN string
+	Region  string
+}
+
+func (t Target) String() string {
+	account := t.RoleARN
+	if account == "" {
+		account = t.Profile
+	}
+
+	if account == "" {
+		account = "default"
+	}
+
+	return account + "/" + t.Region
+}
+
+// Func is run once per target. It should honour ctx, which carries the
+// per-target timeout.
+type Func func(ctx context.Context, target Target, cs *clients.ClientSet) (interface{}, error)
+
+type Options struct {
+	// Concurrency bounds the number of targets run at once; it defaults to 10.
+	Concurrency int
+	// Timeout bounds each target; zero means no timeout.
+	Timeout time.Duration
+	// Base holds the credentials role targets are assumed with. Its Region
+	// and Session are ignored.
+	Base service.Service
+	// SessionName is the role session name of role targets.
+	SessionName string
+}
+
+type Result struct {
+	Target   Target
+	Value    interface{}
+	Err      error
+	Duration time.Duration
+}
+
+// Report holds one result per target, in the order of the targets.
+type Report struct {
+	Results []Result
+}
+
+func (r *Report) Succeeded() []Result {
+	results := []Result{}
+
+	for _, result := range r.Results {
+		if result.Err == nil {
+			results = append(results, result)
+		}
+	}
+
+	return results
+}
+
+func (r *Report) Failed() []Result {
+	results := []Result{}
+
+	for _, result := range r.Results {
+		if result.Err != nil {
+			results = append(results, result)
+		}
+	}
+
+	return results
+}
+
+// Err returns nil when every target succeeded, and otherwise an error
+// listing the failed targets.
+func (r *Report) Err() error {
+	failed := r.Failed()
+	if len(failed) == 0 {
+		return nil
+	}
+
+	return &Error{Failed: failed, Total: len(r.Results)}
+}
+
+type Error struct {
+	Failed []Result
+	Total  int
+}
+
+func (e *Error) Error() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "fleet: %d of %d targets failed", len(e.Failed), e.Total)
+
+	for _, result := range e.Failed {
+		fmt.Fprintf(&b, "; %s: %v", result.Target, result.Err)
+	}
+
+	return b.String()
+}
+
+// Run calls fn for every target and waits for all of them. A failing,
+// panicking or timed out target does not stop the others. A callback that
+// ignores its context is abandoned when its timeout expires.
+func Run(ctx context.Context, targets []Target, opts Options, fn Func) *Report {
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultConcurrency
+	}
+
+	report := &Report{Results: make([]Result, len(targets))}
+	sets := newClientSets(opts)
+	sem := make(chan struct{}, concurrency)
+
+	var wg sync.WaitGroup
+
+	for i, target := range targets {
+		report.Results[i].Target = target
+
+		// Once canceled, the slots freed by the targets running do not
+		// start others.
+		if err := ctx.Err(); err != nil {
+			report.Results[i].Err = err
+
+			continue
+		}
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			report.Results[i].Err = ctx.Err()
+
+			continue
+		}
+
+		wg.Add(1)
+
+		go func(result *Result) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			start := time.Now()
+			result.Value, result.Err = runTarget(ctx, result.Target, opts.Timeout, sets, fn)
+			result.Duration = time.Since(start)
+		}(&report.Results[i])
+	}
+
+	wg.Wait()
+
+	return report
+}
+
+func runTarget(ctx context.Context, target Target, timeout time.Duration, sets *clientSets, fn Func) (interface{}, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	type outcome struct {
+		value interface{}
+		err   error
+	}
+
+	done := make(chan outcome, 1)
+
+	go func() {
+		var o outcome
+
+		defer func() {
+			if r := recover(); r != nil {
+				o = outcome{err: fmt.Errorf("fleet: panic: %v", r)}
+			}
+
+			done <- o
+		}()
+
+		cs, err := sets.get(target)
+		if err != nil {
+			o.err = err
+
+			return
+		}
+
+		o.value, o.err = fn(ctx, target, cs)
+	}()
+
+	select {
+	case o := <-done:
+		return o.value, o.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// clientSets shares one ClientSet, and so one set of credentials, between
+// the regions of an account.
+type clientSets struct {
+	opts Options
+
+	mu       sync.Mutex
+	accounts map[string]*account
+}
+
+type account struct {
+	once sync.Once
+	cs   *clients.ClientSet
+	err  error
+}
+
+func newClientSets(opts Options) *clientSets {
+	return &clientSets{opts: opts, accounts: map[string]*account{}}
+}
+
+func (s *clientSets) get(target Target) (*clients.ClientSet, error) {
+	// Check the region on its own, so that a bad region does not fail the
+	// other regions of the account.
+	regional := service.Service{Region: target.Region}
+	if err := regional.Validate(); err != nil {
+		return nil, err
+	}
+
+	key := target.Profile + "\x00" + target.RoleARN
+
+	s.mu.Lock()
+	a, ok := s.accounts[key]
+	if !ok {
+		a = &account{}
+		s.accounts[key] = a
+	}
+	s.mu.Unlock()
+
+	a.once.Do(func() {
+		svc := s.opts.Base
+		svc.Region = target.Region
+		svc.Session = nil
+
+		// The SDK installs AWS_CA_BUNDLE on the HTTP client of the session,
+		// http.DefaultClient unless one is given, so sessions made at once
+		// need clients of their own.
+		if svc.HTTPClient == nil {
+			svc.HTTPClient = &http.Client{}
+		}
+
+		if target.Profile != "" {
+			svc.Profile = target.Profile
+			svc.AccessKey, svc.SecretKey, svc.SessToken = "", "", ""
+		}
+
+		if target.RoleARN != "" {
+			svc.Roles = append(append([]service.Role{}, svc.Roles...), service.Role{
+				ARN:         target.RoleARN,
+				SessionName: s.opts.SessionName,
+			})
+		}
+
+		if _, a.err = svc.NewSessionE(); a.err == nil {
+			a.cs = clients.NewClientSet(&svc)
+		}
+	})
+
+	if a.err != nil {
+		return nil, a.err
+	}
+
+	return a.cs.ForRegion(target.Region), nil
+}
@@ -0,0 +1,236 @@
+package fleet_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mwlng/aws-go-clients/clients"
+	"github.com/mwlng/aws-go-clients/fleet"
+	"github.com/mwlng/aws-go-clients/service"
+)
+
+var base = service.Service{AccessKey: "AKID", SecretKey: "SECRET"}
+
+// TestMain points the SDK at a credentials file holding the profiles of the
+// targets.
+func TestMain(m *testing.M) {
+	dir, err := ioutil.TempDir("", "fleet")
+	if err != nil {
+		panic(err)
+	}
+
+	var creds strings.Builder
+	for _, profile := range []string{"a", "b", "c", "account-0", "account-1", "account-2", "account-3", "account-4", "account-5", "account-6", "account-7", "account-8", "account-9"} {
+		fmt.Fprintf(&creds, "[%s]\naws_access_key_id = AKID\naws_secret_access_key = SECRET\n", profile)
+	}
+
+	path := filepath.Join(dir, "credentials")
+	if err := ioutil.WriteFile(path, []byte(creds.String()), 0600); err != nil {
+		panic(err)
+	}
+
+	os.Setenv("AWS_SHARED_CREDENTIALS_FILE", path)
+	os.Setenv("AWS_CONFIG_FILE", filepath.Join(dir, "config"))
+
+	code := m.Run()
+
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func targets(n int) []fleet.Target {
+	regions := []string{"us-east-1", "us-west-2", "eu-west-1"}
+	targets := make([]fleet.Target, n)
+
+	for i := range targets {
+		targets[i] = fleet.Target{Profile: fmt.Sprintf("account-%d", i/len(regions)), Region: regions[i%len(regions)]}
+	}
+
+	return targets
+}
+
+func TestRunBoundsConcurrency(t *testing.T) {
+	for _, tt := range []struct {
+		concurrency, want int
+	}{
+		{3, 3},
+		{1, 1},
+		{0, 10},
+	} {
+		var (
+			active, peak int32
+			once         sync.Once
+		)
+
+		// The callbacks wait until the limit is reached, so that any
+		// target started past it would be counted.
+		full := make(chan struct{})
+
+		report := fleet.Run(context.Background(), targets(30), fleet.Options{Concurrency: tt.concurrency, Base: base},
+			func(ctx context.Context, target fleet.Target, cs *clients.ClientSet) (interface{}, error) {
+				n := atomic.AddInt32(&active, 1)
+				defer atomic.AddInt32(&active, -1)
+
+				for {
+					p := atomic.LoadInt32(&peak)
+					if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+						break
+					}
+				}
+
+				if int(n) >= tt.want {
+					once.Do(func() { close(full) })
+				}
+
+				select {
+				case <-full:
+					time.Sleep(time.Millisecond)
+				case <-time.After(5 * time.Second):
+				}
+
+				return target.String(), nil
+			})
+
+		if err := report.Err(); err != nil {
+			t.Fatal(err)
+		}
+
+		if p := atomic.LoadInt32(&peak); int(p) != tt.want {
+			t.Errorf("Concurrency %d ran %d targets at once, want %d", tt.concurrency, p, tt.want)
+		}
+
+		for i, result := range report.Results {
+			if result.Value != result.Target.String() || result.Target != targets(30)[i] {
+				t.Errorf("result %d = %v for %v, want the results in target order", i, result.Value, result.Target)
+			}
+		}
+	}
+}
+
+func TestRunTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	ts := []fleet.Target{{Region: "us-east-1"}, {Region: "us-west-2"}, {Region: "eu-west-1"}}
+	start := time.Now()
+
+	report := fleet.Run(context.Background(), ts, fleet.Options{Timeout: 200 * time.Millisecond, Base: base},
+		func(ctx context.Context, target fleet.Target, cs *clients.ClientSet) (interface{}, error) {
+			switch target.Region {
+			case "us-east-1":
+				<-ctx.Done()
+
+				return nil, ctx.Err()
+			case "us-west-2":
+				// Ignores its context, and is abandoned.
+				<-release
+
+				return "late", nil
+			}
+
+			if cs.Region() != target.Region {
+				return nil, fmt.Errorf("client set of %s, want %s", cs.Region(), target.Region)
+			}
+
+			return "ok", nil
+		})
+
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Run took %v, want the stuck target abandoned at its timeout", elapsed)
+	}
+
+	for i, want := range []error{context.DeadlineExceeded, context.DeadlineExceeded, nil} {
+		if err := report.Results[i].Err; !errors.Is(err, want) {
+			t.Errorf("target %v failed with %v, want %v", ts[i], err, want)
+		}
+	}
+
+	if report.Results[2].Value != "ok" {
+		t.Errorf("target %v returned %v, want ok", ts[2], report.Results[2].Value)
+	}
+
+	if d := report.Results[0].Duration; d < 200*time.Millisecond {
+		t.Errorf("the timed out target ran %v, want at least its timeout", d)
+	}
+}
+
+func TestRunIsolatesFailures(t *testing.T) {
+	ts := []fleet.Target{
+		{Profile: "a", Region: "us-east-1"},
+		{Profile: "a", Region: "nowhere"},
+		{Profile: "b", Region: "us-east-1"},
+		{Profile: "c", Region: "us-east-1"},
+	}
+
+	report := fleet.Run(context.Background(), ts, fleet.Options{Base: base},
+		func(ctx context.Context, target fleet.Target, cs *clients.ClientSet) (interface{}, error) {
+			switch target.Profile {
+			case "b":
+				return nil, errors.New("boom")
+			case "c":
+				panic("bad callback")
+			}
+
+			return target.Region, nil
+		})
+
+	if n := len(report.Succeeded()); n != 1 {
+		t.Errorf("%d targets succeeded, want 1", n)
+	}
+
+	if n := len(report.Failed()); n != 3 {
+		t.Errorf("%d targets failed, want 3", n)
+	}
+
+	if !errors.Is(report.Results[1].Err, service.ErrInvalidConfig) {
+		t.Errorf("the bad region failed with %v, want ErrInvalidConfig", report.Results[1].Err)
+	}
+
+	var ferr *fleet.Error
+	if err := report.Err(); !errors.As(err, &ferr) || ferr.Total != 4 || !strings.Contains(err.Error(), "c/us-east-1: fleet: panic: bad callback") {
+		t.Errorf("Err = %v, want the failed targets listed", err)
+	}
+}
+
+func TestRunCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var (
+		mu  sync.Mutex
+		ran []fleet.Target
+	)
+
+	report := fleet.Run(ctx, targets(5), fleet.Options{Concurrency: 1, Base: base},
+		func(ctx context.Context, target fleet.Target, cs *clients.ClientSet) (interface{}, error) {
+			mu.Lock()
+			ran = append(ran, target)
+			mu.Unlock()
+
+			cancel()
+
+			return nil, nil
+		})
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if len(ran) != 1 {
+		t.Errorf("ran %d targets, want the cancel to stop the others", len(ran))
+	}
+
+	for _, result := range report.Results[1:] {
+		if !errors.Is(result.Err, context.Canceled) {
+			t.Errorf("target %v after the cancel = %v, want context.Canceled", result.Target, result.Err)
+		}
+	}
+}
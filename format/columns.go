@@ -0,0 +1,120 @@
+package format
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ecr"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/aws/aws-sdk-go/service/emr"
+	"github.com/aws/aws-sdk-go/service/glue"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/aws/aws-sdk-go/service/route53"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+)
+
+var (
+	columnsMu sync.RWMutex
+	columns   = map[reflect.Type][]string{}
+)
+
+func init() {
+	RegisterColumns(ec2.Instance{}, "InstanceId", "Tags[Name]", "InstanceType", "State.Name", "PrivateIpAddress", "Placement.AvailabilityZone", "LaunchTime")
+	RegisterColumns(ec2.Vpc{}, "VpcId", "Tags[Name]", "CidrBlock", "State", "IsDefault")
+	RegisterColumns(ecs.Cluster{}, "ClusterName", "Status", "ActiveServicesCount", "RunningTasksCount", "PendingTasksCount")
+	RegisterColumns(ecs.Service{}, "ServiceName", "Status", "DesiredCount", "RunningCount", "LaunchType", "TaskDefinition")
+	RegisterColumns(ecs.Task{}, "TaskArn", "LastStatus", "DesiredStatus", "LaunchType", "TaskDefinitionArn", "StartedAt")
+	RegisterColumns(rds.DBCluster{}, "DBClusterIdentifier", "Engine", "EngineVersion", "Status", "Endpoint")
+	RegisterColumns(rds.DBInstance{}, "DBInstanceIdentifier", "DBInstanceClass", "Engine", "DBInstanceStatus", "DBClusterIdentifier")
+	RegisterColumns(rds.DBClusterSnapshot{}, "DBClusterSnapshotIdentifier", "DBClusterIdentifier", "SnapshotType", "Status", "Engine", "SnapshotCreateTime")
+	RegisterColumns(s3.Bucket{}, "Name", "CreationDate")
+	RegisterColumns(s3.Object{}, "Key", "Size", "LastModified", "StorageClass")
+	RegisterColumns(s3.CommonPrefix{}, "Prefix")
+	RegisterColumns(iam.Role{}, "RoleName", "Arn", "CreateDate")
+	RegisterColumns(iam.User{}, "UserName", "Arn", "CreateDate", "PasswordLastUsed")
+	RegisterColumns(iam.Group{}, "GroupName", "Arn", "CreateDate")
+	RegisterColumns(iam.Policy{}, "PolicyName", "Arn", "AttachmentCount", "UpdateDate")
+	RegisterColumns(iam.AttachedPolicy{}, "PolicyName", "PolicyArn")
+	RegisterColumns(route53.HostedZone{}, "Id", "Name", "Config.PrivateZone", "ResourceRecordSetCount")
+	RegisterColumns(route53.ResourceRecordSet{}, "Name", "Type", "TTL", "ResourceRecords[*].Value", "AliasTarget.DNSName")
+	RegisterColumns(secretsmanager.SecretListEntry{}, "Name", "Description", "LastChangedDate")
+	RegisterColumns(autoscaling.Group{}, "AutoScalingGroupName", "MinSize", "MaxSize", "DesiredCapacity", "CreatedTime")
+	RegisterColumns(cloudformation.StackSummary{}, "StackName", "StackStatus", "CreationTime", "LastUpdatedTime")
+	RegisterColumns(cloudformation.StackResourceSummary{}, "LogicalResourceId", "PhysicalResourceId", "ResourceType", "ResourceStatus")
+	RegisterColumns(ecr.Repository{}, "RepositoryName", "RepositoryUri", "CreatedAt")
+	RegisterColumns(emr.ClusterSummary{}, "Id", "Name", "Status.State", "NormalizedInstanceHours")
+	RegisterColumns(glue.Database{}, "Name", "Description", "CreateTime")
+	RegisterColumns(glue.TableData{}, "Name", "DatabaseName", "TableType", "UpdateTime")
+	RegisterColumns(glue.Crawler{}, "Name", "State", "DatabaseName", "LastCrawl.Status")
+}
+
+// RegisterColumns sets the default columns of the results of sample's type,
+// a struct or a pointer to one.
+func RegisterColumns(sample interface{}, paths ...string) {
+	t := reflect.TypeOf(sample)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	columnsMu.Lock()
+	columns[t] = paths
+	columnsMu.Unlock()
+}
+
+// defaultColumns returns the registered columns of t. Other structs default
+// to their fields holding strings, numbers, booleans and times, followed by
+// their tags.
+func defaultColumns(t reflect.Type) []string {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == nil || t.Kind() != reflect.Struct || t == timeType {
+		return nil
+	}
+
+	columnsMu.RLock()
+	registered, ok := columns[t]
+	columnsMu.RUnlock()
+
+	if ok {
+		return append([]string(nil), registered...)
+	}
+
+	var paths []string
+
+	tagField := ""
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+
+		ft := f.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+
+		switch {
+		case ft == timeType:
+			paths = append(paths, f.Name)
+		case ft.Kind() == reflect.Slice && isTag(ft.Elem()), isTagMap(ft):
+			tagField = f.Name
+		case ft.Kind() == reflect.String, ft.Kind() == reflect.Bool,
+			ft.Kind() >= reflect.Int && ft.Kind() <= reflect.Float64:
+			paths = append(paths, f.Name)
+		}
+	}
+
+	if tagField != "" {
+		paths = append(paths, tagField)
+	}
+
+	return paths
+}
@@ -0,0 +1,337 @@
+// Package format renders the results of the clients, such as
+// []*ec2.Instance or []*rds.DBClusterSnapshot, as an aligned table, JSON,
+// NDJSON, CSV or YAML:
+//
+//	instances, err := cs.EC2().ListAllInstances()
+//	err = format.Write(os.Stdout, instances, format.Options{
+//		Format:  format.Table,
+//		Columns: format.ParseColumns("InstanceId,State.Name,Tags[Name]"),
+//	})
+//
+// Columns are field paths into the result type: "State.Name" selects a
+// nested field, "Tags[Name]" the value of a tag or map key, "Reservations[0]"
+// a list element and "ResourceRecords[*].Value" the field of every element.
+// Tags lists are flattened to key/value maps and times are formatted with
+// Options.TimeFormat in every format.
+package format
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+type Format string
+
+const (
+	Table  Format = "table"
+	JSON   Format = "json"
+	NDJSON Format = "ndjson"
+	CSV    Format = "csv"
+	YAML   Format = "yaml"
+)
+
+// Formats lists the supported formats.
+var Formats = []Format{Table, JSON, NDJSON, CSV, YAML}
+
+// ParseFormat returns the format named s, ignoring case.
+func ParseFormat(s string) (Format, error) {
+	for _, f := range Formats {
+		if strings.EqualFold(s, string(f)) {
+			return f, nil
+		}
+	}
+
+	return "", fmt.Errorf("format: unknown format %q", s)
+}
+
+type Options struct {
+	// Format defaults to Table.
+	Format Format
+	// Columns are the field paths to render. Tables and CSV default to the
+	// columns registered for the result type; JSON, NDJSON and YAML render
+	// whole results unless columns are given.
+	Columns []string
+	// TimeFormat is a time.Format layout, time.RFC3339 when empty.
+	TimeFormat string
+	// Location converts times before formatting; nil keeps them as returned.
+	Location *time.Location
+	// NoHeader leaves out the header row of tables and CSV.
+	NoHeader bool
+}
+
+// ParseColumns splits a comma separated list of field paths. Commas inside
+// brackets, as in a tag key, do not split.
+func ParseColumns(s string) []string {
+	var (
+		columns []string
+		depth   int
+		start   int
+	)
+
+	for i, c := range s {
+		switch c {
+		case '[':
+			depth++
+		case ']':
+			if depth > 0 {
+				depth--
+			}
+		case ',':
+			if depth == 0 {
+				columns = appendColumn(columns, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+
+	return appendColumn(columns, s[start:])
+}
+
+func appendColumn(columns []string, column string) []string {
+	if column = strings.TrimSpace(column); column != "" {
+		columns = append(columns, column)
+	}
+
+	return columns
+}
+
+// Write renders v, a slice of results or a single one, to w.
+func Write(w io.Writer, v interface{}, opts Options) error {
+	if opts.Format == "" {
+		opts.Format = Table
+	}
+
+	if opts.TimeFormat == "" {
+		opts.TimeFormat = time.RFC3339
+	}
+
+	items, single := results(v)
+	n := normalizer{timeFormat: opts.TimeFormat, location: opts.Location}
+
+	values := make([]interface{}, len(items))
+	for i, item := range items {
+		values[i] = n.value(item)
+	}
+
+	columns := opts.Columns
+	if len(columns) == 0 && (opts.Format == Table || opts.Format == CSV) {
+		columns = defaultColumns(itemType(v, items))
+	}
+
+	paths := make([]path, len(columns))
+
+	for i, column := range columns {
+		p, err := parsePath(column)
+		if err == nil {
+			err = p.check(itemType(v, items))
+		}
+
+		if err != nil {
+			return err
+		}
+
+		paths[i] = p
+	}
+
+	if len(columns) > 0 && (opts.Format == JSON || opts.Format == NDJSON || opts.Format == YAML) {
+		for i, value := range values {
+			row := object{}
+			for j, p := range paths {
+				row = append(row, field{name: columns[j], value: p.resolve(value)})
+			}
+
+			values[i] = row
+		}
+	}
+
+	switch opts.Format {
+	case Table:
+		return writeTable(w, columns, paths, values, opts)
+	case CSV:
+		return writeCSV(w, columns, paths, values, opts)
+	case JSON:
+		if single {
+			return writeJSON(w, values[0], "  ")
+		}
+
+		return writeJSON(w, values, "  ")
+	case NDJSON:
+		for _, value := range values {
+			if err := writeJSON(w, value, ""); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	case YAML:
+		if single {
+			return writeYAML(w, values[0])
+		}
+
+		return writeYAML(w, values)
+	}
+
+	return fmt.Errorf("format: unknown format %q", opts.Format)
+}
+
+// results returns the items of v, and whether v is a single result rather
+// than a slice.
+func results(v interface{}) ([]reflect.Value, bool) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() && rv.Elem().Kind() == reflect.Slice {
+		rv = rv.Elem()
+	}
+
+	if !rv.IsValid() {
+		return nil, false
+	}
+
+	if (rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array) || rv.Type().Elem().Kind() == reflect.Uint8 {
+		return []reflect.Value{rv}, true
+	}
+
+	items := make([]reflect.Value, rv.Len())
+	for i := range items {
+		items[i] = rv.Index(i)
+	}
+
+	return items, false
+}
+
+// itemType returns the type of the results, taken from the first item when
+// the slice holds interfaces.
+func itemType(v interface{}, items []reflect.Value) reflect.Type {
+	t := reflect.TypeOf(v)
+	if t == nil {
+		return nil
+	}
+
+	for t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+
+	if (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && t.Elem().Kind() != reflect.Uint8 {
+		t = t.Elem()
+	}
+
+	if t.Kind() == reflect.Interface {
+		for _, item := range items {
+			if !item.IsNil() {
+				return item.Elem().Type()
+			}
+		}
+	}
+
+	return t
+}
+
+func writeTable(w io.Writer, columns []string, paths []path, values []interface{}, opts Options) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	if len(columns) == 0 {
+		columns = []string{"Value"}
+	}
+
+	if !opts.NoHeader {
+		fmt.Fprintln(tw, strings.Join(columns, "\t"))
+	}
+
+	for _, row := range rows(paths, values) {
+		for i, c := range row {
+			row[i] = strings.NewReplacer("\t", " ", "\n", " ", "\r", " ").Replace(c)
+		}
+
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+
+	return tw.Flush()
+}
+
+func writeCSV(w io.Writer, columns []string, paths []path, values []interface{}, opts Options) error {
+	cw := csv.NewWriter(w)
+
+	if len(columns) == 0 {
+		columns = []string{"Value"}
+	}
+
+	if !opts.NoHeader {
+		if err := cw.Write(columns); err != nil {
+			return err
+		}
+	}
+
+	if err := cw.WriteAll(rows(paths, values)); err != nil {
+		return err
+	}
+
+	return cw.Error()
+}
+
+// rows renders the cells of values. Without paths, each value is a cell of
+// its own, which suits slices of names or URLs.
+func rows(paths []path, values []interface{}) [][]string {
+	rows := make([][]string, len(values))
+
+	for i, value := range values {
+		if len(paths) == 0 {
+			rows[i] = []string{cell(value)}
+
+			continue
+		}
+
+		row := make([]string, len(paths))
+		for j, p := range paths {
+			row[j] = cell(p.resolve(value))
+		}
+
+		rows[i] = row
+	}
+
+	return rows
+}
+
+func writeJSON(w io.Writer, v interface{}, indent string) error {
+	if v == nil {
+		v = []interface{}{}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", indent)
+
+	return enc.Encode(v)
+}
+
+func writeYAML(w io.Writer, v interface{}) error {
+	data, err := yaml.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(data)
+
+	return err
+}
+
+// marshal encodes v as compact JSON without escaping HTML characters.
+func marshal(v interface{}) ([]byte, error) {
+	var b bytes.Buffer
+
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+
+	return bytes.TrimRight(b.Bytes(), "\n"), nil
+}
@@ -0,0 +1,163 @@
+package format_test
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/mwlng/aws-go-clients/format"
+	"gopkg.in/yaml.v2"
+)
+
+type result struct {
+	Name    *string
+	Values  []*string
+	Labels  map[string]*string
+	Count   *int64
+	Created *time.Time
+	Nested  *struct{ Note *string }
+}
+
+var instance = &ec2.Instance{
+	InstanceId: aws.String("i-0abc"),
+	State:      &ec2.InstanceState{Name: aws.String("running")},
+	Tags: []*ec2.Tag{
+		{Key: aws.String("Name"), Value: aws.String("web")},
+		{Key: aws.String("env"), Value: aws.String("yes")},
+	},
+	SecurityGroups: []*ec2.GroupIdentifier{{GroupId: aws.String("sg-1")}, {GroupId: aws.String("sg-2")}},
+}
+
+func write(t *testing.T, v interface{}, opts format.Options) string {
+	t.Helper()
+
+	var b bytes.Buffer
+	if err := format.Write(&b, v, opts); err != nil {
+		t.Fatal(err)
+	}
+
+	return b.String()
+}
+
+func TestWriteYAML(t *testing.T) {
+	created := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+	results := []result{
+		{
+			Name:    aws.String("yes"),
+			Values:  []*string{aws.String("null"), aws.String("a: b"), aws.String("- item"), aws.String("line 1\nline 2"), aws.String("123")},
+			Labels:  map[string]*string{},
+			Count:   aws.Int64(3),
+			Created: &created,
+		},
+		{Name: aws.String("plain"), Values: []*string{}, Nested: &struct{ Note *string }{}},
+	}
+
+	want := `- Name: "yes"
+  Values:
+  - "null"
+  - 'a: b'
+  - '- item'
+  - |-
+    line 1
+    line 2
+  - "123"
+  Labels: {}
+  Count: 3
+  Created: "2021-01-02T03:04:05Z"
+- Name: plain
+  Values: []
+  Nested: {}
+`
+	if got := write(t, results, format.Options{Format: format.YAML}); got != want {
+		t.Errorf("YAML =\n%s\nwant\n%s", got, want)
+	}
+
+	if got := write(t, []result{}, format.Options{Format: format.YAML}); got != "[]\n" {
+		t.Errorf("YAML of no results = %q, want []", got)
+	}
+
+	want = `InstanceId: i-0abc
+SecurityGroups:
+- GroupId: sg-1
+- GroupId: sg-2
+State:
+  Name: running
+Tags:
+  Name: web
+  env: "yes"
+`
+	if got := write(t, instance, format.Options{Format: format.YAML}); got != want {
+		t.Errorf("YAML of an instance =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestWriteYAMLRoundTrip(t *testing.T) {
+	values := []string{
+		"", "yes", "No", "ON", "off", "y", "null", "~", "true", "1", "1.5", "0x1F", "1e3", ".inf",
+		"a: b", "a #b", "trailing:", " padded ", "- item", "-", "? key", "[list]", "{map}", "*alias", "&anchor",
+		"!tag", "|", ">", "'single'", `"double"`, "%directive", "@at", "`tick`",
+		"line 1\nline 2", "line\n", "tab\there", "2021-01-02", "2021-01-02T03:04:05Z", "héllo", "a\u0000b",
+	}
+
+	for _, value := range values {
+		out := write(t, []result{{Name: aws.String(value), Values: []*string{aws.String(value)}}}, format.Options{Format: format.YAML})
+
+		var got []struct {
+			Name   string        `yaml:"Name"`
+			Values []interface{} `yaml:"Values"`
+		}
+
+		if err := yaml.Unmarshal([]byte(out), &got); err != nil {
+			t.Errorf("YAML of %q does not parse: %v\n%s", value, err, out)
+
+			continue
+		}
+
+		if len(got) != 1 || got[0].Name != value || !reflect.DeepEqual(got[0].Values, []interface{}{value}) {
+			t.Errorf("YAML of %q reads back as %#v:\n%s", value, got, out)
+		}
+	}
+}
+
+func TestParseColumns(t *testing.T) {
+	for s, want := range map[string][]string{
+		"InstanceId,State.Name,Tags[Name]": {"InstanceId", "State.Name", "Tags[Name]"},
+		" InstanceId , Tags[a,b] ,":        {"InstanceId", "Tags[a,b]"},
+		"Tags[x[y]],Z":                     {"Tags[x[y]]", "Z"},
+		"":                                 nil,
+	} {
+		if got := format.ParseColumns(s); !reflect.DeepEqual(got, want) {
+			t.Errorf("ParseColumns(%q) = %q, want %q", s, got, want)
+		}
+	}
+}
+
+func TestWriteColumns(t *testing.T) {
+	columns := format.ParseColumns("InstanceId,State.Name,Tags[Name],Tags[missing],SecurityGroups[*].GroupId,SecurityGroups[1].GroupId")
+
+	want := "InstanceId,State.Name,Tags[Name],Tags[missing],SecurityGroups[*].GroupId,SecurityGroups[1].GroupId\n" +
+		`i-0abc,running,web,,"sg-1,sg-2",sg-2` + "\n"
+	if got := write(t, []*ec2.Instance{instance}, format.Options{Format: format.CSV, Columns: columns}); got != want {
+		t.Errorf("CSV =\n%s\nwant\n%s", got, want)
+	}
+
+	want = `- InstanceId: i-0abc
+  State.Name: running
+  Tags[Name]: web
+  Tags[missing]: null
+  SecurityGroups[*].GroupId:
+  - sg-1
+  - sg-2
+  SecurityGroups[1].GroupId: sg-2
+`
+	if got := write(t, []*ec2.Instance{instance}, format.Options{Format: format.YAML, Columns: columns}); got != want {
+		t.Errorf("YAML =\n%s\nwant\n%s", got, want)
+	}
+
+	if err := format.Write(&bytes.Buffer{}, []*ec2.Instance{instance}, format.Options{Columns: []string{"State.Nmae"}}); err == nil {
+		t.Error("Write accepted a misspelt column")
+	}
+}
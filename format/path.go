@@ -0,0 +1,157 @@
+package format
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// path is a parsed column such as "Placement.AvailabilityZone",
+// "Tags[Name]" or "ResourceRecords[*].Value".
+type path struct {
+	column   string
+	segments []segment
+}
+
+// segment is a field name, or the key, index or "*" of a bracket.
+type segment struct {
+	name    string
+	bracket bool
+}
+
+func parsePath(column string) (path, error) {
+	p := path{column: column}
+	s := column
+
+	for s != "" {
+		switch {
+		case s[0] == '[':
+			end := strings.IndexByte(s, ']')
+			if end < 0 {
+				return p, fmt.Errorf("format: column %q: missing ]", column)
+			}
+
+			p.segments = append(p.segments, segment{name: s[1:end], bracket: true})
+			s = s[end+1:]
+		case s[0] == '.' && len(p.segments) > 0:
+			s = s[1:]
+
+			fallthrough
+		default:
+			end := strings.IndexAny(s, ".[")
+			if end < 0 {
+				end = len(s)
+			}
+
+			if end == 0 {
+				return p, fmt.Errorf("format: column %q: empty field name", column)
+			}
+
+			p.segments = append(p.segments, segment{name: s[:end]})
+			s = s[end:]
+		}
+	}
+
+	if len(p.segments) == 0 {
+		return p, fmt.Errorf("format: empty column")
+	}
+
+	return p, nil
+}
+
+// check reports the segments of p that cannot exist in t, such as a
+// misspelt field name. A nil t is not checked.
+func (p path) check(t reflect.Type) error {
+	for _, s := range p.segments {
+		for t != nil && t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+
+		if t == nil || t.Kind() == reflect.Interface {
+			return nil
+		}
+
+		if !s.bracket {
+			if t.Kind() != reflect.Struct || t == timeType {
+				return fmt.Errorf("format: column %q: %s has no field %s", p.column, t, s.name)
+			}
+
+			f, ok := t.FieldByName(s.name)
+			if !ok || f.PkgPath != "" {
+				return fmt.Errorf("format: column %q: %s has no field %s", p.column, t, s.name)
+			}
+
+			t = f.Type
+
+			continue
+		}
+
+		switch t.Kind() {
+		case reflect.Slice, reflect.Array:
+			if isTag(t.Elem()) {
+				t = reflect.TypeOf("")
+			} else if _, err := strconv.Atoi(s.name); err == nil || s.name == "*" {
+				t = t.Elem()
+			} else {
+				return fmt.Errorf("format: column %q: %q is not an index of %s", p.column, s.name, t)
+			}
+		case reflect.Map:
+			t = t.Elem()
+		default:
+			return fmt.Errorf("format: column %q: %s is not a list or map", p.column, t)
+		}
+	}
+
+	return nil
+}
+
+// resolve selects the value of p in a normalised result, nil when part of
+// the path is not set.
+func (p path) resolve(v interface{}) interface{} {
+	return resolve(v, p.segments)
+}
+
+func resolve(v interface{}, segments []segment) interface{} {
+	if len(segments) == 0 || v == nil {
+		return v
+	}
+
+	s, rest := segments[0], segments[1:]
+
+	switch v := v.(type) {
+	case object:
+		value, _ := v.get(s.name)
+
+		return resolve(value, rest)
+	case tags:
+		value, _ := object(v).get(s.name)
+
+		return resolve(value, rest)
+	case []interface{}:
+		if !s.bracket {
+			return nil
+		}
+
+		if s.name == "*" {
+			var values []interface{}
+
+			for _, item := range v {
+				if value := resolve(item, rest); value != nil {
+					values = append(values, value)
+				}
+			}
+
+			return values
+		}
+
+		i, err := strconv.Atoi(s.name)
+		if err != nil || i < 0 || i >= len(v) {
+			return nil
+		}
+
+		return resolve(v[i], rest)
+	}
+
+	return nil
+}
@@ -0,0 +1,68 @@
+package format
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/route53"
+)
+
+func TestParsePath(t *testing.T) {
+	for column, want := range map[string][]segment{
+		"InstanceId":                 {{name: "InstanceId"}},
+		"State.Name":                 {{name: "State"}, {name: "Name"}},
+		"Tags[Name]":                 {{name: "Tags"}, {name: "Name", bracket: true}},
+		"Tags[aws:cloudformation.x]": {{name: "Tags"}, {name: "aws:cloudformation.x", bracket: true}},
+		"Tags[]":                     {{name: "Tags"}, {name: "", bracket: true}},
+		"Reservations[0].Instances":  {{name: "Reservations"}, {name: "0", bracket: true}, {name: "Instances"}},
+		"ResourceRecords[*].Value":   {{name: "ResourceRecords"}, {name: "*", bracket: true}, {name: "Value"}},
+		"[0]":                        {{name: "0", bracket: true}},
+	} {
+		p, err := parsePath(column)
+		if err != nil {
+			t.Errorf("parsePath(%q): %v", column, err)
+
+			continue
+		}
+
+		if !reflect.DeepEqual(p.segments, want) {
+			t.Errorf("parsePath(%q) = %+v, want %+v", column, p.segments, want)
+		}
+	}
+
+	for _, column := range []string{"", "Tags[Name", "State..Name", ".State", "State."} {
+		if p, err := parsePath(column); err == nil {
+			t.Errorf("parsePath(%q) = %+v, want an error", column, p.segments)
+		}
+	}
+}
+
+func TestPathCheck(t *testing.T) {
+	for _, tt := range []struct {
+		t      reflect.Type
+		column string
+		ok     bool
+	}{
+		{reflect.TypeOf(ec2.Instance{}), "State.Name", true},
+		{reflect.TypeOf(&ec2.Instance{}), "Tags[Name]", true},
+		{reflect.TypeOf(ec2.Instance{}), "SecurityGroups[*].GroupId", true},
+		{reflect.TypeOf(ec2.Instance{}), "SecurityGroups[0].GroupName", true},
+		{reflect.TypeOf(route53.ResourceRecordSet{}), "ResourceRecords[*].Value", true},
+		{nil, "Anything[at].all", true},
+		{reflect.TypeOf(ec2.Instance{}), "State.Nmae", false},
+		{reflect.TypeOf(ec2.Instance{}), "InstanceId.Length", false},
+		{reflect.TypeOf(ec2.Instance{}), "SecurityGroups[first]", false},
+		{reflect.TypeOf(ec2.Instance{}), "InstanceId[0]", false},
+		{reflect.TypeOf(ec2.Instance{}), "LaunchTime.Year", false},
+	} {
+		p, err := parsePath(tt.column)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := p.check(tt.t); (err == nil) != tt.ok {
+			t.Errorf("check(%s) of %v = %v, want ok %v", tt.column, tt.t, err, tt.ok)
+		}
+	}
+}
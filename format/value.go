@@ -0,0 +1,329 @@
+package format
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Results are normalised before rendering: structs become objects keeping
+// their field order without the fields not set, tags lists become tags,
+// times become strings and numbers int64, uint64 or float64.
+type (
+	field struct {
+		name  string
+		value interface{}
+	}
+	object []field
+	// tags is an object of string values flattened from a list of Key and
+	// Value pairs.
+	tags object
+)
+
+func (o object) get(name string) (interface{}, bool) {
+	for _, f := range o {
+		if f.name == name {
+			return f.value, true
+		}
+	}
+
+	return nil, false
+}
+
+func (o object) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+
+	b.WriteByte('{')
+
+	for i, f := range o {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+
+		name, err := marshal(f.name)
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := marshal(f.value)
+		if err != nil {
+			return nil, err
+		}
+
+		b.Write(name)
+		b.WriteByte(':')
+		b.Write(value)
+	}
+
+	b.WriteByte('}')
+
+	return b.Bytes(), nil
+}
+
+func (t tags) MarshalJSON() ([]byte, error) {
+	return object(t).MarshalJSON()
+}
+
+// MarshalYAML keeps the field order of o in YAML.
+func (o object) MarshalYAML() (interface{}, error) {
+	m := make(yaml.MapSlice, len(o))
+	for i, f := range o {
+		m[i] = yaml.MapItem{Key: f.name, Value: f.value}
+	}
+
+	return m, nil
+}
+
+func (t tags) MarshalYAML() (interface{}, error) {
+	return object(t).MarshalYAML()
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+type normalizer struct {
+	timeFormat string
+	location   *time.Location
+}
+
+func (n normalizer) value(v reflect.Value) interface{} {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+
+		v = v.Elem()
+	}
+
+	if v.Type() == timeType {
+		t := v.Interface().(time.Time)
+		if n.location != nil {
+			t = t.In(n.location)
+		}
+
+		return t.Format(n.timeFormat)
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Uint()
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	case reflect.Struct:
+		return n.structValue(v)
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil
+		}
+
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return base64.StdEncoding.EncodeToString(v.Bytes())
+		}
+
+		if isTag(v.Type().Elem()) {
+			return n.tags(v)
+		}
+
+		return n.list(v)
+	case reflect.Array:
+		return n.list(v)
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+
+		if isTagMap(v.Type()) {
+			return n.tagMap(v)
+		}
+
+		return n.mapValue(v)
+	}
+
+	return nil
+}
+
+func (n normalizer) structValue(v reflect.Value) object {
+	o := object{}
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" || !renderable(f.Type) {
+			continue
+		}
+
+		if value := n.value(v.Field(i)); value != nil {
+			o = append(o, field{name: f.Name, value: value})
+		}
+	}
+
+	return o
+}
+
+// renderable leaves out fields such as the io.ReadCloser bodies of S3
+// outputs.
+func renderable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Interface, reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return false
+	}
+
+	return true
+}
+
+func (n normalizer) list(v reflect.Value) []interface{} {
+	list := make([]interface{}, v.Len())
+	for i := range list {
+		list[i] = n.value(v.Index(i))
+	}
+
+	return list
+}
+
+func (n normalizer) mapValue(v reflect.Value) object {
+	o := object{}
+
+	for _, key := range v.MapKeys() {
+		o = append(o, field{name: fmt.Sprint(key.Interface()), value: n.value(v.MapIndex(key))})
+	}
+
+	sort.Slice(o, func(i, j int) bool { return o[i].name < o[j].name })
+
+	return o
+}
+
+func (n normalizer) tags(v reflect.Value) tags {
+	t := tags{}
+
+	for i := 0; i < v.Len(); i++ {
+		tag := reflect.Indirect(v.Index(i))
+		if !tag.IsValid() {
+			continue
+		}
+
+		key, ok := n.value(tag.FieldByName("Key")).(string)
+		if !ok {
+			continue
+		}
+
+		value, _ := n.value(tag.FieldByName("Value")).(string)
+		t = append(t, field{name: key, value: value})
+	}
+
+	sort.SliceStable(t, func(i, j int) bool { return t[i].name < t[j].name })
+
+	return t
+}
+
+// tagMap renders maps of strings, such as the tags of Lambda functions, like
+// tag slices.
+func (n normalizer) tagMap(v reflect.Value) tags {
+	t := tags{}
+
+	for _, key := range v.MapKeys() {
+		value, _ := n.value(v.MapIndex(key)).(string)
+		t = append(t, field{name: key.String(), value: value})
+	}
+
+	sort.Slice(t, func(i, j int) bool { return t[i].name < t[j].name })
+
+	return t
+}
+
+func isTagMap(t reflect.Type) bool {
+	if t.Kind() != reflect.Map || t.Key().Kind() != reflect.String {
+		return false
+	}
+
+	elem := t.Elem()
+	if elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	}
+
+	return elem.Kind() == reflect.String
+}
+
+// isTag reports whether t is a tag struct, with string Key and Value fields
+// like ec2.Tag, rds.Tag or ecs.Tag.
+func isTag(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+
+	for _, name := range []string{"Key", "Value"} {
+		f, ok := t.FieldByName(name)
+		if !ok {
+			return false
+		}
+
+		ft := f.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+
+		if ft.Kind() != reflect.String {
+			return false
+		}
+	}
+
+	return true
+}
+
+// cell renders a normalised value for a table or CSV: lists are joined with
+// commas, tags as key=value pairs and other objects as JSON.
+func cell(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case uint64:
+		return strconv.FormatUint(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case tags:
+		pairs := make([]string, len(v))
+		for i, f := range v {
+			pairs[i] = f.name + "=" + cell(f.value)
+		}
+
+		return strings.Join(pairs, ",")
+	case []interface{}:
+		cells := make([]string, 0, len(v))
+
+		for _, item := range v {
+			if item != nil {
+				cells = append(cells, cell(item))
+			}
+		}
+
+		return strings.Join(cells, ",")
+	}
+
+	data, err := marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+
+	return string(data)
+}
@@ -2,4 +2,7 @@ module github.com/mwlng/aws-go-clients
 
 go 1.15
 
-require github.com/aws/aws-sdk-go v1.36.16
+require (
+	github.com/aws/aws-sdk-go v1.36.16
+	gopkg.in/yaml.v2 v2.2.8
+)
@@ -0,0 +1,566 @@
+package inventory
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/mwlng/aws-go-clients/arn"
+	"github.com/mwlng/aws-go-clients/clients"
+)
+
+// env is what collectors need besides the clients to build ARNs.
+type env struct {
+	cs        *clients.ClientSet
+	account   string
+	region    string
+	partition string
+}
+
+type collector struct {
+	typ     string
+	collect func(ctx context.Context, e *env) ([]Resource, error)
+}
+
+var collectors = []collector{
+	{"autoscaling:group", collectASGs},
+	{"cloudformation:stack", collectStacks},
+	{"ec2:instance", collectInstances},
+	{"ec2:vpc", collectVpcs},
+	{"ecr:repository", collectRepositories},
+	{"ecs:cluster", collectClusters},
+	{"ecs:service", collectServices},
+	{"glue:table", collectGlueTables},
+	{"iam:role", collectRoles},
+	{"rds:cluster", collectDBClusters},
+	{"rds:db", collectDBInstances},
+	{"route53:hostedzone", collectHostedZones},
+	{"route53:recordset", collectRecordSets},
+	{"secretsmanager:secret", collectSecrets},
+}
+
+func collectInstances(ctx context.Context, e *env) ([]Resource, error) {
+	instances, err := e.cs.EC2().ListAllInstancesWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make([]Resource, 0, len(instances))
+
+	for _, i := range instances {
+		tags := tagMap(len(i.Tags), func(n int) (*string, *string) { return i.Tags[n].Key, i.Tags[n].Value })
+		state := ""
+
+		if i.State != nil {
+			state = aws.StringValue(i.State.Name)
+		}
+
+		az := ""
+		if i.Placement != nil {
+			az = aws.StringValue(i.Placement.AvailabilityZone)
+		}
+
+		resources = append(resources, Resource{
+			ARN:  arn.EC2Instance(e.region, e.account, aws.StringValue(i.InstanceId)).String(),
+			Type: "ec2:instance",
+			Name: nameOr(tags, aws.StringValue(i.InstanceId)),
+			Tags: tags,
+			Attributes: attributes(
+				"InstanceType", i.InstanceType,
+				"State", state,
+				"ImageId", i.ImageId,
+				"AvailabilityZone", az,
+				"VpcId", i.VpcId,
+				"SubnetId", i.SubnetId,
+				"PrivateIpAddress", i.PrivateIpAddress,
+			),
+		})
+	}
+
+	return resources, nil
+}
+
+func collectVpcs(ctx context.Context, e *env) ([]Resource, error) {
+	vpcs, err := e.cs.EC2().ListAllVpcsWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make([]Resource, 0, len(vpcs))
+
+	for _, v := range vpcs {
+		tags := tagMap(len(v.Tags), func(n int) (*string, *string) { return v.Tags[n].Key, v.Tags[n].Value })
+
+		resources = append(resources, Resource{
+			ARN:        arn.EC2VPC(e.region, e.account, aws.StringValue(v.VpcId)).String(),
+			Type:       "ec2:vpc",
+			Name:       nameOr(tags, aws.StringValue(v.VpcId)),
+			Tags:       tags,
+			Attributes: attributes("CidrBlock", v.CidrBlock, "State", v.State, "IsDefault", v.IsDefault),
+		})
+	}
+
+	return resources, nil
+}
+
+func collectASGs(ctx context.Context, e *env) ([]Resource, error) {
+	groups, err := e.cs.ASG().ListAllAutoScalingGroupsWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make([]Resource, 0, len(groups))
+
+	for _, g := range groups {
+		launchTemplate := ""
+		if g.LaunchTemplate != nil {
+			launchTemplate = aws.StringValue(g.LaunchTemplate.LaunchTemplateName) + ":" + aws.StringValue(g.LaunchTemplate.Version)
+		}
+
+		resources = append(resources, Resource{
+			ARN:  aws.StringValue(g.AutoScalingGroupARN),
+			Type: "autoscaling:group",
+			Name: aws.StringValue(g.AutoScalingGroupName),
+			Tags: tagMap(len(g.Tags), func(n int) (*string, *string) { return g.Tags[n].Key, g.Tags[n].Value }),
+			Attributes: attributes(
+				"MinSize", g.MinSize,
+				"MaxSize", g.MaxSize,
+				"DesiredCapacity", g.DesiredCapacity,
+				"LaunchConfigurationName", g.LaunchConfigurationName,
+				"LaunchTemplate", launchTemplate,
+			),
+		})
+	}
+
+	return resources, nil
+}
+
+func collectClusters(ctx context.Context, e *env) ([]Resource, error) {
+	clusters, err := e.cs.ECS().ListClustersWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make([]Resource, 0, len(clusters))
+
+	for _, c := range clusters {
+		resources = append(resources, Resource{
+			ARN:        aws.StringValue(c.ClusterArn),
+			Type:       "ecs:cluster",
+			Name:       aws.StringValue(c.ClusterName),
+			Tags:       tagMap(len(c.Tags), func(n int) (*string, *string) { return c.Tags[n].Key, c.Tags[n].Value }),
+			Attributes: attributes("Status", c.Status),
+		})
+	}
+
+	return resources, nil
+}
+
+func collectServices(ctx context.Context, e *env) ([]Resource, error) {
+	clusters, err := e.cs.ECS().ListClustersWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var resources []Resource
+
+	for _, c := range clusters {
+		services, err := e.cs.ECS().ListServicesByClusterWithContext(ctx, c.ClusterName)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, s := range services {
+			resources = append(resources, Resource{
+				ARN:  aws.StringValue(s.ServiceArn),
+				Type: "ecs:service",
+				Name: aws.StringValue(s.ServiceName),
+				Tags: tagMap(len(s.Tags), func(n int) (*string, *string) { return s.Tags[n].Key, s.Tags[n].Value }),
+				// RunningCount is left out: it moves with every deployment.
+				Attributes: attributes(
+					"Cluster", c.ClusterName,
+					"Status", s.Status,
+					"DesiredCount", s.DesiredCount,
+					"LaunchType", s.LaunchType,
+					"TaskDefinition", s.TaskDefinition,
+				),
+			})
+		}
+	}
+
+	return resources, nil
+}
+
+func collectDBClusters(ctx context.Context, e *env) ([]Resource, error) {
+	clusters, err := e.cs.RDS().ListDBClustersWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make([]Resource, 0, len(clusters))
+
+	for _, c := range clusters {
+		resources = append(resources, Resource{
+			ARN:  aws.StringValue(c.DBClusterArn),
+			Type: "rds:cluster",
+			Name: aws.StringValue(c.DBClusterIdentifier),
+			Tags: tagMap(len(c.TagList), func(n int) (*string, *string) { return c.TagList[n].Key, c.TagList[n].Value }),
+			Attributes: attributes(
+				"Engine", c.Engine,
+				"EngineVersion", c.EngineVersion,
+				"Status", c.Status,
+				"Endpoint", c.Endpoint,
+				"MultiAZ", c.MultiAZ,
+			),
+		})
+	}
+
+	return resources, nil
+}
+
+func collectDBInstances(ctx context.Context, e *env) ([]Resource, error) {
+	instances, err := e.cs.RDS().ListDBInstancesWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make([]Resource, 0, len(instances))
+
+	for _, i := range instances {
+		resources = append(resources, Resource{
+			ARN:  aws.StringValue(i.DBInstanceArn),
+			Type: "rds:db",
+			Name: aws.StringValue(i.DBInstanceIdentifier),
+			Tags: tagMap(len(i.TagList), func(n int) (*string, *string) { return i.TagList[n].Key, i.TagList[n].Value }),
+			Attributes: attributes(
+				"Engine", i.Engine,
+				"EngineVersion", i.EngineVersion,
+				"DBInstanceClass", i.DBInstanceClass,
+				"Status", i.DBInstanceStatus,
+				"DBClusterIdentifier", i.DBClusterIdentifier,
+			),
+		})
+	}
+
+	return resources, nil
+}
+
+func collectRepositories(ctx context.Context, e *env) ([]Resource, error) {
+	repos, err := e.cs.ECR().ListRepositoriesWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make([]Resource, 0, len(repos))
+
+	for _, r := range repos {
+		tags, err := e.cs.ECR().ListTagsForResourceWithContext(ctx, r.RepositoryArn)
+		if err != nil {
+			return nil, err
+		}
+
+		resources = append(resources, Resource{
+			ARN:        aws.StringValue(r.RepositoryArn),
+			Type:       "ecr:repository",
+			Name:       aws.StringValue(r.RepositoryName),
+			Tags:       tagMap(len(tags), func(n int) (*string, *string) { return tags[n].Key, tags[n].Value }),
+			Attributes: attributes("RepositoryUri", r.RepositoryUri, "ImageTagMutability", r.ImageTagMutability),
+		})
+	}
+
+	return resources, nil
+}
+
+func collectHostedZones(ctx context.Context, e *env) ([]Resource, error) {
+	zones, err := e.cs.R53().ListHostedZonesWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]*string, 0, len(zones))
+	for _, z := range zones {
+		ids = append(ids, z.Id)
+	}
+
+	zoneTags, err := e.cs.R53().ListTagsForHostedZonesWithContext(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make([]Resource, 0, len(zones))
+
+	for _, z := range zones {
+		var private, comment interface{}
+		if z.Config != nil {
+			private, comment = z.Config.PrivateZone, z.Config.Comment
+		}
+
+		tags := zoneTags[strings.TrimPrefix(aws.StringValue(z.Id), "/hostedzone/")]
+
+		resources = append(resources, Resource{
+			ARN:        zoneARN(e, z.Id),
+			Type:       "route53:hostedzone",
+			Name:       aws.StringValue(z.Name),
+			Tags:       tagMap(len(tags), func(n int) (*string, *string) { return tags[n].Key, tags[n].Value }),
+			Attributes: attributes("PrivateZone", private, "Comment", comment),
+		})
+	}
+
+	return resources, nil
+}
+
+func collectRecordSets(ctx context.Context, e *env) ([]Resource, error) {
+	zones, err := e.cs.R53().ListHostedZonesWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var resources []Resource
+
+	for _, z := range zones {
+		records, err := e.cs.R53().ListResourceRecordSetsWithContext(ctx, z.Id)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, r := range records {
+			values := make([]string, 0, len(r.ResourceRecords))
+			for _, v := range r.ResourceRecords {
+				values = append(values, aws.StringValue(v.Value))
+			}
+
+			sort.Strings(values)
+
+			alias := ""
+			if r.AliasTarget != nil {
+				alias = aws.StringValue(r.AliasTarget.DNSName)
+			}
+
+			id := aws.StringValue(r.Name) + "/" + aws.StringValue(r.Type)
+			if r.SetIdentifier != nil {
+				id += "/" + aws.StringValue(r.SetIdentifier)
+			}
+
+			resources = append(resources, Resource{
+				ARN:  zoneARN(e, z.Id) + "/recordset/" + id,
+				Type: "route53:recordset",
+				Name: aws.StringValue(r.Name),
+				Attributes: attributes(
+					"Zone", z.Name,
+					"Type", r.Type,
+					"TTL", r.TTL,
+					"Values", strings.Join(values, ","),
+					"AliasTarget", alias,
+				),
+			})
+		}
+	}
+
+	return resources, nil
+}
+
+// zoneARN returns the ARN of a hosted zone, whose ID may carry the
+// "/hostedzone/" prefix returned by ListHostedZones.
+func zoneARN(e *env, id *string) string {
+	zone := arn.Route53HostedZone(aws.StringValue(id))
+	zone.Partition = e.partition
+
+	return zone.String()
+}
+
+func collectRoles(ctx context.Context, e *env) ([]Resource, error) {
+	roles, err := e.cs.IAM().ListRolesWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make([]Resource, 0, len(roles))
+
+	for _, r := range roles {
+		// ListRoles leaves the tags out.
+		tags, err := e.cs.IAM().ListRoleTagsWithContext(ctx, r.RoleName)
+		if err != nil {
+			return nil, err
+		}
+
+		resources = append(resources, Resource{
+			ARN:  aws.StringValue(r.Arn),
+			Type: "iam:role",
+			Name: aws.StringValue(r.RoleName),
+			Tags: tagMap(len(tags), func(n int) (*string, *string) { return tags[n].Key, tags[n].Value }),
+			// A new RoleId means the role was deleted and created again.
+			Attributes: attributes("RoleId", r.RoleId, "Path", r.Path, "MaxSessionDuration", r.MaxSessionDuration),
+		})
+	}
+
+	return resources, nil
+}
+
+func collectSecrets(ctx context.Context, e *env) ([]Resource, error) {
+	secrets, err := e.cs.SecretsManager().ListAllSecretsWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make([]Resource, 0, len(secrets))
+
+	for _, s := range secrets {
+		resources = append(resources, Resource{
+			ARN:  aws.StringValue(s.ARN),
+			Type: "secretsmanager:secret",
+			Name: aws.StringValue(s.Name),
+			Tags: tagMap(len(s.Tags), func(n int) (*string, *string) { return s.Tags[n].Key, s.Tags[n].Value }),
+			Attributes: attributes(
+				"KmsKeyId", s.KmsKeyId,
+				"RotationEnabled", s.RotationEnabled,
+				"LastChangedDate", s.LastChangedDate,
+			),
+		})
+	}
+
+	return resources, nil
+}
+
+func collectGlueTables(ctx context.Context, e *env) ([]Resource, error) {
+	databases, err := e.cs.Glue().ListDatabasesWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var resources []Resource
+
+	for _, db := range databases {
+		tables, err := e.cs.Glue().ListTablesWithContext(ctx, db.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, t := range tables {
+			location := ""
+			if t.StorageDescriptor != nil {
+				location = aws.StringValue(t.StorageDescriptor.Location)
+			}
+
+			tableARN := arn.GlueTable(e.region, e.account, aws.StringValue(db.Name), aws.StringValue(t.Name)).String()
+
+			tags, err := e.cs.Glue().GetTagsWithContext(ctx, aws.String(tableARN))
+			if err != nil {
+				return nil, err
+			}
+
+			var tagsMap map[string]string
+			if len(tags) > 0 {
+				tagsMap = make(map[string]string, len(tags))
+				for key, value := range tags {
+					tagsMap[key] = aws.StringValue(value)
+				}
+			}
+
+			resources = append(resources, Resource{
+				ARN:  tableARN,
+				Type: "glue:table",
+				Name: aws.StringValue(t.Name),
+				Tags: tagsMap,
+				Attributes: attributes(
+					"Database", db.Name,
+					"TableType", t.TableType,
+					"Location", location,
+					"UpdateTime", t.UpdateTime,
+				),
+			})
+		}
+	}
+
+	return resources, nil
+}
+
+func collectStacks(ctx context.Context, e *env) ([]Resource, error) {
+	// Unlike ListStacks, DescribeStacks returns the tags, and leaves out
+	// the stacks deleted in the last 90 days.
+	stacks, err := e.cs.Cloudformation().DescribeStacksWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make([]Resource, 0, len(stacks))
+
+	for _, s := range stacks {
+		resources = append(resources, Resource{
+			ARN:        aws.StringValue(s.StackId),
+			Type:       "cloudformation:stack",
+			Name:       aws.StringValue(s.StackName),
+			Tags:       tagMap(len(s.Tags), func(n int) (*string, *string) { return s.Tags[n].Key, s.Tags[n].Value }),
+			Attributes: attributes("Status", s.StackStatus, "LastUpdatedTime", s.LastUpdatedTime),
+		})
+	}
+
+	return resources, nil
+}
+
+func tagMap(n int, tag func(i int) (key, value *string)) map[string]string {
+	if n == 0 {
+		return nil
+	}
+
+	tags := make(map[string]string, n)
+
+	for i := 0; i < n; i++ {
+		if key, value := tag(i); key != nil {
+			tags[*key] = aws.StringValue(value)
+		}
+	}
+
+	return tags
+}
+
+func nameOr(tags map[string]string, id string) string {
+	if name := tags["Name"]; name != "" {
+		return name
+	}
+
+	return id
+}
+
+// attributes builds the attributes from name and value pairs, leaving out
+// the values not set.
+func attributes(pairs ...interface{}) map[string]string {
+	attrs := map[string]string{}
+
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if value := attributeValue(pairs[i+1]); value != "" {
+			attrs[pairs[i].(string)] = value
+		}
+	}
+
+	if len(attrs) == 0 {
+		return nil
+	}
+
+	return attrs
+}
+
+func attributeValue(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case *string:
+		return aws.StringValue(v)
+	case *int64:
+		if v != nil {
+			return strconv.FormatInt(*v, 10)
+		}
+	case *bool:
+		if v != nil {
+			return strconv.FormatBool(*v)
+		}
+	case *time.Time:
+		if v != nil {
+			return v.UTC().Format(time.RFC3339)
+		}
+	}
+
+	return ""
+}
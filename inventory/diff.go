@@ -0,0 +1,157 @@
+package inventory
+
+import "sort"
+
+// Diff lists the resources added, removed and changed between two
+// snapshots, matched by ARN.
+type Diff struct {
+	Added   []Resource `json:"added"`
+	Removed []Resource `json:"removed"`
+	Changed []Change   `json:"changed"`
+	// Skipped are the resource types not compared because one of the
+	// snapshots was collected without them or could not list them.
+	Skipped []string `json:"skipped,omitempty"`
+}
+
+type Change struct {
+	ARN    string        `json:"arn"`
+	Type   string        `json:"type"`
+	Name   string        `json:"name"`
+	Fields []FieldChange `json:"fields"`
+}
+
+// FieldChange is a changed name, tag or attribute, with Field set to
+// "name", "tags.<key>" or "attributes.<name>". A value that was or became
+// unset is empty.
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// Empty reports whether the snapshots hold the same resources.
+func (d *Diff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// Compare returns the changes from before to after, for the resource types
+// both snapshots listed.
+func Compare(before, after *Snapshot) *Diff {
+	diff := &Diff{Added: []Resource{}, Removed: []Resource{}, Changed: []Change{}}
+
+	beforeTypes, afterTypes := before.listed(), after.listed()
+
+	skipped := map[string]bool{}
+	for _, typ := range append(before.types(), after.types()...) {
+		if !beforeTypes[typ] || !afterTypes[typ] {
+			skipped[typ] = true
+		}
+	}
+
+	for typ := range skipped {
+		diff.Skipped = append(diff.Skipped, typ)
+	}
+
+	sort.Strings(diff.Skipped)
+
+	compared := func(typ string) bool {
+		return beforeTypes[typ] && afterTypes[typ]
+	}
+
+	old := map[string]Resource{}
+
+	for _, r := range before.Resources {
+		if compared(r.Type) {
+			old[r.ARN] = r
+		}
+	}
+
+	for _, r := range after.Resources {
+		if !compared(r.Type) {
+			continue
+		}
+
+		prev, ok := old[r.ARN]
+		if !ok {
+			diff.Added = append(diff.Added, r)
+
+			continue
+		}
+
+		delete(old, r.ARN)
+
+		if fields := compareResources(prev, r); len(fields) > 0 {
+			diff.Changed = append(diff.Changed, Change{ARN: r.ARN, Type: r.Type, Name: r.Name, Fields: fields})
+		}
+	}
+
+	for _, r := range before.Resources {
+		if _, ok := old[r.ARN]; ok {
+			diff.Removed = append(diff.Removed, r)
+		}
+	}
+
+	return diff
+}
+
+// types returns the resource types the snapshot was asked for.
+func (s *Snapshot) types() []string {
+	if len(s.Types) == 0 {
+		return Types()
+	}
+
+	return s.Types
+}
+
+// listed returns the resource types the snapshot was asked for and listed.
+func (s *Snapshot) listed() map[string]bool {
+	listed := map[string]bool{}
+
+	for _, typ := range s.types() {
+		if _, failed := s.Errors[typ]; !failed {
+			listed[typ] = true
+		}
+	}
+
+	return listed
+}
+
+func compareResources(before, after Resource) []FieldChange {
+	var fields []FieldChange
+
+	if before.Name != after.Name {
+		fields = append(fields, FieldChange{Field: "name", Old: before.Name, New: after.Name})
+	}
+
+	fields = append(fields, compareMaps("tags.", before.Tags, after.Tags)...)
+
+	return append(fields, compareMaps("attributes.", before.Attributes, after.Attributes)...)
+}
+
+func compareMaps(prefix string, before, after map[string]string) []FieldChange {
+	keys := map[string]bool{}
+	for key := range before {
+		keys[key] = true
+	}
+
+	for key := range after {
+		keys[key] = true
+	}
+
+	sorted := make([]string, 0, len(keys))
+	for key := range keys {
+		sorted = append(sorted, key)
+	}
+
+	sort.Strings(sorted)
+
+	var fields []FieldChange
+
+	for _, key := range sorted {
+		if before[key] != after[key] {
+			fields = append(fields, FieldChange{Field: prefix + key, Old: before[key], New: after[key]})
+		}
+	}
+
+	return fields
+}
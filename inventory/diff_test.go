@@ -0,0 +1,42 @@
+package inventory
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCompareOnlyTypesInBothSnapshots(t *testing.T) {
+	instance := Resource{ARN: "arn:aws:ec2:us-east-1:1:instance/i-1", Type: "ec2:instance", Name: "web"}
+	role := Resource{ARN: "arn:aws:iam::1:role/deploy", Type: "iam:role", Name: "deploy"}
+	stack := Resource{ARN: "arn:aws:cloudformation:us-east-1:1:stack/app/1", Type: "cloudformation:stack", Name: "app"}
+
+	before := &Snapshot{
+		Types:     []string{"cloudformation:stack", "ec2:instance"},
+		Resources: []Resource{instance},
+		Errors:    map[string]string{"cloudformation:stack": "AccessDenied"},
+	}
+	after := &Snapshot{
+		Types:     []string{"cloudformation:stack", "ec2:instance", "iam:role"},
+		Resources: []Resource{stack, instance, role},
+	}
+
+	diff := Compare(before, after)
+
+	if !diff.Empty() {
+		t.Errorf("diff = %+v, want no change", diff)
+	}
+
+	if want := []string{"cloudformation:stack", "iam:role"}; !reflect.DeepEqual(diff.Skipped, want) {
+		t.Errorf("Skipped = %q, want %q", diff.Skipped, want)
+	}
+}
+
+func TestCompareSnapshotsWithoutTypes(t *testing.T) {
+	role := Resource{ARN: "arn:aws:iam::1:role/deploy", Type: "iam:role", Name: "deploy"}
+
+	diff := Compare(&Snapshot{}, &Snapshot{Resources: []Resource{role}})
+
+	if len(diff.Added) != 1 || len(diff.Skipped) != 0 {
+		t.Errorf("diff = %+v, want the role added", diff)
+	}
+}
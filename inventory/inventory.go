@@ -0,0 +1,236 @@
+// Package inventory captures the resources of an account and region in a
+// snapshot, and compares snapshots to find drift:
+//
+//	snap, err := inventory.Collect(ctx, cs, inventory.Options{})
+//	err = snap.Save("inventory-2021-03-04.json")
+//
+//	before, _ := inventory.Load("inventory-2021-03-03.json")
+//	diff := inventory.Compare(before, snap)
+//
+// Every resource is described by the same model whatever its service: ARN,
+// type, name, tags and the attributes worth watching for changes.
+package inventory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mwlng/aws-go-clients/arn"
+	"github.com/mwlng/aws-go-clients/clients"
+)
+
+// Resource is the normalised description of a resource. Route 53 records,
+// which have no ARN, are given one made of their zone, name and type.
+type Resource struct {
+	ARN        string            `json:"arn"`
+	Type       string            `json:"type"`
+	Name       string            `json:"name"`
+	Tags       map[string]string `json:"tags,omitempty"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+type Snapshot struct {
+	Time      time.Time  `json:"time"`
+	Account   string     `json:"account"`
+	Region    string     `json:"region"`
+	Resources []Resource `json:"resources"`
+	// Types are the resource types the snapshot was asked for, including
+	// the ones that failed. Snapshots saved without them are taken to hold
+	// every type.
+	Types []string `json:"types,omitempty"`
+	// Errors holds the message of each resource type that could not be
+	// listed. Its resources are missing from the snapshot.
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+const defaultConcurrency = 4
+
+type Options struct {
+	// Types limits the snapshot to these resource types, such as
+	// "ec2:instance"; all of Types when empty.
+	Types []string
+	// Concurrency bounds the resource types listed at once; it defaults
+	// to 4.
+	Concurrency int
+}
+
+// Types lists the resource types a snapshot can hold.
+func Types() []string {
+	types := make([]string, 0, len(collectors))
+	for _, c := range collectors {
+		types = append(types, c.typ)
+	}
+
+	return types
+}
+
+// Error is returned by Collect with the snapshot when some resource types
+// could not be listed.
+type Error struct {
+	Failed map[string]error
+}
+
+func (e *Error) Error() string {
+	types := make([]string, 0, len(e.Failed))
+	for typ := range e.Failed {
+		types = append(types, typ)
+	}
+
+	sort.Strings(types)
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "inventory: %d resource types failed", len(types))
+
+	for _, typ := range types {
+		fmt.Fprintf(&b, "; %s: %v", typ, e.Failed[typ])
+	}
+
+	return b.String()
+}
+
+// Collect lists the resources of the account and region of cs. When some
+// resource types fail, the snapshot of the others is returned with an
+// *Error.
+func Collect(ctx context.Context, cs *clients.ClientSet, opts Options) (*Snapshot, error) {
+	selected, err := selectCollectors(opts.Types)
+	if err != nil {
+		return nil, err
+	}
+
+	account, _, _, err := cs.STS().GetCallerIDWithContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("inventory: %w", err)
+	}
+
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultConcurrency
+	}
+
+	env := &env{cs: cs, account: account, region: cs.Region(), partition: arn.PartitionForRegion(cs.Region())}
+	snap := &Snapshot{Time: time.Now().UTC(), Account: account, Region: env.region, Resources: []Resource{}}
+
+	for _, c := range selected {
+		snap.Types = append(snap.Types, c.typ)
+	}
+
+	sort.Strings(snap.Types)
+	failed := map[string]error{}
+
+	var (
+		mu  sync.Mutex
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, concurrency)
+	)
+
+	for _, c := range selected {
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func(c collector) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			resources, err := c.collect(ctx, env)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if err != nil {
+				failed[c.typ] = err
+
+				return
+			}
+
+			snap.Resources = append(snap.Resources, resources...)
+		}(c)
+	}
+
+	wg.Wait()
+
+	sort.Slice(snap.Resources, func(i, j int) bool {
+		a, b := snap.Resources[i], snap.Resources[j]
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+
+		return a.ARN < b.ARN
+	})
+
+	if len(failed) == 0 {
+		return snap, nil
+	}
+
+	snap.Errors = map[string]string{}
+	for typ, err := range failed {
+		snap.Errors[typ] = err.Error()
+	}
+
+	return snap, &Error{Failed: failed}
+}
+
+func selectCollectors(types []string) ([]collector, error) {
+	if len(types) == 0 {
+		return collectors, nil
+	}
+
+	var selected []collector
+
+	for _, typ := range types {
+		found := false
+
+		for _, c := range collectors {
+			if c.typ == typ {
+				selected = append(selected, c)
+				found = true
+			}
+		}
+
+		if !found {
+			return nil, fmt.Errorf("inventory: unknown resource type %q", typ)
+		}
+	}
+
+	return selected, nil
+}
+
+// JSON returns the snapshot as indented JSON.
+func (s *Snapshot) JSON() ([]byte, error) {
+	return json.MarshalIndent(s, "", "  ")
+}
+
+// Save writes the snapshot to path as JSON.
+func (s *Snapshot) Save(path string) error {
+	data, err := s.JSON()
+	if err != nil {
+		return fmt.Errorf("inventory: %w", err)
+	}
+
+	if err := ioutil.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("inventory: %w", err)
+	}
+
+	return nil
+}
+
+// Load reads a snapshot written by Save.
+func Load(path string) (*Snapshot, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("inventory: %w", err)
+	}
+
+	snap := &Snapshot{}
+	if err := json.Unmarshal(data, snap); err != nil {
+		return nil, fmt.Errorf("inventory: %s: %w", path, err)
+	}
+
+	return snap, nil
+}
@@ -0,0 +1,92 @@
+package metrics
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/mwlng/aws-go-clients/service"
+)
+
+// Callback hands every observed call to a function of its own, such as a
+// StatsD or OpenTelemetry sink. The function runs on a separate goroutine,
+// one record at a time, so a slow sink never delays AWS calls; records that
+// do not fit in the buffer are dropped and counted. The calls held back by a
+// dry run or answered from a response cache never reached AWS and are not
+// handed over.
+type Callback struct {
+	fn      func(service.RequestRecord)
+	records chan service.RequestRecord
+	done    chan struct{}
+	dropped uint64
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+// DefaultCallbackBuffer is the buffer of the callbacks created with none.
+const DefaultCallbackBuffer = 1024
+
+// NewCallback returns an exporter buffering up to buffer records, or
+// DefaultCallbackBuffer when buffer is zero or less.
+func NewCallback(fn func(service.RequestRecord), buffer int) *Callback {
+	if buffer <= 0 {
+		buffer = DefaultCallbackBuffer
+	}
+
+	c := &Callback{
+		fn:      fn,
+		records: make(chan service.RequestRecord, buffer),
+		done:    make(chan struct{}),
+	}
+
+	go c.run()
+
+	return c
+}
+
+func (c *Callback) run() {
+	defer close(c.done)
+
+	for record := range c.records {
+		c.fn(record)
+	}
+}
+
+func (c *Callback) ObserveRequest(record service.RequestRecord) {
+	if record.DryRun || record.Cached {
+		return
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.closed {
+		atomic.AddUint64(&c.dropped, 1)
+
+		return
+	}
+
+	select {
+	case c.records <- record:
+	default:
+		atomic.AddUint64(&c.dropped, 1)
+	}
+}
+
+// Dropped returns the number of records dropped so far.
+func (c *Callback) Dropped() uint64 {
+	return atomic.LoadUint64(&c.dropped)
+}
+
+// Close delivers the buffered records and stops the exporter. Later records
+// are dropped.
+func (c *Callback) Close() {
+	c.mu.Lock()
+	if !c.closed {
+		c.closed = true
+		close(c.records)
+	}
+	c.mu.Unlock()
+
+	<-c.done
+}
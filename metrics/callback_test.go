@@ -0,0 +1,65 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/mwlng/aws-go-clients/service"
+)
+
+func TestCallbackDefaultBufferKeepsRecords(t *testing.T) {
+	var got []service.RequestRecord
+
+	release := make(chan struct{})
+	c := NewCallback(func(r service.RequestRecord) {
+		<-release
+		got = append(got, r)
+	}, 0)
+
+	for i := 0; i < 100; i++ {
+		c.ObserveRequest(service.RequestRecord{Service: "sts", Retries: i})
+	}
+
+	close(release)
+	c.Close()
+
+	if c.Dropped() != 0 {
+		t.Errorf("Dropped = %d, want 0", c.Dropped())
+	}
+
+	if len(got) != 100 {
+		t.Fatalf("delivered %d records, want 100", len(got))
+	}
+
+	for i, r := range got {
+		if r.Retries != i {
+			t.Fatalf("record %d delivered out of order", i)
+		}
+	}
+}
+
+func TestCallbackDropsAfterClose(t *testing.T) {
+	c := NewCallback(func(service.RequestRecord) {}, 1)
+	c.Close()
+
+	c.ObserveRequest(service.RequestRecord{})
+
+	if c.Dropped() != 1 {
+		t.Errorf("Dropped = %d, want 1", c.Dropped())
+	}
+}
+
+func TestCallbackSkipsDryRuns(t *testing.T) {
+	var got []service.RequestRecord
+
+	c := NewCallback(func(r service.RequestRecord) {
+		got = append(got, r)
+	}, 0)
+
+	c.ObserveRequest(service.RequestRecord{Operation: "DeleteRole", DryRun: true})
+	c.ObserveRequest(service.RequestRecord{Operation: "ListRoles"})
+	c.Close()
+
+	if len(got) != 1 || got[0].Operation != "ListRoles" {
+		t.Errorf("delivered %+v, want the ListRoles record only", got)
+	}
+}
@@ -0,0 +1,211 @@
+// Package metrics exports the calls observed on sessions built by
+// service.Service.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/mwlng/aws-go-clients/service"
+)
+
+// DefaultBuckets are the latency histogram bounds, in seconds.
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// Prometheus counts calls and records their latency, and serves the result
+// in the Prometheus text exposition format. It is safe for concurrent use.
+type Prometheus struct {
+	namespace string
+	buckets   []float64
+
+	mu        sync.Mutex
+	requests  map[requestKey]uint64
+	retries   map[operationKey]uint64
+	latencies map[operationKey]*histogram
+}
+
+type operationKey struct {
+	service   string
+	operation string
+	region    string
+}
+
+type requestKey struct {
+	operationKey
+	status string
+	code   string
+}
+
+type histogram struct {
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// NewPrometheus returns an exporter whose metric names start with
+// namespace, "aws" when empty, using buckets as latency bounds, or
+// DefaultBuckets when none are given.
+func NewPrometheus(namespace string, buckets ...float64) *Prometheus {
+	if namespace == "" {
+		namespace = "aws"
+	}
+
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+
+	return &Prometheus{
+		namespace: namespace,
+		buckets:   buckets,
+		requests:  map[requestKey]uint64{},
+		retries:   map[operationKey]uint64{},
+		latencies: map[operationKey]*histogram{},
+	}
+}
+
+// ObserveRequest counts the calls that reached AWS: the calls held back by a
+// dry run or answered from a response cache are left out.
+func (p *Prometheus) ObserveRequest(record service.RequestRecord) {
+	if record.DryRun || record.Cached {
+		return
+	}
+
+	op := operationKey{service: record.Service, operation: record.Operation, region: record.Region}
+
+	status := ""
+	if record.StatusCode != 0 {
+		status = strconv.Itoa(record.StatusCode)
+	}
+
+	seconds := record.Latency.Seconds()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.requests[requestKey{operationKey: op, status: status, code: record.ErrorCode}]++
+	p.retries[op] += uint64(record.Retries)
+
+	h, ok := p.latencies[op]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(p.buckets))}
+		p.latencies[op] = h
+	}
+
+	for i, bound := range p.buckets {
+		if seconds <= bound {
+			h.counts[i]++
+		}
+	}
+
+	h.count++
+	h.sum += seconds
+}
+
+func (p *Prometheus) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+	if err := p.Write(w); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// Write writes every metric in the Prometheus text exposition format.
+func (p *Prometheus) Write(w io.Writer) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var b strings.Builder
+
+	name := p.namespace + "_requests_total"
+	fmt.Fprintf(&b, "# HELP %s AWS API calls by service, operation, region, HTTP status and error code.\n", name)
+	fmt.Fprintf(&b, "# TYPE %s counter\n", name)
+
+	requestKeys := make([]requestKey, 0, len(p.requests))
+	for k := range p.requests {
+		requestKeys = append(requestKeys, k)
+	}
+
+	sort.Slice(requestKeys, func(i, j int) bool {
+		a, b := requestKeys[i], requestKeys[j]
+		if a.operationKey != b.operationKey {
+			return a.operationKey.less(b.operationKey)
+		}
+
+		if a.status != b.status {
+			return a.status < b.status
+		}
+
+		return a.code < b.code
+	})
+
+	for _, k := range requestKeys {
+		fmt.Fprintf(&b, "%s{%s,status=%s,code=%s} %d\n", name, k.labels(), quote(k.status), quote(k.code), p.requests[k])
+	}
+
+	opKeys := make([]operationKey, 0, len(p.latencies))
+	for k := range p.latencies {
+		opKeys = append(opKeys, k)
+	}
+
+	sort.Slice(opKeys, func(i, j int) bool { return opKeys[i].less(opKeys[j]) })
+
+	name = p.namespace + "_request_retries_total"
+	fmt.Fprintf(&b, "# HELP %s Retries of AWS API calls.\n", name)
+	fmt.Fprintf(&b, "# TYPE %s counter\n", name)
+
+	for _, k := range opKeys {
+		fmt.Fprintf(&b, "%s{%s} %d\n", name, k.labels(), p.retries[k])
+	}
+
+	name = p.namespace + "_request_duration_seconds"
+	fmt.Fprintf(&b, "# HELP %s Latency of AWS API calls, retries included.\n", name)
+	fmt.Fprintf(&b, "# TYPE %s histogram\n", name)
+
+	for _, k := range opKeys {
+		h := p.latencies[k]
+		labels := k.labels()
+
+		for i, bound := range p.buckets {
+			le := strconv.FormatFloat(bound, 'g', -1, 64)
+			fmt.Fprintf(&b, "%s_bucket{%s,le=%s} %d\n", name, labels, quote(le), h.counts[i])
+		}
+
+		fmt.Fprintf(&b, "%s_bucket{%s,le=\"+Inf\"} %d\n", name, labels, h.count)
+		fmt.Fprintf(&b, "%s_sum{%s} %s\n", name, labels, strconv.FormatFloat(h.sum, 'g', -1, 64))
+		fmt.Fprintf(&b, "%s_count{%s} %d\n", name, labels, h.count)
+	}
+
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
+
+func (k operationKey) less(o operationKey) bool {
+	if k.service != o.service {
+		return k.service < o.service
+	}
+
+	if k.operation != o.operation {
+		return k.operation < o.operation
+	}
+
+	return k.region < o.region
+}
+
+func (k operationKey) labels() string {
+	return fmt.Sprintf("service=%s,operation=%s,region=%s", quote(k.service), quote(k.operation), quote(k.region))
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func quote(value string) string {
+	return `"` + labelEscaper.Replace(value) + `"`
+}
@@ -0,0 +1,197 @@
+package metrics
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/mwlng/aws-go-clients/service"
+)
+
+// fakeSTS answers GetCallerIdentity, and denies every other action.
+func fakeSTS(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("parsing request: %v", err)
+		}
+
+		w.Header().Set("Content-Type", "text/xml")
+
+		if r.Form.Get("Action") != "GetCallerIdentity" {
+			w.WriteHeader(http.StatusForbidden)
+			body := `<ErrorResponse><Error><Type>Sender</Type><Code>AccessDenied</Code><Message>denied</Message></Error><RequestId>r2</RequestId></ErrorResponse>`
+			w.Write([]byte(body))
+
+			return
+		}
+
+		w.Write([]byte(`<GetCallerIdentityResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/">` +
+			`<GetCallerIdentityResult><Arn>arn:aws:iam::123456789012:user/u</Arn><UserId>U</UserId>` +
+			`<Account>123456789012</Account></GetCallerIdentityResult>` +
+			`<ResponseMetadata><RequestId>r1</RequestId></ResponseMetadata></GetCallerIdentityResponse>`))
+	}))
+}
+
+func TestPrometheusScrape(t *testing.T) {
+	api := fakeSTS(t)
+	defer api.Close()
+
+	prom := NewPrometheus("test", 0.5, 10)
+
+	svc := &service.Service{
+		Region:    "us-east-1",
+		AccessKey: "AKID",
+		SecretKey: "SECRET",
+		Endpoint:  api.URL,
+		Observers: []service.Observer{prom},
+	}
+
+	sess, err := svc.NewSessionE()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stsCli := sts.New(sess)
+
+	for i := 0; i < 2; i++ {
+		if _, err := stsCli.GetCallerIdentity(&sts.GetCallerIdentityInput{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := stsCli.GetSessionToken(&sts.GetSessionTokenInput{}); err == nil {
+		t.Fatal("GetSessionToken succeeded, want AccessDenied")
+	}
+
+	exporter := httptest.NewServer(prom)
+	defer exporter.Close()
+
+	resp, err := http.Get(exporter.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type = %q, want the text exposition format", ct)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		"# TYPE test_requests_total counter\n",
+		`test_requests_total{service="sts",operation="GetCallerIdentity",region="us-east-1",status="200",code=""} 2` + "\n",
+		`test_requests_total{service="sts",operation="GetSessionToken",region="us-east-1",status="403",code="AccessDenied"} 1` + "\n",
+		`test_request_retries_total{service="sts",operation="GetCallerIdentity",region="us-east-1"} 0` + "\n",
+		"# TYPE test_request_duration_seconds histogram\n",
+		`test_request_duration_seconds_bucket{service="sts",operation="GetCallerIdentity",region="us-east-1",le="10"} 2` + "\n",
+		`test_request_duration_seconds_bucket{service="sts",operation="GetCallerIdentity",region="us-east-1",le="+Inf"} 2` + "\n",
+		`test_request_duration_seconds_count{service="sts",operation="GetSessionToken",region="us-east-1"} 1` + "\n",
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("exposition lacks %q:\n%s", want, body)
+		}
+	}
+}
+
+func TestQuoteEscapesLabelValues(t *testing.T) {
+	if got, want := quote("a\"b\\c\nd"), `"a\"b\\c\nd"`; got != want {
+		t.Errorf("quote = %s, want %s", got, want)
+	}
+}
+
+func TestPrometheusSkipsDryRuns(t *testing.T) {
+	api := fakeSTS(t)
+	defer api.Close()
+
+	prom := NewPrometheus("test")
+
+	svc := &service.Service{
+		Region:    "us-east-1",
+		AccessKey: "AKID",
+		SecretKey: "SECRET",
+		Endpoint:  api.URL,
+		Observers: []service.Observer{prom},
+		DryRun:    service.NewPlan(),
+	}
+
+	sess, err := svc.NewSessionE()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := iam.New(sess).DeleteRole(&iam.DeleteRoleInput{RoleName: aws.String("r")}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := sts.New(sess).GetCallerIdentity(&sts.GetCallerIdentityInput{}); err != nil {
+		t.Fatal(err)
+	}
+
+	var b strings.Builder
+	if err := prom.Write(&b); err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(b.String(), "DeleteRole") {
+		t.Errorf("exposition counts the dry-run call:\n%s", b.String())
+	}
+
+	if !strings.Contains(b.String(), `operation="GetCallerIdentity"`) {
+		t.Errorf("exposition lacks the call sent:\n%s", b.String())
+	}
+}
+
+func TestPrometheusSkipsCachedCalls(t *testing.T) {
+	api := fakeSTS(t)
+	defer api.Close()
+
+	prom := NewPrometheus("test")
+	cache := service.NewResponseCache(nil, 0)
+	cache.TTLs = map[string]time.Duration{"sts/GetCallerIdentity": time.Minute}
+
+	svc := &service.Service{
+		Region:        "us-east-1",
+		AccessKey:     "AKID",
+		SecretKey:     "SECRET",
+		Endpoint:      api.URL,
+		Observers:     []service.Observer{prom},
+		ResponseCache: cache,
+	}
+
+	sess, err := svc.NewSessionE()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := sts.New(sess).GetCallerIdentity(&sts.GetCallerIdentityInput{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if stats := cache.Stats(); stats.Hits != 2 {
+		t.Fatalf("cache hits = %d, want 2", stats.Hits)
+	}
+
+	var b strings.Builder
+	if err := prom.Write(&b); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `test_requests_total{service="sts",operation="GetCallerIdentity",region="us-east-1",status="200",code=""} 1` + "\n"
+	if !strings.Contains(b.String(), want) {
+		t.Errorf("exposition lacks %q:\n%s", want, b.String())
+	}
+}
@@ -0,0 +1,334 @@
+package recorder
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Cassette is the content of a cassette file.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+type Request struct {
+	Service   string      `json:"service"`
+	Operation string      `json:"operation"`
+	Method    string      `json:"method"`
+	URL       string      `json:"url"`
+	Headers   http.Header `json:"headers,omitempty"`
+	Body      string      `json:"body,omitempty"`
+}
+
+type Response struct {
+	StatusCode int         `json:"status_code"`
+	Headers    http.Header `json:"headers,omitempty"`
+	Body       string      `json:"body,omitempty"`
+	// Encoding is "base64" when Body is not valid UTF-8.
+	Encoding string `json:"encoding,omitempty"`
+}
+
+// Load reads the cassette file at path.
+func Load(path string) (*Cassette, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("recorder: %w", err)
+	}
+
+	cassette := &Cassette{}
+	if err := json.Unmarshal(data, cassette); err != nil {
+		return nil, fmt.Errorf("recorder: %s: %w", path, err)
+	}
+
+	return cassette, nil
+}
+
+func (c *Cassette) Save(path string) error {
+	var buf bytes.Buffer
+
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(c); err != nil {
+		return fmt.Errorf("recorder: %w", err)
+	}
+
+	if err := ioutil.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("recorder: %w", err)
+	}
+
+	return nil
+}
+
+// recordedHeaders are the only request headers saved; the others carry
+// credentials, signatures or nothing worth keeping.
+var recordedHeaders = []string{"Content-Type", "X-Amz-Target"}
+
+func newInteraction(req *http.Request, body []byte, resp *http.Response, respBody []byte) Interaction {
+	key := requestKey(req.Method, req.URL, req.Header, body)
+
+	headers := http.Header{}
+
+	for _, name := range recordedHeaders {
+		if value := req.Header.Get(name); value != "" {
+			headers.Set(name, value)
+		}
+	}
+
+	response := Response{StatusCode: resp.StatusCode, Headers: resp.Header.Clone()}
+	response.Headers.Del("Set-Cookie")
+
+	if utf8.Valid(respBody) {
+		response.Body = string(respBody)
+	} else {
+		response.Body = base64.StdEncoding.EncodeToString(respBody)
+		response.Encoding = "base64"
+	}
+
+	return Interaction{
+		Request: Request{
+			Service:   key.service,
+			Operation: key.operation,
+			Method:    req.Method,
+			URL:       req.URL.String(),
+			Headers:   headers,
+			Body:      string(body),
+		},
+		Response: response,
+	}
+}
+
+func (r *Response) httpResponse(req *http.Request) (*http.Response, error) {
+	body := []byte(r.Body)
+
+	if r.Encoding == "base64" {
+		decoded, err := base64.StdEncoding.DecodeString(r.Body)
+		if err != nil {
+			return nil, fmt.Errorf("recorder: %w", err)
+		}
+
+		body = decoded
+	}
+
+	headers := r.Headers.Clone()
+	if headers == nil {
+		headers = http.Header{}
+	}
+
+	headers.Del("Content-Length")
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", r.StatusCode, http.StatusText(r.StatusCode)),
+		StatusCode:    r.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        headers,
+		Body:          ioutil.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+type matchKey struct {
+	service   string
+	operation string
+	body      string
+}
+
+func (r *Request) key() matchKey {
+	u, err := url.Parse(r.URL)
+	if err != nil {
+		u = &url.URL{}
+	}
+
+	return matchKey{
+		service:   r.Service,
+		operation: r.Operation,
+		body:      normalize(u, r.Headers, []byte(r.Body)),
+	}
+}
+
+func requestKey(method string, u *url.URL, header http.Header, body []byte) matchKey {
+	return matchKey{
+		service:   serviceOf(u, header),
+		operation: operationOf(method, u, header, body),
+		body:      normalize(u, header, body),
+	}
+}
+
+// serviceOf reads the signing name from the credential scope of the
+// signature, "AKID/20210102/us-east-1/iam/aws4_request".
+func serviceOf(u *url.URL, header http.Header) string {
+	credential := ""
+
+	if auth := header.Get("Authorization"); auth != "" {
+		if i := strings.Index(auth, "Credential="); i >= 0 {
+			credential = auth[i+len("Credential="):]
+			if j := strings.IndexAny(credential, ", "); j >= 0 {
+				credential = credential[:j]
+			}
+		}
+	} else {
+		credential = u.Query().Get("X-Amz-Credential")
+	}
+
+	if scope := strings.Split(credential, "/"); len(scope) == 5 {
+		return scope[3]
+	}
+
+	return strings.SplitN(u.Hostname(), ".", 2)[0]
+}
+
+// operationOf reads the operation from the target header of JSON protocols
+// or the action of query protocols. REST protocols only have the method
+// and path.
+func operationOf(method string, u *url.URL, header http.Header, body []byte) string {
+	if target := header.Get("X-Amz-Target"); target != "" {
+		return target[strings.LastIndex(target, ".")+1:]
+	}
+
+	if isForm(header) {
+		if values, err := url.ParseQuery(string(body)); err == nil && values.Get("Action") != "" {
+			return values.Get("Action")
+		}
+	}
+
+	return method + " " + u.EscapedPath()
+}
+
+// volatileFields are filled with random values or timestamps by the SDK or
+// by service.Role, and so differ between recording and replay.
+var volatileFields = map[string]bool{
+	"ClientToken":        true,
+	"ClientRequestToken": true,
+	"IdempotencyToken":   true,
+	"RoleSessionName":    true,
+}
+
+// normalize returns the request body and query string in a canonical form:
+// form and JSON fields sorted, presigning parameters and volatile fields
+// removed.
+func normalize(u *url.URL, header http.Header, body []byte) string {
+	query := u.Query()
+
+	for name := range query {
+		if strings.HasPrefix(name, "X-Amz-") || volatileFields[name] {
+			query.Del(name)
+		}
+	}
+
+	normalized := string(bytes.TrimSpace(body))
+
+	switch {
+	case isForm(header):
+		if values, err := url.ParseQuery(normalized); err == nil {
+			for name := range values {
+				if volatileFields[name] {
+					values.Del(name)
+				}
+			}
+
+			normalized = values.Encode()
+		}
+	case strings.HasPrefix(normalized, "{"):
+		var value interface{}
+		if err := json.Unmarshal(body, &value); err == nil {
+			if data, err := json.Marshal(dropVolatile(value)); err == nil {
+				normalized = string(data)
+			}
+		}
+	}
+
+	if len(query) == 0 {
+		return normalized
+	}
+
+	return query.Encode() + "\n" + normalized
+}
+
+func dropVolatile(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for name, field := range v {
+			if volatileFields[name] {
+				delete(v, name)
+			} else {
+				v[name] = dropVolatile(field)
+			}
+		}
+	case []interface{}:
+		for i := range v {
+			v[i] = dropVolatile(v[i])
+		}
+	}
+
+	return value
+}
+
+func isForm(header http.Header) bool {
+	return strings.HasPrefix(header.Get("Content-Type"), "application/x-www-form-urlencoded")
+}
+
+const redacted = "REDACTED"
+
+var (
+	presignParams = []string{"X-Amz-Credential", "X-Amz-Signature", "X-Amz-Security-Token"}
+
+	credentialFields = []string{"AccessKeyId", "SecretAccessKey", "SessionToken"}
+	xmlCredentials   []*regexp.Regexp
+	jsonCredentials  = regexp.MustCompile(`(?i)("(?:` + strings.Join(credentialFields, "|") + `)"\s*:\s*)"[^"]*"`)
+)
+
+func init() {
+	for _, field := range credentialFields {
+		xmlCredentials = append(xmlCredentials, regexp.MustCompile(`(<`+field+`>)[^<]*(</`+field+`>)`))
+	}
+}
+
+// scrub removes the credentials left in an interaction: presigned URL
+// parameters and the temporary credentials returned by STS, SSO or Cognito.
+func scrub(interaction *Interaction) {
+	if u, err := url.Parse(interaction.Request.URL); err == nil {
+		query := u.Query()
+		presigned := false
+
+		for _, name := range presignParams {
+			if query.Get(name) != "" {
+				query.Set(name, redacted)
+				presigned = true
+			}
+		}
+
+		if presigned {
+			u.RawQuery = query.Encode()
+		}
+
+		interaction.Request.URL = u.String()
+	}
+
+	if interaction.Response.Encoding != "" {
+		return
+	}
+
+	body := interaction.Response.Body
+
+	for _, re := range xmlCredentials {
+		body = re.ReplaceAllString(body, "${1}"+redacted+"${2}")
+	}
+
+	interaction.Response.Body = jsonCredentials.ReplaceAllString(body, `${1}"`+redacted+`"`)
+}
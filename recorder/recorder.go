@@ -0,0 +1,220 @@
+// Package recorder records the HTTP traffic of a session to a cassette file
+// and replays it, so code built on the clients can be tested offline and
+// deterministically:
+//
+//	rec, err := recorder.New("testdata/list_policies.json", recorder.Options{Mode: recorder.Replay})
+//	svc := service.Service{Region: "us-east-1", AccessKey: "test", SecretKey: "test", HTTPClient: rec.Client()}
+//	policies, err := clients.NewIAM(svc.NewSession()).ListPolicies()
+//
+// Requests are matched by service, operation and normalised body, so replay
+// does not depend on credentials, signatures, timestamps or idempotency
+// tokens.
+package recorder
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+)
+
+type Mode int
+
+const (
+	// Replay serves responses from the cassette and never touches the
+	// network.
+	Replay Mode = iota
+	// Record sends requests to AWS and saves them, with their responses, to
+	// the cassette.
+	Record
+)
+
+type Options struct {
+	Mode Mode
+	// Transport sends the requests in record mode, http.DefaultTransport
+	// when nil.
+	Transport http.RoundTripper
+	// Scrubbers run on every recorded interaction after the built-in
+	// scrubbing of credentials and signatures.
+	Scrubbers []func(*Interaction)
+}
+
+// Recorder is an http.RoundTripper recording to or replaying from a
+// cassette. It is safe for concurrent use.
+type Recorder struct {
+	path string
+	opts Options
+
+	mu       sync.Mutex
+	cassette *Cassette
+	used     []bool
+}
+
+// MissError is returned in replay mode for requests not in the cassette.
+type MissError struct {
+	Service   string
+	Operation string
+	Body      string
+}
+
+func (e *MissError) Error() string {
+	return fmt.Sprintf("recorder: no recorded interaction for %s %s with body %q", e.Service, e.Operation, e.Body)
+}
+
+// Temporary keeps the SDK from retrying a request that cannot match.
+func (e *MissError) Temporary() bool {
+	return false
+}
+
+// New returns a recorder for the cassette at path. In replay mode the
+// cassette must exist; in record mode it is replaced by Save.
+func New(path string, opts Options) (*Recorder, error) {
+	rec := &Recorder{path: path, opts: opts, cassette: &Cassette{}}
+
+	if opts.Mode == Replay {
+		cassette, err := Load(path)
+		if err != nil {
+			return nil, err
+		}
+
+		rec.cassette = cassette
+		rec.used = make([]bool, len(cassette.Interactions))
+	}
+
+	return rec, nil
+}
+
+// Client returns an HTTP client sending its requests through the recorder,
+// for service.Service.HTTPClient or aws.Config.HTTPClient.
+func (rec *Recorder) Client() *http.Client {
+	return &http.Client{Transport: rec}
+}
+
+func (rec *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if rec.opts.Mode == Record {
+		return rec.record(req, body)
+	}
+
+	return rec.replay(req, body)
+}
+
+func (rec *Recorder) record(req *http.Request, body []byte) (*http.Response, error) {
+	transport := rec.opts.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	out := req.Clone(req.Context())
+	out.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	resp, err := transport.RoundTrip(out)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+
+	interaction := newInteraction(req, body, resp, respBody)
+	scrub(&interaction)
+
+	for _, scrubber := range rec.opts.Scrubbers {
+		scrubber(&interaction)
+	}
+
+	rec.mu.Lock()
+	rec.cassette.Interactions = append(rec.cassette.Interactions, interaction)
+	rec.mu.Unlock()
+
+	return resp, nil
+}
+
+// replay serves the first unused interaction matching the request. Once all
+// the matching interactions are used, the last one is served again, so
+// polling loops replay their final state.
+func (rec *Recorder) replay(req *http.Request, body []byte) (*http.Response, error) {
+	key := requestKey(req.Method, req.URL, req.Header, body)
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	last := -1
+
+	for i := range rec.cassette.Interactions {
+		recorded := &rec.cassette.Interactions[i].Request
+		if recorded.key() != key {
+			continue
+		}
+
+		if !rec.used[i] {
+			rec.used[i] = true
+
+			return rec.cassette.Interactions[i].Response.httpResponse(req)
+		}
+
+		last = i
+	}
+
+	if last >= 0 {
+		return rec.cassette.Interactions[last].Response.httpResponse(req)
+	}
+
+	return nil, &MissError{Service: key.service, Operation: key.operation, Body: key.body}
+}
+
+// Save writes the recorded interactions to the cassette. It does nothing in
+// replay mode.
+func (rec *Recorder) Save() error {
+	if rec.opts.Mode != Record {
+		return nil
+	}
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	return rec.cassette.Save(rec.path)
+}
+
+// Unused returns the recorded interactions not replayed yet, which usually
+// means the code under test made fewer calls than when it was recorded.
+func (rec *Recorder) Unused() []Interaction {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	var unused []Interaction
+
+	for i, used := range rec.used {
+		if !used {
+			unused = append(unused, rec.cassette.Interactions[i])
+		}
+	}
+
+	return unused
+}
+
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+
+	body, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return body, nil
+}
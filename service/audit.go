@@ -0,0 +1,249 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sts"
+)
+
+// AuditRecord describes one mutating call made through a session.
+type AuditRecord struct {
+	Time time.Time `json:"time"`
+	// Caller is the ARN returned by STS GetCallerIdentity for the
+	// credentials of the call, empty when it could not be found.
+	Caller    string `json:"caller"`
+	Account   string `json:"account"`
+	Region    string `json:"region"`
+	Service   string `json:"service"`
+	Operation string `json:"operation"`
+	// Resources are the identifiers found in the input, such as
+	// "RoleName" or "Bucket" and "Key".
+	Resources map[string]string `json:"resources,omitempty"`
+	// Input is the input of the call, with secret values redacted.
+	Input     json.RawMessage `json:"input,omitempty"`
+	Outcome   string          `json:"outcome"`
+	ErrorCode string          `json:"error_code,omitempty"`
+	RequestID string          `json:"request_id,omitempty"`
+}
+
+const (
+	AuditSuccess = "success"
+	AuditFailure = "failure"
+)
+
+// AuditSink stores audit records. WriteAudit is called once the call
+// completed, from the goroutine that made it.
+type AuditSink interface {
+	WriteAudit(record AuditRecord) error
+}
+
+const auditHandlerName = "awsclients.Audit"
+
+// installAudit writes an audit record for every mutating call of sess.
+// Calls held back by a dry run are not recorded. The records sink fails to
+// store go to onError, or to the logger of the request config.
+func installAudit(sess *session.Session, sink AuditSink, onError func(AuditRecord, error)) {
+	if sink == nil {
+		return
+	}
+
+	identities := &identityCache{ids: map[*credentials.Credentials]*identity{}}
+
+	sess.Handlers.Complete.PushBackNamed(request.NamedHandler{
+		Name: auditHandlerName,
+		Fn: func(r *request.Request) {
+			if !Mutating(r.Operation.Name) || skipReason(r) == skippedDryRun {
+				return
+			}
+
+			record := newAuditRecord(r)
+
+			if id := identities.get(r.Config); id != nil {
+				record.Caller = aws.StringValue(id.Arn)
+				record.Account = aws.StringValue(id.Account)
+			}
+
+			if err := sink.WriteAudit(record); err != nil {
+				switch {
+				case onError != nil:
+					onError(record, err)
+				case r.Config.Logger != nil:
+					r.Config.Logger.Log("service: audit of", record.Service, record.Operation, "failed:", err)
+				default:
+					log.Printf("service: audit of %s %s failed: %v", record.Service, record.Operation, err)
+				}
+			}
+		},
+	})
+}
+
+func newAuditRecord(r *request.Request) AuditRecord {
+	record := AuditRecord{
+		Time:      time.Now().UTC(),
+		Region:    aws.StringValue(r.Config.Region),
+		Service:   serviceName(r),
+		Operation: r.Operation.Name,
+		Outcome:   AuditSuccess,
+		RequestID: r.RequestID,
+	}
+
+	if r.Error != nil {
+		record.Outcome = AuditFailure
+		record.ErrorCode = "Unknown"
+
+		if aerr, ok := r.Error.(awserr.Error); ok {
+			record.ErrorCode = aerr.Code()
+		}
+	}
+
+	var input map[string]interface{}
+
+	data, err := marshalInput(r.Params)
+	if err == nil {
+		err = json.Unmarshal(data, &input)
+	}
+
+	if err != nil {
+		return record
+	}
+
+	redact(input)
+
+	// The value of an SSM parameter may be a secret; tag values elsewhere
+	// are not.
+	if _, ok := input["Value"]; ok && record.Service == "ssm" {
+		input["Value"] = redacted
+	}
+
+	for name, value := range input {
+		if s, ok := value.(string); ok && isIdentifier(name) {
+			if record.Resources == nil {
+				record.Resources = map[string]string{}
+			}
+
+			record.Resources[name] = s
+		}
+	}
+
+	record.Input, _ = json.Marshal(input)
+
+	return record
+}
+
+const redacted = "REDACTED"
+
+// secretFields are input fields whose values never reach an audit record.
+var secretFields = map[string]bool{
+	"SecretString":       true,
+	"SecretBinary":       true,
+	"Password":           true,
+	"MasterUserPassword": true,
+	"NewPassword":        true,
+	"OldPassword":        true,
+	"PrivateKey":         true,
+	"CertificateChain":   true,
+	"TokenCode":          true,
+	"SecretAccessKey":    true,
+	"SessionToken":       true,
+	"Body":               true,
+	"ZipFile":            true,
+}
+
+func redact(value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for name, field := range v {
+			if secretFields[name] {
+				v[name] = redacted
+			} else {
+				redact(field)
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			redact(item)
+		}
+	}
+}
+
+var identifierSuffixes = []string{"Name", "Id", "Identifier", "Arn", "ARN", "Url", "Bucket", "Key", "Source"}
+
+func isIdentifier(field string) bool {
+	for _, suffix := range identifierSuffixes {
+		if strings.HasSuffix(field, suffix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// identityRetry is how long a failed GetCallerIdentity is remembered.
+const identityRetry = time.Minute
+
+// identityCache calls STS GetCallerIdentity once per set of credentials.
+// Concurrent calls with the same credentials wait for the first one, while
+// calls with other credentials go ahead.
+type identityCache struct {
+	mu  sync.Mutex
+	ids map[*credentials.Credentials]*identity
+}
+
+// identity is the outcome of one GetCallerIdentity call; output and checked
+// are set before done is closed.
+type identity struct {
+	done    chan struct{}
+	output  *sts.GetCallerIdentityOutput
+	checked time.Time
+}
+
+// stale reports whether the call failed long enough ago to be made again.
+func (id *identity) stale() bool {
+	select {
+	case <-id.done:
+		return id.output == nil && time.Since(id.checked) >= identityRetry
+	default:
+		return false
+	}
+}
+
+func (c *identityCache) get(cfg aws.Config) *sts.GetCallerIdentityOutput {
+	if cfg.Credentials == nil {
+		return nil
+	}
+
+	c.mu.Lock()
+
+	id, ok := c.ids[cfg.Credentials]
+	if ok && !id.stale() {
+		c.mu.Unlock()
+		<-id.done
+
+		return id.output
+	}
+
+	id = &identity{done: make(chan struct{})}
+	c.ids[cfg.Credentials] = id
+	c.mu.Unlock()
+
+	if sess, err := session.NewSession(cfg.Copy()); err == nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		id.output, _ = sts.New(sess).GetCallerIdentityWithContext(ctx, &sts.GetCallerIdentityInput{})
+		cancel()
+	}
+
+	id.checked = time.Now()
+	close(id.done)
+
+	return id.output
+}
@@ -0,0 +1,340 @@
+package service
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+)
+
+// CredentialCache keeps temporary credentials obtained with MFA on disk, so
+// processes started while they are valid do not prompt for a token again.
+// Entries are encrypted with AES-GCM under a key supplied by the caller and
+// never written to disk; the directory and every file in it are only
+// accessible to their owner.
+type CredentialCache struct {
+	dir string
+	key []byte
+}
+
+// CacheKey identifies the credentials of a cache entry. BaseAccessKeyID is
+// the access key ID of the credentials they were obtained with, which tells
+// apart the entries of static or environment credentials used without a
+// profile.
+type CacheKey struct {
+	Profile         string
+	BaseAccessKeyID string
+	RoleARN         string
+	MFASerial       string
+}
+
+// CacheEntry describes a cached set of credentials without their secrets.
+type CacheEntry struct {
+	CacheKey
+	AccessKeyID string
+	Expiration  time.Time
+}
+
+type cacheRecord struct {
+	Key             CacheKey  `json:"key"`
+	AccessKeyID     string    `json:"access_key_id"`
+	SecretAccessKey string    `json:"secret_access_key"`
+	SessionToken    string    `json:"session_token"`
+	Expiration      time.Time `json:"expiration"`
+}
+
+const (
+	cacheEntryExt  = ".cred"
+	cacheProvider  = "CredentialCache"
+	cacheKeyLength = 32
+
+	// CredentialCacheKeyEnv is the variable CredentialCacheKeyFromEnv reads
+	// the key from.
+	CredentialCacheKeyEnv = "AWS_CREDENTIAL_CACHE_KEY"
+)
+
+// DefaultCredentialCacheDir returns ~/.aws/aws-go-clients/cache.
+func DefaultCredentialCacheDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(home, ".aws", "aws-go-clients", "cache"), nil
+}
+
+// CredentialCacheKeyFromEnv returns the key in AWS_CREDENTIAL_CACHE_KEY,
+// 32 bytes encoded in standard base64, such as the output of
+// "openssl rand -base64 32" kept in a keyring or secret store.
+func CredentialCacheKeyFromEnv() ([]byte, error) {
+	value := os.Getenv(CredentialCacheKeyEnv)
+	if value == "" {
+		return nil, fmt.Errorf("service: credential cache: %s is not set", CredentialCacheKeyEnv)
+	}
+
+	key, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("service: credential cache: %s: %w", CredentialCacheKeyEnv, err)
+	}
+
+	return key, nil
+}
+
+// NewCredentialCache opens the cache in dir, or DefaultCredentialCacheDir
+// when dir is empty, creating the directory as needed. Entries are encrypted
+// with key, which must be 32 bytes long; entries written with another key
+// are ignored.
+func NewCredentialCache(dir string, key []byte) (*CredentialCache, error) {
+	if len(key) != cacheKeyLength {
+		return nil, fmt.Errorf("service: credential cache: key is %d bytes long, want %d", len(key), cacheKeyLength)
+	}
+
+	if dir == "" {
+		var err error
+
+		if dir, err = DefaultCredentialCacheDir(); err != nil {
+			return nil, fmt.Errorf("service: credential cache: %w", err)
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("service: credential cache: %w", err)
+	}
+
+	if err := os.Chmod(dir, 0700); err != nil {
+		return nil, fmt.Errorf("service: credential cache: %w", err)
+	}
+
+	return &CredentialCache{dir: dir, key: append([]byte(nil), key...)}, nil
+}
+
+// Entries returns the cached credentials, expired ones included, sorted by
+// profile, base access key ID, role and MFA serial.
+func (c *CredentialCache) Entries() ([]CacheEntry, error) {
+	paths, err := filepath.Glob(filepath.Join(c.dir, "*"+cacheEntryExt))
+	if err != nil {
+		return nil, fmt.Errorf("service: credential cache: %w", err)
+	}
+
+	entries := []CacheEntry{}
+
+	for _, path := range paths {
+		record, err := c.read(path)
+		if err != nil {
+			continue
+		}
+
+		entries = append(entries, CacheEntry{
+			CacheKey:    record.Key,
+			AccessKeyID: record.AccessKeyID,
+			Expiration:  record.Expiration,
+		})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i].CacheKey, entries[j].CacheKey
+		if a.Profile != b.Profile {
+			return a.Profile < b.Profile
+		}
+
+		if a.BaseAccessKeyID != b.BaseAccessKeyID {
+			return a.BaseAccessKeyID < b.BaseAccessKeyID
+		}
+
+		if a.RoleARN != b.RoleARN {
+			return a.RoleARN < b.RoleARN
+		}
+
+		return a.MFASerial < b.MFASerial
+	})
+
+	return entries, nil
+}
+
+// Delete removes the entry of key, if any.
+func (c *CredentialCache) Delete(key CacheKey) error {
+	err := os.Remove(c.path(key))
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("service: credential cache: %w", err)
+	}
+
+	return nil
+}
+
+// Clear removes every entry.
+func (c *CredentialCache) Clear() error {
+	paths, err := filepath.Glob(filepath.Join(c.dir, "*"+cacheEntryExt))
+	if err != nil {
+		return fmt.Errorf("service: credential cache: %w", err)
+	}
+
+	for _, path := range paths {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("service: credential cache: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Provider returns a credentials provider serving the cached credentials
+// of key while they are valid for more than window, and calling fetch for
+// new ones otherwise. fetch returns the credentials with their expiration.
+// The provider of a nil cache calls fetch every time.
+func (c *CredentialCache) Provider(key CacheKey, window time.Duration, fetch func() (credentials.Value, time.Time, error)) credentials.Provider {
+	return &cachedProvider{cache: c, key: key, window: window, fetch: fetch}
+}
+
+func (c *CredentialCache) path(key CacheKey) string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{key.Profile, key.BaseAccessKeyID, key.RoleARN, key.MFASerial}, "\x00")))
+
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:16])+cacheEntryExt)
+}
+
+func (c *CredentialCache) load(key CacheKey) (*cacheRecord, bool) {
+	record, err := c.read(c.path(key))
+	if err != nil || record.Key != key {
+		return nil, false
+	}
+
+	return record, true
+}
+
+func (c *CredentialCache) read(path string) (*cacheRecord, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := c.gcm()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < gcm.NonceSize() {
+		return nil, errors.New("truncated entry")
+	}
+
+	plain, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+	if err != nil {
+		return nil, err
+	}
+
+	record := &cacheRecord{}
+	if err := json.Unmarshal(plain, record); err != nil {
+		return nil, err
+	}
+
+	return record, nil
+}
+
+// store writes the entry to a temporary file, created readable by its owner
+// only and renamed over the previous one, so readers never see a partial
+// entry.
+func (c *CredentialCache) store(record *cacheRecord) error {
+	plain, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	gcm, err := c.gcm()
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return err
+	}
+
+	file, err := ioutil.TempFile(c.dir, ".tmp-")
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(file.Name())
+
+	if _, err := file.Write(gcm.Seal(nonce, nonce, plain, nil)); err != nil {
+		file.Close()
+
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(file.Name(), c.path(record.Key))
+}
+
+func (c *CredentialCache) gcm() (cipher.AEAD, error) {
+	block, err := aes.NewCipher(c.key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+type cachedProvider struct {
+	credentials.Expiry
+
+	cache  *CredentialCache
+	key    CacheKey
+	window time.Duration
+	fetch  func() (credentials.Value, time.Time, error)
+}
+
+func (p *cachedProvider) Retrieve() (credentials.Value, error) {
+	if p.cache == nil {
+		value, expiration, err := p.fetch()
+		if err == nil {
+			p.SetExpiration(expiration, p.window)
+		}
+
+		return value, err
+	}
+
+	if record, ok := p.cache.load(p.key); ok && time.Until(record.Expiration) > p.window {
+		p.SetExpiration(record.Expiration, p.window)
+
+		return credentials.Value{
+			AccessKeyID:     record.AccessKeyID,
+			SecretAccessKey: record.SecretAccessKey,
+			SessionToken:    record.SessionToken,
+			ProviderName:    cacheProvider,
+		}, nil
+	}
+
+	value, expiration, err := p.fetch()
+	if err != nil {
+		return credentials.Value{ProviderName: cacheProvider}, err
+	}
+
+	p.SetExpiration(expiration, p.window)
+
+	// A cache that cannot be written only costs a prompt next time.
+	_ = p.cache.store(&cacheRecord{
+		Key:             p.key,
+		AccessKeyID:     value.AccessKeyID,
+		SecretAccessKey: value.SecretAccessKey,
+		SessionToken:    value.SessionToken,
+		Expiration:      expiration,
+	})
+
+	return value, nil
+}
@@ -0,0 +1,235 @@
+package service
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+)
+
+func testCacheKey() []byte {
+	return bytes.Repeat([]byte{7}, cacheKeyLength)
+}
+
+func newTestCache(t *testing.T) *CredentialCache {
+	t.Helper()
+
+	cache, err := NewCredentialCache(filepath.Join(t.TempDir(), "cache"), testCacheKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return cache
+}
+
+func TestCredentialCacheRoundTrip(t *testing.T) {
+	cache := newTestCache(t)
+
+	record := &cacheRecord{
+		Key:             CacheKey{Profile: "prod", RoleARN: "arn:aws:iam::111122223333:role/admin"},
+		AccessKeyID:     "ASIAEXAMPLE",
+		SecretAccessKey: "secret-access-key",
+		SessionToken:    "session-token",
+		Expiration:      time.Now().Add(time.Hour).Round(0).UTC(),
+	}
+
+	if err := cache.store(record); err != nil {
+		t.Fatal(err)
+	}
+
+	got, ok := cache.load(record.Key)
+	if !ok {
+		t.Fatal("load found no entry after store")
+	}
+
+	if *got != *record {
+		t.Errorf("load = %+v, want %+v", got, record)
+	}
+
+	data, err := ioutil.ReadFile(cache.path(record.Key))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, secret := range []string{record.AccessKeyID, record.SecretAccessKey, record.SessionToken} {
+		if bytes.Contains(data, []byte(secret)) {
+			t.Errorf("entry holds %q in plaintext", secret)
+		}
+	}
+
+	other, err := NewCredentialCache(cache.dir, bytes.Repeat([]byte{8}, cacheKeyLength))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := other.load(record.Key); ok {
+		t.Error("a cache with another key read the entry")
+	}
+}
+
+func TestCredentialCachePermissions(t *testing.T) {
+	cache := newTestCache(t)
+
+	if err := cache.store(&cacheRecord{Key: CacheKey{Profile: "p"}, Expiration: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := os.Stat(cache.dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if perm := info.Mode().Perm(); perm != 0700 {
+		t.Errorf("directory mode = %o, want 700", perm)
+	}
+
+	info, err = os.Stat(cache.path(CacheKey{Profile: "p"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("entry mode = %o, want 600", perm)
+	}
+}
+
+func TestNewCredentialCacheRejectsShortKeys(t *testing.T) {
+	if _, err := NewCredentialCache(t.TempDir(), []byte("short")); err == nil {
+		t.Error("NewCredentialCache took a 5-byte key")
+	}
+}
+
+func TestCredentialCacheKeyFromEnv(t *testing.T) {
+	old, set := os.LookupEnv(CredentialCacheKeyEnv)
+	t.Cleanup(func() {
+		if set {
+			os.Setenv(CredentialCacheKeyEnv, old)
+		} else {
+			os.Unsetenv(CredentialCacheKeyEnv)
+		}
+	})
+
+	os.Setenv(CredentialCacheKeyEnv, base64.StdEncoding.EncodeToString(testCacheKey()))
+
+	key, err := CredentialCacheKeyFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(key, testCacheKey()) {
+		t.Errorf("key = %x, want %x", key, testCacheKey())
+	}
+
+	os.Unsetenv(CredentialCacheKeyEnv)
+
+	if _, err := CredentialCacheKeyFromEnv(); err == nil {
+		t.Error("CredentialCacheKeyFromEnv succeeded without the variable")
+	}
+}
+
+func TestCachedProviderExpiryWindow(t *testing.T) {
+	cache := newTestCache(t)
+	key := CacheKey{Profile: "p", MFASerial: "arn:aws:iam::111122223333:mfa/alice"}
+
+	fetches := 0
+	expiration := time.Now().Add(time.Hour)
+	fetch := func() (credentials.Value, time.Time, error) {
+		fetches++
+
+		return credentials.Value{AccessKeyID: "ASIAFETCHED", SecretAccessKey: "s", SessionToken: "t"}, expiration, nil
+	}
+
+	if _, err := cache.Provider(key, 5*time.Minute, fetch).Retrieve(); err != nil {
+		t.Fatal(err)
+	}
+
+	value, err := cache.Provider(key, 5*time.Minute, fetch).Retrieve()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if fetches != 1 || value.ProviderName != cacheProvider {
+		t.Errorf("fetches = %d, provider = %q; want the second provider served by the cache", fetches, value.ProviderName)
+	}
+
+	// Valid for less than the window: fetched again.
+	if _, err := cache.Provider(key, 2*time.Hour, fetch).Retrieve(); err != nil {
+		t.Fatal(err)
+	}
+
+	if fetches != 2 {
+		t.Errorf("fetches = %d, want 2 once the entry expires within the window", fetches)
+	}
+
+	failing := func() (credentials.Value, time.Time, error) {
+		return credentials.Value{}, time.Time{}, errors.New("no token")
+	}
+
+	expiration = time.Now().Add(time.Minute)
+	if err := cache.store(&cacheRecord{Key: key, AccessKeyID: "ASIAOLD", Expiration: expiration}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := cache.Provider(key, 5*time.Minute, failing).Retrieve(); err == nil {
+		t.Error("Retrieve served credentials expiring within the window")
+	}
+}
+
+func TestCredentialCacheEntriesDeleteClear(t *testing.T) {
+	cache := newTestCache(t)
+
+	keys := []CacheKey{
+		{Profile: "b"},
+		{BaseAccessKeyID: "AKIAB", RoleARN: "arn:aws:iam::1:role/r"},
+		{BaseAccessKeyID: "AKIAA", RoleARN: "arn:aws:iam::1:role/r"},
+		{Profile: "a", MFASerial: "m"},
+	}
+
+	for _, key := range keys {
+		if err := cache.store(&cacheRecord{Key: key, AccessKeyID: "ASIA" + key.Profile, SecretAccessKey: "s"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entries, err := cache.Entries()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []CacheKey{keys[2], keys[1], keys[3], keys[0]}
+	if len(entries) != len(want) {
+		t.Fatalf("Entries returned %d entries, want %d", len(entries), len(want))
+	}
+
+	for i, e := range entries {
+		if e.CacheKey != want[i] {
+			t.Errorf("entry %d = %+v, want %+v", i, e.CacheKey, want[i])
+		}
+	}
+
+	if err := cache.Delete(keys[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cache.Delete(keys[0]); err != nil {
+		t.Errorf("Delete of a missing entry: %v", err)
+	}
+
+	if entries, _ := cache.Entries(); len(entries) != 3 {
+		t.Errorf("Entries after Delete returned %d entries, want 3", len(entries))
+	}
+
+	if err := cache.Clear(); err != nil {
+		t.Fatal(err)
+	}
+
+	if entries, _ := cache.Entries(); len(entries) != 0 {
+		t.Errorf("Entries after Clear returned %d entries, want none", len(entries))
+	}
+}
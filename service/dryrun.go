@@ -0,0 +1,222 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+)
+
+// Plan collects the calls a dry-run session would have made. It is safe for
+// concurrent use.
+type Plan struct {
+	mu    sync.Mutex
+	calls []PlannedCall
+}
+
+// PlannedCall is a mutating call held back by a dry-run session.
+type PlannedCall struct {
+	Service   string          `json:"service"`
+	Operation string          `json:"operation"`
+	Region    string          `json:"region"`
+	Time      time.Time       `json:"time"`
+	Input     json.RawMessage `json:"input"`
+}
+
+func NewPlan() *Plan {
+	return &Plan{}
+}
+
+// Calls returns the planned calls in the order they were made.
+func (p *Plan) Calls() []PlannedCall {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]PlannedCall(nil), p.calls...)
+}
+
+// Reset forgets the planned calls.
+func (p *Plan) Reset() {
+	p.mu.Lock()
+	p.calls = nil
+	p.mu.Unlock()
+}
+
+func (p *Plan) MarshalJSON() ([]byte, error) {
+	calls := p.Calls()
+	if calls == nil {
+		calls = []PlannedCall{}
+	}
+
+	return json.Marshal(struct {
+		Calls []PlannedCall `json:"calls"`
+	}{calls})
+}
+
+// JSON returns the plan as indented JSON.
+func (p *Plan) JSON() ([]byte, error) {
+	return json.MarshalIndent(p, "", "  ")
+}
+
+func (p *Plan) add(r *request.Request) {
+	input, err := marshalInput(r.Params)
+	if err != nil {
+		input, _ = json.Marshal(err.Error())
+	}
+
+	call := PlannedCall{
+		Service:   serviceName(r),
+		Operation: r.Operation.Name,
+		Region:    aws.StringValue(r.Config.Region),
+		Time:      time.Now(),
+		Input:     input,
+	}
+
+	p.mu.Lock()
+	p.calls = append(p.calls, call)
+	p.mu.Unlock()
+}
+
+// marshalInput returns params as JSON, leaving out the fields not set.
+func marshalInput(params interface{}) ([]byte, error) {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(dropNulls(value))
+}
+
+func dropNulls(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for name, field := range v {
+			if field == nil {
+				delete(v, name)
+			} else {
+				v[name] = dropNulls(field)
+			}
+		}
+	case []interface{}:
+		for i := range v {
+			v[i] = dropNulls(v[i])
+		}
+	}
+
+	return value
+}
+
+const dryRunHandlerName = "awsclients.DryRun"
+
+// installDryRun adds the mutating calls of sess to plan instead of sending
+// them. The calls are still validated, since the handler runs once the
+// Validate handlers passed, and return the zero value of their output.
+func installDryRun(sess *session.Session, plan *Plan) {
+	if plan == nil {
+		return
+	}
+
+	sess.Handlers.Build.PushFrontNamed(request.NamedHandler{
+		Name: dryRunHandlerName,
+		Fn: func(r *request.Request) {
+			if r.Error != nil || !Mutating(r.Operation.Name) {
+				return
+			}
+
+			plan.add(r)
+			skipSend(r, skippedDryRun)
+		},
+	})
+}
+
+// sendSkip tells why a request completed without being sent.
+type sendSkip int
+
+const (
+	notSkipped sendSkip = iota
+	skippedDryRun
+	skippedCached
+)
+
+type sendSkipKey struct{}
+
+// skipSend makes r complete without being sent, leaving its output as it is.
+// The reason is kept in the context of r for the Complete handlers.
+func skipSend(r *request.Request, reason sendSkip) {
+	for _, list := range []*request.HandlerList{
+		&r.Handlers.Sign, &r.Handlers.Send,
+		&r.Handlers.ValidateResponse, &r.Handlers.UnmarshalMeta,
+		&r.Handlers.Unmarshal, &r.Handlers.UnmarshalError,
+		&r.Handlers.Retry, &r.Handlers.AfterRetry,
+	} {
+		list.Clear()
+	}
+
+	r.HTTPResponse = &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{},
+		Body:       ioutil.NopCloser(bytes.NewReader(nil)),
+	}
+	r.SetContext(context.WithValue(r.Context(), sendSkipKey{}, reason))
+}
+
+// skipReason returns why r was not sent, or notSkipped.
+func skipReason(r *request.Request) sendSkip {
+	reason, _ := r.Context().Value(sendSkipKey{}).(sendSkip)
+
+	return reason
+}
+
+var (
+	mutatingVerbs = []string{
+		"Abort", "Add", "Allocate", "Apply", "Associate", "Attach", "Authorize",
+		"Batch", "Cancel", "Change", "Complete", "Copy", "Create", "Delete",
+		"Deregister", "Detach", "Disable", "Disassociate", "Enable", "Execute",
+		"Failover", "Import", "Invoke", "Modify", "Promote", "Publish", "Purge",
+		"Put", "Reboot", "Register", "Release", "Remove", "Replace", "Reset",
+		"Restore", "Revoke", "Rotate", "Run", "Send", "Set", "Start", "Stop",
+		"Tag", "Terminate", "TransactWrite", "Untag", "Update", "Upload",
+	}
+	readOnlyVerbs = []string{"BatchCheck", "BatchDescribe", "BatchGet"}
+)
+
+// Mutating reports whether the operation named op changes resources, judging
+// by its verb: DeleteRole does, DescribeDBClusters does not.
+func Mutating(op string) bool {
+	for _, verb := range readOnlyVerbs {
+		if hasVerb(op, verb) {
+			return false
+		}
+	}
+
+	for _, verb := range mutatingVerbs {
+		if hasVerb(op, verb) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func hasVerb(op, verb string) bool {
+	if !strings.HasPrefix(op, verb) {
+		return false
+	}
+
+	rest := op[len(verb):]
+
+	return rest == "" || (rest[0] >= 'A' && rest[0] <= 'Z')
+}
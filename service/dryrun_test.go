@@ -0,0 +1,133 @@
+package service
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/sqs"
+)
+
+func TestMutating(t *testing.T) {
+	for op, want := range map[string]bool{
+		"GetObject":               false,
+		"PutObject":               true,
+		"HeadBucket":              false,
+		"DescribeInstances":       false,
+		"DescribeDBClusters":      false,
+		"ModifyDBCluster":         true,
+		"ModifyInstanceAttribute": true,
+		"ListRoles":               false,
+		"DeleteRole":              true,
+		"CreateQueue":             true,
+		"SendMessage":             true,
+		"ReceiveMessage":          false,
+		"GetCallerIdentity":       false,
+		"TerminateInstances":      true,
+		"RunInstances":            true,
+		"StartQueryExecution":     true,
+		"GetQueryResults":         false,
+		"BatchWriteItem":          true,
+		"BatchGetItem":            false,
+		"BatchGetImage":           false,
+		"BatchDescribeEntities":   false,
+		"TransactWriteItems":      true,
+		"TransactGetItems":        false,
+		"Query":                   false,
+		"Scan":                    false,
+		"TagResource":             true,
+		"UntagResource":           true,
+		"ListTagsForResource":     false,
+		"UpdateItem":              true,
+		"UploadLayerPart":         true,
+		"Invoke":                  true,
+		// Verbs must end at a word boundary.
+		"Settings":         false,
+		"Starts":           false,
+		"RunningInstances": false,
+		"Describe":         false,
+		"Delete":           true,
+	} {
+		if got := Mutating(op); got != want {
+			t.Errorf("Mutating(%q) = %v, want %v", op, got, want)
+		}
+	}
+}
+
+func TestDryRunNeverSends(t *testing.T) {
+	api := newFakeAPI(t)
+	plan := NewPlan()
+	sqsCli := sqs.New(api.session(t, Service{DryRun: plan}))
+
+	out, err := sqsCli.CreateQueue(&sqs.CreateQueueInput{QueueName: aws.String("orders")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if out.QueueUrl != nil {
+		t.Errorf("CreateQueue returned %q, want the zero output", aws.StringValue(out.QueueUrl))
+	}
+
+	if _, err := sqsCli.DeleteQueue(&sqs.DeleteQueueInput{QueueUrl: aws.String("https://sqs/orders")}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Invalid calls fail validation rather than being planned.
+	if _, err := sqsCli.CreateQueue(&sqs.CreateQueueInput{}); err == nil {
+		t.Error("CreateQueue without a name was planned")
+	}
+
+	// Read-only calls are still sent.
+	if _, err := sqsCli.ListQueues(&sqs.ListQueuesInput{}); err != nil {
+		t.Fatal(err)
+	}
+
+	for action, want := range map[string]int{"CreateQueue": 0, "DeleteQueue": 0, "ListQueues": 1} {
+		if n := api.count(action); n != want {
+			t.Errorf("sent %d %s calls, want %d", n, action, want)
+		}
+	}
+
+	calls := plan.Calls()
+	if len(calls) != 2 {
+		t.Fatalf("planned %d calls, want 2", len(calls))
+	}
+
+	for i, want := range []string{"CreateQueue", "DeleteQueue"} {
+		call := calls[i]
+		if call.Service != "sqs" || call.Operation != want || call.Region != "us-east-1" {
+			t.Errorf("call %d = %s/%s in %s, want sqs/%s in us-east-1", i, call.Service, call.Operation, call.Region, want)
+		}
+	}
+
+	var input map[string]interface{}
+	if err := json.Unmarshal(calls[0].Input, &input); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(input) != 1 || input["QueueName"] != "orders" {
+		t.Errorf("planned input = %s, want the queue name only", calls[0].Input)
+	}
+}
+
+func TestDryRunKeepsResponseCache(t *testing.T) {
+	api := newFakeAPI(t)
+	cache := NewResponseCache(nil, time.Minute)
+	sqsCli := sqs.New(api.session(t, Service{DryRun: NewPlan(), ResponseCache: cache}))
+
+	for i := 0; i < 2; i++ {
+		if _, err := sqsCli.ListQueues(&sqs.ListQueuesInput{}); err != nil {
+			t.Fatal(err)
+		}
+
+		// A planned call changes nothing, so it must not purge the cache.
+		if _, err := sqsCli.CreateQueue(&sqs.CreateQueueInput{QueueName: aws.String("q")}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if n := api.count("ListQueues"); n != 1 {
+		t.Errorf("sent %d ListQueues, want 1", n)
+	}
+}
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/endpoints"
+)
+
+// endpointAliases maps SDK endpoint IDs onto the client names used as keys
+// of Service.Endpoints where the two differ.
+var endpointAliases = map[string]string{
+	"api.ecr":          "ecr",
+	"elasticmapreduce": "emr",
+}
+
+// endpointConfig returns the endpoint settings of the session. The
+// resolver is part of the session config, so it applies to every client
+// built from the session, including the STS clients of the role chain.
+func (svc *Service) endpointConfig() *aws.Config {
+	cfg := &aws.Config{}
+
+	if svc.S3ForcePathStyle {
+		cfg.S3ForcePathStyle = aws.Bool(true)
+	}
+
+	if svc.DisableSSL {
+		cfg.DisableSSL = aws.Bool(true)
+	}
+
+	if svc.Endpoint == "" && len(svc.Endpoints) == 0 {
+		return cfg
+	}
+
+	overrides := make(map[string]string, len(svc.Endpoints))
+	for name, endpoint := range svc.Endpoints {
+		overrides[name] = endpoint
+	}
+
+	fallback := svc.Endpoint
+	disableSSL := svc.DisableSSL
+
+	cfg.EndpointResolver = endpoints.ResolverFunc(func(service, region string, opts ...func(*endpoints.Options)) (endpoints.ResolvedEndpoint, error) {
+		endpoint, ok := overrides[service]
+		if !ok {
+			endpoint, ok = overrides[endpointAliases[service]]
+		}
+
+		if !ok && fallback != "" {
+			endpoint, ok = fallback, true
+		}
+
+		if !ok {
+			return endpoints.DefaultResolver().EndpointFor(service, region, opts...)
+		}
+
+		return endpoints.ResolvedEndpoint{
+			URL:           endpoints.AddScheme(endpoint, disableSSL),
+			SigningRegion: region,
+		}, nil
+	})
+
+	return cfg
+}
+
+// LoadEndpointsFromEnv sets the endpoint settings from the environment:
+//
+//	AWS_ENDPOINT_URL             endpoint of every service
+//	AWS_ENDPOINT_URL_<SERVICE>   endpoint of one service, e.g. AWS_ENDPOINT_URL_DYNAMODB
+//	AWS_S3_FORCE_PATH_STYLE      true for path-style S3 addressing
+//	AWS_DISABLE_SSL              true to use http
+//
+// Service names are the client names, upper-cased. Variables that are not
+// set leave the current settings alone.
+func (svc *Service) LoadEndpointsFromEnv() error {
+	const prefix = "AWS_ENDPOINT_URL_"
+
+	if endpoint := os.Getenv("AWS_ENDPOINT_URL"); endpoint != "" {
+		svc.Endpoint = endpoint
+	}
+
+	for _, kv := range os.Environ() {
+		parts := strings.SplitN(kv, "=", 2)
+		if !strings.HasPrefix(parts[0], prefix) || len(parts) != 2 || parts[1] == "" {
+			continue
+		}
+
+		if svc.Endpoints == nil {
+			svc.Endpoints = map[string]string{}
+		}
+
+		svc.Endpoints[strings.ToLower(strings.TrimPrefix(parts[0], prefix))] = parts[1]
+	}
+
+	for name, field := range map[string]*bool{
+		"AWS_S3_FORCE_PATH_STYLE": &svc.S3ForcePathStyle,
+		"AWS_DISABLE_SSL":         &svc.DisableSSL,
+	} {
+		value := os.Getenv(name)
+		if value == "" {
+			continue
+		}
+
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return invalid("%s=%q is not a boolean", name, value)
+		}
+
+		*field = b
+	}
+
+	return nil
+}
+
+func validateEndpoint(name, endpoint string) error {
+	u, err := url.Parse(endpoints.AddScheme(endpoint, false))
+	if err != nil || u.Host == "" {
+		return invalid("malformed endpoint %q for %s", endpoint, name)
+	}
+
+	return nil
+}
+
+func (svc *Service) validateEndpoints() error {
+	if svc.Endpoint != "" {
+		if err := validateEndpoint("all services", svc.Endpoint); err != nil {
+			return err
+		}
+	}
+
+	for name, endpoint := range svc.Endpoints {
+		if err := validateEndpoint(fmt.Sprintf("service %q", name), endpoint); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,281 @@
+package service
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Config holds the named environments of a config file, such as
+//
+//	environments:
+//	  prod-us:
+//	    profile: prod
+//	    region: us-east-1
+//	    roles:
+//	      - arn: arn:aws:iam::111122223333:role/deployer
+//	        session_name: deploy
+//	        duration: 30m
+//	    retry: {max_attempts: 5, min_delay: 100ms, max_delay: 5s}
+//	    default_tags: {team: payments, env: prod}
+//	  staging-eu:
+//	    region: eu-west-1
+//	    credentials:
+//	      access_key_id: ${STAGING_ACCESS_KEY_ID}
+//	      secret_access_key: ${STAGING_SECRET_ACCESS_KEY}
+//	    endpoints: {s3: http://localhost:4566}
+//	    s3_force_path_style: true
+//
+// Besides those, environments take mfa (serial, duration), endpoint,
+// disable_ssl, rate_limits keyed like Service.RateLimits (rate, burst), and
+// for roles external_id and mfa_serial, and for retry min_throttle_delay and
+// retryable_codes. Durations are written like "30m" or in seconds.
+//
+// Config files are YAML, of which JSON is a subset. Credentials may
+// reference environment variables as ${NAME}, keeping keys out of the file.
+type Config struct {
+	environments map[string]*environment
+}
+
+type configFile struct {
+	Environments map[string]*environment `yaml:"environments"`
+}
+
+type environment struct {
+	Profile     string `yaml:"profile"`
+	Credentials *struct {
+		AccessKeyID     string `yaml:"access_key_id"`
+		SecretAccessKey string `yaml:"secret_access_key"`
+		SessionToken    string `yaml:"session_token"`
+	} `yaml:"credentials"`
+	Region string `yaml:"region"`
+	MFA    *struct {
+		Serial   string   `yaml:"serial"`
+		Duration duration `yaml:"duration"`
+	} `yaml:"mfa"`
+	Roles []struct {
+		ARN         string   `yaml:"arn"`
+		ExternalID  string   `yaml:"external_id"`
+		SessionName string   `yaml:"session_name"`
+		Duration    duration `yaml:"duration"`
+		MFASerial   string   `yaml:"mfa_serial"`
+	} `yaml:"roles"`
+	Endpoint         string            `yaml:"endpoint"`
+	Endpoints        map[string]string `yaml:"endpoints"`
+	S3ForcePathStyle bool              `yaml:"s3_force_path_style"`
+	DisableSSL       bool              `yaml:"disable_ssl"`
+	Retry            *struct {
+		MaxAttempts      int      `yaml:"max_attempts"`
+		MinDelay         duration `yaml:"min_delay"`
+		MaxDelay         duration `yaml:"max_delay"`
+		MinThrottleDelay duration `yaml:"min_throttle_delay"`
+		RetryableCodes   []string `yaml:"retryable_codes"`
+	} `yaml:"retry"`
+	RateLimits map[string]struct {
+		Rate  float64 `yaml:"rate"`
+		Burst int     `yaml:"burst"`
+	} `yaml:"rate_limits"`
+	DefaultTags map[string]string `yaml:"default_tags"`
+}
+
+type duration time.Duration
+
+func (d *duration) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	if err := unmarshal(&s); err != nil {
+		return err
+	}
+
+	if seconds, err := strconv.ParseFloat(s, 64); err == nil {
+		*d = duration(seconds * float64(time.Second))
+
+		return nil
+	}
+
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return fmt.Errorf("invalid duration %q", s)
+	}
+
+	*d = duration(v)
+
+	return nil
+}
+
+// ConfigFile returns the path of the config file read by FromEnvironment:
+// $AWS_ENVIRONMENTS_FILE, or environments.yaml next to the shared config
+// file.
+func ConfigFile() string {
+	if name := os.Getenv("AWS_ENVIRONMENTS_FILE"); name != "" {
+		return name
+	}
+
+	return filepath.Join(filepath.Dir(sharedConfigFile()), "environments.yaml")
+}
+
+// LoadConfig reads a config file. Unknown settings are reported as errors
+// rather than ignored.
+func LoadConfig(path string) (*Config, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("service: reading config: %w", err)
+	}
+
+	var file configFile
+	if err := yaml.UnmarshalStrict(data, &file); err != nil {
+		return nil, invalid("%s: %v", path, err)
+	}
+
+	return &Config{environments: file.Environments}, nil
+}
+
+// FromEnvironment returns the Service of the environment named name in
+// ConfigFile, with the overrides of Config.Environment.
+func FromEnvironment(name string) (*Service, error) {
+	cfg, err := LoadConfig(ConfigFile())
+	if err != nil {
+		return nil, err
+	}
+
+	return cfg.Environment(name)
+}
+
+// Names returns the names of the environments, sorted.
+func (cfg *Config) Names() []string {
+	names := make([]string, 0, len(cfg.environments))
+	for name := range cfg.environments {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// Environment returns the Service of the environment named name. The
+// variables of the AWS CLI override the settings of the file:
+//
+//	AWS_PROFILE                                   profile, in place of static credentials
+//	AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY,
+//	AWS_SESSION_TOKEN                             static credentials
+//	AWS_REGION, AWS_DEFAULT_REGION                region
+//	AWS_MAX_ATTEMPTS                              retry attempts
+//
+// as do the endpoint variables read by LoadEndpointsFromEnv.
+func (cfg *Config) Environment(name string) (*Service, error) {
+	env, ok := cfg.environments[name]
+	if !ok || env == nil {
+		return nil, invalid("unknown environment %q; known environments: %v", name, cfg.Names())
+	}
+
+	svc := env.service()
+
+	if err := svc.loadEnv(); err != nil {
+		return nil, err
+	}
+
+	return svc, nil
+}
+
+func (env *environment) service() *Service {
+	svc := &Service{
+		Profile:          env.Profile,
+		Region:           env.Region,
+		Endpoint:         env.Endpoint,
+		S3ForcePathStyle: env.S3ForcePathStyle,
+		DisableSSL:       env.DisableSSL,
+	}
+
+	if c := env.Credentials; c != nil {
+		svc.AccessKey = os.ExpandEnv(c.AccessKeyID)
+		svc.SecretKey = os.ExpandEnv(c.SecretAccessKey)
+		svc.SessToken = os.ExpandEnv(c.SessionToken)
+	}
+
+	if env.MFA != nil {
+		svc.MFA = &MFA{Serial: env.MFA.Serial, Duration: time.Duration(env.MFA.Duration)}
+	}
+
+	for _, role := range env.Roles {
+		svc.Roles = append(svc.Roles, Role{
+			ARN:         role.ARN,
+			ExternalID:  role.ExternalID,
+			SessionName: role.SessionName,
+			Duration:    time.Duration(role.Duration),
+			MFASerial:   role.MFASerial,
+		})
+	}
+
+	if len(env.Endpoints) > 0 {
+		svc.Endpoints = make(map[string]string, len(env.Endpoints))
+		for name, endpoint := range env.Endpoints {
+			svc.Endpoints[name] = endpoint
+		}
+	}
+
+	if r := env.Retry; r != nil {
+		svc.Retry = &RetryPolicy{
+			MaxAttempts:      r.MaxAttempts,
+			MinDelay:         time.Duration(r.MinDelay),
+			MaxDelay:         time.Duration(r.MaxDelay),
+			MinThrottleDelay: time.Duration(r.MinThrottleDelay),
+			RetryableCodes:   r.RetryableCodes,
+		}
+	}
+
+	if len(env.RateLimits) > 0 {
+		svc.RateLimits = make(map[string]RateLimit, len(env.RateLimits))
+		for key, limit := range env.RateLimits {
+			svc.RateLimits[key] = RateLimit{Rate: limit.Rate, Burst: limit.Burst}
+		}
+	}
+
+	if len(env.DefaultTags) > 0 {
+		svc.DefaultTags = make(map[string]string, len(env.DefaultTags))
+		for key, value := range env.DefaultTags {
+			svc.DefaultTags[key] = value
+		}
+	}
+
+	return svc
+}
+
+func (svc *Service) loadEnv() error {
+	if profile := os.Getenv("AWS_PROFILE"); profile != "" {
+		svc.Profile = profile
+		svc.AccessKey, svc.SecretKey, svc.SessToken = "", "", ""
+	}
+
+	if key := os.Getenv("AWS_ACCESS_KEY_ID"); key != "" {
+		svc.AccessKey = key
+		svc.SecretKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+		svc.SessToken = os.Getenv("AWS_SESSION_TOKEN")
+	}
+
+	for _, name := range []string{"AWS_DEFAULT_REGION", "AWS_REGION"} {
+		if region := os.Getenv(name); region != "" {
+			svc.Region = region
+		}
+	}
+
+	if value := os.Getenv("AWS_MAX_ATTEMPTS"); value != "" {
+		attempts, err := strconv.Atoi(value)
+		if err != nil || attempts < 1 {
+			return invalid("AWS_MAX_ATTEMPTS=%q is not a positive integer", value)
+		}
+
+		if svc.Retry == nil {
+			svc.Retry = &RetryPolicy{}
+		}
+
+		svc.Retry.MaxAttempts = attempts
+	}
+
+	return svc.LoadEndpointsFromEnv()
+}
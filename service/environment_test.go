@@ -0,0 +1,257 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/sqs"
+)
+
+const testConfig = `
+environments:
+  prod-us:
+    profile: prod
+    region: us-east-1
+    roles:
+      - arn: arn:aws:iam::111122223333:role/deployer
+        session_name: deploy
+        duration: 30m
+    retry: {max_attempts: 5, min_delay: 100ms, max_delay: 5}
+    rate_limits:
+      ecs/DescribeTasks: {rate: 10, burst: 20}
+    default_tags: {team: payments, env: prod}
+  staging-eu:
+    region: eu-west-1
+    credentials:
+      access_key_id: ${TEST_STAGING_KEY_ID}
+      secret_access_key: ${TEST_STAGING_SECRET}
+    endpoints: {s3: http://localhost:4566}
+    s3_force_path_style: true
+`
+
+// setenv sets the environment variables for the test, and unsets the other
+// variables Config.Environment reads.
+func setenv(t *testing.T, vars map[string]string) {
+	t.Helper()
+
+	for _, kv := range os.Environ() {
+		name := kv[:strings.Index(kv, "=")]
+		if _, ok := vars[name]; !ok && strings.HasPrefix(name, "AWS_") {
+			vars[name] = ""
+		}
+	}
+
+	for name, value := range vars {
+		old, ok := os.LookupEnv(name)
+		if value == "" {
+			os.Unsetenv(name)
+		} else {
+			os.Setenv(name, value)
+		}
+
+		t.Cleanup(func() {
+			if ok {
+				os.Setenv(name, old)
+			} else {
+				os.Unsetenv(name)
+			}
+		})
+	}
+}
+
+func writeConfig(t *testing.T, config string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "environments.yaml")
+	if err := ioutil.WriteFile(path, []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestFromEnvironment(t *testing.T) {
+	setenv(t, map[string]string{
+		"AWS_ENVIRONMENTS_FILE": writeConfig(t, testConfig),
+		"TEST_STAGING_KEY_ID":   "AKIDSTAGING",
+		"TEST_STAGING_SECRET":   "SECRET",
+	})
+
+	svc, err := FromEnvironment("prod-us")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &Service{
+		Profile: "prod",
+		Region:  "us-east-1",
+		Roles: []Role{{
+			ARN:         "arn:aws:iam::111122223333:role/deployer",
+			SessionName: "deploy",
+			Duration:    30 * time.Minute,
+		}},
+		Retry:       &RetryPolicy{MaxAttempts: 5, MinDelay: 100 * time.Millisecond, MaxDelay: 5 * time.Second},
+		RateLimits:  map[string]RateLimit{"ecs/DescribeTasks": {Rate: 10, Burst: 20}},
+		DefaultTags: map[string]string{"team": "payments", "env": "prod"},
+	}
+
+	if !reflect.DeepEqual(svc, want) {
+		t.Errorf("prod-us = %+v, want %+v", svc, want)
+	}
+
+	svc, err = FromEnvironment("staging-eu")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if svc.AccessKey != "AKIDSTAGING" || svc.SecretKey != "SECRET" {
+		t.Errorf("staging-eu credentials = %q, %q, want them expanded from the environment", svc.AccessKey, svc.SecretKey)
+	}
+
+	if svc.Endpoints["s3"] != "http://localhost:4566" || !svc.S3ForcePathStyle {
+		t.Errorf("staging-eu = %+v, want the s3 endpoint with path style", svc)
+	}
+}
+
+func TestLoadConfigIsStrict(t *testing.T) {
+	for name, config := range map[string]string{
+		"unknown environment setting": "environments:\n  dev:\n    regoin: us-east-1\n",
+		"unknown retry setting":       "environments:\n  dev:\n    retry: {attempts: 3}\n",
+		"unknown top-level setting":   "environment:\n  dev:\n    region: us-east-1\n",
+		"bad duration":                "environments:\n  dev:\n    mfa: {serial: x, duration: soon}\n",
+	} {
+		_, err := LoadConfig(writeConfig(t, config))
+		if !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("%s: LoadConfig = %v, want ErrInvalidConfig", name, err)
+		}
+	}
+
+	if _, err := LoadConfig(filepath.Join(t.TempDir(), "missing.yaml")); !os.IsNotExist(errors.Unwrap(err)) {
+		t.Errorf("LoadConfig of a missing file = %v, want the not-exist error", err)
+	}
+}
+
+func TestFromEnvironmentUnknownName(t *testing.T) {
+	setenv(t, map[string]string{"AWS_ENVIRONMENTS_FILE": writeConfig(t, testConfig)})
+
+	_, err := FromEnvironment("prod-eu")
+	if !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("FromEnvironment = %v, want ErrInvalidConfig", err)
+	}
+
+	if !strings.Contains(err.Error(), "[prod-us staging-eu]") {
+		t.Errorf("error %q does not list the known environments", err)
+	}
+}
+
+func TestFromEnvironmentVariablesWin(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		vars map[string]string
+		want func(*Service) bool
+	}{
+		{
+			"profile replaces static credentials",
+			map[string]string{"AWS_PROFILE": "ops"},
+			func(svc *Service) bool { return svc.Profile == "ops" && svc.AccessKey == "" && svc.SecretKey == "" },
+		},
+		{
+			"static credentials",
+			map[string]string{"AWS_ACCESS_KEY_ID": "AKIDENV", "AWS_SECRET_ACCESS_KEY": "ENV", "AWS_SESSION_TOKEN": "TOKEN"},
+			func(svc *Service) bool {
+				return svc.AccessKey == "AKIDENV" && svc.SecretKey == "ENV" && svc.SessToken == "TOKEN"
+			},
+		},
+		{
+			"AWS_REGION wins over AWS_DEFAULT_REGION",
+			map[string]string{"AWS_REGION": "us-west-2", "AWS_DEFAULT_REGION": "ap-south-1"},
+			func(svc *Service) bool { return svc.Region == "us-west-2" },
+		},
+		{
+			"AWS_DEFAULT_REGION",
+			map[string]string{"AWS_DEFAULT_REGION": "ap-south-1"},
+			func(svc *Service) bool { return svc.Region == "ap-south-1" },
+		},
+		{
+			"max attempts",
+			map[string]string{"AWS_MAX_ATTEMPTS": "7"},
+			func(svc *Service) bool { return svc.Retry != nil && svc.Retry.MaxAttempts == 7 },
+		},
+		{
+			"endpoint",
+			map[string]string{"AWS_ENDPOINT_URL": "http://localhost:9000"},
+			func(svc *Service) bool { return svc.Endpoint == "http://localhost:9000" },
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			vars := map[string]string{
+				"AWS_ENVIRONMENTS_FILE": writeConfig(t, testConfig),
+				"TEST_STAGING_KEY_ID":   "AKIDSTAGING",
+				"TEST_STAGING_SECRET":   "SECRET",
+			}
+			for name, value := range tt.vars {
+				vars[name] = value
+			}
+
+			setenv(t, vars)
+
+			svc, err := FromEnvironment("staging-eu")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !tt.want(svc) {
+				t.Errorf("FromEnvironment = %+v", svc)
+			}
+		})
+	}
+
+	setenv(t, map[string]string{"AWS_ENVIRONMENTS_FILE": writeConfig(t, testConfig), "AWS_MAX_ATTEMPTS": "0"})
+
+	if _, err := FromEnvironment("prod-us"); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("FromEnvironment with AWS_MAX_ATTEMPTS=0 = %v, want ErrInvalidConfig", err)
+	}
+}
+
+func TestFromEnvironmentDefaultTags(t *testing.T) {
+	api := newFakeAPI(t)
+	setenv(t, map[string]string{"AWS_ENVIRONMENTS_FILE": writeConfig(t, testConfig)})
+
+	svc, err := FromEnvironment("prod-us")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plan := NewPlan()
+	// The session of the environment, without its profile and roles.
+	sess := api.session(t, Service{Region: svc.Region, DefaultTags: svc.DefaultTags, DryRun: plan})
+
+	input := &sqs.CreateQueueInput{QueueName: aws.String("orders"), Tags: map[string]*string{"team": aws.String("orders")}}
+	if _, err := sqs.New(sess).CreateQueue(input); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(input.Tags) != 1 {
+		t.Errorf("the caller's input gained tags: %v", input.Tags)
+	}
+
+	var planned struct {
+		Tags map[string]string
+	}
+
+	if err := json.Unmarshal(plan.Calls()[0].Input, &planned); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := map[string]string{"team": "orders", "env": "prod"}; !reflect.DeepEqual(planned.Tags, want) {
+		t.Errorf("planned tags = %v, want %v", planned.Tags, want)
+	}
+}
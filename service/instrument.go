@@ -0,0 +1,103 @@
+package service
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+)
+
+// RequestRecord describes one completed AWS API call.
+type RequestRecord struct {
+	// Service is the client name, such as "s3" or "emr".
+	Service   string
+	Operation string
+	Region    string
+	// Latency covers every attempt and the waits between them.
+	Latency time.Duration
+	Retries int
+	// StatusCode is the HTTP status of the last attempt, or zero when no
+	// response was received.
+	StatusCode int
+	// ErrorCode is empty when the call succeeded.
+	ErrorCode string
+	// DryRun is set for the mutating calls held back by a dry run, and
+	// Cached for the calls answered by a ResponseCache. Neither reaches AWS.
+	DryRun bool
+	Cached bool
+}
+
+// Observer is told about every call made through a session.
+type Observer interface {
+	ObserveRequest(record RequestRecord)
+}
+
+// ObserverFunc adapts an ordinary function to the Observer interface.
+type ObserverFunc func(record RequestRecord)
+
+func (f ObserverFunc) ObserveRequest(record RequestRecord) {
+	f(record)
+}
+
+const observerHandlerName = "awsclients.Observe"
+
+func installObservers(sess *session.Session, observers []Observer) {
+	if len(observers) == 0 {
+		return
+	}
+
+	observers = append([]Observer(nil), observers...)
+
+	sess.Handlers.Complete.PushBackNamed(request.NamedHandler{
+		Name: observerHandlerName,
+		Fn: func(r *request.Request) {
+			record := newRequestRecord(r)
+
+			for _, observer := range observers {
+				observer.ObserveRequest(record)
+			}
+		},
+	})
+}
+
+// serviceName returns the client name of the service r is sent to.
+func serviceName(r *request.Request) string {
+	if alias, ok := endpointAliases[r.ClientInfo.ServiceName]; ok {
+		return alias
+	}
+
+	return r.ClientInfo.ServiceName
+}
+
+func newRequestRecord(r *request.Request) RequestRecord {
+	record := RequestRecord{
+		Service:   serviceName(r),
+		Operation: r.Operation.Name,
+		Region:    aws.StringValue(r.Config.Region),
+		Latency:   time.Since(r.Time),
+		Retries:   r.RetryCount,
+	}
+
+	switch skipReason(r) {
+	case skippedDryRun:
+		record.DryRun = true
+	case skippedCached:
+		record.Cached = true
+	default:
+		if r.HTTPResponse != nil {
+			record.StatusCode = r.HTTPResponse.StatusCode
+		}
+	}
+
+	if r.Error != nil {
+		record.ErrorCode = "Unknown"
+
+		if aerr, ok := r.Error.(awserr.Error); ok {
+			record.ErrorCode = aerr.Code()
+		}
+	}
+
+	return record
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sts"
+)
+
+// MFA exchanges the credentials of a session for temporary ones obtained
+// with STS GetSessionToken, for accounts whose policies require MFA.
+type MFA struct {
+	Serial string
+	// TokenProvider is called for a token code whenever new credentials
+	// are needed, and defaults to prompting on stdin.
+	TokenProvider func() (string, error)
+	// Duration defaults to the STS default of 12 hours.
+	Duration time.Duration
+}
+
+// withSessionToken returns a copy of sess using the temporary credentials
+// of svc.MFA, read from svc.CredentialCache while they are valid.
+func (svc *Service) withSessionToken(sess *session.Session, baseKeyID string) *session.Session {
+	if svc.MFA == nil {
+		return sess
+	}
+
+	mfa := *svc.MFA
+	if mfa.TokenProvider == nil {
+		mfa.TokenProvider = stscreds.StdinTokenProvider
+	}
+
+	stsCli := sts.New(sess)
+
+	fetch := func() (credentials.Value, time.Time, error) {
+		code, err := mfa.TokenProvider()
+		if err != nil {
+			return credentials.Value{}, time.Time{}, err
+		}
+
+		input := &sts.GetSessionTokenInput{
+			SerialNumber: aws.String(mfa.Serial),
+			TokenCode:    aws.String(code),
+		}
+
+		if mfa.Duration > 0 {
+			input.DurationSeconds = aws.Int64(int64(mfa.Duration / time.Second))
+		}
+
+		resp, err := stsCli.GetSessionToken(input)
+		if err != nil {
+			return credentials.Value{}, time.Time{}, err
+		}
+
+		return credentials.Value{
+			AccessKeyID:     aws.StringValue(resp.Credentials.AccessKeyId),
+			SecretAccessKey: aws.StringValue(resp.Credentials.SecretAccessKey),
+			SessionToken:    aws.StringValue(resp.Credentials.SessionToken),
+			ProviderName:    "MFASessionToken",
+		}, aws.TimeValue(resp.Credentials.Expiration), nil
+	}
+
+	key := CacheKey{Profile: svc.Profile, BaseAccessKeyID: baseKeyID, MFASerial: mfa.Serial}
+	creds := credentials.NewCredentials(svc.CredentialCache.Provider(key, roleExpiryWindow, fetch))
+
+	return sess.Copy(&aws.Config{Credentials: creds})
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+)
+
+// RateLimit is a token bucket: requests are sent at Rate per second on
+// average, with bursts of up to Burst requests.
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+// rateLimitHandlerName names the handler installed by installRateLimits.
+const rateLimitHandlerName = "awsclients.RateLimit"
+
+type tokenBucket struct {
+	rate  float64
+	burst float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(limit RateLimit) *tokenBucket {
+	burst := float64(limit.Burst)
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &tokenBucket{rate: limit.Rate, burst: burst, tokens: burst, last: time.Now()}
+}
+
+// wait takes a token, waiting for it when the bucket is empty. Tokens are
+// reserved in arrival order, so waiters are served first come, first served.
+func (b *tokenBucket) wait(ctx context.Context) error {
+	b.mu.Lock()
+
+	now := time.Now()
+
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+
+	b.last = now
+	b.tokens--
+	delay := time.Duration(-b.tokens / b.rate * float64(time.Second))
+
+	b.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		b.mu.Lock()
+		b.tokens++
+		b.mu.Unlock()
+
+		return ctx.Err()
+	}
+}
+
+// installRateLimits makes every attempt of every request of sess, and of
+// the clients and session copies made from it, take a token from the bucket
+// of its operation.
+func installRateLimits(sess *session.Session, limits map[string]RateLimit) {
+	if len(limits) == 0 {
+		return
+	}
+
+	buckets := make(map[string]*tokenBucket, len(limits))
+
+	for key, limit := range limits {
+		if limit.Rate > 0 {
+			buckets[key] = newTokenBucket(limit)
+		}
+	}
+
+	sess.Handlers.Sign.PushFrontNamed(request.NamedHandler{
+		Name: rateLimitHandlerName,
+		Fn: func(r *request.Request) {
+			bucket := bucketFor(buckets, r.ClientInfo.ServiceName, r.Operation.Name)
+			if bucket == nil {
+				return
+			}
+
+			if err := bucket.wait(r.Context()); err != nil {
+				r.Error = awserr.New(request.CanceledErrorCode, "request context canceled while rate limited", err)
+			}
+		},
+	})
+}
+
+func bucketFor(buckets map[string]*tokenBucket, service, operation string) *tokenBucket {
+	for _, key := range operationKeys(service, operation) {
+		if bucket, ok := buckets[key]; ok {
+			return bucket
+		}
+	}
+
+	return nil
+}
+
+// operationKeys returns the keys that settings of operation are looked up
+// by, most specific first: "service/Operation", then "service", with the
+// client name of the service as well as its SDK name.
+func operationKeys(service, operation string) []string {
+	names := []string{service}
+	if alias, ok := endpointAliases[service]; ok {
+		names = append(names, alias)
+	}
+
+	keys := make([]string, 0, 2*len(names))
+	for _, name := range names {
+		keys = append(keys, name+"/"+operation)
+	}
+
+	return append(keys, names...)
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/sqs"
+)
+
+func TestTokenBucketBlocks(t *testing.T) {
+	bucket := newTokenBucket(RateLimit{Rate: 20, Burst: 2})
+	start := time.Now()
+
+	for i := 0; i < 2; i++ {
+		if err := bucket.wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if elapsed := time.Since(start); elapsed > 20*time.Millisecond {
+		t.Errorf("the burst waited %v", elapsed)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := bucket.wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Two tokens past the burst take 2/20s to refill.
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("4 tokens at 20/s with a burst of 2 took %v, want about 100ms", elapsed)
+	}
+}
+
+func TestTokenBucketHonoursCancellation(t *testing.T) {
+	bucket := newTokenBucket(RateLimit{Rate: 1, Burst: 1})
+
+	if err := bucket.wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+
+	if err := bucket.wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("wait = %v, want the context error", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("a canceled wait returned after %v", elapsed)
+	}
+
+	// The canceled wait gave its reserved token back.
+	bucket.mu.Lock()
+	tokens := bucket.tokens
+	bucket.mu.Unlock()
+
+	if tokens < -0.1 {
+		t.Errorf("tokens = %v after the canceled wait, want about 0", tokens)
+	}
+}
+
+func TestRateLimitsApplyBySession(t *testing.T) {
+	api := newFakeAPI(t)
+	sqsCli := sqs.New(api.session(t, Service{RateLimits: map[string]RateLimit{"sqs/ListQueues": {Rate: 0.1, Burst: 1}}}))
+
+	if _, err := sqsCli.ListQueues(&sqs.ListQueuesInput{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Other operations have no bucket.
+	if _, err := sqsCli.CreateQueue(&sqs.CreateQueueInput{QueueName: aws.String("q")}); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := sqsCli.ListQueuesWithContext(ctx, &sqs.ListQueuesInput{})
+
+	var aerr awserr.Error
+	if !errors.As(err, &aerr) || aerr.Code() != request.CanceledErrorCode {
+		t.Fatalf("ListQueues past the limit = %v, want %s", err, request.CanceledErrorCode)
+	}
+
+	if n := api.count("ListQueues"); n != 1 {
+		t.Errorf("sent %d ListQueues, want the limited call held back", n)
+	}
+}
+
+func TestBucketForOperationKeys(t *testing.T) {
+	operation, service, alias := newTokenBucket(RateLimit{Rate: 1}), newTokenBucket(RateLimit{Rate: 1}), newTokenBucket(RateLimit{Rate: 1})
+	buckets := map[string]*tokenBucket{
+		"ecs/DescribeTasks": operation,
+		"ecs":               service,
+		"emr":               alias,
+	}
+
+	for _, tt := range []struct {
+		service, operation string
+		want               *tokenBucket
+	}{
+		{"ecs", "DescribeTasks", operation},
+		{"ecs", "ListTasks", service},
+		{"elasticmapreduce", "ListClusters", alias},
+		{"ec2", "DescribeInstances", nil},
+	} {
+		if got := bucketFor(buckets, tt.service, tt.operation); got != tt.want {
+			t.Errorf("bucketFor(%s, %s) picked the wrong bucket", tt.service, tt.operation)
+		}
+	}
+}
@@ -0,0 +1,309 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+)
+
+// ResponseCache keeps the responses of read-only calls for a while, so that
+// calls repeated with the same input, such as the pages read by
+// EC2Client.ListAllInstances, are answered without calling AWS. Concurrent
+// identical calls wait for the first one and share its response, and
+// mutating calls drop the responses of their service in their region. It is
+// safe for concurrent use.
+type ResponseCache struct {
+	// TTL applies to the Describe and List operations.
+	TTL time.Duration
+	// TTLs replace TTL for the operations they name, keyed like
+	// Service.RateLimits, and make other read-only operations, such as
+	// "sts/GetCallerIdentity", cached too. Zero TTLs turn caching off.
+	TTLs map[string]time.Duration
+	// Store keeps the responses, NewMemoryStore(DefaultMaxResponses) when
+	// nil.
+	Store ResponseStore
+
+	once        sync.Once
+	mu          sync.Mutex
+	flights     map[string]chan struct{}
+	generations map[string]uint64
+
+	hits, misses int64
+}
+
+// ResponseCacheStats counts the calls looked up in a ResponseCache. Calls
+// that shared the response of a concurrent one count as hits.
+type ResponseCacheStats struct {
+	Hits   int64
+	Misses int64
+}
+
+const (
+	responseCacheHandlerName      = "awsclients.ResponseCache"
+	responseInvalidateHandlerName = "awsclients.ResponseCacheInvalidate"
+)
+
+type bypassCacheKey struct{}
+
+// WithoutResponseCache returns a context whose calls are sent even when a
+// ResponseCache holds their response, which they replace. Waiters poll with
+// it, so they see the changes they wait for.
+func WithoutResponseCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, bypassCacheKey{}, true)
+}
+
+func bypassesCache(ctx context.Context) bool {
+	bypass, _ := ctx.Value(bypassCacheKey{}).(bool)
+
+	return bypass
+}
+
+func NewResponseCache(store ResponseStore, ttl time.Duration) *ResponseCache {
+	return &ResponseCache{TTL: ttl, Store: store}
+}
+
+func (c *ResponseCache) init() {
+	c.once.Do(func() {
+		if c.Store == nil {
+			c.Store = NewMemoryStore(DefaultMaxResponses)
+		}
+
+		c.flights = map[string]chan struct{}{}
+		c.generations = map[string]uint64{}
+	})
+}
+
+func (c *ResponseCache) Stats() ResponseCacheStats {
+	return ResponseCacheStats{Hits: atomic.LoadInt64(&c.hits), Misses: atomic.LoadInt64(&c.misses)}
+}
+
+// Invalidate drops the responses of service, a client name such as "ec2",
+// in region, or in every region when region is empty. Calls in flight do
+// not store their response.
+func (c *ResponseCache) Invalidate(service, region string) {
+	c.init()
+
+	c.mu.Lock()
+	c.generations[service]++
+	c.mu.Unlock()
+
+	prefix := service + "/"
+	if region != "" {
+		prefix += region + "/"
+	}
+
+	c.Store.Purge(prefix)
+}
+
+// ttl returns how long the responses of operation are kept.
+func (c *ResponseCache) ttl(service, operation string) time.Duration {
+	if Mutating(operation) {
+		return 0
+	}
+
+	for _, key := range operationKeys(service, operation) {
+		if ttl, ok := c.TTLs[key]; ok {
+			return ttl
+		}
+	}
+
+	if hasVerb(operation, "Describe") || hasVerb(operation, "List") {
+		return c.TTL
+	}
+
+	return 0
+}
+
+// key identifies the response of r by its service, region, operation and
+// input, and by the endpoint and credentials it is sent with, so sessions
+// of different accounts sharing a store do not see each other's responses.
+func (c *ResponseCache) key(r *request.Request) (string, bool) {
+	input, err := marshalInput(r.Params)
+	if err != nil {
+		return "", false
+	}
+
+	var accessKeyID string
+
+	if r.Config.Credentials != nil {
+		creds, err := r.Config.Credentials.GetWithContext(r.Context())
+		if err != nil {
+			return "", false
+		}
+
+		accessKeyID = creds.AccessKeyID
+	}
+
+	region := aws.StringValue(r.Config.Region)
+	if region == "" {
+		region = "global"
+	}
+
+	digest := sha256.New()
+	fmt.Fprintf(digest, "%s\n%s\n", r.ClientInfo.Endpoint, accessKeyID)
+	digest.Write(input)
+
+	return strings.Join([]string{serviceName(r), region, r.Operation.Name, hex.EncodeToString(digest.Sum(nil))}, "/"), true
+}
+
+// lookup answers r from the store, or lets it through to store its
+// response, after waiting for any identical call in flight. Calls made with
+// WithoutResponseCache are let through at once.
+func (c *ResponseCache) lookup(r *request.Request) {
+	// Presigned requests are built but not sent.
+	if r.Error != nil || r.ExpireTime != 0 || r.Data == nil {
+		return
+	}
+
+	ttl := c.ttl(serviceName(r), r.Operation.Name)
+	if ttl <= 0 {
+		return
+	}
+
+	key, ok := c.key(r)
+	if !ok {
+		return
+	}
+
+	c.init()
+
+	if bypassesCache(r.Context()) {
+		c.mu.Lock()
+		generation := c.generations[serviceName(r)]
+		c.mu.Unlock()
+
+		atomic.AddInt64(&c.misses, 1)
+		r.Handlers.Complete.PushBack(func(r *request.Request) {
+			c.store(r, key, ttl, generation)
+		})
+
+		return
+	}
+
+	for {
+		if c.serve(r, key) {
+			atomic.AddInt64(&c.hits, 1)
+
+			return
+		}
+
+		c.mu.Lock()
+
+		done, inFlight := c.flights[key]
+		if !inFlight {
+			done = make(chan struct{})
+			c.flights[key] = done
+			generation := c.generations[serviceName(r)]
+			c.mu.Unlock()
+
+			atomic.AddInt64(&c.misses, 1)
+			r.Handlers.Complete.PushBack(func(r *request.Request) {
+				c.store(r, key, ttl, generation)
+
+				c.mu.Lock()
+				delete(c.flights, key)
+				c.mu.Unlock()
+				close(done)
+			})
+
+			return
+		}
+
+		c.mu.Unlock()
+
+		select {
+		case <-done:
+		case <-r.Context().Done():
+			r.Error = awserr.New(request.CanceledErrorCode, "request context canceled while waiting for a cached response", r.Context().Err())
+
+			return
+		}
+	}
+}
+
+// serve sets the output of r to the response stored under key, and skips
+// sending r, when the response has not expired.
+func (c *ResponseCache) serve(r *request.Request, key string) bool {
+	response, ok := c.Store.Get(key)
+	if !ok || !time.Now().Before(response.Expires) {
+		return false
+	}
+
+	// Decode into a new output, so a response that no longer fits the
+	// output leaves r untouched.
+	output := reflect.New(reflect.TypeOf(r.Data).Elem())
+	if err := json.Unmarshal(response.Output, output.Interface()); err != nil {
+		return false
+	}
+
+	reflect.ValueOf(r.Data).Elem().Set(output.Elem())
+	skipSend(r, skippedCached)
+
+	return true
+}
+
+func (c *ResponseCache) store(r *request.Request, key string, ttl time.Duration, generation uint64) {
+	if r.Error != nil {
+		return
+	}
+
+	c.mu.Lock()
+	invalidated := c.generations[serviceName(r)] != generation
+	c.mu.Unlock()
+
+	if invalidated {
+		return
+	}
+
+	output, err := json.Marshal(r.Data)
+	if err != nil {
+		return
+	}
+
+	c.Store.Put(key, &CachedResponse{Output: output, Expires: time.Now().Add(ttl)})
+}
+
+// invalidate drops the responses made stale by r, a mutating call that was
+// sent, rather than planned by a dry run.
+func (c *ResponseCache) invalidate(r *request.Request) {
+	if r.Error != nil || !Mutating(r.Operation.Name) || skipReason(r) != notSkipped {
+		return
+	}
+
+	region := aws.StringValue(r.Config.Region)
+	if region == "" {
+		region = "global"
+	}
+
+	c.Invalidate(serviceName(r), region)
+}
+
+// installResponseCache answers the read-only calls of sess from cache. The
+// lookup runs once the Validate handlers passed, and cached responses skip
+// the rate limits, since they are not sent.
+func installResponseCache(sess *session.Session, cache *ResponseCache) {
+	if cache == nil {
+		return
+	}
+
+	sess.Handlers.Build.PushFrontNamed(request.NamedHandler{
+		Name: responseCacheHandlerName,
+		Fn:   cache.lookup,
+	})
+	sess.Handlers.Complete.PushBackNamed(request.NamedHandler{
+		Name: responseInvalidateHandlerName,
+		Fn:   cache.invalidate,
+	})
+}
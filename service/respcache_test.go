@@ -0,0 +1,335 @@
+package service
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/sts"
+)
+
+// fakeAPI answers the STS and SQS query calls used by the tests and counts
+// the calls it receives by action. Calls wait for release when it is set.
+type fakeAPI struct {
+	*httptest.Server
+
+	release chan struct{}
+
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+var fakeResponses = map[string]string{
+	"GetCallerIdentity": `<GetCallerIdentityResponse><GetCallerIdentityResult><Arn>arn:aws:iam::123456789012:user/u</Arn>` +
+		`<UserId>U</UserId><Account>123456789012</Account></GetCallerIdentityResult></GetCallerIdentityResponse>`,
+	"ListQueues":  `<ListQueuesResponse><ListQueuesResult><QueueUrl>https://sqs/q</QueueUrl></ListQueuesResult></ListQueuesResponse>`,
+	"CreateQueue": `<CreateQueueResponse><CreateQueueResult><QueueUrl>https://sqs/q</QueueUrl></CreateQueueResult></CreateQueueResponse>`,
+	"DeleteQueue": `<DeleteQueueResponse></DeleteQueueResponse>`,
+}
+
+func newFakeAPI(t *testing.T) *fakeAPI {
+	t.Helper()
+
+	api := &fakeAPI{calls: map[string]int{}}
+	api.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("parsing request: %v", err)
+		}
+
+		action := r.Form.Get("Action")
+
+		api.mu.Lock()
+		api.calls[action]++
+		api.mu.Unlock()
+
+		if api.release != nil {
+			<-api.release
+		}
+
+		w.Header().Set("Content-Type", "text/xml")
+		w.Write([]byte(fakeResponses[action]))
+	}))
+	t.Cleanup(api.Close)
+
+	return api
+}
+
+func (api *fakeAPI) count(action string) int {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+
+	return api.calls[action]
+}
+
+func (api *fakeAPI) session(t *testing.T, svc Service) *session.Session {
+	t.Helper()
+
+	svc.AccessKey, svc.SecretKey, svc.Endpoint = "AKID", "SECRET", api.URL
+	if svc.Region == "" {
+		svc.Region = "us-east-1"
+	}
+
+	sess, err := svc.NewSessionE()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return sess
+}
+
+func TestResponseCacheTTL(t *testing.T) {
+	cache := NewResponseCache(nil, time.Minute)
+	cache.TTLs = map[string]time.Duration{"sqs/ListQueues": 0, "ecs/DescribeTasks": time.Second, "route53": time.Hour}
+
+	for _, tt := range []struct {
+		service, operation string
+		want               time.Duration
+	}{
+		{"ec2", "DescribeInstances", time.Minute},
+		{"iam", "ListRoles", time.Minute},
+		{"sqs", "ListQueues", 0},
+		{"ecs", "DescribeTasks", time.Second},
+		{"route53", "GetHostedZone", time.Hour},
+		{"sts", "GetCallerIdentity", 0},
+		{"route53", "ChangeResourceRecordSets", 0},
+		{"ec2", "TerminateInstances", 0},
+	} {
+		if got := cache.ttl(tt.service, tt.operation); got != tt.want {
+			t.Errorf("ttl(%s, %s) = %v, want %v", tt.service, tt.operation, got, tt.want)
+		}
+	}
+}
+
+func TestResponseCacheExpiry(t *testing.T) {
+	api := newFakeAPI(t)
+	cache := NewResponseCache(nil, 0)
+	cache.TTLs = map[string]time.Duration{"sts/GetCallerIdentity": 50 * time.Millisecond}
+	stsCli := sts.New(api.session(t, Service{ResponseCache: cache}))
+
+	for i := 0; i < 2; i++ {
+		out, err := stsCli.GetCallerIdentity(&sts.GetCallerIdentityInput{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if aws.StringValue(out.Account) != "123456789012" {
+			t.Fatalf("call %d returned account %q", i, aws.StringValue(out.Account))
+		}
+	}
+
+	if n := api.count("GetCallerIdentity"); n != 1 {
+		t.Fatalf("sent %d calls before the TTL, want 1", n)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+
+	if _, err := stsCli.GetCallerIdentity(&sts.GetCallerIdentityInput{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := api.count("GetCallerIdentity"); n != 2 {
+		t.Errorf("sent %d calls after the TTL, want 2", n)
+	}
+
+	if stats := cache.Stats(); stats.Hits != 1 || stats.Misses != 2 {
+		t.Errorf("Stats = %+v, want 1 hit and 2 misses", stats)
+	}
+}
+
+func TestResponseCacheSendsConcurrentCallsOnce(t *testing.T) {
+	api := newFakeAPI(t)
+	api.release = make(chan struct{})
+
+	cache := NewResponseCache(nil, time.Minute)
+	sqsCli := sqs.New(api.session(t, Service{ResponseCache: cache}))
+
+	const callers = 8
+
+	var wg sync.WaitGroup
+
+	errs := make(chan error, callers)
+
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			out, err := sqsCli.ListQueues(&sqs.ListQueuesInput{})
+			if err == nil && len(out.QueueUrls) != 1 {
+				t.Errorf("ListQueues returned %d queues, want 1", len(out.QueueUrls))
+			}
+
+			errs <- err
+		}()
+	}
+
+	// Let every caller reach the cache before the first call returns.
+	time.Sleep(50 * time.Millisecond)
+	close(api.release)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if n := api.count("ListQueues"); n != 1 {
+		t.Errorf("sent %d identical calls, want 1", n)
+	}
+
+	if stats := cache.Stats(); stats.Hits != callers-1 || stats.Misses != 1 {
+		t.Errorf("Stats = %+v, want %d hits and 1 miss", stats, callers-1)
+	}
+}
+
+func TestResponseCachePurgedByMutatingCalls(t *testing.T) {
+	api := newFakeAPI(t)
+	cache := NewResponseCache(nil, time.Minute)
+
+	east := sqs.New(api.session(t, Service{Region: "us-east-1", ResponseCache: cache}))
+	west := sqs.New(api.session(t, Service{Region: "us-west-2", ResponseCache: cache}))
+
+	list := func() {
+		t.Helper()
+
+		for _, cli := range []*sqs.SQS{east, west} {
+			if _, err := cli.ListQueues(&sqs.ListQueuesInput{}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	list()
+	list()
+
+	if n := api.count("ListQueues"); n != 2 {
+		t.Fatalf("sent %d ListQueues, want one per region", n)
+	}
+
+	if _, err := east.CreateQueue(&sqs.CreateQueueInput{QueueName: aws.String("q")}); err != nil {
+		t.Fatal(err)
+	}
+
+	list()
+
+	if n := api.count("ListQueues"); n != 3 {
+		t.Errorf("sent %d ListQueues after CreateQueue in us-east-1, want us-east-1 only sent again", n)
+	}
+
+	if _, err := east.CreateQueue(&sqs.CreateQueueInput{QueueName: aws.String("q")}); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := api.count("CreateQueue"); n != 2 {
+		t.Errorf("sent %d CreateQueue, want mutating calls never cached", n)
+	}
+}
+
+func TestMemoryStoreEvictsLeastRecentlyUsed(t *testing.T) {
+	store := NewMemoryStore(2)
+	response := func(s string) *CachedResponse { return &CachedResponse{Output: []byte(`"` + s + `"`)} }
+
+	store.Put("ec2/us-east-1/DescribeA/1", response("a"))
+	store.Put("ec2/us-east-1/DescribeB/1", response("b"))
+
+	if _, ok := store.Get("ec2/us-east-1/DescribeA/1"); !ok {
+		t.Fatal("Get missed a response just put")
+	}
+
+	store.Put("ec2/us-east-1/DescribeC/1", response("c"))
+
+	if store.Len() != 2 {
+		t.Errorf("Len = %d, want the bound of 2", store.Len())
+	}
+
+	if _, ok := store.Get("ec2/us-east-1/DescribeB/1"); ok {
+		t.Error("the least recently used response was kept")
+	}
+
+	for _, key := range []string{"ec2/us-east-1/DescribeA/1", "ec2/us-east-1/DescribeC/1"} {
+		if _, ok := store.Get(key); !ok {
+			t.Errorf("%s was evicted", key)
+		}
+	}
+
+	store.Purge("ec2/us-east-1/DescribeA")
+
+	if _, ok := store.Get("ec2/us-east-1/DescribeA/1"); ok || store.Len() != 1 {
+		t.Errorf("Purge left %d responses, want 1", store.Len())
+	}
+}
+
+func TestFileStoreRoundTrip(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "responses")
+
+	store, err := NewFileStore(dir, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expires := time.Now().Add(time.Hour).Round(0).UTC()
+	keys := []string{"sqs/us-east-1/ListQueues/a", "sqs/us-west-2/ListQueues/b", "ec2/us-east-1/DescribeVpcs/c"}
+
+	for i, key := range keys[:2] {
+		store.Put(key, &CachedResponse{Output: []byte(fmt.Sprintf(`{"n":%d}`, i)), Expires: expires})
+	}
+
+	got, ok := store.Get(keys[0])
+	if !ok {
+		t.Fatal("Get missed a response just put")
+	}
+
+	if string(got.Output) != `{"n":0}` || !got.Expires.Equal(expires) {
+		t.Errorf("Get = %s expiring %v, want the response put", got.Output, got.Expires)
+	}
+
+	info, err := os.Stat(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if perm := info.Mode().Perm(); perm != 0700 {
+		t.Errorf("directory mode = %o, want 700", perm)
+	}
+
+	// keys[1] was used least recently.
+	old := time.Now().Add(-time.Hour)
+	os.Chtimes(store.path(keys[1]), old, old)
+	store.Put(keys[2], &CachedResponse{Output: []byte(`{}`), Expires: expires})
+
+	if _, ok := store.Get(keys[1]); ok {
+		t.Error("the least recently used response was kept past the bound")
+	}
+
+	// A second store on the directory, as in another process, shares the
+	// responses.
+	other, err := NewFileStore(dir, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := other.Get(keys[2]); !ok {
+		t.Error("another store on the directory missed the response")
+	}
+
+	other.Purge("sqs/")
+
+	if _, ok := store.Get(keys[0]); ok {
+		t.Error("Purge left the sqs response")
+	}
+
+	if _, ok := store.Get(keys[2]); !ok {
+		t.Error("Purge removed the ec2 response")
+	}
+}
@@ -0,0 +1,267 @@
+package service
+
+import (
+	"container/list"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ResponseStore keeps the responses of a ResponseCache. Keys are made of
+// "/"-separated segments, "service/region/Operation/digest", and stores
+// are safe for concurrent use. Failing stores act as if they were empty.
+type ResponseStore interface {
+	Get(key string) (*CachedResponse, bool)
+	Put(key string, response *CachedResponse)
+	// Purge removes the responses whose keys start with prefix.
+	Purge(prefix string)
+}
+
+// CachedResponse is the output of a call as JSON.
+type CachedResponse struct {
+	Output  json.RawMessage `json:"output"`
+	Expires time.Time       `json:"expires"`
+}
+
+// DefaultMaxResponses bounds the stores of the response caches created
+// without one.
+const DefaultMaxResponses = 1000
+
+// MemoryStore keeps responses in memory, evicting the least recently used
+// ones past its size.
+type MemoryStore struct {
+	maxEntries int
+
+	mu      sync.Mutex
+	entries *list.List
+	keys    map[string]*list.Element
+}
+
+type memoryEntry struct {
+	key      string
+	response *CachedResponse
+}
+
+// NewMemoryStore returns a store of up to maxEntries responses, or of any
+// number when maxEntries is zero.
+func NewMemoryStore(maxEntries int) *MemoryStore {
+	return &MemoryStore{maxEntries: maxEntries, entries: list.New(), keys: map[string]*list.Element{}}
+}
+
+func (s *MemoryStore) Get(key string) (*CachedResponse, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.keys[key]
+	if !ok {
+		return nil, false
+	}
+
+	s.entries.MoveToFront(e)
+
+	return e.Value.(*memoryEntry).response, true
+}
+
+func (s *MemoryStore) Put(key string, response *CachedResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if e, ok := s.keys[key]; ok {
+		e.Value.(*memoryEntry).response = response
+		s.entries.MoveToFront(e)
+
+		return
+	}
+
+	s.keys[key] = s.entries.PushFront(&memoryEntry{key: key, response: response})
+
+	for s.maxEntries > 0 && s.entries.Len() > s.maxEntries {
+		oldest := s.entries.Back()
+		s.entries.Remove(oldest)
+		delete(s.keys, oldest.Value.(*memoryEntry).key)
+	}
+}
+
+func (s *MemoryStore) Purge(prefix string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, e := range s.keys {
+		if strings.HasPrefix(key, prefix) {
+			s.entries.Remove(e)
+			delete(s.keys, key)
+		}
+	}
+}
+
+// Len returns the number of responses in the store, expired ones included.
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.entries.Len()
+}
+
+// FileStore keeps responses in files under a directory, one per key, so
+// processes share them. Reading a response marks it as used, and the least
+// recently used responses past the size of the store are removed. The
+// directory and its files are only accessible to their owner.
+type FileStore struct {
+	dir        string
+	maxEntries int
+}
+
+const responseFileExt = ".json"
+
+// DefaultResponseStoreDir returns ~/.aws/aws-go-clients/responses.
+func DefaultResponseStoreDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(home, ".aws", "aws-go-clients", "responses"), nil
+}
+
+// NewFileStore opens the store in dir, or DefaultResponseStoreDir when dir
+// is empty, keeping up to maxEntries responses, or any number when
+// maxEntries is zero.
+func NewFileStore(dir string, maxEntries int) (*FileStore, error) {
+	if dir == "" {
+		var err error
+
+		if dir, err = DefaultResponseStoreDir(); err != nil {
+			return nil, fmt.Errorf("service: response store: %w", err)
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("service: response store: %w", err)
+	}
+
+	return &FileStore{dir: dir, maxEntries: maxEntries}, nil
+}
+
+func (s *FileStore) path(key string) string {
+	return filepath.Join(s.dir, filepath.FromSlash(key)+responseFileExt)
+}
+
+func (s *FileStore) Get(key string) (*CachedResponse, bool) {
+	path := s.path(key)
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+
+	response := &CachedResponse{}
+	if err := json.Unmarshal(data, response); err != nil {
+		return nil, false
+	}
+
+	now := time.Now()
+	os.Chtimes(path, now, now)
+
+	return response, true
+}
+
+// Put writes the response to a temporary file renamed over the previous
+// one, so readers never see a partial response.
+func (s *FileStore) Put(key string, response *CachedResponse) {
+	data, err := json.Marshal(response)
+	if err != nil {
+		return
+	}
+
+	path := s.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return
+	}
+
+	file, err := ioutil.TempFile(filepath.Dir(path), ".tmp-")
+	if err != nil {
+		return
+	}
+
+	defer os.Remove(file.Name())
+
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+
+		return
+	}
+
+	if err := file.Close(); err != nil {
+		return
+	}
+
+	if err := os.Rename(file.Name(), path); err != nil {
+		return
+	}
+
+	if s.maxEntries > 0 {
+		s.evict()
+	}
+}
+
+func (s *FileStore) Purge(prefix string) {
+	for _, file := range s.files() {
+		if strings.HasPrefix(file.key, prefix) {
+			os.Remove(file.path)
+		}
+	}
+}
+
+// evict removes the least recently used responses past the size of the
+// store.
+func (s *FileStore) evict() {
+	files := s.files()
+	if len(files) <= s.maxEntries {
+		return
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].used.Before(files[j].used)
+	})
+
+	for _, file := range files[:len(files)-s.maxEntries] {
+		os.Remove(file.path)
+	}
+}
+
+type responseFile struct {
+	path string
+	key  string
+	used time.Time
+}
+
+func (s *FileStore) files() []responseFile {
+	var files []responseFile
+
+	filepath.Walk(s.dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || !strings.HasSuffix(path, responseFileExt) {
+			return nil
+		}
+
+		rel, err := filepath.Rel(s.dir, path)
+		if err != nil {
+			return nil
+		}
+
+		files = append(files, responseFile{
+			path: path,
+			key:  strings.TrimSuffix(filepath.ToSlash(rel), responseFileExt),
+			used: info.ModTime(),
+		})
+
+		return nil
+	})
+
+	return files
+}
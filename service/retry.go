@@ -0,0 +1,74 @@
+package service
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/client"
+	"github.com/aws/aws-sdk-go/aws/request"
+)
+
+// RetryPolicy controls how failed requests are retried. The delay doubles
+// with every attempt, starting from MinDelay and capped by MaxDelay, with
+// random jitter. Throttled requests start from at least MinThrottleDelay.
+type RetryPolicy struct {
+	// MaxAttempts counts the first attempt; 1 disables retries, and zero
+	// keeps the SDK default of client.DefaultRetryerMaxNumRetries retries.
+	MaxAttempts      int
+	MinDelay         time.Duration
+	MaxDelay         time.Duration
+	MinThrottleDelay time.Duration
+	// RetryableCodes are error codes retried on top of the errors the SDK
+	// already considers retryable, such as throttling and 5xx errors.
+	RetryableCodes []string
+}
+
+// Config returns the client config applying the policy, for use with a
+// single client:
+//
+//	iamCli := clients.NewIAM(sess, policy.Config())
+func (p RetryPolicy) Config() *aws.Config {
+	return request.WithRetryer(aws.NewConfig(), p.Retryer())
+}
+
+// Retryer returns the policy as an SDK retryer.
+func (p RetryPolicy) Retryer() request.Retryer {
+	retries := p.MaxAttempts - 1
+
+	switch {
+	case p.MaxAttempts == 0:
+		retries = client.DefaultRetryerMaxNumRetries
+	case retries < 0:
+		retries = 0
+	}
+
+	codes := make(map[string]bool, len(p.RetryableCodes))
+	for _, code := range p.RetryableCodes {
+		codes[code] = true
+	}
+
+	return policyRetryer{
+		DefaultRetryer: client.DefaultRetryer{
+			NumMaxRetries:    retries,
+			MinRetryDelay:    p.MinDelay,
+			MaxRetryDelay:    p.MaxDelay,
+			MinThrottleDelay: p.MinThrottleDelay,
+			MaxThrottleDelay: p.MaxDelay,
+		},
+		codes: codes,
+	}
+}
+
+type policyRetryer struct {
+	client.DefaultRetryer
+	codes map[string]bool
+}
+
+func (r policyRetryer) ShouldRetry(req *request.Request) bool {
+	if aerr, ok := req.Error.(awserr.Error); ok && r.codes[aerr.Code()] {
+		return true
+	}
+
+	return r.DefaultRetryer.ShouldRetry(req)
+}
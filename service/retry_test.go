@@ -0,0 +1,117 @@
+package service
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/client"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/sqs"
+)
+
+func TestRetryPolicyMaxAttempts(t *testing.T) {
+	for attempts, want := range map[int]int{
+		0:  client.DefaultRetryerMaxNumRetries,
+		1:  0,
+		5:  4,
+		-1: 0,
+	} {
+		if got := (RetryPolicy{MaxAttempts: attempts}).Retryer().MaxRetries(); got != want {
+			t.Errorf("MaxRetries with MaxAttempts %d = %d, want %d", attempts, got, want)
+		}
+	}
+}
+
+func TestRetryPolicyBackoffBounds(t *testing.T) {
+	policy := RetryPolicy{
+		MaxAttempts:      10,
+		MinDelay:         10 * time.Millisecond,
+		MaxDelay:         time.Second,
+		MinThrottleDelay: 200 * time.Millisecond,
+	}
+	retryer := policy.Retryer()
+
+	for _, tt := range []struct {
+		name     string
+		status   int
+		retries  int
+		min, max time.Duration
+	}{
+		// Each delay is 2^retries times a jittered delay in [min, 2*min),
+		// and falls back to [max/2, max) past MaxDelay.
+		{"first retry", http.StatusInternalServerError, 0, 10 * time.Millisecond, 20 * time.Millisecond},
+		{"third retry", http.StatusInternalServerError, 2, 40 * time.Millisecond, 80 * time.Millisecond},
+		{"capped", http.StatusInternalServerError, 8, 500 * time.Millisecond, time.Second},
+		{"throttled", http.StatusTooManyRequests, 0, 200 * time.Millisecond, 400 * time.Millisecond},
+		{"throttled capped", http.StatusTooManyRequests, 3, 500 * time.Millisecond, time.Second},
+	} {
+		r := &request.Request{
+			Error:        awserr.New("Failure", "failure", nil),
+			HTTPResponse: &http.Response{StatusCode: tt.status},
+			RetryCount:   tt.retries,
+		}
+
+		for i := 0; i < 50; i++ {
+			if delay := retryer.RetryRules(r); delay < tt.min || delay >= tt.max {
+				t.Errorf("%s: delay = %v, want within [%v, %v)", tt.name, delay, tt.min, tt.max)
+
+				break
+			}
+		}
+	}
+}
+
+func TestRetryPolicyRetryableCodes(t *testing.T) {
+	retryer := RetryPolicy{MaxAttempts: 3, RetryableCodes: []string{"ResourceInUse"}}.Retryer()
+
+	for code, want := range map[string]bool{
+		"ResourceInUse":        true,
+		"ValidationError":      false,
+		"Throttling":           true,
+		"RequestLimitExceeded": true,
+	} {
+		r := &request.Request{
+			Error:        awserr.New(code, code, nil),
+			HTTPResponse: &http.Response{StatusCode: http.StatusBadRequest},
+		}
+
+		if got := retryer.ShouldRetry(r); got != want {
+			t.Errorf("ShouldRetry(%s) = %v, want %v", code, got, want)
+		}
+	}
+}
+
+func TestRetryPolicyAttempts(t *testing.T) {
+	var calls int32
+
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer api.Close()
+
+	svc := Service{
+		Region:    "us-east-1",
+		AccessKey: "AKID",
+		SecretKey: "SECRET",
+		Endpoint:  api.URL,
+		Retry:     &RetryPolicy{MaxAttempts: 3, MinDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond, MinThrottleDelay: time.Millisecond},
+	}
+
+	sess, err := svc.NewSessionE()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := sqs.New(sess).ListQueues(&sqs.ListQueuesInput{}); err == nil {
+		t.Fatal("ListQueues succeeded against a failing endpoint")
+	}
+
+	if n := atomic.LoadInt32(&calls); n != 3 {
+		t.Errorf("sent %d attempts, want MaxAttempts of 3", n)
+	}
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sts"
+)
+
+// roleExpiryWindow is how long before expiry assumed role credentials are
+// refreshed.
+const roleExpiryWindow = 5 * time.Minute
+
+// Role is one hop of an assume-role chain.
+type Role struct {
+	ARN         string
+	ExternalID  string
+	SessionName string
+	// Duration defaults to 15 minutes. Chained hops are limited to one hour.
+	Duration time.Duration
+	// MFASerial is the serial number or ARN of the MFA device required by
+	// the role's trust policy. TokenProvider is called for a new token code
+	// on every refresh and defaults to prompting on stdin.
+	MFASerial     string
+	TokenProvider func() (string, error)
+}
+
+func (role Role) configure(p *stscreds.AssumeRoleProvider) {
+	p.RoleSessionName = role.SessionName
+	p.Duration = role.Duration
+	p.ExpiryWindow = roleExpiryWindow
+
+	if role.ExternalID != "" {
+		p.ExternalID = aws.String(role.ExternalID)
+	}
+
+	if role.MFASerial != "" {
+		p.SerialNumber = aws.String(role.MFASerial)
+		p.TokenProvider = role.TokenProvider
+
+		if p.TokenProvider == nil {
+			p.TokenProvider = stscreds.StdinTokenProvider
+		}
+	}
+}
+
+// assumeRoles returns a copy of sess using the credentials of the last role
+// of the chain. Each hop is assumed with the credentials of the previous
+// one, and every hop refreshes its credentials before they expire. The
+// credentials of hops requiring MFA are kept in svc.CredentialCache.
+func (svc *Service) assumeRoles(sess *session.Session, baseKeyID string) *session.Session {
+	for _, role := range svc.Roles {
+		var creds *credentials.Credentials
+
+		if role.MFASerial != "" && svc.CredentialCache != nil {
+			creds = svc.cachedRole(sess, role, baseKeyID)
+		} else {
+			creds = stscreds.NewCredentials(sess, role.ARN, role.configure)
+		}
+
+		sess = sess.Copy(&aws.Config{Credentials: creds})
+	}
+
+	return sess
+}
+
+func (svc *Service) cachedRole(sess *session.Session, role Role, baseKeyID string) *credentials.Credentials {
+	provider := &stscreds.AssumeRoleProvider{Client: sts.New(sess), RoleARN: role.ARN}
+	role.configure(provider)
+
+	fetch := func() (credentials.Value, time.Time, error) {
+		value, err := provider.Retrieve()
+		if err != nil {
+			return value, time.Time{}, err
+		}
+
+		return value, provider.ExpiresAt().Add(provider.ExpiryWindow), nil
+	}
+
+	key := CacheKey{Profile: svc.Profile, BaseAccessKeyID: baseKeyID, RoleARN: role.ARN, MFASerial: role.MFASerial}
+
+	return credentials.NewCredentials(svc.CredentialCache.Provider(key, roleExpiryWindow, fetch))
+}
@@ -1,6 +1,8 @@
 package service
 
 import (
+	"net/http"
+
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
@@ -12,10 +14,58 @@ type Service struct {
 	AccessKey string
 	SecretKey string
 	SessToken string
-	Session   *session.Session
+	// MFA, when set, replaces the credentials above with session
+	// credentials obtained with an MFA token.
+	MFA *MFA
+	// Roles are assumed in order on top of the credentials above.
+	Roles []Role
+	// CredentialCache keeps the credentials obtained with MFA across
+	// processes.
+	CredentialCache *CredentialCache
+	// Endpoint replaces the endpoint of every service, and Endpoints the
+	// endpoint of single services, keyed by client name such as "s3".
+	Endpoint         string
+	Endpoints        map[string]string
+	S3ForcePathStyle bool
+	DisableSSL       bool
+	// Retry applies to every client of the session, unless the client is
+	// created with a policy of its own.
+	Retry *RetryPolicy
+	// RateLimits are token buckets shared by every client of the session,
+	// keyed by "service/Operation", such as "ecs/DescribeTasks", or by
+	// service alone for one bucket shared by all its operations.
+	RateLimits map[string]RateLimit
+	// Observers are told about every call made through the session.
+	Observers []Observer
+	// DryRun, when set, collects the mutating calls of the session instead of
+	// sending them; they return an empty output.
+	DryRun *Plan
+	// Audit receives a record of every mutating call of the session, and
+	// AuditError the records Audit failed to store, which are otherwise
+	// written to the logger of the session config.
+	Audit      AuditSink
+	AuditError func(record AuditRecord, err error)
+	// DefaultTags are added to the tags of the resources created through the
+	// session; tags passed to the call win.
+	DefaultTags map[string]string
+	// ResponseCache answers the read-only calls of the session it has the
+	// responses of.
+	ResponseCache *ResponseCache
+	// HTTPClient sends every request of the session, such as the client of
+	// a recorder.Recorder.
+	HTTPClient *http.Client
+	// VerifyCredentials makes NewSessionE call STS GetCallerIdentity.
+	VerifyCredentials bool
+	Session           *session.Session
 }
 
 func (svc *Service) NewSession() *session.Session {
+	svc.Session = session.Must(svc.newSession())
+
+	return svc.Session
+}
+
+func (svc *Service) newSession() (*session.Session, error) {
 	var (
 		awsConfig   *aws.Config
 		sessOptions session.Options
@@ -78,7 +128,49 @@ func (svc *Service) NewSession() *session.Session {
 		}
 	}
 
-	svc.Session = session.Must(session.NewSessionWithOptions(sessOptions))
+	sessOptions.Config.MergeIn(svc.endpointConfig())
 
-	return svc.Session
+	if svc.Retry != nil {
+		sessOptions.Config.MergeIn(svc.Retry.Config())
+	}
+
+	if svc.HTTPClient != nil {
+		sessOptions.Config.HTTPClient = svc.HTTPClient
+	}
+
+	sess, err := session.NewSessionWithOptions(sessOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	installRateLimits(sess, svc.RateLimits)
+	installObservers(sess, svc.Observers)
+	installDryRun(sess, svc.DryRun)
+	installAudit(sess, svc.Audit, svc.AuditError)
+	installDefaultTags(sess, svc.DefaultTags)
+	installResponseCache(sess, svc.ResponseCache)
+
+	baseKeyID := svc.baseAccessKeyID(sess)
+
+	return svc.assumeRoles(svc.withSessionToken(sess, baseKeyID), baseKeyID), nil
+}
+
+// baseAccessKeyID returns the access key ID of the credentials of sess, for
+// the cache keys of the credentials obtained with them. It is empty without
+// a cache, and with a profile, whose name identifies the credentials and
+// whose role, if any, should not be assumed just to find the key.
+func (svc *Service) baseAccessKeyID(sess *session.Session) string {
+	switch {
+	case svc.CredentialCache == nil || svc.Profile != "":
+		return ""
+	case svc.AccessKey != "":
+		return svc.AccessKey
+	}
+
+	value, err := sess.Config.Credentials.Get()
+	if err != nil {
+		return ""
+	}
+
+	return value.AccessKeyID
 }
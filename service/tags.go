@@ -0,0 +1,137 @@
+package service
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+)
+
+const defaultTagsHandlerName = "awsclients.DefaultTags"
+
+var stringPtrType = reflect.TypeOf((*string)(nil))
+
+// installDefaultTags adds tags to the Tags of the inputs of the calls of
+// sess that create resources, such as rds.CreateDBClusterSnapshotInput or
+// sqs.CreateQueueInput. The handler runs before validation, so dry runs and
+// audit records show the tags.
+func installDefaultTags(sess *session.Session, tags map[string]string) {
+	if len(tags) == 0 {
+		return
+	}
+
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	sess.Handlers.Validate.PushFrontNamed(request.NamedHandler{
+		Name: defaultTagsHandlerName,
+		Fn: func(r *request.Request) {
+			if creates(r.Operation.Name) {
+				r.Params = withTags(r.Params, keys, tags)
+			}
+		},
+	})
+}
+
+// creates reports whether op creates resources: CreateRole and
+// CopyDBSnapshot do, CreateTags only tags existing ones.
+func creates(op string) bool {
+	if strings.HasSuffix(op, "Tags") {
+		return false
+	}
+
+	for _, verb := range []string{"Create", "Copy", "Run"} {
+		if hasVerb(op, verb) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// withTags returns params with the tags missing from its Tags field, either
+// a map of strings or a slice of structs with Key and Value strings. The
+// caller's input is left alone: the tags go into copies of the input and of
+// its Tags.
+func withTags(params interface{}, keys []string, tags map[string]string) interface{} {
+	v := reflect.ValueOf(params)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return params
+	}
+
+	field := v.Elem().FieldByName("Tags")
+	if !field.IsValid() || !field.CanSet() {
+		return params
+	}
+
+	var merged reflect.Value
+
+	switch t := field.Type(); {
+	case t.Kind() == reflect.Map && t.Key().Kind() == reflect.String && t.Elem() == stringPtrType:
+		merged = reflect.MakeMap(t)
+
+		for _, key := range field.MapKeys() {
+			merged.SetMapIndex(key, field.MapIndex(key))
+		}
+
+		for _, key := range keys {
+			if !merged.MapIndex(reflect.ValueOf(key)).IsValid() {
+				merged.SetMapIndex(reflect.ValueOf(key), reflect.ValueOf(aws.String(tags[key])))
+			}
+		}
+	case t.Kind() == reflect.Slice && isTagType(t.Elem()):
+		present := map[string]bool{}
+
+		for i := 0; i < field.Len(); i++ {
+			if tag := field.Index(i); !tag.IsNil() {
+				present[aws.StringValue(tag.Elem().FieldByName("Key").Interface().(*string))] = true
+			}
+		}
+
+		merged = reflect.MakeSlice(t, field.Len(), field.Len()+len(keys))
+		reflect.Copy(merged, field)
+
+		for _, key := range keys {
+			if present[key] {
+				continue
+			}
+
+			tag := reflect.New(t.Elem().Elem())
+			tag.Elem().FieldByName("Key").Set(reflect.ValueOf(aws.String(key)))
+			tag.Elem().FieldByName("Value").Set(reflect.ValueOf(aws.String(tags[key])))
+			merged = reflect.Append(merged, tag)
+		}
+	default:
+		return params
+	}
+
+	input := reflect.New(v.Elem().Type())
+	input.Elem().Set(v.Elem())
+	input.Elem().FieldByName("Tags").Set(merged)
+
+	return input.Interface()
+}
+
+// isTagType reports whether t is a pointer to a struct with *string Key and
+// Value fields, like *rds.Tag.
+func isTagType(t reflect.Type) bool {
+	if t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct {
+		return false
+	}
+
+	for _, name := range []string{"Key", "Value"} {
+		f, ok := t.Elem().FieldByName(name)
+		if !ok || f.Type != stringPtrType {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/defaults"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sts"
+)
+
+// ErrInvalidConfig is wrapped by the errors describing a bad configuration.
+var ErrInvalidConfig = errors.New("invalid service configuration")
+
+const verifyTimeout = 30 * time.Second
+
+func invalid(format string, args ...interface{}) error {
+	return fmt.Errorf("service: %w: %s", ErrInvalidConfig, fmt.Sprintf(format, args...))
+}
+
+// NewSessionE is NewSession returning an error instead of panicking. The
+// configuration is validated first, and when VerifyCredentials is set the
+// credentials are checked with STS GetCallerIdentity.
+func (svc *Service) NewSessionE() (*session.Session, error) {
+	if err := svc.Validate(); err != nil {
+		return nil, err
+	}
+
+	sess, err := svc.newSession()
+	if err != nil {
+		return nil, fmt.Errorf("service: creating session: %w", err)
+	}
+
+	region := aws.StringValue(sess.Config.Region)
+	if region == "" {
+		return nil, invalid("no region configured; set Region, AWS_REGION or the region of the profile")
+	}
+
+	if !validRegion(region) {
+		return nil, invalid("malformed region %q", region)
+	}
+
+	if svc.VerifyCredentials {
+		ctx, cancel := context.WithTimeout(context.Background(), verifyTimeout)
+		defer cancel()
+
+		_, err = sts.New(sess).GetCallerIdentityWithContext(ctx, &sts.GetCallerIdentityInput{})
+		if err != nil {
+			return nil, fmt.Errorf("service: verifying credentials: %w", err)
+		}
+	}
+
+	svc.Session = sess
+
+	return sess, nil
+}
+
+// Validate checks the configuration without creating a session or calling AWS.
+func (svc *Service) Validate() error {
+	if svc.Region != "" && !validRegion(svc.Region) {
+		return invalid("malformed region %q", svc.Region)
+	}
+
+	switch {
+	case svc.AccessKey != "" && svc.SecretKey == "":
+		return invalid("access key is set without a secret key")
+	case svc.AccessKey == "" && svc.SecretKey != "":
+		return invalid("secret key is set without an access key")
+	case svc.AccessKey == "" && svc.SessToken != "":
+		return invalid("session token is set without an access key and secret key")
+	}
+
+	if svc.AccessKey == "" && svc.Profile != "" {
+		found, err := profileExists(svc.Profile)
+		if err != nil {
+			return fmt.Errorf("service: reading shared config: %w", err)
+		}
+
+		if !found {
+			return invalid("profile %q not found in %s or %s", svc.Profile, sharedConfigFile(), sharedCredentialsFile())
+		}
+	}
+
+	if err := svc.validateEndpoints(); err != nil {
+		return err
+	}
+
+	if svc.MFA != nil && svc.MFA.Serial == "" {
+		return invalid("MFA without a device serial number")
+	}
+
+	for i, role := range svc.Roles {
+		if !strings.HasPrefix(role.ARN, "arn:") {
+			return invalid("role %d: malformed role ARN %q", i, role.ARN)
+		}
+	}
+
+	return nil
+}
+
+// regionFormat matches region names such as "eu-west-1" or "us-gov-east-1".
+// Only the format is checked: the region list of the SDK misses the regions
+// launched after its release.
+var regionFormat = regexp.MustCompile(`^[a-z]{2}(-[a-z]+)+-\d$`)
+
+func validRegion(region string) bool {
+	return regionFormat.MatchString(region)
+}
+
+func sharedConfigFile() string {
+	if name := os.Getenv("AWS_CONFIG_FILE"); name != "" {
+		return name
+	}
+
+	return defaults.SharedConfigFilename()
+}
+
+func sharedCredentialsFile() string {
+	if name := os.Getenv("AWS_SHARED_CREDENTIALS_FILE"); name != "" {
+		return name
+	}
+
+	return defaults.SharedCredentialsFilename()
+}
+
+// profileExists looks for the profile in the shared credentials file, where
+// sections are named after profiles, and in the shared config file, where
+// they are named "profile <name>" except for the default profile.
+func profileExists(profile string) (bool, error) {
+	found, err := hasSection(sharedCredentialsFile(), profile)
+	if err != nil || found {
+		return found, err
+	}
+
+	section := "profile " + profile
+	if profile == "default" {
+		section = profile
+	}
+
+	return hasSection(sharedConfigFile(), section)
+}
+
+func hasSection(filename, section string) (bool, error) {
+	f, err := os.Open(filename)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "[") || !strings.HasSuffix(line, "]") {
+			continue
+		}
+
+		if strings.Join(strings.Fields(line[1:len(line)-1]), " ") == section {
+			return true, nil
+		}
+	}
+
+	return false, scanner.Err()
+}
@@ -0,0 +1,241 @@
+package tagsearch
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/mwlng/aws-go-clients/arn"
+	"github.com/mwlng/aws-go-clients/clients"
+)
+
+// listers list the resources of each service with their tags, tagged or
+// not, through the service APIs.
+var listers = map[string]func(ctx context.Context, cs *clients.ClientSet, acct *account) ([]Resource, error){
+	"dynamodb":       listTables,
+	"ec2":            listInstances,
+	"ecr":            listRepositories,
+	"ecs":            listECS,
+	"lambda":         listFunctions,
+	"rds":            listRDS,
+	"s3":             listBuckets,
+	"secretsmanager": listSecrets,
+}
+
+func listInstances(ctx context.Context, cs *clients.ClientSet, acct *account) ([]Resource, error) {
+	instances, err := cs.EC2().ListAllInstancesWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make([]Resource, 0, len(instances))
+
+	for _, i := range instances {
+		tags := map[string]string{}
+		for _, t := range i.Tags {
+			tags[aws.StringValue(t.Key)] = aws.StringValue(t.Value)
+		}
+
+		resources = append(resources, newResource(arn.EC2Instance(acct.region, acct.id, aws.StringValue(i.InstanceId)).String(), tags))
+	}
+
+	return resources, nil
+}
+
+func listRDS(ctx context.Context, cs *clients.ClientSet, acct *account) ([]Resource, error) {
+	clusters, err := cs.RDS().ListDBClustersWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	instances, err := cs.RDS().ListDBInstancesWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make([]Resource, 0, len(clusters)+len(instances))
+
+	for _, c := range clusters {
+		tags := map[string]string{}
+		for _, t := range c.TagList {
+			tags[aws.StringValue(t.Key)] = aws.StringValue(t.Value)
+		}
+
+		resources = append(resources, newResource(aws.StringValue(c.DBClusterArn), tags))
+	}
+
+	for _, i := range instances {
+		tags := map[string]string{}
+		for _, t := range i.TagList {
+			tags[aws.StringValue(t.Key)] = aws.StringValue(t.Value)
+		}
+
+		resources = append(resources, newResource(aws.StringValue(i.DBInstanceArn), tags))
+	}
+
+	return resources, nil
+}
+
+func listECS(ctx context.Context, cs *clients.ClientSet, acct *account) ([]Resource, error) {
+	clusters, err := cs.ECS().ListClustersWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var resources []Resource
+
+	for _, c := range clusters {
+		tags := map[string]string{}
+		for _, t := range c.Tags {
+			tags[aws.StringValue(t.Key)] = aws.StringValue(t.Value)
+		}
+
+		resources = append(resources, newResource(aws.StringValue(c.ClusterArn), tags))
+
+		services, err := cs.ECS().ListServicesByClusterWithContext(ctx, c.ClusterName)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, s := range services {
+			tags := map[string]string{}
+			for _, t := range s.Tags {
+				tags[aws.StringValue(t.Key)] = aws.StringValue(t.Value)
+			}
+
+			resources = append(resources, newResource(aws.StringValue(s.ServiceArn), tags))
+		}
+	}
+
+	return resources, nil
+}
+
+func listRepositories(ctx context.Context, cs *clients.ClientSet, acct *account) ([]Resource, error) {
+	repos, err := cs.ECR().ListRepositoriesWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make([]Resource, 0, len(repos))
+
+	for _, r := range repos {
+		list, err := cs.ECR().ListTagsForResourceWithContext(ctx, r.RepositoryArn)
+		if err != nil {
+			return nil, err
+		}
+
+		tags := map[string]string{}
+		for _, t := range list {
+			tags[aws.StringValue(t.Key)] = aws.StringValue(t.Value)
+		}
+
+		resources = append(resources, newResource(aws.StringValue(r.RepositoryArn), tags))
+	}
+
+	return resources, nil
+}
+
+func listSecrets(ctx context.Context, cs *clients.ClientSet, acct *account) ([]Resource, error) {
+	secrets, err := cs.SecretsManager().ListAllSecretsWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make([]Resource, 0, len(secrets))
+
+	for _, s := range secrets {
+		tags := map[string]string{}
+		for _, t := range s.Tags {
+			tags[aws.StringValue(t.Key)] = aws.StringValue(t.Value)
+		}
+
+		resources = append(resources, newResource(aws.StringValue(s.ARN), tags))
+	}
+
+	return resources, nil
+}
+
+// listBuckets lists the buckets located in the region of cs, as the Tagging
+// API does.
+func listBuckets(ctx context.Context, cs *clients.ClientSet, acct *account) ([]Resource, error) {
+	resp, err := cs.S3().ListBucketsWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var resources []Resource
+
+	for _, b := range resp.Buckets {
+		region, err := cs.S3().GetBucketRegionWithContext(ctx, b.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		if region != acct.region {
+			continue
+		}
+
+		list, err := cs.S3().GetBucketTaggingWithContext(ctx, b.Name)
+		if err != nil && !clients.IsNotFound(err) {
+			return nil, err
+		}
+
+		tags := map[string]string{}
+		for _, t := range list {
+			tags[aws.StringValue(t.Key)] = aws.StringValue(t.Value)
+		}
+
+		bucket := arn.S3Bucket(aws.StringValue(b.Name))
+		bucket.Partition = arn.PartitionForRegion(acct.region)
+
+		resources = append(resources, newResource(bucket.String(), tags))
+	}
+
+	return resources, nil
+}
+
+func listTables(ctx context.Context, cs *clients.ClientSet, acct *account) ([]Resource, error) {
+	names, err := cs.DynamoDB().ListTablesWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make([]Resource, 0, len(names))
+
+	for _, name := range names {
+		table := arn.DynamoDBTable(acct.region, acct.id, aws.StringValue(name)).String()
+
+		list, err := cs.DynamoDB().ListTagsOfResourceWithContext(ctx, aws.String(table))
+		if err != nil {
+			return nil, err
+		}
+
+		tags := map[string]string{}
+		for _, t := range list {
+			tags[aws.StringValue(t.Key)] = aws.StringValue(t.Value)
+		}
+
+		resources = append(resources, newResource(table, tags))
+	}
+
+	return resources, nil
+}
+
+func listFunctions(ctx context.Context, cs *clients.ClientSet, acct *account) ([]Resource, error) {
+	functions, err := cs.Lambda().ListFunctionsWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make([]Resource, 0, len(functions))
+
+	for _, f := range functions {
+		list, err := cs.Lambda().ListTagsWithContext(ctx, f.FunctionArn)
+		if err != nil {
+			return nil, err
+		}
+
+		resources = append(resources, newResource(aws.StringValue(f.FunctionArn), aws.StringValueMap(list)))
+	}
+
+	return resources, nil
+}
@@ -0,0 +1,491 @@
+package tagsearch
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Expr is a condition on the tags of a resource.
+type Expr interface {
+	Match(tags map[string]string) bool
+	String() string
+}
+
+// Eq matches resources with the tag key set to value. Keys and values are
+// compared case-sensitively, as AWS does.
+func Eq(key, value string) Expr {
+	return eqExpr{key: key, value: value}
+}
+
+// Glob matches resources with a tag whose key and value match the patterns,
+// where "*" stands for any characters and "?" for one.
+func Glob(key, value string) Expr {
+	return globExpr{key: key, value: value}
+}
+
+// Exists matches resources with a tag whose key matches the pattern key,
+// whatever its value.
+func Exists(key string) Expr {
+	return existsExpr{key: key}
+}
+
+func Not(e Expr) Expr {
+	return notExpr{e}
+}
+
+// And matches resources matched by all of exprs, and every resource when
+// exprs is empty.
+func And(exprs ...Expr) Expr {
+	return andExpr(exprs)
+}
+
+// Or matches resources matched by any of exprs, and none when exprs is
+// empty.
+func Or(exprs ...Expr) Expr {
+	return orExpr(exprs)
+}
+
+type eqExpr struct{ key, value string }
+
+func (e eqExpr) Match(tags map[string]string) bool {
+	value, ok := tags[e.key]
+
+	return ok && value == e.value
+}
+
+func (e eqExpr) String() string {
+	return quote(e.key) + "=" + quote(e.value)
+}
+
+type globExpr struct{ key, value string }
+
+func (e globExpr) Match(tags map[string]string) bool {
+	for key, value := range tags {
+		if glob(e.key, key) && glob(e.value, value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (e globExpr) String() string {
+	return quote(e.key) + "=" + quote(e.value)
+}
+
+type existsExpr struct{ key string }
+
+func (e existsExpr) Match(tags map[string]string) bool {
+	for key := range tags {
+		if glob(e.key, key) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (e existsExpr) String() string {
+	return quote(e.key)
+}
+
+type notExpr struct{ e Expr }
+
+func (e notExpr) Match(tags map[string]string) bool {
+	return !e.e.Match(tags)
+}
+
+func (e notExpr) String() string {
+	if eq, ok := e.e.(eqExpr); ok {
+		return quote(eq.key) + "!=" + quote(eq.value)
+	}
+
+	if eq, ok := e.e.(globExpr); ok {
+		return quote(eq.key) + "!=" + quote(eq.value)
+	}
+
+	return "NOT " + group(e.e)
+}
+
+type andExpr []Expr
+
+func (e andExpr) Match(tags map[string]string) bool {
+	for _, sub := range e {
+		if !sub.Match(tags) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (e andExpr) String() string {
+	return join(e, " AND ")
+}
+
+type orExpr []Expr
+
+func (e orExpr) Match(tags map[string]string) bool {
+	for _, sub := range e {
+		if sub.Match(tags) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (e orExpr) String() string {
+	return join(e, " OR ")
+}
+
+func join(exprs []Expr, sep string) string {
+	parts := make([]string, len(exprs))
+	for i, e := range exprs {
+		parts[i] = group(e)
+	}
+
+	return strings.Join(parts, sep)
+}
+
+// group parenthesises the combinations nested in another expression.
+func group(e Expr) string {
+	switch e.(type) {
+	case andExpr, orExpr:
+		return "(" + e.String() + ")"
+	}
+
+	return e.String()
+}
+
+func quote(s string) string {
+	if s == "" || strings.ContainsAny(s, " \t\"()!=&|") {
+		return fmt.Sprintf("%q", s)
+	}
+
+	return s
+}
+
+// glob reports whether s matches pattern, where "*" stands for any
+// characters, "/" included, and "?" for one.
+func glob(pattern, s string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '*':
+			for i := len(s); i >= 0; i-- {
+				if glob(pattern[1:], s[i:]) {
+					return true
+				}
+			}
+
+			return false
+		case '?':
+			if s == "" {
+				return false
+			}
+
+			_, size := utf8.DecodeRuneInString(s)
+			pattern, s = pattern[1:], s[size:]
+		default:
+			if s == "" || pattern[0] != s[0] {
+				return false
+			}
+
+			pattern, s = pattern[1:], s[1:]
+		}
+	}
+
+	return s == ""
+}
+
+func hasWildcard(s string) bool {
+	return strings.ContainsAny(s, "*?")
+}
+
+// Parse reads a query such as
+//
+//	team=payments OR !cost-center
+//	env=prod* AND (team=payments OR team=billing) AND owner!=legacy
+//
+// A term is a tag key, which matches when the tag exists, or key=value,
+// which matches when the tag has that value; key!=value also matches when
+// the tag is missing. Keys and values may contain the wildcards "*" and "?"
+// and be double quoted. Terms combine with NOT (or "!"), AND (or "&&") and
+// OR (or "||"), which binds loosest, and with parentheses.
+func Parse(query string) (Expr, error) {
+	tokens, err := tokenize(query)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{query: query, tokens: tokens}
+
+	e, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.done() {
+		return nil, p.errorf("unexpected %q", p.peek().text)
+	}
+
+	return e, nil
+}
+
+type tokenKind int
+
+const (
+	tokenTerm tokenKind = iota
+	tokenAnd
+	tokenOr
+	tokenNot
+	tokenOpen
+	tokenClose
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	// key, op and value are the parts of a term; op is "", "=" or "!=".
+	key, op, value string
+}
+
+func tokenize(query string) ([]token, error) {
+	var tokens []token
+
+	s := query
+
+	for {
+		s = strings.TrimLeftFunc(s, unicode.IsSpace)
+		if s == "" {
+			return tokens, nil
+		}
+
+		switch {
+		case s[0] == '(':
+			tokens = append(tokens, token{kind: tokenOpen, text: "("})
+			s = s[1:]
+		case s[0] == ')':
+			tokens = append(tokens, token{kind: tokenClose, text: ")"})
+			s = s[1:]
+		case strings.HasPrefix(s, "&&"):
+			tokens = append(tokens, token{kind: tokenAnd, text: "&&"})
+			s = s[2:]
+		case strings.HasPrefix(s, "||"):
+			tokens = append(tokens, token{kind: tokenOr, text: "||"})
+			s = s[2:]
+		case s[0] == '!':
+			tokens = append(tokens, token{kind: tokenNot, text: "!"})
+			s = s[1:]
+		default:
+			t, rest, err := scanTerm(s)
+			if err != nil {
+				return nil, fmt.Errorf("tagsearch: %q: %w", query, err)
+			}
+
+			switch strings.ToUpper(t.text) {
+			case "AND":
+				t.kind = tokenAnd
+			case "OR":
+				t.kind = tokenOr
+			case "NOT":
+				t.kind = tokenNot
+			}
+
+			tokens = append(tokens, t)
+			s = rest
+		}
+	}
+}
+
+// scanTerm reads a term from the start of s and returns the rest.
+func scanTerm(s string) (token, string, error) {
+	t := token{kind: tokenTerm}
+	start := s
+
+	key, s, err := scanWord(s)
+	if err != nil {
+		return t, "", err
+	}
+
+	t.key = key
+
+	switch {
+	case strings.HasPrefix(s, "!="):
+		t.op, s = "!=", s[2:]
+	case strings.HasPrefix(s, "="):
+		t.op, s = "=", s[1:]
+	}
+
+	if t.op != "" {
+		if t.value, s, err = scanWord(s); err != nil {
+			return t, "", err
+		}
+	}
+
+	if t.key == "" && !strings.HasPrefix(start, `"`) {
+		return t, "", fmt.Errorf("missing tag key before %q", start)
+	}
+
+	t.text = start[:len(start)-len(s)]
+
+	return t, s, nil
+}
+
+// scanWord reads a key or value, quoted or ending at a space, parenthesis,
+// operator or "=".
+func scanWord(s string) (string, string, error) {
+	if strings.HasPrefix(s, `"`) {
+		var b strings.Builder
+
+		for i := 1; i < len(s); i++ {
+			switch s[i] {
+			case '\\':
+				if i+1 < len(s) {
+					i++
+					b.WriteByte(s[i])
+				}
+			case '"':
+				return b.String(), s[i+1:], nil
+			default:
+				b.WriteByte(s[i])
+			}
+		}
+
+		return "", "", fmt.Errorf("unterminated quote")
+	}
+
+	end := strings.IndexFunc(s, func(r rune) bool {
+		return unicode.IsSpace(r) || strings.ContainsRune("()=!&|", r)
+	})
+	if end < 0 {
+		end = len(s)
+	}
+
+	return s[:end], s[end:], nil
+}
+
+type parser struct {
+	query  string
+	tokens []token
+	pos    int
+}
+
+func (p *parser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("tagsearch: %q: %s", p.query, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) or() (Expr, error) {
+	var exprs []Expr
+
+	for {
+		e, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+
+		exprs = append(exprs, e)
+
+		if p.done() || p.peek().kind != tokenOr {
+			break
+		}
+
+		p.pos++
+	}
+
+	if len(exprs) == 1 {
+		return exprs[0], nil
+	}
+
+	return Or(exprs...), nil
+}
+
+func (p *parser) and() (Expr, error) {
+	var exprs []Expr
+
+	for {
+		e, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+
+		exprs = append(exprs, e)
+
+		if p.done() || p.peek().kind != tokenAnd {
+			break
+		}
+
+		p.pos++
+	}
+
+	if len(exprs) == 1 {
+		return exprs[0], nil
+	}
+
+	return And(exprs...), nil
+}
+
+func (p *parser) unary() (Expr, error) {
+	if p.done() {
+		return nil, p.errorf("unexpected end of query")
+	}
+
+	t := p.peek()
+	p.pos++
+
+	switch t.kind {
+	case tokenNot:
+		e, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+
+		return Not(e), nil
+	case tokenOpen:
+		e, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+
+		if p.done() || p.peek().kind != tokenClose {
+			return nil, p.errorf("missing )")
+		}
+
+		p.pos++
+
+		return e, nil
+	case tokenTerm:
+		return term(t), nil
+	}
+
+	return nil, p.errorf("unexpected %q", t.text)
+}
+
+func term(t token) Expr {
+	if t.op == "" {
+		return Exists(t.key)
+	}
+
+	var e Expr
+	if hasWildcard(t.key) || hasWildcard(t.value) {
+		e = Glob(t.key, t.value)
+	} else {
+		e = Eq(t.key, t.value)
+	}
+
+	if t.op == "!=" {
+		return Not(e)
+	}
+
+	return e
+}
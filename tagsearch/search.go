@@ -0,0 +1,242 @@
+// Package tagsearch finds resources by their tags across services:
+//
+//	query, err := tagsearch.Parse("team=payments OR !cost-center")
+//	resources, err := tagsearch.Search(ctx, cs, query, tagsearch.Options{})
+//
+// Searches use the Resource Groups Tagging API when it can answer them,
+// and the list and describe calls of each service otherwise.
+package tagsearch
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/mwlng/aws-go-clients/arn"
+	"github.com/mwlng/aws-go-clients/clients"
+)
+
+type Resource struct {
+	ARN     string `json:"arn"`
+	Service string `json:"service"`
+	// Type is the resource type within the service, such as "instance" or
+	// "cluster".
+	Type string            `json:"type"`
+	Tags map[string]string `json:"tags"`
+}
+
+type Source int
+
+const (
+	// Auto uses the Tagging API unless the query matches untagged
+	// resources, which the Tagging API does not return, such as
+	// "!cost-center". It falls back to the service APIs when the Tagging
+	// API fails.
+	Auto Source = iota
+	TaggingAPI
+	ServiceAPIs
+)
+
+// Services lists the services searched.
+var Services = []string{"dynamodb", "ec2", "ecr", "ecs", "lambda", "rds", "s3", "secretsmanager"}
+
+// taggingTypes are the Tagging API resource types of each service, the
+// ones the service APIs list.
+var taggingTypes = map[string][]string{
+	"dynamodb":       {"dynamodb:table"},
+	"ec2":            {"ec2:instance"},
+	"ecr":            {"ecr:repository"},
+	"ecs":            {"ecs:cluster", "ecs:service"},
+	"lambda":         {"lambda:function"},
+	"rds":            {"rds:cluster", "rds:db"},
+	"s3":             {"s3"},
+	"secretsmanager": {"secretsmanager:secret"},
+}
+
+const defaultConcurrency = 4
+
+type Options struct {
+	// Services limits the search to these services, all of Services when
+	// empty.
+	Services []string
+	Source   Source
+	// Concurrency bounds the services searched at once with the service
+	// APIs; it defaults to 4.
+	Concurrency int
+}
+
+// Error is returned by Search with the resources found when some services
+// could not be searched.
+type Error struct {
+	Failed map[string]error
+}
+
+func (e *Error) Error() string {
+	services := make([]string, 0, len(e.Failed))
+	for service := range e.Failed {
+		services = append(services, service)
+	}
+
+	sort.Strings(services)
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "tagsearch: %d services failed", len(services))
+
+	for _, service := range services {
+		fmt.Fprintf(&b, "; %s: %v", service, e.Failed[service])
+	}
+
+	return b.String()
+}
+
+// Search returns the resources in the region of cs whose tags match query,
+// sorted by ARN. When some services fail, the resources found in the
+// others are returned with an *Error.
+func Search(ctx context.Context, cs *clients.ClientSet, query Expr, opts Options) ([]Resource, error) {
+	services := opts.Services
+	if len(services) == 0 {
+		services = Services
+	}
+
+	for _, service := range services {
+		if _, ok := taggingTypes[service]; !ok {
+			return nil, fmt.Errorf("tagsearch: unsupported service %q", service)
+		}
+	}
+
+	source := opts.Source
+	if source == Auto && query.Match(map[string]string{}) {
+		source = ServiceAPIs
+	}
+
+	var (
+		resources []Resource
+		err       error
+	)
+
+	if source != ServiceAPIs {
+		resources, err = searchTaggingAPI(ctx, cs, services)
+		if err != nil && (source == TaggingAPI || ctx.Err() != nil) {
+			return nil, err
+		}
+	}
+
+	if source == ServiceAPIs || err != nil {
+		resources, err = searchServices(ctx, cs, services, opts.Concurrency)
+		if resources == nil {
+			return nil, err
+		}
+	}
+
+	matched := []Resource{}
+
+	for _, r := range resources {
+		if query.Match(r.Tags) {
+			matched = append(matched, r)
+		}
+	}
+
+	sort.Slice(matched, func(i, j int) bool { return matched[i].ARN < matched[j].ARN })
+
+	return matched, err
+}
+
+func searchTaggingAPI(ctx context.Context, cs *clients.ClientSet, services []string) ([]Resource, error) {
+	var types []string
+	for _, service := range services {
+		types = append(types, taggingTypes[service]...)
+	}
+
+	mappings, err := cs.Tagging().GetResourcesWithContext(ctx, types, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make([]Resource, 0, len(mappings))
+
+	for _, m := range mappings {
+		tags := map[string]string{}
+		for _, t := range m.Tags {
+			tags[aws.StringValue(t.Key)] = aws.StringValue(t.Value)
+		}
+
+		resources = append(resources, newResource(aws.StringValue(m.ResourceARN), tags))
+	}
+
+	return resources, nil
+}
+
+// newResource takes the service and type of the resource from its ARN.
+func newResource(s string, tags map[string]string) Resource {
+	r := Resource{ARN: s, Tags: tags}
+
+	if a, err := arn.Parse(s); err == nil {
+		r.Service, r.Type = a.Service, a.ResourceType
+	}
+
+	return r
+}
+
+// account is what the service APIs need to build the ARNs they do not
+// return.
+type account struct {
+	id     string
+	region string
+}
+
+func searchServices(ctx context.Context, cs *clients.ClientSet, services []string, concurrency int) ([]Resource, error) {
+	id, _, _, err := cs.STS().GetCallerIDWithContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("tagsearch: %w", err)
+	}
+
+	acct := &account{id: id, region: cs.Region()}
+
+	if concurrency <= 0 {
+		concurrency = defaultConcurrency
+	}
+
+	resources := []Resource{}
+	failed := map[string]error{}
+
+	var (
+		mu  sync.Mutex
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, concurrency)
+	)
+
+	for _, service := range services {
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func(service string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			found, err := listers[service](ctx, cs, acct)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if err != nil {
+				failed[service] = err
+
+				return
+			}
+
+			resources = append(resources, found...)
+		}(service)
+	}
+
+	wg.Wait()
+
+	if len(failed) > 0 {
+		return resources, &Error{Failed: failed}
+	}
+
+	return resources, nil
+}
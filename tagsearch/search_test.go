@@ -0,0 +1,119 @@
+package tagsearch_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/mwlng/aws-go-clients/clients"
+	"github.com/mwlng/aws-go-clients/clients/fakes"
+	"github.com/mwlng/aws-go-clients/service"
+	"github.com/mwlng/aws-go-clients/tagsearch"
+)
+
+// fakeClientSet backs the s3, dynamodb and sts clients of a ClientSet with
+// the fakes: buckets "web" and "logs" and tables "orders" and "sessions" in
+// Region, and bucket "eu" in another region.
+func fakeClientSet(t *testing.T) *clients.ClientSet {
+	t.Helper()
+
+	s3API := fakes.NewS3()
+	s3API.SetBucketTagging("web", map[string]string{"team": "payments", "env": "prod"})
+	s3API.AddBucket("logs")
+	s3API.SetBucketTagging("eu", map[string]string{"team": "payments"})
+	s3API.SetBucketRegion("eu", "eu-west-1")
+
+	dynamoAPI := fakes.NewDynamoDB()
+	for _, name := range []string{"orders", "sessions"} {
+		_, err := dynamoAPI.CreateTableWithContext(context.Background(), &dynamodb.CreateTableInput{
+			TableName: aws.String(name),
+			AttributeDefinitions: []*dynamodb.AttributeDefinition{
+				{AttributeName: aws.String("id"), AttributeType: aws.String(dynamodb.ScalarAttributeTypeS)},
+			},
+			KeySchema: []*dynamodb.KeySchemaElement{
+				{AttributeName: aws.String("id"), KeyType: aws.String(dynamodb.KeyTypeHash)},
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := dynamoAPI.SetTags("orders", map[string]string{"team": "payments"}); err != nil {
+		t.Fatal(err)
+	}
+
+	cs := clients.NewClientSet(&service.Service{Region: fakes.Region, AccessKey: "AKID", SecretKey: "SECRET"})
+	cs.SetClient("s3", clients.NewS3FromAPI(s3API))
+	cs.SetClient("dynamodb", clients.NewDynamoDBFromAPI(dynamoAPI))
+	cs.SetClient("sts", clients.NewSTSFromAPI(fakes.NewSTS()))
+
+	return cs
+}
+
+func search(t *testing.T, cs *clients.ClientSet, query string) []string {
+	t.Helper()
+
+	expr, err := tagsearch.Parse(query)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resources, err := tagsearch.Search(context.Background(), cs, expr, tagsearch.Options{
+		Services: []string{"dynamodb", "s3"},
+		Source:   tagsearch.ServiceAPIs,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	arns := make([]string, 0, len(resources))
+	for _, r := range resources {
+		arns = append(arns, r.ARN)
+	}
+
+	return arns
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func TestSearchServiceAPIs(t *testing.T) {
+	cs := fakeClientSet(t)
+
+	got := search(t, cs, "team=payments")
+	want := []string{
+		"arn:aws:dynamodb:us-east-1:123456789012:table/orders",
+		"arn:aws:s3:::web",
+	}
+
+	if !equal(got, want) {
+		t.Errorf("team=payments found %q, want %q", got, want)
+	}
+}
+
+func TestSearchServiceAPIsUntagged(t *testing.T) {
+	cs := fakeClientSet(t)
+
+	got := search(t, cs, "!team")
+	want := []string{
+		"arn:aws:dynamodb:us-east-1:123456789012:table/sessions",
+		"arn:aws:s3:::logs",
+	}
+
+	if !equal(got, want) {
+		t.Errorf("!team found %q, want %q", got, want)
+	}
+}